@@ -0,0 +1,75 @@
+package todev
+
+import (
+	"context"
+	"time"
+)
+
+// Activity entity types.
+const (
+	ActivityEntityRepo        = "repo"
+	ActivityEntityTask        = "task"
+	ActivityEntityContributor = "contributor"
+)
+
+// Activity actions.
+const (
+	ActivityActionCreate   = "create"
+	ActivityActionUpdate   = "update"
+	ActivityActionDelete   = "delete"
+	ActivityActionRestore  = "restore"
+	ActivityActionAttach   = "attach"
+	ActivityActionUnattach = "unattach"
+)
+
+// Activity represents a recorded change to a repo, one of its tasks or one of
+// its contributors. Unlike events, activities are never purged and serve as
+// the audit log of a repo.
+type Activity struct {
+	ID int `json:"id"`
+
+	// Repo the change was made in.
+	RepoID int `json:"repoID"`
+
+	// User who made the change. Zero if the user has been deleted.
+	UserID int `json:"userID"`
+
+	// Changed entity and the kind of change made to it.
+	EntityType string `json:"entityType"`
+	EntityID   int    `json:"entityID"`
+	Action     string `json:"action"`
+
+	// Values of the changed fields before and after the change. Before is
+	// empty for created entities and After is empty for deleted ones.
+	Before map[string]interface{} `json:"before"`
+	After  map[string]interface{} `json:"after"`
+
+	// Timestamp of the change.
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ActivityService represents a service for reading the activity log of repos.
+// Activities are recorded by the other services as changes are made.
+type ActivityService interface {
+	// Retrieves a list of activities of the repos the current user is a
+	// member of based on filter. Activities are returned from the newest to
+	// the oldest.
+	FindActivities(ctx context.Context, filter ActivityFilter) ([]*Activity, int, error)
+}
+
+// ActivityFilter represents a filter used by FindActivities().
+type ActivityFilter struct {
+	RepoID     *int    `json:"repoID"`
+	UserID     *int    `json:"userID"`
+	EntityType *string `json:"entityType"`
+	EntityID   *int    `json:"entityID"`
+	Action     *string `json:"action"`
+
+	// Restricts to a subset of results.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+
+	// Resumes listing after the item the cursor points to, as returned
+	// with the previous page. Cannot be combined with Offset.
+	Cursor string `json:"cursor"`
+}
@@ -2,14 +2,14 @@ package todev
 
 import (
 	"context"
-	"fmt"
 	"time"
 )
 
 // Authentication providers.
 const (
-	AuthSourceGitHub  = "github"
-	DefaultAvatarSize = 128
+	AuthSourceGitHub = "github"
+	AuthSourceGitLab = "gitlab"
+	AuthSourceOIDC   = "oidc"
 )
 
 // Auth represents a set of OAuth creadentials.
@@ -19,6 +19,13 @@ type Auth struct {
 	RefreshToken string    `json:"-"`
 	Expiry       time.Time `json:"-"`
 
+	// Time the tokens were found to be revoked by the provider because they
+	// could not be refreshed. Cleared when the user signs in again.
+	RevokedAt time.Time `json:"revokedAt"`
+
+	// URL of the user's avatar image as reported by the provider.
+	AvatarURL string `json:"avatarURL"`
+
 	// Timestamps of creation and last update.
 	UpdatedAt time.Time `json:"updatedAt"`
 	CreatedAt time.Time `json:"createdAt"`
@@ -49,15 +56,6 @@ func (a *Auth) Validate() error {
 	return nil
 }
 
-func (a *Auth) AvatarURL() string {
-	switch a.Source {
-	case AuthSourceGitHub:
-		return fmt.Sprintf("https://avatars1.githubusercontent.com/u/%s?s=%d", a.SourceID, DefaultAvatarSize)
-	default:
-		return ""
-	}
-}
-
 // AuthService represents a service for managing auths.
 type AuthService interface {
 	// Looks up authentication object by ID along with the associated object.
@@ -67,6 +65,11 @@ type AuthService interface {
 	// Retrieves authentication objects based on filter.
 	FindAuths(ctx context.Context, filter AuthFilter) ([]*Auth, int, error)
 
+	// Returns a valid access token for the auth, refreshing it through its
+	// provider if it has expired. Returns EUNAUTHORIZED and marks the auth as
+	// revoked if the provider rejects the refresh token.
+	AccessToken(ctx context.Context, id int) (string, error)
+
 	// Creates a new auth object.
 	CreateAuth(ctx context.Context, auth *Auth) error
 
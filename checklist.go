@@ -0,0 +1,53 @@
+package todev
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// Checklist constants.
+const (
+	MaxChecklistItems       = 50
+	MaxChecklistItemTextLen = 150
+)
+
+// ChecklistItem represents a single step of a task. Items are kept in order
+// and can be checked off one by one as the work progresses.
+type ChecklistItem struct {
+	ID     int `json:"id"`
+	TaskID int `json:"taskID"`
+
+	// Description of the step.
+	Text string `json:"text"`
+
+	// Position of the item within the checklist, starting from zero.
+	Position int `json:"position"`
+
+	// Set once the step is done.
+	IsChecked bool `json:"isChecked"`
+
+	// Timestamps for item creation and last update.
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Validate returns an error if the item contains invalid fields.
+func (i ChecklistItem) Validate() error {
+	if i.TaskID == 0 {
+		return Errorf(EINVALID, "Task ID required.")
+	} else if i.Text == "" {
+		return Errorf(EINVALID, "Checklist item text required.")
+	} else if utf8.RuneCountInString(i.Text) > MaxChecklistItemTextLen {
+		return Errorf(EINVALID, "Checklist item text too long.")
+	}
+	return nil
+}
+
+// ChecklistItemUpdate represents a set of fields to update on a checklist item.
+type ChecklistItemUpdate struct {
+	Text      *string `json:"text"`
+	IsChecked *bool   `json:"isChecked"`
+
+	// Moves the item to a new position. Other items are shifted to make room.
+	Position *int `json:"position"`
+}
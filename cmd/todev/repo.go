@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/http"
+)
+
+// ReposCommand represents a collection of repo listing commands.
+type ReposCommand struct {
+	Stdout io.Writer
+}
+
+// Run executes the "repos" subcommand.
+func (c *ReposCommand) Run(ctx context.Context, args []string) error {
+	var cmd string
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "ls":
+		return (&RepoListCommand{Stdout: c.Stdout}).Run(ctx, args)
+	case "", "-h", "help":
+		c.Usage()
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("todev repos %s: unknown command", cmd)
+	}
+}
+
+// Usage prints the help screen to STDOUT.
+func (c *ReposCommand) Usage() {
+	fmt.Fprintln(c.Stdout, `
+Manage the list of repos.
+
+Usage:
+
+	todev repos <command> [arguments]
+
+The commands are:
+
+	ls   list repos you own or contribute to
+`[1:])
+}
+
+// RepoCommand represents a collection of single repo commands.
+type RepoCommand struct {
+	Stdout io.Writer
+}
+
+// Run executes the "repo" subcommand.
+func (c *RepoCommand) Run(ctx context.Context, args []string) error {
+	var cmd string
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "create":
+		return (&RepoCreateCommand{Stdout: c.Stdout}).Run(ctx, args)
+	case "link":
+		return (&RepoLinkCommand{Stdout: c.Stdout}).Run(ctx, args)
+	case "import":
+		return (&RepoImportCommand{Stdout: c.Stdout}).Run(ctx, args)
+	case "", "-h", "help":
+		c.Usage()
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("todev repo %s: unknown command", cmd)
+	}
+}
+
+// Usage prints the help screen to STDOUT.
+func (c *RepoCommand) Usage() {
+	fmt.Fprintln(c.Stdout, `
+Manage a single repo.
+
+Usage:
+
+	todev repo <command> [arguments]
+
+The commands are:
+
+	create   create a new repo
+	link     link a repo to a GitHub repo
+	import   import open GitHub issues as tasks
+`[1:])
+}
+
+// RepoListCommand represents a command for listing repos.
+type RepoListCommand struct {
+	Stdout     io.Writer
+	ConfigPath string
+}
+
+// Run executes the "repos ls" command.
+func (c *RepoListCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("todev-repos-ls", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	config, err := loadConfig(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+	ctx = config.NewContext(ctx)
+
+	repoService := http.NewRepoService(config.Client())
+	repos, _, err := repoService.FindRepos(ctx, todev.RepoFilter{})
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tUPDATED")
+	for _, repo := range repos {
+		fmt.Fprintf(w, "%d\t%s\t%s\n",
+			repo.ID,
+			repo.Name,
+			repo.UpdatedAt.Local().Format(time.RFC3339),
+		)
+	}
+	return w.Flush()
+}
+
+// RepoCreateCommand represents a command for creating a repo.
+type RepoCreateCommand struct {
+	Stdout     io.Writer
+	ConfigPath string
+}
+
+// Run executes the "repo create" command.
+func (c *RepoCreateCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("todev-repo-create", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath)
+	fs.Usage = func() {
+		fmt.Fprintln(c.Stdout, "usage: todev repo create [-config PATH] NAME")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("repo name required")
+	}
+
+	config, err := loadConfig(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+	ctx = config.NewContext(ctx)
+
+	repo := &todev.Repo{Name: strings.Join(fs.Args(), " ")}
+	if err = http.NewRepoService(config.Client()).CreateRepo(ctx, repo); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.Stdout, "Repo %q created with ID %d.\n", repo.Name, repo.ID)
+	return nil
+}
+
+// RepoLinkCommand represents a command for linking a repo to a GitHub repo.
+type RepoLinkCommand struct {
+	Stdout     io.Writer
+	ConfigPath string
+}
+
+// Run executes the "repo link" command.
+func (c *RepoLinkCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("todev-repo-link", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath)
+	fs.Usage = func() {
+		fmt.Fprintln(c.Stdout, "usage: todev repo link [-config PATH] ID OWNER/NAME")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() != 2 {
+		return fmt.Errorf("repo ID and GitHub repo required")
+	}
+
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid repo ID: %q", fs.Arg(0))
+	}
+
+	config, err := loadConfig(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+	ctx = config.NewContext(ctx)
+
+	githubRepo := fs.Arg(1)
+	repo, err := http.NewRepoService(config.Client()).UpdateRepo(ctx, id, todev.RepoUpdate{GitHubRepo: &githubRepo})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.Stdout, "Repo %q linked to %s.\n", repo.Name, repo.GitHubRepo)
+	return nil
+}
+
+// RepoImportCommand represents a command for importing GitHub issues.
+type RepoImportCommand struct {
+	Stdout     io.Writer
+	ConfigPath string
+}
+
+// Run executes the "repo import" command.
+func (c *RepoImportCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("todev-repo-import", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath)
+	fs.Usage = func() {
+		fmt.Fprintln(c.Stdout, "usage: todev repo import [-config PATH] ID")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() != 1 {
+		return fmt.Errorf("repo ID required")
+	}
+
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid repo ID: %q", fs.Arg(0))
+	}
+
+	config, err := loadConfig(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+	ctx = config.NewContext(ctx)
+
+	result, err := http.NewRepoService(config.Client()).ImportIssues(ctx, id, nil)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.Stdout, "Imported %d new and %d updated issues.\n", result.Created, result.Updated)
+	return nil
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/http"
+)
+
+// TasksCommand represents a collection of task listing commands.
+type TasksCommand struct {
+	Stdout io.Writer
+}
+
+// Run executes the "tasks" subcommand.
+func (c *TasksCommand) Run(ctx context.Context, args []string) error {
+	var cmd string
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "ls":
+		return (&TaskListCommand{Stdout: c.Stdout}).Run(ctx, args)
+	case "", "-h", "help":
+		c.Usage()
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("todev tasks %s: unknown command", cmd)
+	}
+}
+
+// Usage prints the help screen to STDOUT.
+func (c *TasksCommand) Usage() {
+	fmt.Fprintln(c.Stdout, `
+Manage the list of tasks.
+
+Usage:
+
+	todev tasks <command> [arguments]
+
+The commands are:
+
+	ls   list tasks in a repo
+`[1:])
+}
+
+// TaskCommand represents a collection of single task commands.
+type TaskCommand struct {
+	Stdout io.Writer
+}
+
+// Run executes the "task" subcommand.
+func (c *TaskCommand) Run(ctx context.Context, args []string) error {
+	var cmd string
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "add":
+		return (&TaskAddCommand{Stdout: c.Stdout}).Run(ctx, args)
+	case "done":
+		return (&TaskDoneCommand{Stdout: c.Stdout}).Run(ctx, args)
+	case "assign":
+		return (&TaskAssignCommand{Stdout: c.Stdout}).Run(ctx, args)
+	case "", "-h", "help":
+		c.Usage()
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("todev task %s: unknown command", cmd)
+	}
+}
+
+// Usage prints the help screen to STDOUT.
+func (c *TaskCommand) Usage() {
+	fmt.Fprintln(c.Stdout, `
+Manage a single task.
+
+Usage:
+
+	todev task <command> [arguments]
+
+The commands are:
+
+	add      add a task to a repo
+	done     mark a task as completed
+	assign   give a task to a contributor
+`[1:])
+}
+
+// TaskListCommand represents a command for listing the tasks of a repo.
+type TaskListCommand struct {
+	Stdout     io.Writer
+	ConfigPath string
+}
+
+// Run executes the "tasks ls" command.
+func (c *TaskListCommand) Run(ctx context.Context, args []string) error {
+	var repoID int
+	var completed, pending bool
+	var label, sortBy string
+	fs := flag.NewFlagSet("todev-tasks-ls", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath)
+	fs.IntVar(&repoID, "repo", 0, "repo ID")
+	fs.BoolVar(&completed, "completed", false, "only list completed tasks")
+	fs.BoolVar(&pending, "pending", false, "only list uncompleted tasks")
+	fs.StringVar(&label, "label", "", "only list tasks with the label")
+	fs.StringVar(&sortBy, "sort", "", "sort order (due_at_asc, priority_desc, ...)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if repoID == 0 {
+		return fmt.Errorf("repo ID required")
+	} else if completed && pending {
+		return fmt.Errorf("cannot specify both -completed and -pending")
+	}
+
+	config, err := loadConfig(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+	ctx = config.NewContext(ctx)
+
+	filter := todev.TaskFilter{RepoID: &repoID, SortBy: sortBy}
+	if completed || pending {
+		filter.IsCompleted = &completed
+	}
+	if label != "" {
+		filter.Label = &label
+	}
+
+	tasks, _, err := http.NewTaskService(config.Client()).FindTasks(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tDONE\tPRIORITY\tDUE\tCONTRIBUTORS\tLABELS\tDESCRIPTION")
+	for _, task := range tasks {
+		done := "[ ]"
+		if task.IsCompleted {
+			done = "[x]"
+		}
+
+		contributorIDs := make([]string, len(task.ContributorIDs))
+		for i, id := range task.ContributorIDs {
+			contributorIDs[i] = strconv.Itoa(id)
+		}
+
+		due := "-"
+		if !task.DueAt.IsZero() {
+			due = task.DueAt.Local().Format(time.DateOnly)
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\t%s\t%s\n",
+			task.ID,
+			done,
+			task.Priority,
+			due,
+			strings.Join(contributorIDs, ","),
+			strings.Join(task.Labels, ","),
+			task.Description,
+		)
+	}
+	return w.Flush()
+}
+
+// TaskAddCommand represents a command for adding a task to a repo.
+type TaskAddCommand struct {
+	Stdout     io.Writer
+	ConfigPath string
+}
+
+// Run executes the "task add" command.
+func (c *TaskAddCommand) Run(ctx context.Context, args []string) error {
+	var repoID, priority int
+	var due, labels string
+	fs := flag.NewFlagSet("todev-task-add", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath)
+	fs.IntVar(&repoID, "repo", 0, "repo ID")
+	fs.IntVar(&priority, "priority", todev.TaskPriorityNone, "priority from 0 (none) to 3 (high)")
+	fs.StringVar(&due, "due", "", "due date (YYYY-MM-DD)")
+	fs.StringVar(&labels, "labels", "", "comma-separated list of labels")
+	fs.Usage = func() {
+		fmt.Fprintln(c.Stdout, "usage: todev task add [-config PATH] -repo ID [-priority N] [-due DATE] [-labels LIST] DESCRIPTION")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if repoID == 0 {
+		return fmt.Errorf("repo ID required")
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("task description required")
+	}
+
+	config, err := loadConfig(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+	ctx = config.NewContext(ctx)
+
+	task := &todev.Task{
+		RepoID:      repoID,
+		Description: strings.Join(fs.Args(), " "),
+		Priority:    priority,
+	}
+	if due != "" {
+		if task.DueAt, err = time.ParseInLocation(time.DateOnly, due, time.Local); err != nil {
+			return fmt.Errorf("invalid due date: %q", due)
+		}
+	}
+	if labels != "" {
+		task.Labels = strings.Split(labels, ",")
+	}
+	if err = http.NewTaskService(config.Client()).CreateTask(ctx, task); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.Stdout, "Task %d added.\n", task.ID)
+	return nil
+}
+
+// TaskDoneCommand represents a command for marking a task as completed.
+type TaskDoneCommand struct {
+	Stdout     io.Writer
+	ConfigPath string
+}
+
+// Run executes the "task done" command.
+func (c *TaskDoneCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("todev-task-done", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath)
+	fs.Usage = func() {
+		fmt.Fprintln(c.Stdout, "usage: todev task done [-config PATH] TASK_ID")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() != 1 {
+		return fmt.Errorf("exactly one task ID required")
+	}
+
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid task ID: %q", fs.Arg(0))
+	}
+
+	config, err := loadConfig(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+	ctx = config.NewContext(ctx)
+
+	// Move the task into the first terminal state of its repo's workflow.
+	taskService := http.NewTaskService(config.Client())
+	task, err := taskService.FindTaskByID(ctx, id)
+	if err != nil {
+		return err
+	} else if task.IsCompleted {
+		fmt.Fprintf(c.Stdout, "Task %d is already completed.\n", task.ID)
+		return nil
+	}
+
+	repo, err := http.NewRepoService(config.Client()).FindRepoByID(ctx, task.RepoID)
+	if err != nil {
+		return err
+	}
+	state := repo.TerminalState()
+	if state == nil {
+		return fmt.Errorf("repo %d has no terminal state", repo.ID)
+	} else if _, err = taskService.UpdateTask(ctx, id, todev.TaskUpdate{SetStatus: &state.ID}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.Stdout, "Task %d completed.\n", task.ID)
+	return nil
+}
+
+// TaskAssignCommand represents a command for giving a task to a contributor.
+type TaskAssignCommand struct {
+	Stdout     io.Writer
+	ConfigPath string
+}
+
+// Run executes the "task assign" command.
+func (c *TaskAssignCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("todev-task-assign", flag.ContinueOnError)
+	attachConfigFlags(fs, &c.ConfigPath)
+	fs.Usage = func() {
+		fmt.Fprintln(c.Stdout, "usage: todev task assign [-config PATH] TASK_ID CONTRIBUTOR_ID")
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	} else if fs.NArg() != 2 {
+		return fmt.Errorf("task ID and contributor ID required")
+	}
+
+	taskID, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("invalid task ID: %q", fs.Arg(0))
+	}
+	contributorID, err := strconv.Atoi(fs.Arg(1))
+	if err != nil {
+		return fmt.Errorf("invalid contributor ID: %q", fs.Arg(1))
+	}
+
+	config, err := loadConfig(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+	ctx = config.NewContext(ctx)
+
+	task := &todev.Task{ID: taskID}
+	if err = http.NewTaskService(config.Client()).AttachContributor(ctx, task, contributorID); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.Stdout, "Task %d assigned to contributor %d.\n", taskID, contributorID)
+	return nil
+}
@@ -10,12 +10,17 @@ import (
 	"os/user"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/rollbar/rollbar-go"
 	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/github"
+	"github.com/saiddis/todev/gitlab"
 	"github.com/saiddis/todev/http"
 	"github.com/saiddis/todev/inmem"
+	"github.com/saiddis/todev/oidc"
 	"github.com/saiddis/todev/postgres"
+	"github.com/saiddis/todev/smtp"
 	"github.com/spf13/viper"
 )
 
@@ -24,6 +29,12 @@ const (
 	DefaultConfigPath = "~/todev/config.yaml"
 )
 
+// Event service names available in the configuration.
+const (
+	EventServiceInmem    = "inmem"
+	EventServicePostgres = "postgres"
+)
+
 // Build version, injected during build.
 var (
 	version string
@@ -80,12 +91,16 @@ type Main struct {
 	// PostgreSQL database used by PostgresSQl service implementation.
 	DB *postgres.Conn
 
+	// Event service shared across instances. Only set if enabled in config.
+	EventService *postgres.EventService
+
 	// HTTP server for handling HTTP communication.
 	// PostgresSQL services are attached to it before running.
 	HTTPServer *http.Server
 
 	// Services exposed for end-to-end tests.
-	UserService todev.UserService
+	UserService    todev.UserService
+	SessionService todev.SessionService
 }
 
 func NewMain() *Main {
@@ -99,6 +114,8 @@ func (m *Main) Run(ctx context.Context) (err error) {
 	// Initialize server and database.
 	m.HTTPServer = http.NewServer()
 	m.DB = postgres.New(m.Config.DB.DSN)
+	m.DB.EventRetention = m.Config.Events.Retention
+	m.DB.TrashRetention = m.Config.Trash.Retention
 
 	// Initialize error tracking
 	if m.Config.Rollbar.Token != "" {
@@ -111,11 +128,6 @@ func (m *Main) Run(ctx context.Context) (err error) {
 		log.Print("rollbar error tracking enabled")
 	}
 
-	// Initialize event service for real-time events.
-	eventService := inmem.NewEventService()
-
-	m.DB.EventService = eventService
-
 	if m.DB.DSN, err = expand(m.Config.DB.DSN); err != nil {
 		return fmt.Errorf("error expanding dsn: %w", err)
 	}
@@ -124,30 +136,78 @@ func (m *Main) Run(ctx context.Context) (err error) {
 		return fmt.Errorf("error openning db: %w", err)
 	}
 
+	// Initialize event service for real-time events. The in-memory service
+	// only delivers events within this process so deployments running
+	// multiple instances should use the postgres one.
+	var eventService todev.EventService
+	switch m.Config.Events.Service {
+	case "", EventServiceInmem:
+		eventService = inmem.NewEventService()
+	case EventServicePostgres:
+		m.EventService = postgres.NewEventService(m.DB, inmem.NewEventService())
+		if err = m.EventService.Open(); err != nil {
+			return fmt.Errorf("error opening event service: %w", err)
+		}
+		eventService = m.EventService
+	default:
+		return fmt.Errorf("unknown event service: %q", m.Config.Events.Service)
+	}
+
+	m.DB.EventService = eventService
+
 	// Initialize PostgreSQL-backed services.
 	authService := postgres.NewAuthService(m.DB)
 	repoService := postgres.NewRepoService(m.DB)
 	contributorService := postgres.NewContrubutorService(m.DB)
 	taskService := postgres.NewTaskService(m.DB)
+	commentService := postgres.NewCommentService(m.DB)
 	userService := postgres.NewUserService(m.DB)
+	tokenService := postgres.NewTokenService(m.DB)
+	sessionService := postgres.NewSessionService(m.DB)
+	eventLogService := postgres.NewEventLogService(m.DB)
+	activityService := postgres.NewActivityService(m.DB)
 
-	// Attach user service Main for testing.
+	// Attach user and session services to Main for testing.
 	m.UserService = userService
+	m.SessionService = sessionService
 
 	// Copy configuration settings to the HTTP server.
 	m.HTTPServer.Addr = m.Config.HTTP.Addr
 	m.HTTPServer.Domain = m.Config.HTTP.Domain
 	m.HTTPServer.HashKey = m.Config.HTTP.HashKey
 	m.HTTPServer.BlockKey = m.Config.HTTP.BlockKey
-	m.HTTPServer.GitHubClientID = m.Config.Github.ClientID
-	m.HTTPServer.GitHubClientSecret = m.Config.Github.ClientSecret
+	m.HTTPServer.GitHubWebhookSecret = m.Config.Github.WebhookSecret
 
 	m.HTTPServer.AuthService = authService
 	m.HTTPServer.RepoService = repoService
 	m.HTTPServer.ContributorService = contributorService
 	m.HTTPServer.UserService = userService
+	m.HTTPServer.TokenService = tokenService
+	m.HTTPServer.SessionService = sessionService
 	m.HTTPServer.TaskService = taskService
+	m.HTTPServer.CommentService = commentService
 	m.HTTPServer.EventService = eventService
+	m.HTTPServer.EventLogService = eventLogService
+	m.HTTPServer.ActivityService = activityService
+	m.HTTPServer.IssueService = github.NewIssueService()
+
+	if m.HTTPServer.OAuthProviders, err = m.oauthProviders(ctx); err != nil {
+		return err
+	}
+	authService.OAuthProviders = m.HTTPServer.OAuthProviders
+
+	// Enable local accounts. Without SMTP server, verification and password
+	// reset links are written to the log for the administrator to pass on.
+	if m.Config.Local.Enabled {
+		m.HTTPServer.PasswordService = postgres.NewPasswordService(m.DB)
+		if c := m.Config.SMTP; c.Addr != "" {
+			mailer := smtp.NewMailer(c.Addr, c.From)
+			mailer.Username, mailer.Password = c.Username, c.Password
+			m.HTTPServer.Mailer = mailer
+		} else {
+			m.HTTPServer.Mailer = &logMailer{}
+		}
+	}
 
 	// Start HTTP server.
 	if err = m.HTTPServer.Open(); err != nil {
@@ -177,6 +237,12 @@ func (m *Main) Close() error {
 		}
 	}
 
+	if m.EventService != nil {
+		if err := m.EventService.Close(); err != nil {
+			return err
+		}
+	}
+
 	if m.DB != nil {
 		if err := m.DB.Close(); err != nil {
 			return err
@@ -215,6 +281,21 @@ type Config struct {
 		DSN string `mapstructure:"dsn"`
 	} `mapstructure:"db"`
 
+	Events struct {
+		// Either "inmem" (default) or "postgres".
+		Service string `mapstructure:"service"`
+
+		// Duration events are kept for replay (e.g. "168h"). Zero keeps
+		// events forever.
+		Retention time.Duration `mapstructure:"retention"`
+	} `mapstructure:"events"`
+
+	Trash struct {
+		// Duration trashed tasks and repos are kept before being
+		// permanently deleted (e.g. "720h"). Zero keeps them forever.
+		Retention time.Duration `mapstructure:"retention"`
+	} `mapstructure:"trash"`
+
 	HTTP struct {
 		Addr     string `mapstructure:"addr"`
 		Domain   string `mapstructure:"domain"`
@@ -229,13 +310,87 @@ type Config struct {
 	Github struct {
 		ClientID     string `mapstructure:"client_id"`
 		ClientSecret string `mapstructure:"client_secret"`
+
+		// Secret shared with GitHub to sign webhook payloads.
+		WebhookSecret string `mapstructure:"webhook_secret"`
 	} `mapstructure:"github"`
 
+	Gitlab struct {
+		// Base URL of a self-hosted GitLab instance. Uses gitlab.com if empty.
+		URL string `mapstructure:"url"`
+
+		ClientID     string `mapstructure:"client_id"`
+		ClientSecret string `mapstructure:"client_secret"`
+	} `mapstructure:"gitlab"`
+
+	OIDC struct {
+		// Name of the provider shown on the login page.
+		Name string `mapstructure:"name"`
+
+		// Issuer the endpoints are discovered from.
+		Issuer string `mapstructure:"issuer"`
+
+		ClientID     string   `mapstructure:"client_id"`
+		ClientSecret string   `mapstructure:"client_secret"`
+		Scopes       []string `mapstructure:"scopes"`
+	} `mapstructure:"oidc"`
+
+	Local struct {
+		// Enables registration and login with email and password.
+		Enabled bool `mapstructure:"enabled"`
+	} `mapstructure:"local"`
+
+	SMTP struct {
+		// Address of the SMTP server (e.g. "mail.example.com:587").
+		Addr     string `mapstructure:"addr"`
+		Username string `mapstructure:"username"`
+		Password string `mapstructure:"password"`
+
+		// Address mails are sent from.
+		From string `mapstructure:"from"`
+	} `mapstructure:"smtp"`
+
 	Rollbar struct {
 		Token string `mapstructure:"token"`
 	} `mapstructure:"rollbar"`
 }
 
+// oauthProviders returns the login providers enabled in the configuration.
+// A provider is enabled once its client ID is set.
+func (m *Main) oauthProviders(ctx context.Context) ([]todev.OAuthProvider, error) {
+	var providers []todev.OAuthProvider
+
+	if c := m.Config.Github; c.ClientID != "" {
+		providers = append(providers, github.NewOAuthProvider(c.ClientID, c.ClientSecret))
+	}
+
+	if c := m.Config.Gitlab; c.ClientID != "" {
+		p := gitlab.NewOAuthProvider(c.ClientID, c.ClientSecret)
+		p.URL = c.URL
+		providers = append(providers, p)
+	}
+
+	if c := m.Config.OIDC; c.ClientID != "" {
+		p := oidc.NewOAuthProvider(c.Issuer, c.ClientID, c.ClientSecret)
+		p.DisplayName = c.Name
+		p.Scopes = c.Scopes
+		if err := p.Open(ctx); err != nil {
+			return nil, fmt.Errorf("error opening oidc provider: %w", err)
+		}
+		providers = append(providers, p)
+	}
+
+	return providers, nil
+}
+
+// logMailer implements todev.Mailer by writing mails to the log.
+type logMailer struct{}
+
+func (m *logMailer) SendMail(ctx context.Context, mail *todev.Mail) error {
+	log.Printf("mail to %s: %s\n%s", mail.To, mail.Subject, mail.Body)
+	return nil
+}
+
 // ReadConfigFile unmarshals configs from a config file.
 func ReadConfigFile(filename string) (Config, error) {
 	var config Config
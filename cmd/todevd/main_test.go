@@ -53,10 +53,17 @@ func MustCreateUser(tb testing.TB, m *main.Main, user *todev.User) (*todev.User,
 // Login returns a Chrome action that generates a secure cookie and attaches it
 // to the browser. This approach is used to avoid OAuth communication with GitHub.
 func Login(ctx context.Context, m *main.Main) chromedp.Action {
+	userCtx := ctx
 	return chromedp.ActionFunc(func(ctx context.Context) error {
+		// Create a server-side session for the user.
+		session := &todev.Session{UserID: todev.UserIDFromContext(userCtx)}
+		if err := m.SessionService.CreateSession(userCtx, session); err != nil {
+			return fmt.Errorf("error creating session: %v", err)
+		}
+
 		// Generate cookie value from the server.
 		value, err := m.HTTPServer.MarshalSession(http.Session{
-			UserID: todev.UserIDFromContext(ctx),
+			Secret: session.Secret,
 		})
 		if err != nil {
 			return fmt.Errorf("error marshalling session: %v", err)
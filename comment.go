@@ -0,0 +1,86 @@
+package todev
+
+import (
+	"context"
+	"time"
+	"unicode/utf8"
+)
+
+// Comment constants.
+const (
+	MaxCommentBodyLen = 2000
+)
+
+// Comment represents a message left by a contributor on a task.
+type Comment struct {
+	ID int `json:"id"`
+
+	// Task the comment was left on and the repo the task belongs to.
+	TaskID int `json:"taskID"`
+	RepoID int `json:"repoID"`
+
+	// Author of the comment.
+	UserID int   `json:"userID"`
+	User   *User `json:"user"`
+
+	// Text of the comment.
+	Body string `json:"body"`
+
+	// Timestamps for comment creation and last update.
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Validate returns an error if the comment contains invalid fields.
+func (c Comment) Validate() error {
+	if c.TaskID == 0 {
+		return Errorf(EINVALID, "Task ID required.")
+	} else if len(c.Body) == 0 {
+		return Errorf(EINVALID, "Comment body required.")
+	} else if utf8.RuneCountInString(c.Body) > MaxCommentBodyLen {
+		return Errorf(EINVALID, "Comment body too long.")
+	}
+	return nil
+}
+
+// CanDeleteComment returns true if a user with the given role within the
+// comment's repo can delete it. Authors can delete their own comments while
+// repo admins can delete any comment.
+func CanDeleteComment(ctx context.Context, role Role, comment Comment) bool {
+	return comment.UserID == UserIDFromContext(ctx) || role.IsAdmin()
+}
+
+// CommentService represents a service for managing task comments.
+type CommentService interface {
+	// Retrieves a single comment by ID along with its author.
+	FindCommentByID(ctx context.Context, id int) (*Comment, error)
+
+	// Retrieves a list of comments based on filter. Comments are returned
+	// from the oldest to the newest.
+	FindComments(ctx context.Context, filter CommentFilter) ([]*Comment, int, error)
+
+	// Creates a new comment on a task for the current user.
+	CreateComment(ctx context.Context, comment *Comment) error
+
+	// Updates an existing comment by ID. Only the author can edit a comment.
+	UpdateComment(ctx context.Context, id int, upd CommentUpdate) (*Comment, error)
+
+	// Permanently deletes a comment by ID. Only the author or repo admins
+	// can delete a comment.
+	DeleteComment(ctx context.Context, id int) error
+}
+
+// CommentFilter represents a filter used by FindComments().
+type CommentFilter struct {
+	ID     *int `json:"id"`
+	TaskID *int `json:"taskID"`
+
+	// Restricts to a subset of results.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+// CommentUpdate represents a set of fields to update on a comment.
+type CommentUpdate struct {
+	Body *string `json:"body"`
+}
@@ -13,6 +13,12 @@ const (
 
 	// Stores the "flash" in the context.
 	flashContextKey
+
+	// Stores the access token the current request is authenticated with.
+	tokenContextKey
+
+	// Stores the browser session the current request is authenticated with.
+	sessionContextKey
 )
 
 // NewContextWithUser returns a new context with the given user.
@@ -34,6 +40,30 @@ func UserIDFromContext(ctx context.Context) int {
 	return 0
 }
 
+// NewContextWithToken returns a new context with the given access token.
+func NewContextWithToken(ctx context.Context, token *Token) context.Context {
+	return context.WithValue(ctx, tokenContextKey, token)
+}
+
+// TokenFromContext returns the access token the current request is
+// authenticated with. Returns nil for requests made with a browser session.
+func TokenFromContext(ctx context.Context) *Token {
+	token, _ := ctx.Value(tokenContextKey).(*Token)
+	return token
+}
+
+// NewContextWithSession returns a new context with the given browser session.
+func NewContextWithSession(ctx context.Context, session *Session) context.Context {
+	return context.WithValue(ctx, sessionContextKey, session)
+}
+
+// SessionFromContext returns the browser session the current request is
+// authenticated with. Returns nil for requests made with an access token.
+func SessionFromContext(ctx context.Context) *Session {
+	session, _ := ctx.Value(sessionContextKey).(*Session)
+	return session
+}
+
 // NewContextWithFlash returns a new context with the given flash value.
 func NewContextWithFlash(ctx context.Context, v string) context.Context {
 	return context.WithValue(ctx, flashContextKey, v)
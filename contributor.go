@@ -25,20 +25,36 @@ type Contributor struct {
 	UserID int `json:"userID"`
 	ID     int `json:"id"`
 
+	// Access level of the contributor within the repo.
+	Role Role `json:"role"`
+
+	// Set if the contributor's role has administrative privileges.
 	IsAdmin bool `json:"isAdmin"`
 }
 
-// CanEditContributor returns true if the current user can edit contributor.
-func CanEditContributor(ctx context.Context, contrib Contributor) bool {
-	return contrib.UserID == UserIDFromContext(ctx)
+// CanUpdateContributorRole returns an error if a contributor with the given
+// role cannot change the role of contrib to newRole. Only contributors that
+// outrank both the current and the new role can change it.
+func CanUpdateContributorRole(role Role, contrib Contributor, newRole Role) error {
+	if err := newRole.Validate(); err != nil {
+		return err
+	} else if contrib.Role == RoleOwner || newRole == RoleOwner {
+		return Errorf(EUNAUTHORIZED, "Repo ownership cannot be changed.")
+	} else if !role.Outranks(contrib.Role) || !role.Outranks(newRole) {
+		return Errorf(EUNAUTHORIZED, "You do not have permission to update the contributor.")
+	}
+	return nil
 }
 
-func CanDeleteContributor(ctx context.Context, contributor Contributor) error {
+// CanDeleteContributor returns an error if the current user with the given role
+// cannot delete contributor. Users can always leave a repo they do not own, and
+// contributors can remove others that they outrank.
+func CanDeleteContributor(ctx context.Context, role Role, contributor Contributor) error {
 	userID := UserIDFromContext(ctx)
-	// Verify user is the contributor or ownes parent repo.
-	if contributor.UserID != userID && contributor.OwnerID != userID {
+	// Verify user is the contributor or outranks them.
+	if contributor.UserID != userID && !role.Outranks(contributor.Role) {
 		return Errorf(EUNAUTHORIZED, "You do not have permission to delete the contributor.")
-	} else if contributor.UserID == contributor.OwnerID { // Do not let repo owner delete their own contributor object.
+	} else if contributor.Role == RoleOwner { // Do not let repo owner delete their own contributor object.
 		return Errorf(ECONFLICT, "Repo owner cannot be deleted.")
 	}
 
@@ -70,6 +86,31 @@ type ContributorService interface {
 
 	// Premanetly deletes contributor by ID.
 	DeleteContributor(ctx context.Context, id int) error
+
+	// Creates a new contributor on a repo for the current user through the
+	// invitation with the given code. The contributor is given the role of
+	// the invitation.
+	JoinRepo(ctx context.Context, code string) (*Contributor, error)
+
+	// Retrieves a usable invitation by code along with its repo.
+	FindInvitationByCode(ctx context.Context, code string) (*Invitation, error)
+
+	// Retrieves a list of matching invitations based on filter.
+	FindInvitations(ctx context.Context, filter InvitationFilter) ([]*Invitation, int, error)
+
+	// Creates a new invitation to a repo.
+	CreateInvitation(ctx context.Context, inv *Invitation) error
+
+	// Replaces the code of an invitation so that previously shared links
+	// stop working.
+	RotateInvitation(ctx context.Context, id int) (*Invitation, error)
+
+	// Revokes an invitation by ID. Revoked invitations cannot be used again.
+	RevokeInvitation(ctx context.Context, id int) error
+
+	// Retrieves the list of users that joined through an invitation, newest
+	// first.
+	FindInvitationUses(ctx context.Context, invitationID int) ([]*InvitationUse, error)
 }
 
 const (
@@ -79,7 +120,7 @@ const (
 // ContributorFilter represents a filter used by FindContributors().
 type ContributorFilter struct {
 	ID     *int `json:"id"`
-	RepoID *int `json:"dialID"`
+	RepoID *int `json:"repoID"`
 	UserID *int `json:"userID"`
 	TaskID *int `json:"taskID"`
 
@@ -87,11 +128,18 @@ type ContributorFilter struct {
 	Offset int `json:"offset"`
 	Limit  int `json:"limit"`
 
+	// Resumes listing after the item the cursor points to, as returned
+	// with the previous page. Cannot be combined with Offset.
+	Cursor string `json:"cursor"`
+
 	// Sorting option for results.
 	SortBy string `json:"sortBy"`
 }
 
 // ContributorUpdate represents a set of fields to update on a contributor.
 type ContributorUpdate struct {
-	IsAdmin *bool
+	Role *Role `json:"role"`
+
+	// Shorthand for setting the role to admin or back to member.
+	IsAdmin *bool `json:"isAdmin"`
 }
@@ -0,0 +1,32 @@
+package todev
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+)
+
+// cursorPrefix is prepended to item IDs before encoding them as cursors.
+const cursorPrefix = "after:"
+
+// EncodeCursor returns an opaque cursor pointing after the item with the
+// given ID. Cursors are used by filters to resume a listing where the
+// previous page ended.
+func EncodeCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(id)))
+}
+
+// DecodeCursor returns the ID of the item a cursor points after. Returns
+// EINVALID if the cursor is malformed.
+func DecodeCursor(cursor string) (int, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(buf), cursorPrefix) {
+		return 0, Errorf(EINVALID, "Invalid cursor.")
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(string(buf), cursorPrefix))
+	if err != nil || id <= 0 {
+		return 0, Errorf(EINVALID, "Invalid cursor.")
+	}
+	return id, nil
+}
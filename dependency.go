@@ -0,0 +1,21 @@
+package todev
+
+// TaskDependency represents a "blocked by" relationship between two tasks of
+// the same repo. A task is blocked until all of its blockers are completed.
+type TaskDependency struct {
+	// Task that cannot start until the blocker is completed.
+	TaskID int `json:"taskID"`
+
+	// Task that has to be completed first.
+	BlockerID int `json:"blockerID"`
+}
+
+// Validate returns an error if the dependency contains invalid fields.
+func (d TaskDependency) Validate() error {
+	if d.TaskID == 0 || d.BlockerID == 0 {
+		return Errorf(EINVALID, "Task and blocker IDs required.")
+	} else if d.TaskID == d.BlockerID {
+		return Errorf(EINVALID, "A task cannot block itself.")
+	}
+	return nil
+}
@@ -1,19 +1,40 @@
 package todev
 
-import "context"
+import (
+	"context"
+	"slices"
+	"time"
+)
 
 // Event type constants.
 const (
-	EventTypeTaskAdded               = "task:added"
-	EventTypeTaskCompletionToggled   = "task:completion_toggled"
-	EventTypeTaskDescriptionChanged  = "task:description_changed"
-	EventTypeTaskAttachContributor   = "task:attach_contributor"
-	EventTypeTaskUnattachContributor = "task:unattach_contributor"
-	EventTypeTaskDeleted             = "task:deleted"
-	EventTypeContributorAdded        = "contributor:added"
-	EventTypeContributorSetAdmin     = "contributor:set_admin"
-	EventTypeContributorResetAdmin   = "contributor:reset_admin"
-	EventTypeContributorDeleted      = "contributor:deleted"
+	EventTypeTaskAdded                = "task:added"
+	EventTypeTaskStatusChanged        = "task:status_changed"
+	EventTypeTaskDescriptionChanged   = "task:description_changed"
+	EventTypeTaskAttachContributor    = "task:attach_contributor"
+	EventTypeTaskUnattachContributor  = "task:unattach_contributor"
+	EventTypeTaskDeleted              = "task:deleted"
+	EventTypeTaskDueAtChanged         = "task:due_at_changed"
+	EventTypeTaskPriorityChanged      = "task:priority_changed"
+	EventTypeTaskLabelsChanged        = "task:labels_changed"
+	EventTypeTaskChecklistItemAdded   = "task:checklist_item_added"
+	EventTypeTaskChecklistItemChanged = "task:checklist_item_changed"
+	EventTypeTaskChecklistItemRemoved = "task:checklist_item_removed"
+	EventTypeTaskDependencyAdded      = "task:dependency_added"
+	EventTypeTaskDependencyRemoved    = "task:dependency_removed"
+	EventTypeTaskBlocked              = "task:blocked"
+	EventTypeTaskUnblocked            = "task:unblocked"
+	EventTypeRepoStatesChanged        = "repo:states_changed"
+	EventTypeContributorAdded         = "contributor:added"
+	EventTypeContributorSetAdmin      = "contributor:set_admin"
+	EventTypeContributorResetAdmin    = "contributor:reset_admin"
+	EventTypeContributorDeleted       = "contributor:deleted"
+	EventTypeCommentAdded             = "comment:added"
+	EventTypeCommentEdited            = "comment:edited"
+	EventTypeCommentDeleted           = "comment:deleted"
+
+	// Sent to clients whose replay cursor points to purged events.
+	EventTypeEventsExpired = "events:expired"
 )
 
 // Event represents an event that occurs in the system.
@@ -21,6 +42,12 @@ type Event struct {
 	// Specifies the type of event that is occuring.
 	Type string `json:"type"`
 
+	// Repo the event occured in and its position within the repo's events.
+	// Sequence numbers increase monotonically for each repo and are used as
+	// cursors to replay missed events.
+	RepoID int `json:"repoID,omitempty"`
+	Seq    int `json:"seq,omitempty"`
+
 	// The actual data from the event.
 	Payload interface{} `json:"payload"`
 }
@@ -54,13 +81,58 @@ type TaskAdded struct {
 	Task *Task `json:"task"`
 }
 
-// RepoTaskCompletionToggled represents a payload for an event and
-// is due to update IsCompleted field of a task object to the
-// opposite of the current one.
-type TaskCompletionToggled struct {
+// TaskStatusChanged represents a payload for an event and
+// is due to move a task object to another workflow state.
+type TaskStatusChanged struct {
+	ID          int  `json:"id"`
+	StateID     int  `json:"stateID"`
+	IsCompleted bool `json:"isCompleted"`
+}
+
+// TaskChecklistItemAdded represents a payload for an event and
+// is due to append an item to the checklist of a task.
+type TaskChecklistItemAdded struct {
+	Item *ChecklistItem `json:"item"`
+}
+
+// TaskChecklistItemChanged represents a payload for an event and
+// is due to replace an item of a task checklist. Moved items carry their new
+// position and the other items shift accordingly.
+type TaskChecklistItemChanged struct {
+	Item *ChecklistItem `json:"item"`
+}
+
+// TaskChecklistItemRemoved represents a payload for an event and
+// is due to remove an item from the checklist of a task.
+type TaskChecklistItemRemoved struct {
+	ID     int `json:"id"`
+	TaskID int `json:"taskID"`
+}
+
+// TaskDependencyAdded represents a payload for an event and
+// is due to add an edge to the dependency graph of a repo.
+type TaskDependencyAdded struct {
+	Dependency *TaskDependency `json:"dependency"`
+}
+
+// TaskDependencyRemoved represents a payload for an event and
+// is due to remove an edge from the dependency graph of a repo.
+type TaskDependencyRemoved struct {
+	Dependency *TaskDependency `json:"dependency"`
+}
+
+// TaskBlockedChanged represents a payload for task:blocked and task:unblocked
+// events and is due to update IsBlocked field of a task object.
+type TaskBlockedChanged struct {
 	ID int `json:"id"`
 }
 
+// RepoStatesChanged represents a payload for an event and
+// is due to replace the workflow states of a repo.
+type RepoStatesChanged struct {
+	States []*TaskState `json:"states"`
+}
+
 // TaskContributorAttached represents a payload for an event and
 // is due to attach a contributor ID into the task for the given task ID.
 type TaskContributorAttached struct {
@@ -89,22 +161,89 @@ type TaskContributorIDChanged struct {
 	Value int `json:"value"`
 }
 
+// TaskDueAtChanged represents a payload for an event and
+// is due to update DueAt field of a task object.
+type TaskDueAtChanged struct {
+	ID    int       `json:"id"`
+	Value time.Time `json:"value"`
+}
+
+// TaskPriorityChanged represents a payload for an event and
+// is due to update Priority field of a task object.
+type TaskPriorityChanged struct {
+	ID    int `json:"id"`
+	Value int `json:"value"`
+}
+
+// TaskLabelsChanged represents a payload for an event and
+// is due to replace Labels field of a task object.
+type TaskLabelsChanged struct {
+	ID    int      `json:"id"`
+	Value []string `json:"value"`
+}
+
 // RepoTaskDeleted represents a payload for an event and
 // is due to delete a task object from a repo by ID.
 type TaskDeleted struct {
 	ID int `json:"id"`
 }
 
+// CommentAdded represents a payload for an event and
+// is due to add a new comment to a task.
+type CommentAdded struct {
+	Comment *Comment `json:"comment"`
+}
+
+// CommentEdited represents a payload for an event and
+// is due to update Body field of a comment object.
+type CommentEdited struct {
+	ID     int    `json:"id"`
+	TaskID int    `json:"taskID"`
+	Value  string `json:"value"`
+}
+
+// CommentDeleted represents a payload for an event and
+// is due to delete a comment object from a task by ID.
+type CommentDeleted struct {
+	ID     int `json:"id"`
+	TaskID int `json:"taskID"`
+}
+
 type EventService interface {
 	// Publiches an event to a user's event listeners.
 	PublishEvent(id int, event Event)
 
-	// Creates a subscription for the current user's events.
-	Subscribe(ctx context.Context) (Subscription, error)
+	// Creates a subscription for the current user's events matching filter.
+	Subscribe(ctx context.Context, filter SubscriptionFilter) (Subscription, error)
+}
+
+// SubscriptionFilter represents a filter used by Subscribe() to limit which
+// events are delivered to a subscription.
+type SubscriptionFilter struct {
+	// Restricts the subscription to events of the given repos.
+	// Events of all repos are delivered if empty.
+	RepoIDs []int `json:"repoIDs"`
+}
+
+// Match returns true if the event should be delivered to a subscription with
+// the filter. Events which do not belong to a repo always match.
+func (f SubscriptionFilter) Match(event Event) bool {
+	if len(f.RepoIDs) == 0 || event.RepoID == 0 {
+		return true
+	}
+	return slices.Contains(f.RepoIDs, event.RepoID)
+}
+
+// EventLogService represents a service for replaying past repo events.
+type EventLogService interface {
+	// Retrieves the events of a repo with a sequence number greater than since
+	// in sequence order. Returns ENOTFOUND if the current user cannot view the
+	// repo and ECONFLICT if some of the events are no longer available.
+	FindEvents(ctx context.Context, repoID, since int) ([]Event, error)
 }
 
 type Subscription interface {
-	// Event stream for all user's event.
+	// Event stream of the user's events that match the subscription filter.
 	C() <-chan Event
 
 	// For cleaning up after calling Done().
@@ -118,6 +257,6 @@ type nopEventService struct{}
 
 func (*nopEventService) PublishEvent(id int, event Event) {}
 
-func (*nopEventService) Subscribe(ctx context.Context) (Subscription, error) {
+func (*nopEventService) Subscribe(ctx context.Context, filter SubscriptionFilter) (Subscription, error) {
 	panic("not implemented")
 }
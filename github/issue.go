@@ -0,0 +1,98 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	gogithub "github.com/google/go-github/v32/github"
+	"github.com/saiddis/todev"
+	"golang.org/x/oauth2"
+)
+
+// Ensure service implements interface.
+var _ todev.IssueService = (*IssueService)(nil)
+
+// IssuesPerPage is the number of issues requested from the GitHub API at once.
+const IssuesPerPage = 100
+
+// IssueService implements todev.IssueService over the GitHub REST API.
+type IssueService struct {
+	// Base URL of the GitHub API. Uses the public API if empty.
+	BaseURL string
+}
+
+// NewIssueService returns a new instance of IssueService.
+func NewIssueService() *IssueService {
+	return &IssueService{}
+}
+
+// FindOpenIssues retrieves all the open issues of a GitHub repository using
+// the given OAuth access token. Pull requests are skipped as the GitHub API
+// lists them along with issues.
+func (s *IssueService) FindOpenIssues(ctx context.Context, accessToken, fullName string) ([]*todev.Issue, error) {
+	owner, name, err := todev.SplitGitHubRepo(fullName)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.newClient(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &gogithub.IssueListByRepoOptions{
+		State:       "open",
+		ListOptions: gogithub.ListOptions{PerPage: IssuesPerPage},
+	}
+
+	issues := make([]*todev.Issue, 0)
+	for {
+		page, resp, err := client.Issues.ListByRepo(ctx, owner, name, opts)
+		if resp != nil && resp.StatusCode == 404 {
+			return nil, todev.Errorf(todev.ENOTFOUND, "GitHub repo not found.")
+		} else if err != nil {
+			return nil, fmt.Errorf("error listing issues: %w", err)
+		}
+
+		for _, issue := range page {
+			if issue.IsPullRequest() {
+				continue
+			}
+
+			other := &todev.Issue{
+				Number:      issue.GetNumber(),
+				Title:       issue.GetTitle(),
+				AssigneeIDs: make([]string, 0, len(issue.Assignees)),
+			}
+			for _, assignee := range issue.Assignees {
+				other.AssigneeIDs = append(other.AssigneeIDs, strconv.FormatInt(assignee.GetID(), 10))
+			}
+			issues = append(issues, other)
+		}
+
+		if resp.NextPage == 0 {
+			return issues, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// newClient returns a GitHub API client authenticated with the access token.
+func (s *IssueService) newClient(ctx context.Context, accessToken string) (*gogithub.Client, error) {
+	client := gogithub.NewClient(oauth2.NewClient(ctx, oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: accessToken},
+	)))
+	if s.BaseURL == "" {
+		return client, nil
+	}
+
+	baseURL, err := url.Parse(strings.TrimSuffix(s.BaseURL, "/") + "/")
+	if err != nil {
+		return nil, fmt.Errorf("error parsing base URL: %w", err)
+	}
+	client.BaseURL = baseURL
+	return client, nil
+}
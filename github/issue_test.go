@@ -0,0 +1,75 @@
+package github_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/github"
+)
+
+func TestIssueService_FindOpenIssues(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		// Serve two pages of issues, one of them being a pull request.
+		mux := http.NewServeMux()
+		ts := httptest.NewServer(mux)
+		defer ts.Close()
+
+		mux.HandleFunc("/repos/bob/todo/issues", func(w http.ResponseWriter, r *http.Request) {
+			if got, want := r.Header.Get("Authorization"), "Bearer TOKEN"; got != want {
+				t.Errorf("Authorization=%q, want %q", got, want)
+			} else if got, want := r.URL.Query().Get("state"), "open"; got != want {
+				t.Errorf("state=%q, want %q", got, want)
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Query().Get("page") {
+			case "":
+				w.Header().Set("Link", fmt.Sprintf(`<%s/repos/bob/todo/issues?page=2>; rel="next"`, ts.URL))
+				fmt.Fprint(w, `[
+					{"number": 1, "title": "Fix login", "assignees": [{"id": 100}, {"id": 200}]},
+					{"number": 2, "title": "Bump deps", "pull_request": {"url": "https://example.com"}}
+				]`)
+			case "2":
+				fmt.Fprint(w, `[{"number": 3, "title": "Add docs", "assignees": []}]`)
+			default:
+				t.Errorf("unexpected page: %s", r.URL.Query().Get("page"))
+			}
+		})
+
+		s := github.NewIssueService()
+		s.BaseURL = ts.URL
+
+		issues, err := s.FindOpenIssues(context.Background(), "TOKEN", "bob/todo")
+		if err != nil {
+			t.Fatal(err)
+		} else if want := []*todev.Issue{
+			{Number: 1, Title: "Fix login", AssigneeIDs: []string{"100", "200"}},
+			{Number: 3, Title: "Add docs", AssigneeIDs: []string{}},
+		}; !reflect.DeepEqual(issues, want) {
+			t.Fatalf("unexpected issues: %#v", issues)
+		}
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		ts := httptest.NewServer(http.NotFoundHandler())
+		defer ts.Close()
+
+		s := github.NewIssueService()
+		s.BaseURL = ts.URL
+
+		if _, err := s.FindOpenIssues(context.Background(), "TOKEN", "bob/todo"); todev.ErrorCode(err) != todev.ENOTFOUND {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("ErrInvalidName", func(t *testing.T) {
+		if _, err := github.NewIssueService().FindOpenIssues(context.Background(), "TOKEN", "todo"); todev.ErrorCode(err) != todev.EINVALID {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	gogithub "github.com/google/go-github/v32/github"
+	"github.com/saiddis/todev"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+)
+
+// Ensure provider implements interface.
+var _ todev.OAuthProvider = (*OAuthProvider)(nil)
+
+// OAuthProvider implements todev.OAuthProvider for logging in with GitHub.
+type OAuthProvider struct {
+	ClientID     string
+	ClientSecret string
+
+	// Base URL of the GitHub web site serving the OAuth endpoints. Uses
+	// github.com if empty.
+	URL string
+
+	// Base URL of the GitHub API. Uses the public API if empty.
+	BaseURL string
+}
+
+// NewOAuthProvider returns a new instance of OAuthProvider.
+func NewOAuthProvider(clientID, clientSecret string) *OAuthProvider {
+	return &OAuthProvider{ClientID: clientID, ClientSecret: clientSecret}
+}
+
+// Source returns the GitHub auth source.
+func (p *OAuthProvider) Source() string {
+	return todev.AuthSourceGitHub
+}
+
+// Name returns the name shown on the login page.
+func (p *OAuthProvider) Name() string {
+	return "GitHub"
+}
+
+// AuthCodeURL returns the URL of the GitHub authorization page.
+func (p *OAuthProvider) AuthCodeURL(state, redirectURL string) string {
+	return p.config(redirectURL).AuthCodeURL(state)
+}
+
+// Exchange exchanges an authorization code for OAuth tokens.
+func (p *OAuthProvider) Exchange(ctx context.Context, code, redirectURL string) (*todev.OAuthToken, error) {
+	tok, err := p.config(redirectURL).Exchange(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("oauth exchange error: %w", err)
+	}
+	return &todev.OAuthToken{
+		AccessToken:  tok.AccessToken,
+		RefreshToken: tok.RefreshToken,
+		Expiry:       tok.Expiry,
+	}, nil
+}
+
+// Refresh exchanges a refresh token for new OAuth tokens.
+func (p *OAuthProvider) Refresh(ctx context.Context, refreshToken string) (*todev.OAuthToken, error) {
+	tok, err := p.config("").TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		return nil, fmt.Errorf("oauth refresh error: %w", err)
+	}
+	return &todev.OAuthToken{
+		AccessToken:  tok.AccessToken,
+		RefreshToken: tok.RefreshToken,
+		Expiry:       tok.Expiry,
+	}, nil
+}
+
+// UserInfo retrieves the GitHub user the access token belongs to.
+func (p *OAuthProvider) UserInfo(ctx context.Context, accessToken string) (*todev.OAuthUser, error) {
+	client, err := p.newClient(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// Require that we at least recieve a user ID from GitHub.
+	u, _, err := client.Users.Get(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	} else if u.ID == nil {
+		return nil, fmt.Errorf("user ID not returned by GitHub, cannot authenticate user")
+	}
+
+	// Fall back to the login for users that haven't set a name.
+	name := u.GetName()
+	if name == "" {
+		name = u.GetLogin()
+	}
+
+	// Email is not nessarily availible for all accounts. If it is, it is
+	// used to link together the auths of multiple providers.
+	return &todev.OAuthUser{
+		ID:        strconv.FormatInt(u.GetID(), 10),
+		Name:      name,
+		Email:     u.GetEmail(),
+		AvatarURL: u.GetAvatarURL(),
+	}, nil
+}
+
+// config returns the OAuth2 configuration for GitHub.
+func (p *OAuthProvider) config(redirectURL string) *oauth2.Config {
+	endpoint := github.Endpoint
+	if p.URL != "" {
+		u := strings.TrimSuffix(p.URL, "/")
+		endpoint = oauth2.Endpoint{
+			AuthURL:  u + "/login/oauth/authorize",
+			TokenURL: u + "/login/oauth/access_token",
+		}
+	}
+
+	return &oauth2.Config{
+		ClientID:     p.ClientID,
+		ClientSecret: p.ClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{},
+		Endpoint:     endpoint,
+	}
+}
+
+// newClient returns a GitHub API client authenticated with the access token.
+func (p *OAuthProvider) newClient(ctx context.Context, accessToken string) (*gogithub.Client, error) {
+	client := gogithub.NewClient(oauth2.NewClient(ctx, oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: accessToken},
+	)))
+	if p.BaseURL == "" {
+		return client, nil
+	}
+
+	baseURL, err := url.Parse(strings.TrimSuffix(p.BaseURL, "/") + "/")
+	if err != nil {
+		return nil, fmt.Errorf("error parsing base URL: %w", err)
+	}
+	client.BaseURL = baseURL
+	return client, nil
+}
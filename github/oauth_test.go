@@ -0,0 +1,69 @@
+package github_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/github"
+)
+
+func TestOAuthProvider(t *testing.T) {
+	// Stand in for both the GitHub web site and API.
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.FormValue("code"), "CODE"; got != want {
+			t.Errorf("code=%q, want %q", got, want)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token": "TOKEN", "token_type": "bearer"}`)
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("Authorization"), "Bearer TOKEN"; got != want {
+			t.Errorf("Authorization=%q, want %q", got, want)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id": 100, "login": "bob", "email": "bob@gmail.com", "avatar_url": "https://example.com/bob.png"}`)
+	})
+
+	p := github.NewOAuthProvider("CLIENTID", "SECRET")
+	p.URL, p.BaseURL = ts.URL, ts.URL
+
+	if loc, err := url.Parse(p.AuthCodeURL("STATE", "http://localhost/oauth/github/callback")); err != nil {
+		t.Fatal(err)
+	} else if got, want := loc.Path, "/login/oauth/authorize"; got != want {
+		t.Fatalf("Path=%s, want %s", got, want)
+	} else if got, want := loc.Query().Get("client_id"), "CLIENTID"; got != want {
+		t.Fatalf("client_id=%s, want %s", got, want)
+	} else if got, want := loc.Query().Get("state"), "STATE"; got != want {
+		t.Fatalf("state=%s, want %s", got, want)
+	}
+
+	ctx := context.Background()
+	tok, err := p.Exchange(ctx, "CODE", "http://localhost/oauth/github/callback")
+	if err != nil {
+		t.Fatal(err)
+	} else if got, want := tok.AccessToken, "TOKEN"; got != want {
+		t.Fatalf("AccessToken=%q, want %q", got, want)
+	}
+
+	// Users without a name fall back to their login.
+	if u, err := p.UserInfo(ctx, tok.AccessToken); err != nil {
+		t.Fatal(err)
+	} else if want := (&todev.OAuthUser{
+		ID:        "100",
+		Name:      "bob",
+		Email:     "bob@gmail.com",
+		AvatarURL: "https://example.com/bob.png",
+	}); !reflect.DeepEqual(u, want) {
+		t.Fatalf("unexpected user: %#v", u)
+	}
+}
@@ -0,0 +1,141 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/saiddis/todev"
+	"golang.org/x/oauth2"
+)
+
+// Ensure provider implements interface.
+var _ todev.OAuthProvider = (*OAuthProvider)(nil)
+
+// DefaultURL is the GitLab instance used if none is configured.
+const DefaultURL = "https://gitlab.com"
+
+// OAuthProvider implements todev.OAuthProvider for logging in with GitLab.
+type OAuthProvider struct {
+	ClientID     string
+	ClientSecret string
+
+	// Base URL of the GitLab instance, for self-hosted installations.
+	// Uses DefaultURL if empty.
+	URL string
+}
+
+// NewOAuthProvider returns a new instance of OAuthProvider.
+func NewOAuthProvider(clientID, clientSecret string) *OAuthProvider {
+	return &OAuthProvider{ClientID: clientID, ClientSecret: clientSecret}
+}
+
+// Source returns the GitLab auth source.
+func (p *OAuthProvider) Source() string {
+	return todev.AuthSourceGitLab
+}
+
+// Name returns the name shown on the login page.
+func (p *OAuthProvider) Name() string {
+	return "GitLab"
+}
+
+// AuthCodeURL returns the URL of the GitLab authorization page.
+func (p *OAuthProvider) AuthCodeURL(state, redirectURL string) string {
+	return p.config(redirectURL).AuthCodeURL(state)
+}
+
+// Exchange exchanges an authorization code for OAuth tokens.
+func (p *OAuthProvider) Exchange(ctx context.Context, code, redirectURL string) (*todev.OAuthToken, error) {
+	tok, err := p.config(redirectURL).Exchange(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("oauth exchange error: %w", err)
+	}
+	return &todev.OAuthToken{
+		AccessToken:  tok.AccessToken,
+		RefreshToken: tok.RefreshToken,
+		Expiry:       tok.Expiry,
+	}, nil
+}
+
+// Refresh exchanges a refresh token for new OAuth tokens.
+func (p *OAuthProvider) Refresh(ctx context.Context, refreshToken string) (*todev.OAuthToken, error) {
+	tok, err := p.config("").TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		return nil, fmt.Errorf("oauth refresh error: %w", err)
+	}
+	return &todev.OAuthToken{
+		AccessToken:  tok.AccessToken,
+		RefreshToken: tok.RefreshToken,
+		Expiry:       tok.Expiry,
+	}, nil
+}
+
+// UserInfo retrieves the GitLab user the access token belongs to.
+func (p *OAuthProvider) UserInfo(ctx context.Context, accessToken string) (*todev.OAuthUser, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.url()+"/api/v4/user", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error retrieving user: unexpected status %d", resp.StatusCode)
+	}
+
+	var u struct {
+		ID        int64  `json:"id"`
+		Username  string `json:"username"`
+		Name      string `json:"name"`
+		Email     string `json:"email"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&u); err != nil {
+		return nil, fmt.Errorf("error decoding user: %w", err)
+	} else if u.ID == 0 {
+		return nil, fmt.Errorf("user ID not returned by GitLab, cannot authenticate user")
+	}
+
+	name := u.Name
+	if name == "" {
+		name = u.Username
+	}
+
+	return &todev.OAuthUser{
+		ID:        strconv.FormatInt(u.ID, 10),
+		Name:      name,
+		Email:     u.Email,
+		AvatarURL: u.AvatarURL,
+	}, nil
+}
+
+// config returns the OAuth2 configuration for the GitLab instance.
+func (p *OAuthProvider) config(redirectURL string) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     p.ClientID,
+		ClientSecret: p.ClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       []string{"read_user"},
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  p.url() + "/oauth/authorize",
+			TokenURL: p.url() + "/oauth/token",
+		},
+	}
+}
+
+// url returns the base URL of the GitLab instance without trailing slash.
+func (p *OAuthProvider) url() string {
+	if p.URL == "" {
+		return DefaultURL
+	}
+	return strings.TrimSuffix(p.URL, "/")
+}
@@ -0,0 +1,110 @@
+package gitlab_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/gitlab"
+)
+
+func TestOAuthProvider(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		// Stand in for a self-hosted GitLab instance.
+		mux := http.NewServeMux()
+		ts := httptest.NewServer(mux)
+		defer ts.Close()
+
+		mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+			if got, want := r.FormValue("code"), "CODE"; got != want {
+				t.Errorf("code=%q, want %q", got, want)
+			} else if got, want := r.FormValue("redirect_uri"), "http://localhost/oauth/gitlab/callback"; got != want {
+				t.Errorf("redirect_uri=%q, want %q", got, want)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"access_token": "TOKEN", "refresh_token": "REFRESH", "token_type": "Bearer", "expires_in": 7200}`)
+		})
+		mux.HandleFunc("/api/v4/user", func(w http.ResponseWriter, r *http.Request) {
+			if got, want := r.Header.Get("Authorization"), "Bearer TOKEN"; got != want {
+				t.Errorf("Authorization=%q, want %q", got, want)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"id": 100, "username": "bob", "name": "Bob", "email": "bob@gmail.com", "avatar_url": "https://example.com/bob.png"}`)
+		})
+
+		p := gitlab.NewOAuthProvider("CLIENTID", "SECRET")
+		p.URL = ts.URL + "/"
+
+		if loc, err := url.Parse(p.AuthCodeURL("STATE", "http://localhost/oauth/gitlab/callback")); err != nil {
+			t.Fatal(err)
+		} else if got, want := loc.Path, "/oauth/authorize"; got != want {
+			t.Fatalf("Path=%s, want %s", got, want)
+		} else if got, want := loc.Query().Get("scope"), "read_user"; got != want {
+			t.Fatalf("scope=%s, want %s", got, want)
+		}
+
+		ctx := context.Background()
+		tok, err := p.Exchange(ctx, "CODE", "http://localhost/oauth/gitlab/callback")
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := tok.RefreshToken, "REFRESH"; got != want {
+			t.Fatalf("RefreshToken=%q, want %q", got, want)
+		} else if tok.Expiry.IsZero() {
+			t.Fatal("expected expiry")
+		}
+
+		if u, err := p.UserInfo(ctx, tok.AccessToken); err != nil {
+			t.Fatal(err)
+		} else if want := (&todev.OAuthUser{
+			ID:        "100",
+			Name:      "Bob",
+			Email:     "bob@gmail.com",
+			AvatarURL: "https://example.com/bob.png",
+		}); !reflect.DeepEqual(u, want) {
+			t.Fatalf("unexpected user: %#v", u)
+		}
+	})
+
+	t.Run("Refresh", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if got, want := r.FormValue("grant_type"), "refresh_token"; got != want {
+				t.Errorf("grant_type=%q, want %q", got, want)
+			} else if got, want := r.FormValue("refresh_token"), "REFRESH"; got != want {
+				t.Errorf("refresh_token=%q, want %q", got, want)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"access_token": "TOKEN2", "refresh_token": "REFRESH2", "token_type": "Bearer", "expires_in": 7200}`)
+		}))
+		defer ts.Close()
+
+		p := gitlab.NewOAuthProvider("CLIENTID", "SECRET")
+		p.URL = ts.URL
+
+		if tok, err := p.Refresh(context.Background(), "REFRESH"); err != nil {
+			t.Fatal(err)
+		} else if got, want := tok.AccessToken, "TOKEN2"; got != want {
+			t.Fatalf("AccessToken=%q, want %q", got, want)
+		} else if got, want := tok.RefreshToken, "REFRESH2"; got != want {
+			t.Fatalf("RefreshToken=%q, want %q", got, want)
+		}
+	})
+
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, `{"message": "401 Unauthorized"}`, http.StatusUnauthorized)
+		}))
+		defer ts.Close()
+
+		p := gitlab.NewOAuthProvider("CLIENTID", "SECRET")
+		p.URL = ts.URL
+
+		if _, err := p.UserInfo(context.Background(), "TOKEN"); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
@@ -0,0 +1,65 @@
+const burndownChart = document.getElementById('burndown-chart')
+const svgNS = 'http://www.w3.org/2000/svg'
+
+let burndownTimeout = null
+
+// Redraw the chart whenever tasks are added, completed or removed. Requests
+// are delayed so that a burst of events results in a single fetch.
+function refreshBurndown() {
+	clearTimeout(burndownTimeout)
+	burndownTimeout = setTimeout(drawBurndown, 500)
+}
+
+tasksPane.addEventListener('add-task', refreshBurndown)
+tasksPane.addEventListener('escape-task', refreshBurndown)
+
+async function fetchReport() {
+	try {
+		const resp = await fetch(`/repos/${repoID}/report`, {
+			method: 'GET',
+			headers: {
+				'Accept': 'application/json',
+			}
+		})
+
+		if (resp.ok) {
+			return resp.json()
+		} else {
+			console.error('unexpected status: ' + resp.status)
+			return null
+		}
+	} catch (err) {
+		console.error('unexpected error: ' + err)
+		return null
+	}
+}
+
+async function drawBurndown() {
+	const report = await fetchReport()
+	if (!report || report.records.length == 0) {
+		return
+	}
+
+	const width = 300, height = 100, padding = 4
+	const counts = report.records.map(record => record.tasks.length)
+	const max = Math.max(...counts, 1)
+	const step = counts.length > 1 ? (width - 2 * padding) / (counts.length - 1) : 0
+
+	const points = counts.map((n, i) => {
+		const x = padding + i * step
+		const y = height - padding - (n / max) * (height - 2 * padding)
+		return `${x},${y}`
+	})
+
+	const line = document.createElementNS(svgNS, 'polyline')
+	line.setAttribute('points', points.join(' '))
+	line.setAttribute('class', 'burndown-line')
+
+	const title = document.createElementNS(svgNS, 'title')
+	title.textContent = `${counts[counts.length - 1]} tasks left`
+
+	burndownChart.setAttribute('viewBox', `0 0 ${width} ${height}`)
+	burndownChart.replaceChildren(title, line)
+}
+
+window.addEventListener('load', drawBurndown)
@@ -0,0 +1,216 @@
+// Checklist renders the steps of a task below it along with the number of
+// checked steps. Only admins can add, move or remove steps.
+class Checklist {
+	constructor(task, items) {
+		this.taskId = task.id
+		this.items = new Map
+
+		this.progress = document.createElement('span')
+		this.progress.className = 'checklist-progress'
+
+		this.list = document.createElement('ul')
+		this.list.className = 'checklist-list'
+
+		this.elem = document.createElement('div')
+		this.elem.className = 'checklist flex col gap-half'
+		this.elem.append(this.progress, this.list)
+
+		if (isAdmin == 'true') {
+			this.input = document.createElement('input')
+			this.input.type = 'text'
+			this.input.placeholder = 'Add a step...'
+			this.input.onkeydown = (event) => {
+				if (event.key == 'Enter') {
+					this.onSubmit()
+				}
+			}
+			this.elem.append(this.input)
+		}
+
+		task.wrapper.append(this.elem)
+		if (items) {
+			items.forEach(item => this.add(item))
+		}
+		this.updateProgress()
+	}
+
+	async onSubmit() {
+		const text = this.input.value.trim()
+		if (!text) {
+			return
+		}
+
+		const item = await createChecklistItem(this.taskId, text)
+		if (item) {
+			this.input.value = ''
+			this.add(item)
+		}
+	}
+
+	// add renders an item at its position unless it is already displayed.
+	add(item) {
+		if (this.items.has(item.id)) {
+			return
+		}
+
+		const elem = document.createElement('li')
+		elem.className = 'checklist-item flex gap-half center-h'
+		elem.dataset.itemId = item.id
+
+		const checkBox = document.createElement('input')
+		checkBox.type = 'checkbox'
+		checkBox.checked = item.isChecked
+		checkBox.onchange = async () => {
+			if (!await updateChecklistItem(item.id, {isChecked: checkBox.checked})) {
+				checkBox.checked = !checkBox.checked
+			}
+			this.updateProgress()
+		}
+
+		const text = document.createElement('span')
+		text.className = 'checklist-text'
+		text.textContent = item.text
+
+		elem.append(checkBox, text)
+
+		if (isAdmin == 'true') {
+			const up = document.createElement('button')
+			up.textContent = '↑'
+			up.onclick = async () => {
+				const position = this.indexOf(item.id) - 1
+				if (position >= 0 && await updateChecklistItem(item.id, {position: position})) {
+					this.move(item.id, position)
+				}
+			}
+
+			const remove = document.createElement('div')
+			remove.className = 'icon-cross'
+			remove.style.width = 1 + 'rem'
+			remove.style.height = 1 + 'rem'
+			remove.onclick = async () => {
+				if (await deleteChecklistItem(item.id)) {
+					this.remove(item.id)
+				}
+			}
+			elem.prepend(remove)
+			elem.append(up)
+		}
+
+		this.items.set(item.id, elem)
+		this.list.append(elem)
+		this.move(item.id, item.position)
+	}
+
+	// change applies an edited item, moving it if its position changed.
+	change(item) {
+		const elem = this.items.get(item.id)
+		if (!elem) {
+			this.add(item)
+			return
+		}
+
+		elem.querySelector('input[type="checkbox"]').checked = item.isChecked
+		elem.querySelector('.checklist-text').textContent = item.text
+		this.move(item.id, item.position)
+	}
+
+	remove(id) {
+		const elem = this.items.get(id)
+		if (elem) {
+			elem.remove()
+			this.items.delete(id)
+			this.updateProgress()
+		}
+	}
+
+	// move places an item at the given position. The other items shift the
+	// same way they do on the server.
+	move(id, position) {
+		const elem = this.items.get(id)
+		if (!elem) {
+			return
+		}
+
+		elem.remove()
+		const next = this.list.children[position]
+		if (next) {
+			this.list.insertBefore(elem, next)
+		} else {
+			this.list.append(elem)
+		}
+		this.updateProgress()
+	}
+
+	indexOf(id) {
+		return Array.from(this.list.children).indexOf(this.items.get(id))
+	}
+
+	updateProgress() {
+		const total = this.items.size
+		const checked = this.list.querySelectorAll('input[type="checkbox"]:checked').length
+		this.progress.textContent = total ? `${checked}/${total}` : ''
+	}
+}
+
+async function createChecklistItem(taskId, text) {
+	try {
+		const resp = await fetch(`/tasks/${taskId}/checklist`, {
+			method: 'POST',
+			headers: {
+				'Content-type': 'application/json',
+				'Accept': 'application/json',
+			},
+			body: JSON.stringify({
+				text: text,
+			}),
+		})
+
+		if (resp.ok) {
+			return resp.json()
+		}
+		console.error('unexpected status: ' + resp.status)
+		return null
+	} catch (err) {
+		console.error(err)
+		return null
+	}
+}
+
+async function updateChecklistItem(itemId, upd) {
+	try {
+		const resp = await fetch(`/checklist/${itemId}`, {
+			method: 'PATCH',
+			headers: {
+				'Content-type': 'application/json',
+				'Accept': 'application/json',
+			},
+			body: JSON.stringify(upd),
+		})
+
+		if (resp.ok) {
+			return resp.json()
+		}
+		console.error('unexpected status: ' + resp.status)
+		return null
+	} catch (err) {
+		console.error(err)
+		return null
+	}
+}
+
+async function deleteChecklistItem(itemId) {
+	try {
+		const resp = await fetch(`/checklist/${itemId}`, {
+			method: 'DELETE',
+			headers: {
+				'Content-type': 'application/json',
+				'Accept': 'application/json',
+			}
+		})
+
+		return resp.ok
+	} catch (err) {
+		console.error(err)
+		return false
+	}
+}
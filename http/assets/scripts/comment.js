@@ -0,0 +1,185 @@
+// CommentThread renders the discussion of a task below it. Comments are only
+// fetched the first time the thread is opened.
+class CommentThread {
+	constructor(task) {
+		this.taskId = task.id
+		this.loaded = false
+		this.comments = new Map
+
+		this.toggle = document.createElement('button')
+		this.toggle.className = 'comments-toggle'
+		this.toggle.textContent = 'Comments'
+		this.toggle.onclick = () => this.onToggle()
+
+		this.list = document.createElement('ul')
+		this.list.className = 'comments-list'
+
+		this.input = document.createElement('input')
+		this.input.type = 'text'
+		this.input.placeholder = 'Write a comment...'
+		this.input.onkeydown = (event) => {
+			if (event.key == 'Enter') {
+				this.onSubmit()
+			}
+		}
+
+		this.elem = document.createElement('div')
+		this.elem.className = 'comments flex col gap-half'
+		this.elem.hidden = true
+		this.elem.append(this.list, this.input)
+
+		task.wrapper.append(this.toggle, this.elem)
+	}
+
+	async onToggle() {
+		if (!this.loaded) {
+			const comments = await findComments(this.taskId)
+			if (!comments) {
+				return
+			}
+			comments.forEach(comment => this.add(comment))
+			this.loaded = true
+		}
+		this.elem.hidden = !this.elem.hidden
+	}
+
+	async onSubmit() {
+		const body = this.input.value.trim()
+		if (!body) {
+			return
+		}
+
+		const comment = await createComment(this.taskId, body)
+		if (comment) {
+			this.input.value = ''
+			this.add(comment)
+		}
+	}
+
+	// receive renders a comment received over the websocket. Comments received
+	// before the thread is loaded are skipped as they will be fetched anyway.
+	receive(comment) {
+		if (this.loaded) {
+			this.add(comment)
+		}
+	}
+
+	// add renders a comment unless it is already displayed.
+	add(comment) {
+		if (this.comments.has(comment.id)) {
+			return
+		}
+
+		const elem = document.createElement('li')
+		elem.className = 'comment flex gap-half center-h'
+		elem.dataset.commentId = comment.id
+
+		const author = document.createElement('strong')
+		author.textContent = comment.user ? comment.user.name : ''
+
+		const body = document.createElement('span')
+		body.className = 'comment-body'
+		body.textContent = comment.body
+
+		elem.append(author, body)
+
+		if (comment.userID == currUserId || isAdmin == 'true') {
+			const remove = document.createElement('div')
+			remove.className = 'icon-cross'
+			remove.style.width = 1 + 'rem'
+			remove.style.height = 1 + 'rem'
+			remove.onclick = async () => {
+				if (await deleteComment(this.taskId, comment.id)) {
+					this.remove(comment.id)
+				}
+			}
+			elem.prepend(remove)
+		}
+
+		this.list.append(elem)
+		this.comments.set(comment.id, elem)
+		this.updateToggle()
+	}
+
+	edit(id, value) {
+		const elem = this.comments.get(id)
+		if (elem) {
+			elem.querySelector('.comment-body').textContent = value
+		}
+	}
+
+	remove(id) {
+		const elem = this.comments.get(id)
+		if (elem) {
+			elem.remove()
+			this.comments.delete(id)
+			this.updateToggle()
+		}
+	}
+
+	updateToggle() {
+		this.toggle.textContent = this.comments.size ? `Comments (${this.comments.size})` : 'Comments'
+	}
+}
+
+async function findComments(taskId) {
+	try {
+		const resp = await fetch(`/tasks/${taskId}/comments`, {
+			method: 'GET',
+			headers: {
+				'Accept': 'application/json',
+			}
+		})
+
+		if (resp.ok) {
+			const data = await resp.json()
+			return data.comments
+		}
+		console.error('unexpected status: ' + resp.status)
+		return null
+	} catch (err) {
+		console.error(err)
+		return null
+	}
+}
+
+async function createComment(taskId, body) {
+	try {
+		const resp = await fetch(`/tasks/${taskId}/comments`, {
+			method: 'POST',
+			headers: {
+				'Content-type': 'application/json',
+				'Accept': 'application/json',
+			},
+			body: JSON.stringify({
+				body: body,
+			}),
+		})
+
+		if (resp.ok) {
+			return resp.json()
+		}
+		console.error('unexpected status: ' + resp.status)
+		return null
+	} catch (err) {
+		console.error(err)
+		return null
+	}
+}
+
+async function deleteComment(taskId, commentId) {
+	try {
+		const resp = await fetch(`/tasks/${taskId}/comments/${commentId}`, {
+			method: 'DELETE',
+			headers: {
+				'Content-type': 'application/json',
+				'Accept': 'application/json',
+			}
+		})
+
+		return resp.ok
+	} catch (err) {
+		console.error(err)
+		return false
+	}
+}
@@ -1,13 +1,12 @@
 const repoInfo = document.getElementById('repo-info')
 const repoID = repoInfo.dataset.repoId
 const currContributorId = repoInfo.dataset.contributorId
+const currUserId = repoInfo.dataset.userId
 const isAdmin = repoInfo.dataset.isAdmin
 const tasksPane = document.getElementById('tasks-pane')
 const contributorsPane = document.getElementById('contributors-pane')
-const tasksList = document.getElementById('tasks-list')
+const board = document.getElementById('board')
 const contributorsList = document.getElementById('contributors-list')
-const completedTasksList = document.getElementById('completed-tasks-list')
-const titleCompleted = document.getElementById('title-completed')
 const addTaskButton = document.getElementById('add-task-button')
 const expandContributorsPaneButton = document.getElementById('expand-contributors-pane-button')
 const copyContent = async (text) => {
@@ -22,42 +21,46 @@ const copyContent = async (text) => {
 let contributorsMap = new Map;
 let tasksMap = new Map;
 
-tasksPane.addEventListener('escape-task', function(event) {
-	if (event.target.parentElement == tasksList) {
-		if (!event.detail.remove) {
-			completedTasksList.append(event.target)
-		}
-	} else if (event.target.parentElement == completedTasksList) {
-		if (!event.detail.remove) {
-			tasksList.append(event.target)
-		}
-	}
+// stateColumn returns the board column of a workflow state.
+function stateColumn(stateId) {
+	return board.querySelector(`.state-column[data-state-id="${stateId}"]`)
+}
+
+// initialColumn returns the column new tasks are placed in.
+function initialColumn() {
+	return board.querySelector('.state-column[data-is-terminal="false"]')
+}
 
+tasksPane.addEventListener('escape-task', function(event) {
 	if (event.detail.remove) {
 		event.target.remove()
 		tasksMap.delete(parseInt(event.target.dataset.taskId))
-	}
-
-	if (completedTasksList.childElementCount < 3) {
-		completedTasksList.hidden = true
-	} else {
-		completedTasksList.hidden = false
+	} else if (event.detail.stateId) {
+		const column = stateColumn(event.detail.stateId)
+		if (column && event.target.parentElement != column) {
+			column.append(event.target)
+		}
 	}
 })
 
 tasksPane.addEventListener('add-task', function(event) {
+	// Events of the current user are not received live but are replayed
+	// after reconnecting, so the task may already be on the board.
+	if (tasksMap.has(parseInt(event.detail.id))) {
+		return
+	}
+
 	let task = null;
 	if (isAdmin == 'true') {
-		task = new Task(event.detail.elem, event.detail.description, event.detail.id, event.detail.isCompleted)
+		task = new Task(event.detail.elem, event.detail.description, event.detail.id, event.detail.stateId)
 	} else {
-		task = new UserTask(event.detail.elem, event.detail.description, event.detail.id, event.detail.isCompleted)
+		task = new UserTask(event.detail.elem, event.detail.description, event.detail.id, event.detail.stateId)
 	}
 
 	makeTaskDroppable(task)
-
-	if (event.target.hidden) {
-		event.target.hidden = false
-	}
+	task.checklist = new Checklist(task, event.detail.checklist)
+	task.wrapper.classList.toggle('blocked', !!event.detail.isBlocked)
+	task.comments = new CommentThread(task)
 
 	event.target.append(event.detail.elem)
 	tasksMap.set(parseInt(task.id), task)
@@ -209,7 +212,7 @@ if (isAdmin == 'true') {
 
 function addTask() {
 	const input = document.createElement('input')
-	tasksList.append(input)
+	initialColumn().append(input)
 
 	input.focus()
 	input.onblur = async () => {
@@ -220,12 +223,12 @@ function addTask() {
 		let task = await createTask(input.value)
 		if (task) {
 			input.remove()
-			tasksList.dispatchEvent(new CustomEvent('add-task', {
+			stateColumn(task.stateID).dispatchEvent(new CustomEvent('add-task', {
 				bubbles: true,
 				detail: {
 					elem: document.createElement('li'),
 					description: task.description,
-					isCompleted: false,
+					stateId: task.stateID,
 					id: task.id,
 				}
 			}))
@@ -260,20 +263,39 @@ async function createTask(description) {
 	}
 }
 
+// Sequence number of the last repo event applied to the page. Sent to the
+// server on every (re)connect so that missed events are replayed.
+let lastEventSeq = Number(repoInfo.dataset.eventSeq) || 0
+
+function eventsURL() {
+	return (location.protocol == 'https:' ? 'wss:' : 'ws:') + '//' + location.host + `/events?repo=${repoID}&since=${lastEventSeq}`
+}
+
 function connect() {
-	const socket = new ReconnectingWebSocket((location.protocol == 'https:' ? 'wss:' : 'ws:') + '//' + location.host + '/events');
+	const socket = new ReconnectingWebSocket(eventsURL());
+	socket.onconnecting = function() {
+		socket.url = eventsURL()
+	}
 	socket.onmessage = function(event) {
 		const e = JSON.parse(event.data)
 		let task = null
 
+		if (e.repoID == repoID && e.seq > lastEventSeq) {
+			lastEventSeq = e.seq
+		}
+
 		switch (e.type) {
+			case 'events:expired':
+				// Missed events are no longer available so start over.
+				location.reload()
+				break
 			case 'task:added':
-				tasksList.dispatchEvent(new CustomEvent('add-task', {
+				stateColumn(e.payload.task.stateID).dispatchEvent(new CustomEvent('add-task', {
 					bubbles: true,
 					detail: {
 						elem: document.createElement('li'),
 						description: e.payload.task.description,
-						isCompleted: false,
+						stateId: e.payload.task.stateID,
 						id: e.payload.task.id,
 					}
 				}))
@@ -292,16 +314,18 @@ function connect() {
 					console.error("couldn't find task by id: " + e.payload.id)
 				}
 				break
-			case 'task:completion_toggled':
+			case 'task:status_changed':
 				task = tasksMap.get(e.payload.id)
 				if (task) {
-					task.checkBox.dispatchEvent(new Event('change', {
-						bubbles: false,
-					}))
+					task.move(e.payload.stateID)
 				} else {
 					console.error("couldn't find task by id: " + e.payload.id)
 				}
 				break
+			case 'repo:states_changed':
+				// Columns are rendered by the server so redraw the board.
+				location.reload()
+				break
 			case 'task:description_changed':
 				task = tasksMap.get(e.payload.id)
 				if (task) {
@@ -325,6 +349,49 @@ function connect() {
 					console.error('no task for ' + e.payload.taskID)
 				}
 				break
+			case 'task:blocked':
+			case 'task:unblocked':
+				task = tasksMap.get(e.payload.id)
+				if (task) {
+					task.wrapper.classList.toggle('blocked', e.type == 'task:blocked')
+				}
+				break
+			case 'task:checklist_item_added':
+				task = tasksMap.get(e.payload.item.taskID)
+				if (task) {
+					task.checklist.add(e.payload.item)
+				}
+				break
+			case 'task:checklist_item_changed':
+				task = tasksMap.get(e.payload.item.taskID)
+				if (task) {
+					task.checklist.change(e.payload.item)
+				}
+				break
+			case 'task:checklist_item_removed':
+				task = tasksMap.get(e.payload.taskID)
+				if (task) {
+					task.checklist.remove(e.payload.id)
+				}
+				break
+			case 'comment:added':
+				task = tasksMap.get(e.payload.comment.taskID)
+				if (task) {
+					task.comments.receive(e.payload.comment)
+				}
+				break
+			case 'comment:edited':
+				task = tasksMap.get(e.payload.taskID)
+				if (task) {
+					task.comments.edit(e.payload.id, e.payload.value)
+				}
+				break
+			case 'comment:deleted':
+				task = tasksMap.get(e.payload.taskID)
+				if (task) {
+					task.comments.remove(e.payload.id)
+				}
+				break
 			case 'task:unattach_contributor':
 				task = tasksMap.get(e.payload.taskID)
 				if (task) {
@@ -1,37 +1,38 @@
 class Task {
-	constructor(wrapper, description, id, isCompleted) {
+	constructor(wrapper, description, id, stateId) {
 		this.id = id
 
 		this.elem = this.getTaskElement()
 		this.remove = this.getRemoveElement()
-		this.checkBox = this.getCheckBoxElement()
-		this.checkBoxIcon = this.getCheckBoxIconElement()
+		this.state = this.getStateElement()
 		this.description = this.getDescriptionElement(description)
-		this.checkBox.checked = isCompleted
+		this.state.value = stateId
 
 		this.wrapper = this.getWrapper(wrapper)
 
-		this.checkBox.onchange = (event) => this.onCheckBox(event)
+		this.state.onchange = (event) => this.onStateChange(event)
 		this.remove.onclick = (event) => this.onRemove(event)
 		this.description.onchange = (event) => this.onDescriptionChange(event)
 	}
 
 	getTaskElement() {
 		let label = document.createElement('label')
-		label.classList.add('checkbox', 'inline-flex', 'center-h', 'gap')
+		label.classList.add('inline-flex', 'center-h', 'gap')
 		return label
 	}
 
-	getCheckBoxElement() {
-		let input = document.createElement('input')
-		input.type = 'checkbox'
-		return input
-	}
-
-	getCheckBoxIconElement() {
-		let span = document.createElement('span')
-		span.className = 'checkbox__icon'
-		return span
+	// getStateElement returns a select with an option for every column of
+	// the board.
+	getStateElement() {
+		let select = document.createElement('select')
+		select.className = 'state'
+		for (const column of document.querySelectorAll('.state-column')) {
+			let option = document.createElement('option')
+			option.value = column.dataset.stateId
+			option.textContent = column.querySelector('h3').textContent
+			select.append(option)
+		}
+		return select
 	}
 
 	getRemoveElement() {
@@ -49,7 +50,7 @@ class Task {
 	}
 
 	getWrapper(wrapper) {
-		this.elem.append(this.checkBox, this.checkBoxIcon, this.description)
+		this.elem.append(this.state, this.description)
 		wrapper.className = 'task'
 		wrapper.setAttribute('data-task-id', this.id)
 		wrapper.append(this.remove, this.elem)
@@ -99,16 +100,14 @@ class Task {
 			})
 	}
 
-	onCheckBox(event) {
-		if (event.target != this.checkBox) {
+	onStateChange(event) {
+		if (event.target != this.state) {
 			event.preventDefault()
 			return false
-		} else if (!this.description.value.trim()) { // if its input is empty
-			this.description.focus()
-			return false; // prevent from adding the elem to the .completed-elem-list
 		}
 
-		toggleCompletion(this.id).
+		const stateId = this.state.value
+		setStatus(this.id, stateId).
 			then(ok => {
 				if (!ok) {
 					event.preventDefault()
@@ -116,19 +115,20 @@ class Task {
 				}
 			})
 
+		this.move(stateId)
+	}
+
+	// move moves the task to the column of the given state.
+	move(stateId) {
+		this.state.value = stateId
 		this.wrapper.dispatchEvent(new CustomEvent('escape-task', {
 			bubbles: true,
 			detail: {
 				id: this.id,
 				remove: false,
+				stateId: stateId,
 			}
 		}))
-		if (this.description.style.opacity = '0.4') {
-
-			this.description.style.opacity = '1'
-		} else {
-			this.description.style.opacity = '0.4'
-		}
 	}
 }
 
@@ -143,17 +143,14 @@ class UserTask extends Task {
 		return input
 	}
 
-	getCheckBoxElement() {
-		let input = document.createElement('input')
-		input.type = 'checkbox'
-		input.onclick = (event) => {
-			event.preventDefault()
-		}
-		return input
+	getStateElement() {
+		let select = super.getStateElement()
+		select.disabled = true
+		return select
 	}
 
 	getWrapper(wrapper) {
-		this.elem.append(this.checkBox, this.checkBoxIcon, this.description)
+		this.elem.append(this.state, this.description)
 		wrapper.className = 'task'
 		wrapper.setAttribute('data-task-id', this.id)
 		wrapper.append(this.elem)
@@ -161,24 +158,6 @@ class UserTask extends Task {
 		return wrapper
 	}
 
-	onCheckBox() {
-		this.wrapper.dispatchEvent(new CustomEvent('escape-task', {
-			bubbles: true,
-			detail: {
-				id: this.id,
-				remove: false,
-			}
-		}))
-
-		this.checkBox.checked = this.checkBox.checked ? false : true
-
-		if (this.description.style.opacity = '0.4') {
-			this.description.style.opacity = '1'
-		} else {
-			this.description.style.opacity = '0.4'
-		}
-	}
-
 }
 
 async function deleteTask(taskId) {
@@ -202,7 +181,7 @@ async function deleteTask(taskId) {
 	}
 }
 
-async function toggleCompletion(taskId) {
+async function setStatus(taskId, stateId) {
 	try {
 
 		let resp = await fetch('/tasks/' + taskId, {
@@ -212,7 +191,7 @@ async function toggleCompletion(taskId) {
 				'Accept': 'application/json',
 			},
 			body: JSON.stringify({
-				toggleCompletion: true,
+				setStatus: parseInt(stateId),
 			}),
 		})
 		if (resp.ok) {
@@ -1,6 +1,8 @@
 package http
 
 import (
+	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
@@ -9,34 +11,62 @@ import (
 	"net/http"
 	"strconv"
 
-	"github.com/google/go-github/v32/github"
 	"github.com/gorilla/mux"
 	"github.com/saiddis/todev"
-	"golang.org/x/oauth2"
+	"github.com/saiddis/todev/http/html"
+	"github.com/saiddis/todev/http/json"
 )
 
 // registerAuthRoutes is a helper function to register auth routes to the router.
 func (s *Server) registerAuthRoutes(r *mux.Router) {
 	r.HandleFunc("/login", s.handleLogin).Methods("GET")
+	r.HandleFunc("/oauth/{source}", s.handleOAuth).Methods("GET")
+	r.HandleFunc("/oauth/{source}/callback", s.handleOAuthCallback).Methods("GET")
+}
+
+// registerLogoutRoutes is a helper function to register the logout route. It
+// needs the session loaded by the authenticate middleware so it can be revoked.
+func (s *Server) registerLogoutRoutes(r *mux.Router) {
 	r.HandleFunc("/logout", s.handleLogout).Methods("DELETE")
-	r.HandleFunc("/oauth/github", s.handleOAuthGitHub).Methods("GET")
-	r.HandleFunc("/oauth/github/callback", s.handleOAuthGitHubCallback).Methods("GET")
 }
 
-// handleLogin handles the "GET /login" route.
+// registerAuthAPIRoutes is a helper function to register routes for managing
+// the current user's auth objects. These routes are only called via JSON API.
+func (s *Server) registerAuthAPIRoutes(r *mux.Router) {
+	r.HandleFunc("/auths", s.handleAuthIndex).Methods("GET")
+	r.HandleFunc("/auths/{id}", s.handleAuthView).Methods("GET")
+	r.HandleFunc("/auths/{id}", s.handleAuthDelete).Methods("DELETE")
+}
+
+// handleLogin handles the "GET /login" route. It lists the configured
+// OAuth providers and the local account form if enabled.
 func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
-	if tmpl, err := template.ParseFS(templateFiles, "html/login.html"); err != nil {
+	tmpl, err := template.ParseFS(templateFiles, "html/login.html")
+	if err != nil {
 		LogError(r, fmt.Errorf("error parsing html file: %v", err))
 		return
-	} else if err = tmpl.Execute(w, nil); err != nil {
+	}
+
+	data := html.LoginTemplate{
+		Providers: s.OAuthProviders,
+		Local:     s.PasswordService != nil,
+	}
+	if err = tmpl.Execute(w, data); err != nil {
 		LogError(r, fmt.Errorf("error executing template: %v", err))
 		return
 	}
 }
 
-// handleLogout handles the "DELETE /logout" route. It clears the session cookie
-// and redirects the user to the home page.
+// handleLogout handles the "DELETE /logout" route. It revokes the current
+// session, clears the session cookie and redirects the user to the home page.
 func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if session := todev.SessionFromContext(r.Context()); session != nil {
+		if err := s.SessionService.DeleteSession(r.Context(), session.ID); err != nil && todev.ErrorCode(err) != todev.ENOTFOUND {
+			Error(w, r, fmt.Errorf("error revoking session: %w", err))
+			return
+		}
+	}
+
 	// Clear session cookie on HTTP response.
 	if err := s.setSession(w, Session{}); err != nil {
 		Error(w, r, err)
@@ -46,12 +76,18 @@ func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/", http.StatusFound)
 }
 
-// handleOAuthGitHub handles the "GET /oauth/github" route. It generates a random
-// state variable and redirects the user to the GitHub OAuth endpoint.
+// handleOAuth handles the "GET /oauth/:source" route. It generates a random
+// state variable and redirects the user to the provider's OAuth endpoint.
 //
 // After authentication, user will be redirected back to the callback page where
 // we can store the returned OAuth tokens.
-func (s *Server) handleOAuthGitHub(w http.ResponseWriter, r *http.Request) {
+func (s *Server) handleOAuth(w http.ResponseWriter, r *http.Request) {
+	provider, err := s.OAuthProvider(mux.Vars(r)["source"])
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
 	session, err := s.session(r)
 	if err != nil {
 		Error(w, r, err)
@@ -71,15 +107,20 @@ func (s *Server) handleOAuthGitHub(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	http.Redirect(w, r, s.OAuth2Config().AuthCodeURL(session.State), http.StatusFound)
-
+	http.Redirect(w, r, provider.AuthCodeURL(session.State, s.oauthRedirectURL(provider)), http.StatusFound)
 }
 
-// handleOAuthGitHubCallback handles the "GET /oauth/github/callback" route.
+// handleOAuthCallback handles the "GET /oauth/:source/callback" route.
 // It validates the required OAuth state that we generated previously, looks up
 // the current user's information, and creates an "Auth" object in the database.
-func (s *Server) handleOAuthGitHubCallback(w http.ResponseWriter, r *http.Request) {
-	// Read form variables passed in from GitHub.
+func (s *Server) handleOAuthCallback(w http.ResponseWriter, r *http.Request) {
+	provider, err := s.OAuthProvider(mux.Vars(r)["source"])
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Read form variables passed in from the provider.
 	state, code := r.FormValue("state"), r.FormValue("code")
 
 	session, err := s.session(r)
@@ -95,53 +136,33 @@ func (s *Server) handleOAuthGitHubCallback(w http.ResponseWriter, r *http.Reques
 	}
 
 	// Exchange code for OAuth tokens.
-	tok, err := s.OAuth2Config().Exchange(r.Context(), code)
+	tok, err := provider.Exchange(r.Context(), code, s.oauthRedirectURL(provider))
 	if err != nil {
 		Error(w, r, fmt.Errorf("oauth exchage error: %v", err))
 		return
 	}
 
-	// Create a new GitHub API token.
-	client := github.NewClient(oauth2.NewClient(r.Context(), oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: tok.AccessToken},
-	)))
-
 	// Fetch user information for the currently authenticated user.
-	// Require that we at least recieve a user ID from GitHub.
-
-	u, _, err := client.Users.Get(r.Context(), "")
+	u, err := provider.UserInfo(r.Context(), tok.AccessToken)
 	if err != nil {
-		Error(w, r, fmt.Errorf("user ID not returned by GitHub, cannot authenticate user"))
+		Error(w, r, fmt.Errorf("cannot authenticate user with %s: %v", provider.Source(), err))
 		return
 	}
 
-	// Email is not nessarily availible for all accounts. If it is, store it
-	// so we can link together multiple OAuth providers in the future.
-	var name string
-	if u.Name != nil {
-		name = *u.Name
-	} else if u.Login != nil {
-		name = *u.Login
-	}
-	var email string
-	if u.Email != nil {
-		email = *u.Email
-	}
-
-	// Create an authentication object with the associated user.
+	// Create an authentication object with the associated user. Email is
+	// used to link together multiple OAuth providers if available.
 	auth := &todev.Auth{
-		Source:       todev.AuthSourceGitHub,
-		SourceID:     strconv.FormatInt(*u.ID, 10),
+		Source:       provider.Source(),
+		SourceID:     u.ID,
 		AccessToken:  tok.AccessToken,
 		RefreshToken: tok.RefreshToken,
+		Expiry:       tok.Expiry,
+		AvatarURL:    u.AvatarURL,
 		User: &todev.User{
-			Name:  name,
-			Email: email,
+			Name:  u.Name,
+			Email: u.Email,
 		},
 	}
-	if !tok.Expiry.IsZero() {
-		auth.Expiry = tok.Expiry
-	}
 
 	// Create the "Auth" object in the database. The AuthService will lookup
 	// the user by email if they already exist. Otherwise, a new user will be
@@ -151,15 +172,32 @@ func (s *Server) handleOAuthGitHubCallback(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	s.login(w, r, session, auth.UserID, auth.AvatarURL)
+}
+
+// login creates a server-side session for the user, stores it in the browser
+// session and redirects to the URL stored before logging in. Used by every
+// login method so they all produce the same session.
+func (s *Server) login(w http.ResponseWriter, r *http.Request, session Session, userID int, avatarURL string) {
+	other := &todev.Session{
+		UserID:    userID,
+		UserAgent: clientUserAgent(r),
+		IP:        clientIP(r),
+	}
+	if err := s.SessionService.CreateSession(r.Context(), other); err != nil {
+		Error(w, r, fmt.Errorf("error creating session: %w", err))
+		return
+	}
+
 	// Restore redirect URL stored on login.
 	redirectURL := session.RedirectURL
 
-	// Update browser session to store user's ID and clear OAuth state.
-	session.UserID = auth.UserID
+	// Update browser session to store the session secret and clear OAuth state.
+	session.Secret = other.Secret
 	session.RedirectURL = ""
 	session.State = ""
-	session.AvatarURL = auth.AvatarURL()
-	if err = s.setSession(w, session); err != nil {
+	session.AvatarURL = avatarURL
+	if err := s.setSession(w, session); err != nil {
 		Error(w, r, fmt.Errorf("error setting session cookie: %v", err))
 		return
 	}
@@ -171,3 +209,178 @@ func (s *Server) handleOAuthGitHubCallback(w http.ResponseWriter, r *http.Reques
 
 	http.Redirect(w, r, redirectURL, http.StatusFound)
 }
+
+// oauthRedirectURL returns the callback URL the provider sends users back to.
+func (s *Server) oauthRedirectURL(provider todev.OAuthProvider) string {
+	return s.URL() + "/oauth/" + provider.Source() + "/callback"
+}
+
+// handleAuthIndex handles the "GET /auths" route. Results are always restricted
+// to the current user's auths.
+func (s *Server) handleAuthIndex(w http.ResponseWriter, r *http.Request) {
+	r.Header.Set("Accept", "application/json")
+
+	var filter todev.AuthFilter
+	if err := json.Decode(r.Body, &filter); err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid JSON body"))
+		return
+	}
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			LogError(r, fmt.Errorf("error closing request body: %v", err))
+		}
+	}()
+
+	userID := todev.UserIDFromContext(r.Context())
+	filter.UserID = &userID
+
+	auths, n, err := s.AuthService.FindAuths(r.Context(), filter)
+	if err != nil {
+		Error(w, r, fmt.Errorf("error retrieving auths: %w", err))
+		return
+	} else if err = json.Write(w, http.StatusOK, json.FindAuthsResponse{Auths: auths, N: n}); err != nil {
+		LogError(r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
+// handleAuthView handles the "GET /auths/:id" route. Returns ENOTFOUND if the
+// auth does not belong to the current user.
+func (s *Server) handleAuthView(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	r.Header.Set("Accept", "application/json")
+
+	auth, err := s.AuthService.FindAuthByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, fmt.Errorf("error retrieving auth by ID=%d: %w", id, err))
+		return
+	} else if auth.UserID != todev.UserIDFromContext(r.Context()) {
+		Error(w, r, todev.Errorf(todev.ENOTFOUND, "Auth not found."))
+		return
+	} else if err = json.Write(w, http.StatusOK, auth); err != nil {
+		LogError(r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
+// handleAuthDelete handles the "DELETE /auths/:id" route.
+func (s *Server) handleAuthDelete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	r.Header.Set("Accept", "application/json")
+
+	if err = s.AuthService.DeleteAuth(r.Context(), id); err != nil {
+		Error(w, r, fmt.Errorf("error deleting auth by ID=%d: %w", id, err))
+		return
+	} else if err = json.Write(w, http.StatusOK, struct{}{}); err != nil {
+		LogError(r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
+// AuthService implements the todev.AuthService over the HTTP protocol.
+// The server only exposes the auths of the current user.
+type AuthService struct {
+	Client *Client
+}
+
+func NewAuthService(client *Client) *AuthService {
+	return &AuthService{Client: client}
+}
+
+// FindAuthByID retrieves an auth object by ID along with the associated user.
+// Returns ENOTFOUND if the auth doesn't exist or belongs to another user.
+func (s *AuthService) FindAuthByID(ctx context.Context, id int) (*todev.Auth, error) {
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/auths/%d", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var auth todev.Auth
+	if err = json.Decode(resp.Body, &auth); err != nil {
+		return nil, err
+	}
+	return &auth, nil
+}
+
+// FindAuths retrieves the current user's auth objects based on a filter.
+// Also returns a count of total matching auths.
+func (s *AuthService) FindAuths(ctx context.Context, filter todev.AuthFilter) ([]*todev.Auth, int, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := json.Encode(filter, buf); err != nil {
+		return nil, 0, fmt.Errorf("error creating request: %v", err)
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", "/auths", buf)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal result set of auths and total auth count.
+	var jsonResponse json.FindAuthsResponse
+	if err = json.Decode(resp.Body, &jsonResponse); err != nil {
+		return nil, 0, fmt.Errorf("error decoding response: %v", err)
+	}
+	return jsonResponse.Auths, jsonResponse.N, nil
+}
+
+// AccessToken is not supported by the HTTP client as OAuth tokens are never
+// returned by the server.
+func (s *AuthService) AccessToken(ctx context.Context, id int) (string, error) {
+	return "", todev.Errorf(todev.ENOTIMPLEMENTED, "Not implemented.")
+}
+
+// CreateAuth is not supported by the HTTP client as auths are only created by
+// logging in through the OAuth provider.
+func (s *AuthService) CreateAuth(ctx context.Context, auth *todev.Auth) error {
+	return todev.Errorf(todev.ENOTIMPLEMENTED, "Not implemented.")
+}
+
+// DeleteAuth permanently removes an auth object by ID. The parent user object
+// is not removed.
+func (s *AuthService) DeleteAuth(ctx context.Context, id int) error {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "DELETE", fmt.Sprintf("/auths/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
@@ -1,11 +1,15 @@
 package http_test
 
 import (
+	"context"
 	"net/http"
+	"net/http/cookiejar"
 	"net/url"
 	"testing"
 
+	"github.com/saiddis/todev"
 	todevhttp "github.com/saiddis/todev/http"
+	"github.com/saiddis/todev/mock"
 )
 
 func TestLogin_OAuth_GitHub(t *testing.T) {
@@ -51,3 +55,162 @@ func TestLogin_OAuth_GitHub(t *testing.T) {
 		t.Fatalf("Location.Query.state=%s, want %s", got, want)
 	}
 }
+
+// Ensure the callback of a provider exchanges the code, creates an auth from
+// the provider's user info and logs the user in.
+func TestLogin_OAuth_Callback(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	var provider mock.OAuthProvider
+	provider.SourceFn = func() string { return todev.AuthSourceGitLab }
+	provider.AuthCodeURLFn = func(state, redirectURL string) string {
+		if got, want := redirectURL, s.URL()+"/oauth/gitlab/callback"; got != want {
+			t.Fatalf("redirectURL=%q, want %q", got, want)
+		}
+		return "https://gitlab.example.com/oauth/authorize?state=" + state
+	}
+	provider.ExchangeFn = func(ctx context.Context, code, redirectURL string) (*todev.OAuthToken, error) {
+		if got, want := code, "CODE"; got != want {
+			t.Fatalf("code=%q, want %q", got, want)
+		}
+		return &todev.OAuthToken{AccessToken: "TOKEN", RefreshToken: "REFRESH"}, nil
+	}
+	provider.UserInfoFn = func(ctx context.Context, accessToken string) (*todev.OAuthUser, error) {
+		return &todev.OAuthUser{ID: "100", Name: "bob", Email: "bob@gmail.com", AvatarURL: "https://example.com/bob.png"}, nil
+	}
+	s.OAuthProviders = append(s.OAuthProviders, &provider)
+
+	s.AuthService.CreateAuthFn = func(ctx context.Context, auth *todev.Auth) error {
+		if got, want := auth.Source, todev.AuthSourceGitLab; got != want {
+			t.Fatalf("Source=%q, want %q", got, want)
+		} else if got, want := auth.SourceID, "100"; got != want {
+			t.Fatalf("SourceID=%q, want %q", got, want)
+		} else if got, want := auth.RefreshToken, "REFRESH"; got != want {
+			t.Fatalf("RefreshToken=%q, want %q", got, want)
+		} else if got, want := auth.User.Email, "bob@gmail.com"; got != want {
+			t.Fatalf("User.Email=%q, want %q", got, want)
+		}
+		auth.UserID = 1
+		return nil
+	}
+
+	s.SessionService.CreateSessionFn = func(ctx context.Context, session *todev.Session) error {
+		if got, want := session.UserID, 1; got != want {
+			t.Fatalf("UserID=%d, want %d", got, want)
+		}
+		session.ID, session.Secret = 1, "SESSION"
+		return nil
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{
+		Jar: jar,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	// Start the login to store the OAuth state in the session.
+	resp, err := client.Get(s.URL() + "/oauth/gitlab")
+	if err != nil {
+		t.Fatal(err)
+	} else if err = resp.Body.Close(); err != nil {
+		t.Fatal(err)
+	}
+	loc, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err = client.Get(s.URL() + "/oauth/gitlab/callback?code=CODE&state=" + loc.Query().Get("state"))
+	if err != nil {
+		t.Fatal(err)
+	} else if err = resp.Body.Close(); err != nil {
+		t.Fatal(err)
+	} else if got, want := resp.StatusCode, http.StatusFound; got != want {
+		t.Fatalf("StatusCode=%d, want %d", got, want)
+	}
+
+	var session todevhttp.Session
+	if err = s.UnmarshalSession(resp.Cookies()[0].Value, &session); err != nil {
+		t.Fatal(err)
+	} else if got, want := session.Secret, "SESSION"; got != want {
+		t.Fatalf("Secret=%q, want %q", got, want)
+	} else if got, want := session.AvatarURL, "https://example.com/bob.png"; got != want {
+		t.Fatalf("AvatarURL=%q, want %q", got, want)
+	}
+
+	// Unknown providers are not found.
+	if resp, err = client.Get(s.URL() + "/oauth/bitbucket"); err != nil {
+		t.Fatal(err)
+	} else if err = resp.Body.Close(); err != nil {
+		t.Fatal(err)
+	} else if got, want := resp.StatusCode, http.StatusNotFound; got != want {
+		t.Fatalf("StatusCode=%d, want %d", got, want)
+	}
+}
+
+// Ensure the HTTP client round-trips auth operations through the JSON API and
+// that the server only exposes auths of the current user.
+func TestAuthService(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &todev.User{ID: 1, Name: "user1", APIKey: "apiKey"}
+	ctx0 := todev.NewContextWithUser(context.Background(), user0)
+	s.MustAuthenticateAPIKey(t, user0)
+
+	authService := todevhttp.NewAuthService(todevhttp.NewClient(s.URL()))
+
+	t.Run("FindAuths", func(t *testing.T) {
+		s.AuthService.FindAuthsFn = func(ctx context.Context, filter todev.AuthFilter) ([]*todev.Auth, int, error) {
+			if filter.UserID == nil || *filter.UserID != 1 {
+				t.Fatalf("unexpected filter: %#v", filter)
+			}
+			return []*todev.Auth{{ID: 1, UserID: 1, Source: todev.AuthSourceGitHub, SourceID: "100"}}, 1, nil
+		}
+
+		// Request other user's auths which must be ignored by the server.
+		userID := 2
+		if auths, n, err := authService.FindAuths(ctx0, todev.AuthFilter{UserID: &userID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(auths), 1; got != want {
+			t.Fatalf("len=%d, want %d", got, want)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		}
+	})
+
+	t.Run("FindAuthByID/OtherUser", func(t *testing.T) {
+		s.AuthService.FindAuthByIDFn = func(ctx context.Context, id int) (*todev.Auth, error) {
+			return &todev.Auth{ID: id, UserID: 2}, nil
+		}
+
+		if _, err := authService.FindAuthByID(ctx0, 1); todev.ErrorCode(err) != todev.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	// Auths are only created by logging in through the OAuth provider so
+	// they cannot be linked to a source user someone does not own.
+	t.Run("CreateAuth", func(t *testing.T) {
+		auth := &todev.Auth{Source: todev.AuthSourceGitHub, SourceID: "200", AccessToken: "accessToken"}
+		if err := authService.CreateAuth(ctx0, auth); todev.ErrorCode(err) != todev.ENOTIMPLEMENTED {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("DeleteAuth", func(t *testing.T) {
+		s.AuthService.DeleteAuthFn = func(ctx context.Context, id int) error {
+			return nil
+		}
+
+		if err := authService.DeleteAuth(ctx0, 1); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,256 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/http/json"
+)
+
+// registerCommentRoutes is a helper function for registering comment routes.
+func (s *Server) registerCommentRoutes(r *mux.Router) {
+	// Listing of all comments on a task.
+	r.HandleFunc("/tasks/{id}/comments", s.handleCommentIndex).Methods("GET")
+
+	// API endpoint for commenting on a task.
+	r.HandleFunc("/tasks/{id}/comments", s.handleCommentCreate).Methods("POST")
+
+	// Edit comment.
+	r.HandleFunc("/tasks/{id}/comments/{commentID}", s.handleCommentUpdate).Methods("PATCH")
+
+	// Delete comment.
+	r.HandleFunc("/tasks/{id}/comments/{commentID}", s.handleCommentDelete).Methods("DELETE")
+}
+
+// handleCommentIndex handles the "GET /tasks/:id/comments" route. This route
+// is only called via JSON API and returns the comments of a task from the
+// oldest to the newest.
+func (s *Server) handleCommentIndex(w http.ResponseWriter, r *http.Request) {
+	taskID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	r.Header.Set("Accept", "application/json")
+
+	filter := todev.CommentFilter{TaskID: &taskID}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil {
+			Error(w, r, todev.Errorf(todev.EINVALID, "Invalid limit format"))
+			return
+		}
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		if filter.Offset, err = strconv.Atoi(v); err != nil {
+			Error(w, r, todev.Errorf(todev.EINVALID, "Invalid offset format"))
+			return
+		}
+	}
+
+	comments, n, err := s.CommentService.FindComments(r.Context(), filter)
+	if err != nil {
+		Error(w, r, fmt.Errorf("error retrieving comments: %w", err))
+		return
+	} else if err = json.Write(w, http.StatusOK, json.FindCommentsResponse{Comments: comments, N: n}); err != nil {
+		Error(w, r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
+// handleCommentCreate handles the "POST /tasks/:id/comments" route.
+func (s *Server) handleCommentCreate(w http.ResponseWriter, r *http.Request) {
+	taskID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	r.Header.Set("Accept", "application/json")
+
+	var comment todev.Comment
+	if err = json.Decode(r.Body, &comment); err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid JSON body"))
+		return
+	}
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			LogError(r, fmt.Errorf("error closing request body: %v", err))
+		}
+	}()
+	comment.TaskID = taskID
+
+	if err = s.CommentService.CreateComment(r.Context(), &comment); err != nil {
+		Error(w, r, fmt.Errorf("error creating comment: %w", err))
+		return
+	} else if err = json.Write(w, http.StatusCreated, comment); err != nil {
+		Error(w, r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
+// handleCommentUpdate handles the "PATCH /tasks/:id/comments/:commentID" route.
+func (s *Server) handleCommentUpdate(w http.ResponseWriter, r *http.Request) {
+	taskID, commentID, err := parseCommentVars(r)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	r.Header.Set("Accept", "application/json")
+
+	var upd todev.CommentUpdate
+	if err = json.Decode(r.Body, &upd); err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid JSON body"))
+		return
+	}
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			LogError(r, fmt.Errorf("error closing request body: %v", err))
+		}
+	}()
+
+	if err = s.checkCommentTask(r.Context(), taskID, commentID); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	if comment, err := s.CommentService.UpdateComment(r.Context(), commentID, upd); err != nil {
+		Error(w, r, fmt.Errorf("error updating comment: %w", err))
+		return
+	} else if err = json.Write(w, http.StatusOK, comment); err != nil {
+		Error(w, r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
+// handleCommentDelete handles the "DELETE /tasks/:id/comments/:commentID" route.
+func (s *Server) handleCommentDelete(w http.ResponseWriter, r *http.Request) {
+	taskID, commentID, err := parseCommentVars(r)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	r.Header.Set("Accept", "application/json")
+
+	if err = s.checkCommentTask(r.Context(), taskID, commentID); err != nil {
+		Error(w, r, err)
+		return
+	} else if err = s.CommentService.DeleteComment(r.Context(), commentID); err != nil {
+		Error(w, r, fmt.Errorf("error deleting comment by ID=%d: %w", commentID, err))
+		return
+	} else if err = json.Write(w, http.StatusOK, struct{}{}); err != nil {
+		Error(w, r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
+// parseCommentVars returns the task and comment IDs from the route variables.
+func parseCommentVars(r *http.Request) (taskID, commentID int, err error) {
+	if taskID, err = strconv.Atoi(mux.Vars(r)["id"]); err != nil {
+		return 0, 0, todev.Errorf(todev.EINVALID, "Invalid ID format")
+	} else if commentID, err = strconv.Atoi(mux.Vars(r)["commentID"]); err != nil {
+		return 0, 0, todev.Errorf(todev.EINVALID, "Invalid comment ID format")
+	}
+	return taskID, commentID, nil
+}
+
+// checkCommentTask returns ENOTFOUND if the comment does not belong to the task.
+func (s *Server) checkCommentTask(ctx context.Context, taskID, commentID int) error {
+	comment, err := s.CommentService.FindCommentByID(ctx, commentID)
+	if err != nil {
+		return fmt.Errorf("error retrieving comment by ID=%d: %w", commentID, err)
+	} else if comment.TaskID != taskID {
+		return todev.Errorf(todev.ENOTFOUND, "Comment not found.")
+	}
+	return nil
+}
+
+// CommentService implements the todev.CommentService over the HTTP protocol.
+type CommentService struct {
+	Client *Client
+}
+
+// NewCommentService returns a new instance of CommentService.
+func NewCommentService(client *Client) *CommentService {
+	return &CommentService{Client: client}
+}
+
+// FindCommentByID is not supported by the HTTP API as comments are always
+// accessed through their task. Use FindComments() with an ID filter instead.
+func (s *CommentService) FindCommentByID(ctx context.Context, id int) (*todev.Comment, error) {
+	return nil, todev.Errorf(todev.ENOTIMPLEMENTED, "Not implemented.")
+}
+
+// FindComments retrieves the comments of a task. The filter requires a task ID.
+// Also returns a count of total matching comments.
+func (s *CommentService) FindComments(ctx context.Context, filter todev.CommentFilter) ([]*todev.Comment, int, error) {
+	if filter.TaskID == nil {
+		return nil, 0, todev.Errorf(todev.EINVALID, "Task ID required.")
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/tasks/%d/comments?limit=%d&offset=%d", *filter.TaskID, filter.Limit, filter.Offset), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var jsonResponse json.FindCommentsResponse
+	if err = json.Decode(resp.Body, &jsonResponse); err != nil {
+		return nil, 0, fmt.Errorf("error decoding response: %v", err)
+	}
+	return jsonResponse.Comments, jsonResponse.N, nil
+}
+
+// CreateComment creates a new comment on a task. On success, comment is
+// updated with the new state returned by the server.
+func (s *CommentService) CreateComment(ctx context.Context, comment *todev.Comment) error {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := json.Encode(comment, buf); err != nil {
+		return fmt.Errorf("error creating request: %v", err)
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/tasks/%d/comments", comment.TaskID), buf)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-201 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusCreated {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return json.Decode(resp.Body, comment)
+}
+
+// UpdateComment is not supported by the HTTP client as comment routes are
+// scoped by task. Use the JSON API directly instead.
+func (s *CommentService) UpdateComment(ctx context.Context, id int, upd todev.CommentUpdate) (*todev.Comment, error) {
+	return nil, todev.Errorf(todev.ENOTIMPLEMENTED, "Not implemented.")
+}
+
+// DeleteComment is not supported by the HTTP client as comment routes are
+// scoped by task. Use the JSON API directly instead.
+func (s *CommentService) DeleteComment(ctx context.Context, id int) error {
+	return todev.Errorf(todev.ENOTIMPLEMENTED, "Not implemented.")
+}
@@ -0,0 +1,84 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/saiddis/todev"
+	todevhttp "github.com/saiddis/todev/http"
+)
+
+// Ensure the HTTP client round-trips comment operations through the JSON API.
+func TestCommentService(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &todev.User{ID: 1, Name: "user1", APIKey: "apiKey"}
+	ctx0 := todev.NewContextWithUser(context.Background(), user0)
+	s.MustAuthenticateAPIKey(t, user0)
+
+	commentService := todevhttp.NewCommentService(todevhttp.NewClient(s.URL()))
+
+	t.Run("FindComments", func(t *testing.T) {
+		s.CommentService.FindCommentsFn = func(ctx context.Context, filter todev.CommentFilter) ([]*todev.Comment, int, error) {
+			if filter.TaskID == nil || *filter.TaskID != 1 {
+				t.Fatalf("unexpected filter: %#v", filter)
+			} else if filter.Limit != 10 {
+				t.Fatalf("Limit=%d, want 10", filter.Limit)
+			}
+			return []*todev.Comment{{ID: 1, TaskID: 1}, {ID: 2, TaskID: 1}}, 2, nil
+		}
+
+		taskID := 1
+		if comments, n, err := commentService.FindComments(ctx0, todev.CommentFilter{TaskID: &taskID, Limit: 10}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(comments), 2; got != want {
+			t.Fatalf("len=%d, want %d", got, want)
+		} else if got, want := n, 2; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		}
+	})
+
+	t.Run("CreateComment", func(t *testing.T) {
+		s.CommentService.CreateCommentFn = func(ctx context.Context, comment *todev.Comment) error {
+			if comment.TaskID != 1 {
+				t.Fatalf("TaskID=%d, want 1", comment.TaskID)
+			}
+			comment.ID, comment.UserID = 3, todev.UserIDFromContext(ctx)
+			return nil
+		}
+
+		comment := &todev.Comment{TaskID: 1, Body: "Looks good."}
+		if err := commentService.CreateComment(ctx0, comment); err != nil {
+			t.Fatal(err)
+		} else if got, want := comment.ID, 3; got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		} else if got, want := comment.UserID, 1; got != want {
+			t.Fatalf("UserID=%d, want %d", got, want)
+		}
+	})
+
+	t.Run("ErrDeleteOtherTask", func(t *testing.T) {
+		s.CommentService.FindCommentByIDFn = func(ctx context.Context, id int) (*todev.Comment, error) {
+			return &todev.Comment{ID: id, TaskID: 2}, nil
+		}
+		s.CommentService.DeleteCommentFn = func(ctx context.Context, id int) error {
+			t.Fatal("unexpected delete")
+			return nil
+		}
+
+		req := s.MustNewRequest(t, context.Background(), "DELETE", "/tasks/1/comments/3", nil)
+		req.Header.Set("Authorization", "Bearer apiKey")
+		req.Header.Set("Accept", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if got, want := resp.StatusCode, http.StatusNotFound; got != want {
+			t.Fatalf("StatusCode=%d, want %d", got, want)
+		}
+	})
+}
@@ -1,9 +1,12 @@
 package http
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"html/template"
 	"net/http"
+	"net/url"
 	"strconv"
 
 	"github.com/gorilla/mux"
@@ -15,10 +18,16 @@ import (
 // registerContributorRoutes is a helper function for registering contirbutor
 // routes.
 func (s *Server) registerContributorRoutes(r *mux.Router) {
-	// Create contributor via invite code
+	// Create contributor via invitation code
 	r.HandleFunc("/invite/{code}", s.handleContirbutorNew).Methods("GET")
 	r.HandleFunc("/invite/{code}", s.handleContributorCreate).Methods("POST")
 
+	// Listing of all contributors user can view.
+	r.HandleFunc("/contributors", s.handleContributorIndex).Methods("GET")
+
+	// View a single contributor.
+	r.HandleFunc("/contributor/{id}", s.handleContributorView).Methods("GET")
+
 	// Update contributor
 	r.HandleFunc("/contributor/{id}", s.handleContritbutorUpdate).Methods("PATCH")
 
@@ -27,26 +36,30 @@ func (s *Server) registerContributorRoutes(r *mux.Router) {
 }
 
 // handleContirbutorNew handles the "GET /invite/:code" route. This route uses
-// the repo's invite code to allow users to join an existing repo.
+// an invitation code to allow users to join an existing repo.
 func (s *Server) handleContirbutorNew(w http.ResponseWriter, r *http.Request) {
 	userID := todev.UserIDFromContext(r.Context())
 
 	code := mux.Vars(r)["code"]
 
-	repos, _, err := s.RepoService.FindRepos(r.Context(), todev.RepoFilter{InviteCode: &code})
+	inv, err := s.ContributorService.FindInvitationByCode(r.Context(), code)
 	if err != nil {
-		Error(w, r, fmt.Errorf("error retrieving repos: %v", err))
+		Error(w, r, fmt.Errorf("error retrieving invitation: %w", err))
 		return
-	} else if len(repos) == 0 {
-		Error(w, r, todev.Errorf(todev.ENOTFOUND, "Invalid invitation URL."))
+	}
+
+	if r.Header.Get("Accept") == "application/json" {
+		if err = json.Write(w, http.StatusOK, inv); err != nil {
+			LogError(r, fmt.Errorf("error writing response: %v", err))
+		}
 		return
 	}
 
 	if contributors, _, err := s.ContributorService.FindContributors(r.Context(), todev.ContributorFilter{
-		RepoID: &repos[0].ID,
+		RepoID: &inv.RepoID,
 		UserID: &userID,
 	}); err != nil {
-		Error(w, r, fmt.Errorf("error retrieving contributors: %v", err))
+		Error(w, r, fmt.Errorf("error retrieving contributors: %w", err))
 		return
 	} else if len(contributors) != 0 {
 		SetFlash(w, "You are already a contributing for this repo.")
@@ -54,7 +67,7 @@ func (s *Server) handleContirbutorNew(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tmplData := html.ContributorCreateTemplate{Repo: repos[0]}
+	tmplData := html.ContributorCreateTemplate{Invitation: inv}
 	if tmpl, err := template.ParseFS(templateFiles, "html/base.html", "html/contributorCreate.html"); err != nil {
 		LogError(r, fmt.Errorf("error parsing html file: %v", err))
 		return
@@ -64,34 +77,26 @@ func (s *Server) handleContirbutorNew(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// handleContributorCreate handles the "POST /repos/:code" route. This route
-// adds a new contributor for the current user's user ID to a repo.
+// handleContributorCreate handles the "POST /invite/:code" route. This route
+// adds a new contributor for the current user's user ID to the repo of the
+// invitation.
 func (s *Server) handleContributorCreate(w http.ResponseWriter, r *http.Request) {
-	userID := todev.UserIDFromContext(r.Context())
-
 	code := mux.Vars(r)["code"]
 
-	repos, _, err := s.RepoService.FindRepos(r.Context(), todev.RepoFilter{InviteCode: &code})
+	contributor, err := s.ContributorService.JoinRepo(r.Context(), code)
 	if err != nil {
-		Error(w, r, fmt.Errorf("error retrieving repos: %v", err))
-		return
-	} else if len(repos) == 0 {
-		Error(w, r, todev.Errorf(todev.ENOTFOUND, "Invalid invitation URL."))
+		Error(w, r, fmt.Errorf("error joining repo: %w", err))
 		return
 	}
 
-	// Create a new membership between the current user and the repo associated
-	// with the invite code.
-	contributor := &todev.Contributor{
-		RepoID: repos[0].ID,
-		UserID: userID,
-	}
-	if err = s.ContributorService.CreateContributor(r.Context(), contributor); err != nil {
-		Error(w, r, fmt.Errorf("error creating contirbutor: %v", err))
+	if r.Header.Get("Accept") == "application/json" {
+		if err = json.Write(w, http.StatusCreated, contributor); err != nil {
+			LogError(r, fmt.Errorf("error writing response: %v", err))
+		}
 		return
 	}
 
-	SetFlash(w, fmt.Sprintf("You have now joined the %s repo", repos[0].Name))
+	SetFlash(w, "You have now joined the repo.")
 	http.Redirect(w, r, fmt.Sprintf("/repos/%d", contributor.RepoID), http.StatusFound)
 }
 
@@ -119,7 +124,7 @@ func (s *Server) handleContritbutorUpdate(w http.ResponseWriter, r *http.Request
 	}()
 
 	if contritbutor, err := s.ContributorService.UpdateContributor(r.Context(), id, upd); err != nil {
-		Error(w, r, fmt.Errorf("error updating contritbutor: %v", err))
+		Error(w, r, fmt.Errorf("error updating contritbutor: %w", err))
 		return
 	} else if err = json.Encode(contritbutor, w); err != nil {
 		LogError(r, fmt.Errorf("error writing response: %v", err))
@@ -138,21 +143,226 @@ func (s *Server) handleContritbutorDelete(w http.ResponseWriter, r *http.Request
 
 	contritbutor, err := s.ContributorService.FindContributorByID(r.Context(), id)
 	if err != nil {
-		Error(w, r, fmt.Errorf("error retrieving contritbutor by ID: %v", err))
+		Error(w, r, fmt.Errorf("error retrieving contritbutor by ID: %w", err))
 		return
 	} else if err = s.ContributorService.DeleteContributor(r.Context(), id); err != nil {
-		Error(w, r, fmt.Errorf("error deleting contritbutor: %v", err))
+		Error(w, r, fmt.Errorf("error deleting contritbutor: %w", err))
+		return
+	}
+
+	if r.Header.Get("Accept") == "application/json" {
+		if err = json.Write(w, http.StatusOK, struct{}{}); err != nil {
+			LogError(r, fmt.Errorf("error writing response: %v", err))
+		}
 		return
 	}
 
 	SetFlash(w, "Contributor successfully deleted.")
 
-	// If user is owner then redirect back to the repo's view page. However,
-	// if user was just a member then they won't be able to see the repo anymore
+	// If user removed someone else then redirect back to the repo's view page.
+	// However, if user left the repo then they won't be able to see it anymore
 	// so redirect them to the home page.
-	if contritbutor.OwnerID == todev.UserIDFromContext(r.Context()) {
+	if contritbutor.UserID != todev.UserIDFromContext(r.Context()) {
 		http.Redirect(w, r, fmt.Sprintf("/repos/%d", contritbutor.RepoID), http.StatusFound)
 	} else {
 		http.Redirect(w, r, fmt.Sprintf("/repos"), http.StatusFound)
 	}
 }
+
+// handleContributorIndex handles the "GET /contributors" route. This route is
+// only called via JSON API and returns the contributors of repos that the
+// current user is a member of.
+func (s *Server) handleContributorIndex(w http.ResponseWriter, r *http.Request) {
+	r.Header.Set("Accept", "application/json")
+
+	var filter todev.ContributorFilter
+	if err := json.Decode(r.Body, &filter); err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid JSON body."))
+		return
+	}
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			LogError(r, fmt.Errorf("error closing request body: %v", err))
+		}
+	}()
+
+	contributors, n, err := s.ContributorService.FindContributors(r.Context(), filter)
+	if err != nil {
+		Error(w, r, fmt.Errorf("error retrieving contributors: %w", err))
+		return
+	} else if err = json.Write(w, http.StatusOK, json.FindContributorsResponse{
+		Contributors: contributors,
+		N:            n,
+		Next:         nextCursor(contributors, n, filter.Offset, func(c *todev.Contributor) int { return c.ID }),
+	}); err != nil {
+		LogError(r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
+// handleContributorView handles the "GET /contributor/:id" route. This route
+// is only called via JSON API.
+func (s *Server) handleContributorView(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid id format."))
+		return
+	}
+
+	r.Header.Set("Accept", "application/json")
+
+	if contributor, err := s.ContributorService.FindContributorByID(r.Context(), id); err != nil {
+		Error(w, r, fmt.Errorf("error retrieving contritbutor by ID: %w", err))
+		return
+	} else if err = json.Write(w, http.StatusOK, contributor); err != nil {
+		LogError(r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
+// ContributorService implements the todev.ContributorService over the HTTP protocol.
+type ContributorService struct {
+	Client *Client
+}
+
+func NewContributorService(client *Client) *ContributorService {
+	return &ContributorService{Client: client}
+}
+
+// FindContributorByID retrieves a contributor by ID along with associated user.
+// Returns ENOTFOUND if contributor does not exist or user does not have
+// permission to view it.
+func (s *ContributorService) FindContributorByID(ctx context.Context, id int) (*todev.Contributor, error) {
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/contributor/%d", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var contributor todev.Contributor
+	if err = json.Decode(resp.Body, &contributor); err != nil {
+		return nil, err
+	}
+	return &contributor, nil
+}
+
+// FindContributors retrieves a list of contributors based on a filter. Only
+// returns contributors of repos that the current user is a member of. Also
+// returns a count of total matching contributors.
+func (s *ContributorService) FindContributors(ctx context.Context, filter todev.ContributorFilter) ([]*todev.Contributor, int, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := json.Encode(filter, buf); err != nil {
+		return nil, 0, fmt.Errorf("error creating request: %v", err)
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", "/contributors", buf)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal result set of contributors and total contributor count.
+	var jsonResponse json.FindContributorsResponse
+	if err = json.Decode(resp.Body, &jsonResponse); err != nil {
+		return nil, 0, fmt.Errorf("error decoding response: %v", err)
+	}
+	return jsonResponse.Contributors, jsonResponse.N, nil
+}
+
+// CreateContributor is not supported by the HTTP service because joining a
+// repo requires an invitation code. Use JoinRepo instead.
+func (s *ContributorService) CreateContributor(ctx context.Context, contributor *todev.Contributor) error {
+	return todev.Errorf(todev.ENOTIMPLEMENTED, "Joining a repo requires an invitation code.")
+}
+
+// JoinRepo creates a new contributor for the current user on the repo of the
+// invitation with the given code.
+func (s *ContributorService) JoinRepo(ctx context.Context, inviteCode string) (*todev.Contributor, error) {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "POST", "/invite/"+url.PathEscape(inviteCode), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-201 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusCreated {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var contributor todev.Contributor
+	if err = json.Decode(resp.Body, &contributor); err != nil {
+		return nil, err
+	}
+	return &contributor, nil
+}
+
+// UpdateContributor updates the value of a contributor and returns its new state.
+func (s *ContributorService) UpdateContributor(ctx context.Context, id int, upd todev.ContributorUpdate) (*todev.Contributor, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := json.Encode(upd, buf); err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "PATCH", fmt.Sprintf("/contributor/%d", id), buf)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var contributor todev.Contributor
+	if err = json.Decode(resp.Body, &contributor); err != nil {
+		return nil, err
+	}
+	return &contributor, nil
+}
+
+// DeleteContributor permanently removes a contributor by ID. Only the repo
+// owner and the contributor's associated user can delete a contributor.
+func (s *ContributorService) DeleteContributor(ctx context.Context, id int) error {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "DELETE", fmt.Sprintf("/contributor/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
@@ -1,27 +1,169 @@
 package http_test
 
-// import (
-// 	"context"
-// 	"testing"
-// 	"time"
-//
-// 	"github.com/saiddis/todev"
-// )
-//
-// func TestContributorNew(t *testing.T) {
-// 	s := MustOpenServer(t)
-// 	defer MustCloseServer(t, s)
-//
-// 	user0 := &todev.User{ID: 1, Name: "user1", APIKey: "apiKey"}
-// 	ctx0 := todev.NewContextWithUser(context.Background(), user0)
-//
-// 	repo := &todev.Repo{
-// 		ID:         1,
-// 		UserID:     1,
-// 		Name:       "repo1",
-// 		InviteCode: "inviteCode",
-// 		CreatedAt:  time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
-// 		UpdatedAt:  time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
-// 	}
-//
-// }
+import (
+	"context"
+	"testing"
+
+	"github.com/saiddis/todev"
+	todevhttp "github.com/saiddis/todev/http"
+)
+
+// Ensure the HTTP client round-trips contributor operations through the JSON API.
+func TestContributorService(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &todev.User{ID: 1, Name: "user1", APIKey: "apiKey"}
+	ctx0 := todev.NewContextWithUser(context.Background(), user0)
+	s.MustAuthenticateAPIKey(t, user0)
+
+	contributorService := todevhttp.NewContributorService(todevhttp.NewClient(s.URL()))
+
+	t.Run("FindContributors", func(t *testing.T) {
+		s.ContributorService.FindContributorsFn = func(ctx context.Context, filter todev.ContributorFilter) ([]*todev.Contributor, int, error) {
+			if filter.RepoID == nil || *filter.RepoID != 1 {
+				t.Fatalf("unexpected filter: %#v", filter)
+			}
+			return []*todev.Contributor{{ID: 1, RepoID: 1, UserID: 1}}, 1, nil
+		}
+
+		repoID := 1
+		if contributors, n, err := contributorService.FindContributors(ctx0, todev.ContributorFilter{RepoID: &repoID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(contributors), 1; got != want {
+			t.Fatalf("len=%d, want %d", got, want)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		}
+	})
+
+	t.Run("FindContributorByID", func(t *testing.T) {
+		s.ContributorService.FindContributorByIDFn = func(ctx context.Context, id int) (*todev.Contributor, error) {
+			return nil, todev.Errorf(todev.ENOTFOUND, "Contributor not found.")
+		}
+
+		if _, err := contributorService.FindContributorByID(ctx0, 1); todev.ErrorCode(err) != todev.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("JoinRepo", func(t *testing.T) {
+		s.ContributorService.JoinRepoFn = func(ctx context.Context, code string) (*todev.Contributor, error) {
+			if code != "inviteCode" {
+				return nil, todev.Errorf(todev.ENOTFOUND, "Invalid invitation URL.")
+			}
+			return &todev.Contributor{ID: 2, RepoID: 1, Role: todev.RoleViewer}, nil
+		}
+
+		if contributor, err := contributorService.JoinRepo(ctx0, "inviteCode"); err != nil {
+			t.Fatal(err)
+		} else if got, want := contributor.ID, 2; got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		} else if got, want := contributor.RepoID, 1; got != want {
+			t.Fatalf("RepoID=%d, want %d", got, want)
+		} else if got, want := contributor.Role, todev.RoleViewer; got != want {
+			t.Fatalf("Role=%q, want %q", got, want)
+		}
+
+		if _, err := contributorService.JoinRepo(ctx0, "badCode"); todev.ErrorCode(err) != todev.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("Invitations", func(t *testing.T) {
+		s.ContributorService.CreateInvitationFn = func(ctx context.Context, inv *todev.Invitation) error {
+			if inv.Role == todev.RoleOwner {
+				return todev.Errorf(todev.EINVALID, "Repo ownership cannot be granted by invitation.")
+			}
+			inv.ID, inv.Code = 1, "code1"
+			return nil
+		}
+		s.ContributorService.FindInvitationsFn = func(ctx context.Context, filter todev.InvitationFilter) ([]*todev.Invitation, int, error) {
+			if filter.RepoID != nil && *filter.RepoID != 1 {
+				t.Fatalf("unexpected filter: %#v", filter)
+			}
+			return []*todev.Invitation{{ID: 1, RepoID: 1, Code: "code1"}}, 1, nil
+		}
+		s.ContributorService.RotateInvitationFn = func(ctx context.Context, id int) (*todev.Invitation, error) {
+			return &todev.Invitation{ID: id, RepoID: 1, Code: "code2"}, nil
+		}
+		s.ContributorService.RevokeInvitationFn = func(ctx context.Context, id int) error {
+			return nil
+		}
+		s.ContributorService.FindInvitationUsesFn = func(ctx context.Context, invitationID int) ([]*todev.InvitationUse, error) {
+			return []*todev.InvitationUse{{ID: 1, InvitationID: invitationID, UserID: 2}}, nil
+		}
+
+		inv := &todev.Invitation{RepoID: 1, Role: todev.RoleViewer, MaxUses: 5}
+		if err := contributorService.CreateInvitation(ctx0, inv); err != nil {
+			t.Fatal(err)
+		} else if got, want := inv.Code, "code1"; got != want {
+			t.Fatalf("Code=%q, want %q", got, want)
+		} else if err = contributorService.CreateInvitation(ctx0, &todev.Invitation{RepoID: 1, Role: todev.RoleOwner}); todev.ErrorCode(err) != todev.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		repoID := 1
+		if invitations, n, err := contributorService.FindInvitations(ctx0, todev.InvitationFilter{RepoID: &repoID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		} else if got, want := invitations[0].Code, "code1"; got != want {
+			t.Fatalf("Code=%q, want %q", got, want)
+		}
+
+		if rotated, err := contributorService.RotateInvitation(ctx0, 1); err != nil {
+			t.Fatal(err)
+		} else if got, want := rotated.Code, "code2"; got != want {
+			t.Fatalf("Code=%q, want %q", got, want)
+		} else if err = contributorService.RevokeInvitation(ctx0, 1); err != nil {
+			t.Fatal(err)
+		}
+
+		if uses, err := contributorService.FindInvitationUses(ctx0, 1); err != nil {
+			t.Fatal(err)
+		} else if got, want := uses[0].UserID, 2; got != want {
+			t.Fatalf("UserID=%d, want %d", got, want)
+		}
+	})
+
+	t.Run("UpdateContributor", func(t *testing.T) {
+		s.ContributorService.UpdateContributorFn = func(ctx context.Context, id int, upd todev.ContributorUpdate) (*todev.Contributor, error) {
+			if upd.Role == nil {
+				return &todev.Contributor{ID: id, IsAdmin: *upd.IsAdmin}, nil
+			} else if *upd.Role == todev.RoleOwner {
+				return nil, todev.Errorf(todev.EUNAUTHORIZED, "Repo ownership cannot be changed.")
+			}
+			return &todev.Contributor{ID: id, Role: *upd.Role}, nil
+		}
+
+		isAdmin := true
+		if contributor, err := contributorService.UpdateContributor(ctx0, 1, todev.ContributorUpdate{IsAdmin: &isAdmin}); err != nil {
+			t.Fatal(err)
+		} else if !contributor.IsAdmin {
+			t.Fatal("expected admin contributor")
+		}
+
+		viewer, owner := todev.RoleViewer, todev.RoleOwner
+		if contributor, err := contributorService.UpdateContributor(ctx0, 1, todev.ContributorUpdate{Role: &viewer}); err != nil {
+			t.Fatal(err)
+		} else if got, want := contributor.Role, todev.RoleViewer; got != want {
+			t.Fatalf("Role=%q, want %q", got, want)
+		} else if _, err = contributorService.UpdateContributor(ctx0, 1, todev.ContributorUpdate{Role: &owner}); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("DeleteContributor", func(t *testing.T) {
+		s.ContributorService.FindContributorByIDFn = func(ctx context.Context, id int) (*todev.Contributor, error) {
+			return &todev.Contributor{ID: id, RepoID: 1, UserID: 2, OwnerID: 1}, nil
+		}
+		s.ContributorService.DeleteContributorFn = func(ctx context.Context, id int) error {
+			return nil
+		}
+
+		if err := contributorService.DeleteContributor(ctx0, 1); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
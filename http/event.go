@@ -4,11 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/saiddis/todev"
 )
 
 // Websocket metrics.
@@ -27,7 +29,37 @@ func (s *Server) registerEventRoutes(r *mux.Router) {
 
 // handleEvents handles the "GET /events" route. This provides real-time event
 // notification over Websockets.
+//
+// Clients can pass one or more "repo" query parameters to only receive the
+// events of those repos. Passing a single repo along with the "since" query
+// parameter first replays the events of the repo that occured after the given
+// sequence number.
 func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	// Parse optional repo scope.
+	var filter todev.SubscriptionFilter
+	for _, v := range r.URL.Query()["repo"] {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			Error(w, r, todev.Errorf(todev.EINVALID, "Invalid repo ID format."))
+			return
+		}
+		filter.RepoIDs = append(filter.RepoIDs, id)
+	}
+
+	// Parse optional replay cursor.
+	var repoID, since int
+	if v := r.URL.Query().Get("since"); v != "" {
+		var err error
+		if since, err = strconv.Atoi(v); err != nil || since < 0 {
+			Error(w, r, todev.Errorf(todev.EINVALID, "Invalid since format."))
+			return
+		} else if len(filter.RepoIDs) != 1 {
+			Error(w, r, todev.Errorf(todev.EINVALID, "A single repo ID is required to replay events."))
+			return
+		}
+		repoID = filter.RepoIDs[0]
+	}
+
 	websocketConnections.Inc()
 	defer websocketConnections.Dec()
 
@@ -53,14 +85,36 @@ func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 	// Ignore all incoming messages.
 	go ignoreWebSocketReaders(conn)
 
-	sub, err := s.EventService.Subscribe(r.Context())
+	sub, err := s.EventService.Subscribe(r.Context(), filter)
 	if err != nil {
 		LogError(r, err)
 		return
 	}
 	defer sub.Close()
 
-	// Stream all events to outgoing websocket writer.
+	// Replay missed events only after subscribing so that no events are lost
+	// in between. Live events that have already been replayed are skipped.
+	if repoID != 0 {
+		events, err := s.EventLogService.FindEvents(r.Context(), repoID, since)
+		if todev.ErrorCode(err) == todev.ECONFLICT {
+			// Let the client know that it has to reload the repo instead.
+			events = []todev.Event{{Type: todev.EventTypeEventsExpired, RepoID: repoID}}
+		} else if err != nil {
+			LogError(r, err)
+			return
+		}
+
+		for _, event := range events {
+			if err = writeEvent(conn, event); err != nil {
+				LogError(r, err)
+				return
+			} else if event.Seq > since {
+				since = event.Seq
+			}
+		}
+	}
+
+	// Stream subscribed events to outgoing websocket writer.
 	for {
 		select {
 		case <-r.Context().Done():
@@ -69,16 +123,11 @@ func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 			// Exit if subscription is closed
 			if !ok {
 				return
+			} else if repoID != 0 && event.RepoID == repoID && event.Seq <= since {
+				continue
 			}
 
-			buf, err := json.Marshal(event)
-			if err != nil {
-				LogError(r, err)
-				return
-			}
-
-			// Write JSON data out to the websocket connection.
-			if err := conn.WriteMessage(websocket.TextMessage, buf); err != nil {
+			if err := writeEvent(conn, event); err != nil {
 				LogError(r, err)
 				return
 			}
@@ -86,6 +135,15 @@ func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// writeEvent writes an event as JSON to the websocket connection.
+func writeEvent(conn *websocket.Conn, event todev.Event) error {
+	buf, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return conn.WriteMessage(websocket.TextMessage, buf)
+}
+
 // ignoreWebSocketReaders ignores all incoming WS message on conn.
 // This is required by the underlying library if we don't care about sent messages.
 //
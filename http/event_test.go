@@ -0,0 +1,149 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/mock"
+)
+
+func TestEvents(t *testing.T) {
+	t.Run("Replay", func(t *testing.T) {
+		s := MustOpenServer(t)
+		defer MustCloseServer(t, s)
+		s.MustAuthenticateAPIKey(t, &todev.User{ID: 1, APIKey: "apiKey"})
+
+		c := make(chan todev.Event, 2)
+		s.EventService.SubscribeFn = func(ctx context.Context, filter todev.SubscriptionFilter) (todev.Subscription, error) {
+			if !reflect.DeepEqual(filter.RepoIDs, []int{1}) {
+				t.Fatalf("unexpected filter: %#v", filter)
+			}
+			return mock.Subscription{
+				CFn:     func() <-chan todev.Event { return c },
+				CloseFn: func() {},
+			}, nil
+		}
+		s.EventLogService.FindEventsFn = func(ctx context.Context, repoID, since int) ([]todev.Event, error) {
+			if repoID != 1 || since != 1 {
+				t.Fatalf("unexpected cursor: repo=%d since=%d", repoID, since)
+			}
+			return []todev.Event{
+				{Type: todev.EventTypeTaskDeleted, RepoID: 1, Seq: 2},
+				{Type: todev.EventTypeTaskDeleted, RepoID: 1, Seq: 3},
+			}, nil
+		}
+
+		// The live stream repeats an already replayed event.
+		c <- todev.Event{Type: todev.EventTypeTaskDeleted, RepoID: 1, Seq: 3}
+		c <- todev.Event{Type: todev.EventTypeTaskDeleted, RepoID: 1, Seq: 4}
+
+		conn := MustDialEvents(t, s, "/events?repo=1&since=1", "apiKey")
+		defer conn.Close()
+
+		for _, want := range []int{2, 3, 4} {
+			if event := MustReadEvent(t, conn); event.Seq != want {
+				t.Fatalf("Seq=%d, want %d", event.Seq, want)
+			}
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		s := MustOpenServer(t)
+		defer MustCloseServer(t, s)
+		s.MustAuthenticateAPIKey(t, &todev.User{ID: 1, APIKey: "apiKey"})
+
+		s.EventService.SubscribeFn = func(ctx context.Context, filter todev.SubscriptionFilter) (todev.Subscription, error) {
+			return mock.Subscription{
+				CFn:     func() <-chan todev.Event { return make(chan todev.Event) },
+				CloseFn: func() {},
+			}, nil
+		}
+		s.EventLogService.FindEventsFn = func(ctx context.Context, repoID, since int) ([]todev.Event, error) {
+			return nil, todev.Errorf(todev.ECONFLICT, "Events since %d are no longer available.", since)
+		}
+
+		conn := MustDialEvents(t, s, "/events?repo=1&since=1", "apiKey")
+		defer conn.Close()
+
+		if event := MustReadEvent(t, conn); event.Type != todev.EventTypeEventsExpired {
+			t.Fatalf("Type=%q, want %q", event.Type, todev.EventTypeEventsExpired)
+		}
+	})
+
+	t.Run("RepoScope", func(t *testing.T) {
+		s := MustOpenServer(t)
+		defer MustCloseServer(t, s)
+		s.MustAuthenticateAPIKey(t, &todev.User{ID: 1, APIKey: "apiKey"})
+
+		s.EventService.SubscribeFn = func(ctx context.Context, filter todev.SubscriptionFilter) (todev.Subscription, error) {
+			if !reflect.DeepEqual(filter.RepoIDs, []int{7, 12}) {
+				t.Fatalf("unexpected filter: %#v", filter)
+			}
+			c := make(chan todev.Event, 1)
+			c <- todev.Event{Type: todev.EventTypeTaskDeleted, RepoID: 7}
+			return mock.Subscription{
+				CFn:     func() <-chan todev.Event { return c },
+				CloseFn: func() {},
+			}, nil
+		}
+
+		conn := MustDialEvents(t, s, "/events?repo=7&repo=12", "apiKey")
+		defer conn.Close()
+
+		if event := MustReadEvent(t, conn); event.RepoID != 7 {
+			t.Fatalf("RepoID=%d, want 7", event.RepoID)
+		}
+	})
+
+	t.Run("ErrInvalidCursor", func(t *testing.T) {
+		s := MustOpenServer(t)
+		defer MustCloseServer(t, s)
+
+		s.MustAuthenticateAPIKey(t, &todev.User{ID: 1, APIKey: "apiKey"})
+
+		req := s.MustNewRequest(t, context.Background(), "GET", "/events?since=1", nil)
+		req.Header.Set("Authorization", "Bearer apiKey")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		if got, want := resp.StatusCode, http.StatusBadRequest; got != want {
+			t.Fatalf("StatusCode=%d, want %d", got, want)
+		}
+	})
+}
+
+// MustDialEvents opens a websocket connection to the events endpoint
+// authenticated with the given API key.
+func MustDialEvents(tb testing.TB, s *Server, path, apiKey string) *websocket.Conn {
+	tb.Helper()
+	header := http.Header{"Authorization": {"Bearer " + apiKey}}
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(s.URL(), "http")+path, header)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return conn
+}
+
+// MustReadEvent reads the next event from a websocket connection.
+func MustReadEvent(tb testing.TB, conn *websocket.Conn) todev.Event {
+	tb.Helper()
+	_, buf, err := conn.ReadMessage()
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	var event todev.Event
+	if err = json.Unmarshal(buf, &event); err != nil {
+		tb.Fatal(err)
+	}
+	return event
+}
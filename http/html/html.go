@@ -20,6 +20,26 @@ type IndexTemplate struct {
 	Contributors []*todev.Contributor
 }
 
+// LoginTemplate represents template data for "GET /login".
+type LoginTemplate struct {
+	// Providers users can log in with.
+	Providers []todev.OAuthProvider
+
+	// Set if users can log in with a local account.
+	Local bool
+}
+
+// PasswordForgotTemplate represents template data for "GET /password/forgot".
+type PasswordForgotTemplate struct {
+	// Set once the reset link has been requested.
+	Sent bool
+}
+
+// PasswordResetTemplate represents template data for "GET /password/reset".
+type PasswordResetTemplate struct {
+	Token string
+}
+
 // Errortemplate represents error template data payload for an error.
 type Errortemplate struct {
 	StatusCode int
@@ -27,9 +47,9 @@ type Errortemplate struct {
 	Message    string
 }
 
-// ContributorCreateTemplate represents template data for "POST /contributors".
+// ContributorCreateTemplate represents template data for "GET /invite/{code}".
 type ContributorCreateTemplate struct {
-	Repo *todev.Repo
+	Invitation *todev.Invitation
 }
 
 // RepoViewTemplate represents template data for "GET /repos/{id}".
@@ -37,15 +57,56 @@ type RepoViewTemplate struct {
 	UserID      int
 	Contributor *todev.Contributor
 	Repo        *todev.Repo
-	InviteCode  string
 }
 
 // RepoIndexTemplate represents template data for "GET /repos".
 type RepoIndexTemplate struct {
-	Repos  []*todev.Repo
-	N      int
-	Filter todev.RepoFilter
-	URL    url.URL
+	Repos      []*todev.Repo
+	N          int
+	Filter     todev.RepoFilter
+	Pagination Pagination
+}
+
+// RepoTrashTemplate represents template data for "GET /repos/{id}/trash".
+type RepoTrashTemplate struct {
+	Repo       *todev.Repo
+	Tasks      []*todev.Task
+	Pagination Pagination
+}
+
+// RepoInvitationsTemplate represents template data for
+// "GET /repos/{id}/invitations".
+type RepoInvitationsTemplate struct {
+	Repo        *todev.Repo
+	Invitations []*todev.Invitation
+
+	// Prefix of the invitation URLs, to be followed by the invitation code.
+	InviteURL string
+
+	Pagination Pagination
+}
+
+// TokenIndexTemplate represents template data for "GET /tokens".
+type TokenIndexTemplate struct {
+	Tokens []*todev.Token
+
+	// Scopes a new token can be created with.
+	Scopes []string
+
+	// Token that was just created. Its secret is only shown once.
+	Created *todev.Token
+
+	Pagination Pagination
+}
+
+// SessionIndexTemplate represents template data for "GET /sessions".
+type SessionIndexTemplate struct {
+	Sessions []*todev.Session
+
+	// ID of the session of the current browser.
+	CurrentID int
+
+	Pagination Pagination
 }
 
 // RepoEditTemplate represents template data for "GET /repos/{id}/edit".
@@ -103,61 +164,21 @@ func (r *Flash) Render(ctx context.Context, w io.Writer) {
 	fmt.Fprintf(w, `</div>`)
 }
 
+// Pagination links to the next page of a list paginated with cursors.
 type Pagination struct {
-	URL    url.URL
-	Offset int
-	Limit  int
-	N      int
-}
-
-func (r *Pagination) Render(ctx context.Context, w io.Writer) {
-	if r.Limit == 0 || r.N <= r.Limit {
-		return
-	}
-
-	current := (r.Offset / r.Limit) + 1
-	pageN := ((r.N - 1) / r.Limit) + 1
-
-	prev := current - 1
-
-	if prev <= 0 {
-		prev = 1
-	}
-	next := current + 1
-	if next > pageN {
-		next = pageN
-	}
-
-	// Print container and "previous" link.
-	fmt.Fprintf(w, `<nav aria-label="Page navigaion">`)
-	fmt.Fprintf(w, `<ul class="paginaiton pagination-sm justify-content-end mb-0">`)
-	fmt.Fprintf(w, `<li class="page-item"><a class="page_link" href="%s">Previous</a></li>`, r.pageURL(current-1))
-
-	// Print a button for each page number.
-	for page := 1; page <= pageN; page++ {
-		className := ""
-		if page == current {
-			className = " active"
-		}
-		fmt.Fprintf(w, `<li class="page-item %s"><a class="page-link" href="%s">%d</a></li>`, className, r.pageURL(page), page)
-	}
-
-	// Print "next" link and close container.
-	fmt.Fprintf(w, `<li class="page-item"><a class="page-link" href="%s">Next</a></li>`, r.pageURL(current-1))
-	fmt.Fprintf(w, `</ul>`)
-	fmt.Fprintf(w, `</nav>`)
+	URL  url.URL
+	Next string
 }
 
-func (r *Pagination) pageURL(page int) string {
-	pageN := ((r.N - 1) / r.Limit) + 1
-	if page < 1 {
-		page = 1
-	} else if page > pageN {
-		page = pageN
+// NextURL returns the URL of the next page, or an empty string if the list
+// has no more pages.
+func (r Pagination) NextURL() string {
+	if r.Next == "" {
+		return ""
 	}
 
 	q := r.URL.Query()
-	q.Set("offset", fmt.Sprint((page-1)*r.Limit))
+	q.Set("cursor", r.Next)
 	u := url.URL{Path: r.URL.Path, RawQuery: q.Encode()}
 	return u.String()
 }
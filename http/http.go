@@ -54,7 +54,9 @@ const SessionCookieName = "session"
 
 // Session represensts session data strored in a secure cookie.
 type Session struct {
-	UserID      int    `json:"userID"`
+	// Secret of the server-side session the browser is logged in with.
+	Secret string `json:"secret"`
+
 	RedirectURL string `json:"redirectURL"`
 	State       string `json:"state"`
 	AvatarURL   string `json:"avatarURL"`
@@ -81,7 +83,7 @@ func Error(w http.ResponseWriter, r *http.Request, err error) {
 	}
 
 	switch r.Header.Get("Accept") {
-	case "appilcation/json":
+	case "application/json":
 		w.Header().Set("Content-type", "application/json")
 		w.WriteHeader(ErrorStatusCode(code))
 		json.NewEncoder(w).Encode(&ErrorResponse{Error: message})
@@ -158,3 +160,13 @@ func FromErrorStatusCode(code int) string {
 	}
 	return todev.EINTERNAL
 }
+
+// nextCursor returns the cursor of the page following items, or an empty
+// string if items is the last page. n and offset are the total number of
+// results and the offset of the Find*() call that returned items.
+func nextCursor[T any](items []T, n, offset int, id func(T) int) string {
+	if len(items) == 0 || n <= offset+len(items) {
+		return ""
+	}
+	return todev.EncodeCursor(id(items[len(items)-1]))
+}
@@ -0,0 +1,389 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/http/html"
+	"github.com/saiddis/todev/http/json"
+)
+
+// registerInvitationRoutes is a helper function for registering invitation
+// routes.
+func (s *Server) registerInvitationRoutes(r *mux.Router) {
+	// Listing of the invitations of a repo.
+	r.HandleFunc("/repos/{id}/invitations", s.handleInvitationIndex).Methods("GET")
+
+	// Creating a new invitation to a repo.
+	r.HandleFunc("/repos/{id}/invitations", s.handleInvitationCreate).Methods("POST")
+
+	// Replacing the code of an invitation.
+	r.HandleFunc("/invitations/{id}/rotate", s.handleInvitationRotate).Methods("POST")
+
+	// Revoking an invitation.
+	r.HandleFunc("/invitations/{id}/revoke", s.handleInvitationRevoke).Methods("POST")
+
+	// Users that joined through an invitation.
+	r.HandleFunc("/invitations/{id}/uses", s.handleInvitationUses).Methods("GET")
+}
+
+// handleInvitationIndex handles the "GET /repos/:id/invitations" route. It
+// lists the invitations of a repo, newest first. The JSON format accepts an
+// optional filter as the body and a "cursor" query parameter.
+func (s *Server) handleInvitationIndex(w http.ResponseWriter, r *http.Request) {
+	// Parse repo ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	var filter todev.InvitationFilter
+	if err := json.Decode(r.Body, &filter); err != nil && err != io.EOF {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid JSON body"))
+		return
+	}
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			LogError(r, fmt.Errorf("error closing request body: %v", err))
+		}
+	}()
+
+	filter.RepoID = &id
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		filter.Cursor = v
+	}
+
+	invitations, n, err := s.ContributorService.FindInvitations(r.Context(), filter)
+	if err != nil {
+		Error(w, r, fmt.Errorf("error retrieving invitations: %w", err))
+		return
+	}
+	next := nextCursor(invitations, n, filter.Offset, func(inv *todev.Invitation) int { return inv.ID })
+
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err = json.Write(w, http.StatusOK, json.FindInvitationsResponse{Invitations: invitations, N: n, Next: next}); err != nil {
+			LogError(r, fmt.Errorf("error writing response: %v", err))
+			return
+		}
+	default:
+		repo, err := s.RepoService.FindRepoByID(r.Context(), id)
+		if err != nil {
+			Error(w, r, fmt.Errorf("error retrieving repo by ID: %w", err))
+			return
+		}
+
+		tmplData := html.RepoInvitationsTemplate{
+			Repo:        repo,
+			Invitations: invitations,
+			InviteURL:   s.URL() + "/invite/",
+			Pagination:  html.Pagination{URL: *r.URL, Next: next},
+		}
+		if tmpl, err := template.ParseFS(templateFiles, "html/base.html", "html/repoInvitations.html"); err != nil {
+			LogError(r, fmt.Errorf("error parsing html file: %v", err))
+			return
+		} else if err = tmpl.Execute(w, tmplData); err != nil {
+			LogError(r, fmt.Errorf("error executing template: %v", err))
+			return
+		}
+	}
+}
+
+// handleInvitationCreate handles the "POST /repos/:id/invitations" route. HTML
+// forms set the expiry as a duration from now in the "expiresIn" field.
+func (s *Server) handleInvitationCreate(w http.ResponseWriter, r *http.Request) {
+	// Parse repo ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	var inv todev.Invitation
+	switch r.Header.Get("Content-type") {
+	case "application/json":
+		if err := json.Decode(r.Body, &inv); err != nil {
+			Error(w, r, todev.Errorf(todev.EINVALID, "Invalid JSON body"))
+			return
+		}
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				LogError(r, fmt.Errorf("error closing request body: %v", err))
+			}
+		}()
+	default:
+		inv.Role = todev.Role(r.PostFormValue("role"))
+		if v := r.PostFormValue("maxUses"); v != "" {
+			if inv.MaxUses, err = strconv.Atoi(v); err != nil {
+				Error(w, r, todev.Errorf(todev.EINVALID, "Invalid max uses format"))
+				return
+			}
+		}
+		if v := r.PostFormValue("expiresIn"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				Error(w, r, todev.Errorf(todev.EINVALID, "Invalid expiry format"))
+				return
+			}
+			inv.ExpiresAt = time.Now().Add(d)
+		}
+	}
+	inv.RepoID = id
+
+	if err = s.ContributorService.CreateInvitation(r.Context(), &inv); err != nil {
+		Error(w, r, fmt.Errorf("error creating invitation: %w", err))
+		return
+	}
+
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err = json.Write(w, http.StatusCreated, inv); err != nil {
+			LogError(r, fmt.Errorf("error writing response: %v", err))
+			return
+		}
+	default:
+		SetFlash(w, "Invitation successfully created.")
+		http.Redirect(w, r, fmt.Sprintf("/repos/%d/invitations", id), http.StatusFound)
+	}
+}
+
+// handleInvitationRotate handles the "POST /invitations/:id/rotate" route.
+func (s *Server) handleInvitationRotate(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	inv, err := s.ContributorService.RotateInvitation(r.Context(), id)
+	if err != nil {
+		Error(w, r, fmt.Errorf("error rotating invitation: %w", err))
+		return
+	}
+
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err = json.Write(w, http.StatusOK, inv); err != nil {
+			LogError(r, fmt.Errorf("error writing response: %v", err))
+			return
+		}
+	default:
+		SetFlash(w, "Invitation link successfully replaced.")
+		http.Redirect(w, r, fmt.Sprintf("/repos/%d/invitations", inv.RepoID), http.StatusFound)
+	}
+}
+
+// handleInvitationRevoke handles the "POST /invitations/:id/revoke" route.
+func (s *Server) handleInvitationRevoke(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Look up the invitation first so HTML forms can be redirected back to
+	// the invitations of its repo.
+	invitations, _, err := s.ContributorService.FindInvitations(r.Context(), todev.InvitationFilter{ID: &id})
+	if err != nil {
+		Error(w, r, fmt.Errorf("error retrieving invitation: %w", err))
+		return
+	} else if len(invitations) == 0 {
+		Error(w, r, todev.Errorf(todev.ENOTFOUND, "Invitation not found."))
+		return
+	} else if err = s.ContributorService.RevokeInvitation(r.Context(), id); err != nil {
+		Error(w, r, fmt.Errorf("error revoking invitation: %w", err))
+		return
+	}
+
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err = json.Write(w, http.StatusOK, struct{}{}); err != nil {
+			LogError(r, fmt.Errorf("error writing response: %v", err))
+			return
+		}
+	default:
+		SetFlash(w, "Invitation successfully revoked.")
+		http.Redirect(w, r, fmt.Sprintf("/repos/%d/invitations", invitations[0].RepoID), http.StatusFound)
+	}
+}
+
+// handleInvitationUses handles the "GET /invitations/:id/uses" route. This
+// route is only called via JSON API.
+func (s *Server) handleInvitationUses(w http.ResponseWriter, r *http.Request) {
+	r.Header.Set("Accept", "application/json")
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	if uses, err := s.ContributorService.FindInvitationUses(r.Context(), id); err != nil {
+		Error(w, r, fmt.Errorf("error retrieving invitation uses: %w", err))
+		return
+	} else if err = json.Write(w, http.StatusOK, json.FindInvitationUsesResponse{Uses: uses}); err != nil {
+		LogError(r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
+// FindInvitationByCode retrieves a usable invitation by code along with the
+// name of its repo.
+func (s *ContributorService) FindInvitationByCode(ctx context.Context, code string) (*todev.Invitation, error) {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", "/invite/"+url.PathEscape(code), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var inv todev.Invitation
+	if err = json.Decode(resp.Body, &inv); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// FindInvitations retrieves a list of invitations of a repo based on filter.
+// Returns EINVALID if the filter does not set a repo.
+func (s *ContributorService) FindInvitations(ctx context.Context, filter todev.InvitationFilter) ([]*todev.Invitation, int, error) {
+	if filter.RepoID == nil {
+		return nil, 0, todev.Errorf(todev.EINVALID, "Repo required for listing invitations.")
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := json.Encode(filter, buf); err != nil {
+		return nil, 0, fmt.Errorf("error creating request: %v", err)
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/repos/%d/invitations", *filter.RepoID), buf)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var jsonResponse json.FindInvitationsResponse
+	if err = json.Decode(resp.Body, &jsonResponse); err != nil {
+		return nil, 0, fmt.Errorf("error decoding response: %v", err)
+	}
+	return jsonResponse.Invitations, jsonResponse.N, nil
+}
+
+// CreateInvitation creates a new invitation to a repo.
+func (s *ContributorService) CreateInvitation(ctx context.Context, inv *todev.Invitation) error {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := json.Encode(inv, buf); err != nil {
+		return fmt.Errorf("error creating request: %v", err)
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/repos/%d/invitations", inv.RepoID), buf)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-201 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusCreated {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return json.Decode(resp.Body, inv)
+}
+
+// RotateInvitation replaces the code of an invitation by ID.
+func (s *ContributorService) RotateInvitation(ctx context.Context, id int) (*todev.Invitation, error) {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/invitations/%d/rotate", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var inv todev.Invitation
+	if err = json.Decode(resp.Body, &inv); err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// RevokeInvitation revokes an invitation by ID.
+func (s *ContributorService) RevokeInvitation(ctx context.Context, id int) error {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/invitations/%d/revoke", id), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+// FindInvitationUses retrieves the users that joined through an invitation.
+func (s *ContributorService) FindInvitationUses(ctx context.Context, invitationID int) ([]*todev.InvitationUse, error) {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/invitations/%d/uses", invitationID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var jsonResponse json.FindInvitationUsesResponse
+	if err = json.Decode(resp.Body, &jsonResponse); err != nil {
+		return nil, fmt.Errorf("error decoding response: %v", err)
+	}
+	return jsonResponse.Uses, nil
+}
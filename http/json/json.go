@@ -60,10 +60,77 @@ type ErrorResponse struct {
 type FindReposResponse struct {
 	Repos []*todev.Repo `json:"repos"`
 	N     int           `json:"n"`
+	Next  string        `json:"next,omitempty"`
 }
 
 // FindTasksResponse represents payload for "GET /tasks".
 type FindTasksResponse struct {
 	Tasks []*todev.Task `json:"tasks"`
 	N     int           `json:"n"`
+	Next  string        `json:"next,omitempty"`
+}
+
+// FindActivitiesResponse represents payload for "GET /repos/:id/activity".
+type FindActivitiesResponse struct {
+	Activities []*todev.Activity `json:"activities"`
+	N          int               `json:"n"`
+	Next       string            `json:"next,omitempty"`
+}
+
+// FindInvitationsResponse represents payload for "GET /repos/:id/invitations".
+type FindInvitationsResponse struct {
+	Invitations []*todev.Invitation `json:"invitations"`
+	N           int                 `json:"n"`
+	Next        string              `json:"next,omitempty"`
+}
+
+// FindInvitationUsesResponse represents payload for "GET /invitations/:id/uses".
+type FindInvitationUsesResponse struct {
+	Uses []*todev.InvitationUse `json:"uses"`
+}
+
+// FindTokensResponse represents payload for "GET /tokens".
+type FindTokensResponse struct {
+	Tokens []*todev.Token `json:"tokens"`
+	N      int            `json:"n"`
+	Next   string         `json:"next,omitempty"`
+}
+
+// FindSessionsResponse represents payload for "GET /sessions".
+type FindSessionsResponse struct {
+	Sessions []*todev.Session `json:"sessions"`
+	N        int              `json:"n"`
+	Next     string           `json:"next,omitempty"`
+}
+
+// FindCommentsResponse represents payload for "GET /tasks/:id/comments".
+type FindCommentsResponse struct {
+	Comments []*todev.Comment `json:"comments"`
+	N        int              `json:"n"`
+}
+
+// FindContributorsResponse represents payload for "GET /contributors".
+type FindContributorsResponse struct {
+	Contributors []*todev.Contributor `json:"contributors"`
+	N            int                  `json:"n"`
+	Next         string               `json:"next,omitempty"`
+}
+
+// FindUsersResponse represents payload for "GET /users".
+type FindUsersResponse struct {
+	Users []*todev.User `json:"users"`
+	N     int           `json:"n"`
+	Next  string        `json:"next,omitempty"`
+}
+
+// FindAuthsResponse represents payload for "GET /auths".
+type FindAuthsResponse struct {
+	Auths []*todev.Auth `json:"auths"`
+	N     int           `json:"n"`
+}
+
+// WebhookResponse represents the response payload for "POST /webhooks/github".
+type WebhookResponse struct {
+	// IDs of the tasks completed by the webhook event.
+	CompletedTaskIDs []int `json:"completedTaskIDs"`
 }
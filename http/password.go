@@ -0,0 +1,240 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/http/html"
+)
+
+// registerPasswordRoutes is a helper function to register the routes used to
+// log in with a local account.
+func (s *Server) registerPasswordRoutes(r *mux.Router) {
+	r = r.PathPrefix("/").Subrouter()
+	r.Use(s.requireLocalAccounts)
+
+	r.HandleFunc("/login", s.handleLoginPassword).Methods("POST")
+	r.HandleFunc("/register", s.handleRegister).Methods("GET")
+	r.HandleFunc("/register", s.handleRegisterCreate).Methods("POST")
+	r.HandleFunc("/password/forgot", s.handlePasswordForgot).Methods("GET")
+	r.HandleFunc("/password/forgot", s.handlePasswordForgotCreate).Methods("POST")
+	r.HandleFunc("/password/reset", s.handlePasswordReset).Methods("GET")
+	r.HandleFunc("/password/reset", s.handlePasswordResetUpdate).Methods("POST")
+}
+
+// registerEmailRoutes is a helper function to register the routes of the links
+// sent by email, which may be opened whether the user is logged in or not.
+func (s *Server) registerEmailRoutes(r *mux.Router) {
+	r = r.PathPrefix("/").Subrouter()
+	r.Use(s.requireLocalAccounts)
+
+	r.HandleFunc("/verify-email", s.handleVerifyEmail).Methods("GET")
+}
+
+// registerPasswordSettingsRoutes is a helper function to register the routes
+// used by logged in users to manage their password.
+func (s *Server) registerPasswordSettingsRoutes(r *mux.Router) {
+	r = r.PathPrefix("/").Subrouter()
+	r.Use(s.requireLocalAccounts)
+
+	r.HandleFunc("/password/edit", s.handlePasswordEdit).Methods("GET")
+	r.HandleFunc("/password", s.handlePasswordUpdate).Methods("PATCH")
+}
+
+// requireLocalAccounts is middleware returning ENOTFOUND if local accounts
+// are not enabled.
+func (s *Server) requireLocalAccounts(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.PasswordService == nil {
+			Error(w, r, todev.Errorf(todev.ENOTFOUND, "Local accounts are not enabled."))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleLoginPassword handles the "POST /login" route. It logs the user in
+// with their email and password.
+func (s *Server) handleLoginPassword(w http.ResponseWriter, r *http.Request) {
+	user, err := s.PasswordService.Login(r.Context(), r.PostFormValue("email"), r.PostFormValue("password"))
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	session, err := s.session(r)
+	if err != nil {
+		Error(w, r, fmt.Errorf("error reading session: %v", err))
+		return
+	}
+
+	s.login(w, r, session, user.ID, user.AvatarURL())
+}
+
+// handleRegister handles the "GET /register" route.
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	if tmpl, err := template.ParseFS(templateFiles, "html/register.html"); err != nil {
+		LogError(r, fmt.Errorf("error parsing html file: %v", err))
+		return
+	} else if err = tmpl.Execute(w, nil); err != nil {
+		LogError(r, fmt.Errorf("error executing template: %v", err))
+		return
+	}
+}
+
+// handleRegisterCreate handles the "POST /register" route. It creates a local
+// account, sends the email verification link and logs the user in.
+func (s *Server) handleRegisterCreate(w http.ResponseWriter, r *http.Request) {
+	user := &todev.User{
+		Name:  r.PostFormValue("name"),
+		Email: r.PostFormValue("email"),
+	}
+
+	token, err := s.PasswordService.Register(r.Context(), user, r.PostFormValue("password"))
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	// Unverified users can still log in so don't fail the registration if
+	// the mail cannot be sent.
+	if err = s.sendMail(r.Context(), user.Email, "Verify your email",
+		fmt.Sprintf("Hi %s,\n\nConfirm your email by opening the link below:\n\n%s\n\nThe link expires after %s.\n",
+			user.Name, s.URL()+"/verify-email?token="+url.QueryEscape(token), formatTTL(todev.EmailVerificationTTL)),
+	); err != nil {
+		LogError(r, err)
+	}
+
+	session, err := s.session(r)
+	if err != nil {
+		Error(w, r, fmt.Errorf("error reading session: %v", err))
+		return
+	}
+
+	s.login(w, r, session, user.ID, user.AvatarURL())
+}
+
+// handleVerifyEmail handles the "GET /verify-email" route, which is the link
+// sent by email after registration.
+func (s *Server) handleVerifyEmail(w http.ResponseWriter, r *http.Request) {
+	if err := s.PasswordService.VerifyEmail(r.Context(), r.URL.Query().Get("token")); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	SetFlash(w, "Email successfully verified.")
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// handlePasswordForgot handles the "GET /password/forgot" route.
+func (s *Server) handlePasswordForgot(w http.ResponseWriter, r *http.Request) {
+	s.renderPasswordForgot(w, r, html.PasswordForgotTemplate{})
+}
+
+// handlePasswordForgotCreate handles the "POST /password/forgot" route. It
+// sends a password reset link to the email if it belongs to a local account.
+// The response is the same otherwise so registered emails cannot be guessed.
+func (s *Server) handlePasswordForgotCreate(w http.ResponseWriter, r *http.Request) {
+	email := todev.NormalizeEmail(r.PostFormValue("email"))
+
+	token, err := s.PasswordService.CreatePasswordReset(r.Context(), email)
+	if err != nil && todev.ErrorCode(err) != todev.ENOTFOUND {
+		Error(w, r, err)
+		return
+	} else if err == nil {
+		if err = s.sendMail(r.Context(), email, "Reset your password",
+			fmt.Sprintf("Someone asked to reset your password. If it was you, open the link below:\n\n%s\n\nThe link expires after %s.\n",
+				s.URL()+"/password/reset?token="+url.QueryEscape(token), formatTTL(todev.PasswordResetTTL)),
+		); err != nil {
+			Error(w, r, err)
+			return
+		}
+	}
+
+	s.renderPasswordForgot(w, r, html.PasswordForgotTemplate{Sent: true})
+}
+
+// renderPasswordForgot renders the page used to request a password reset.
+func (s *Server) renderPasswordForgot(w http.ResponseWriter, r *http.Request, data html.PasswordForgotTemplate) {
+	if tmpl, err := template.ParseFS(templateFiles, "html/passwordForgot.html"); err != nil {
+		LogError(r, fmt.Errorf("error parsing html file: %v", err))
+		return
+	} else if err = tmpl.Execute(w, data); err != nil {
+		LogError(r, fmt.Errorf("error executing template: %v", err))
+		return
+	}
+}
+
+// handlePasswordReset handles the "GET /password/reset" route, which is the
+// link sent by email to reset a password.
+func (s *Server) handlePasswordReset(w http.ResponseWriter, r *http.Request) {
+	data := html.PasswordResetTemplate{Token: r.URL.Query().Get("token")}
+	if tmpl, err := template.ParseFS(templateFiles, "html/passwordReset.html"); err != nil {
+		LogError(r, fmt.Errorf("error parsing html file: %v", err))
+		return
+	} else if err = tmpl.Execute(w, data); err != nil {
+		LogError(r, fmt.Errorf("error executing template: %v", err))
+		return
+	}
+}
+
+// handlePasswordResetUpdate handles the "POST /password/reset" route. Users
+// are sent to the login page once their password is replaced.
+func (s *Server) handlePasswordResetUpdate(w http.ResponseWriter, r *http.Request) {
+	if err := s.PasswordService.ResetPassword(r.Context(), r.PostFormValue("token"), r.PostFormValue("password")); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	http.Redirect(w, r, "/login", http.StatusFound)
+}
+
+// handlePasswordEdit handles the "GET /password/edit" route.
+func (s *Server) handlePasswordEdit(w http.ResponseWriter, r *http.Request) {
+	if tmpl, err := template.ParseFS(templateFiles, "html/base.html", "html/passwordEdit.html"); err != nil {
+		LogError(r, fmt.Errorf("error parsing html file: %v", err))
+		return
+	} else if err = tmpl.Execute(w, nil); err != nil {
+		LogError(r, fmt.Errorf("error executing template: %v", err))
+		return
+	}
+}
+
+// handlePasswordUpdate handles the "PATCH /password" route. It replaces the
+// password of the current user.
+func (s *Server) handlePasswordUpdate(w http.ResponseWriter, r *http.Request) {
+	if err := s.PasswordService.ChangePassword(r.Context(), r.PostFormValue("current"), r.PostFormValue("password")); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	SetFlash(w, "Password successfully changed.")
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// sendMail sends a plain text mail through the server's mailer.
+func (s *Server) sendMail(ctx context.Context, to, subject, body string) error {
+	if err := s.Mailer.SendMail(ctx, &todev.Mail{To: to, Subject: subject, Body: body}); err != nil {
+		return fmt.Errorf("error sending mail: %w", err)
+	}
+	return nil
+}
+
+// formatTTL returns the lifetime of a link in a short form, e.g. "48h".
+func formatTTL(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
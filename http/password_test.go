@@ -0,0 +1,195 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/saiddis/todev"
+	todevhttp "github.com/saiddis/todev/http"
+)
+
+// Ensure local accounts log in with the same session as OAuth logins.
+func TestLogin_Password(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	s.PasswordService.LoginFn = func(ctx context.Context, email, password string) (*todev.User, error) {
+		if email != "bob@gmail.com" || password != "password1" {
+			return nil, todev.Errorf(todev.EUNAUTHORIZED, "Invalid email or password.")
+		}
+		return &todev.User{ID: 1, Name: "bob", Email: email}, nil
+	}
+
+	s.SessionService.CreateSessionFn = func(ctx context.Context, session *todev.Session) error {
+		if got, want := session.UserID, 1; got != want {
+			t.Fatalf("UserID=%d, want %d", got, want)
+		}
+		session.ID, session.Secret = 1, "SESSION"
+		return nil
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		resp := MustPostForm(t, s.URL()+"/login", url.Values{"email": {"bob@gmail.com"}, "password": {"password1"}})
+		if got, want := resp.StatusCode, http.StatusFound; got != want {
+			t.Fatalf("StatusCode=%d, want %d", got, want)
+		}
+
+		var session todevhttp.Session
+		if err := s.UnmarshalSession(resp.Cookies()[0].Value, &session); err != nil {
+			t.Fatal(err)
+		} else if got, want := session.Secret, "SESSION"; got != want {
+			t.Fatalf("Secret=%q, want %q", got, want)
+		}
+	})
+
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		resp := MustPostForm(t, s.URL()+"/login", url.Values{"email": {"bob@gmail.com"}, "password": {"wrong"}})
+		if got, want := resp.StatusCode, http.StatusUnauthorized; got != want {
+			t.Fatalf("StatusCode=%d, want %d", got, want)
+		}
+	})
+}
+
+// Ensure registration sends the verification link and logs the user in.
+func TestRegister(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	s.PasswordService.RegisterFn = func(ctx context.Context, user *todev.User, password string) (string, error) {
+		if got, want := user.Name, "bob"; got != want {
+			t.Fatalf("Name=%q, want %q", got, want)
+		} else if got, want := password, "password1"; got != want {
+			t.Fatalf("password=%q, want %q", got, want)
+		}
+		user.ID = 1
+		return "TOKEN", nil
+	}
+
+	s.SessionService.CreateSessionFn = func(ctx context.Context, session *todev.Session) error {
+		if got, want := session.UserID, 1; got != want {
+			t.Fatalf("UserID=%d, want %d", got, want)
+		}
+		session.ID, session.Secret = 1, "SESSION"
+		return nil
+	}
+
+	var sent *todev.Mail
+	s.Mailer.SendMailFn = func(ctx context.Context, mail *todev.Mail) error {
+		sent = mail
+		return nil
+	}
+
+	resp := MustPostForm(t, s.URL()+"/register", url.Values{
+		"name":     {"bob"},
+		"email":    {"bob@gmail.com"},
+		"password": {"password1"},
+	})
+	if got, want := resp.StatusCode, http.StatusFound; got != want {
+		t.Fatalf("StatusCode=%d, want %d", got, want)
+	} else if sent == nil {
+		t.Fatal("expected verification mail")
+	} else if got, want := sent.To, "bob@gmail.com"; got != want {
+		t.Fatalf("To=%q, want %q", got, want)
+	} else if !strings.Contains(sent.Body, s.URL()+"/verify-email?token=TOKEN") {
+		t.Fatalf("missing verification link: %q", sent.Body)
+	}
+
+	var session todevhttp.Session
+	if err := s.UnmarshalSession(resp.Cookies()[0].Value, &session); err != nil {
+		t.Fatal(err)
+	} else if got, want := session.Secret, "SESSION"; got != want {
+		t.Fatalf("Secret=%q, want %q", got, want)
+	}
+}
+
+// Ensure reset links are only sent to local accounts without telling whether
+// the email is registered.
+func TestPasswordForgot(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	s.PasswordService.CreatePasswordResetFn = func(ctx context.Context, email string) (string, error) {
+		if email != "bob@gmail.com" {
+			return "", todev.Errorf(todev.ENOTFOUND, "User not found.")
+		}
+		return "TOKEN", nil
+	}
+
+	var sent []*todev.Mail
+	s.Mailer.SendMailFn = func(ctx context.Context, mail *todev.Mail) error {
+		sent = append(sent, mail)
+		return nil
+	}
+
+	for _, email := range []string{"judy@gmail.com", "Bob@gmail.com"} {
+		if resp := MustPostForm(t, s.URL()+"/password/forgot", url.Values{"email": {email}}); resp.StatusCode != http.StatusOK {
+			t.Fatalf("StatusCode=%d, want %d", resp.StatusCode, http.StatusOK)
+		}
+	}
+
+	if got, want := len(sent), 1; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	} else if !strings.Contains(sent[0].Body, s.URL()+"/password/reset?token=TOKEN") {
+		t.Fatalf("missing reset link: %q", sent[0].Body)
+	}
+}
+
+func TestPasswordUpdate(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &todev.User{ID: 1, Name: "user1", APIKey: "apiKey"}
+	s.MustAuthenticateAPIKey(t, user0)
+
+	s.PasswordService.ChangePasswordFn = func(ctx context.Context, current, password string) error {
+		if got, want := todev.UserIDFromContext(ctx), 1; got != want {
+			t.Fatalf("UserID=%d, want %d", got, want)
+		} else if current != "password1" || password != "password2" {
+			t.Fatalf("unexpected passwords: %q, %q", current, password)
+		}
+		return nil
+	}
+
+	req, err := http.NewRequest("PATCH", s.URL()+"/password", strings.NewReader(url.Values{
+		"current":  {"password1"},
+		"password": {"password2"},
+	}.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Bearer apiKey")
+
+	resp, err := NoRedirectClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	} else if err = resp.Body.Close(); err != nil {
+		t.Fatal(err)
+	} else if got, want := resp.StatusCode, http.StatusFound; got != want {
+		t.Fatalf("StatusCode=%d, want %d", got, want)
+	}
+}
+
+// NoRedirectClient is an HTTP client returning redirects instead of following
+// them.
+var NoRedirectClient = &http.Client{
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+// MustPostForm posts an HTML form without following redirects. Fail on error.
+func MustPostForm(tb testing.TB, target string, values url.Values) *http.Response {
+	tb.Helper()
+
+	resp, err := NoRedirectClient.PostForm(target, values)
+	if err != nil {
+		tb.Fatal(err)
+	} else if err = resp.Body.Close(); err != nil {
+		tb.Fatal(err)
+	}
+	return resp
+}
@@ -3,11 +3,14 @@ package http
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
 	"fmt"
 	"html/template"
-	"log"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/saiddis/todev"
@@ -31,11 +34,29 @@ func (s *Server) registerRepoRoutes(r *mux.Router) {
 	r.HandleFunc("/repos/{id}", s.handleRepoView).Methods("GET")
 
 	// HTML form for updating an existing repo.
-	r.HandleFunc("repo/{id}/edit", s.handleRepoEdit).Methods("GET")
-	r.HandleFunc("repo/{id}/edit", s.handleRepoUpdate).Methods("PATCH")
+	r.HandleFunc("/repos/{id}/edit", s.handleRepoEdit).Methods("GET")
+	r.HandleFunc("/repos/{id}/edit", s.handleRepoUpdate).Methods("PATCH")
+
+	// API endpoint for updating a repo.
+	r.HandleFunc("/repos/{id}", s.handleRepoUpdate).Methods("PATCH")
 
 	// Removing a repo.
-	r.HandleFunc("repo/{id}", s.handleRepoDelete).Methods("DELETE")
+	r.HandleFunc("/repos/{id}", s.handleRepoDelete).Methods("DELETE")
+
+	// Restoring a repo from the trash.
+	r.HandleFunc("/repos/{id}/restore", s.handleRepoRestore).Methods("POST")
+
+	// Trashed tasks of a repo.
+	r.HandleFunc("/repos/{id}/trash", s.handleRepoTrash).Methods("GET")
+
+	// Burndown report of the tasks left in a repo.
+	r.HandleFunc("/repos/{id}/report", s.handleRepoReport).Methods("GET")
+
+	// Import the open issues of the linked GitHub repo as tasks.
+	r.HandleFunc("/repos/{id}/import", s.handleRepoImport).Methods("POST")
+
+	// Audit log of the changes made to a repo.
+	r.HandleFunc("/repos/{id}/activity", s.handleRepoActivity).Methods("GET")
 }
 
 // handleRepoIndex handles the "GET /repos" route. This route can optionaly accept
@@ -57,25 +78,31 @@ func (s *Server) handleRepoIndex(w http.ResponseWriter, r *http.Request) {
 			}
 		}()
 	default:
-		filter.Offset, _ = strconv.Atoi(r.URL.Query().Get("offset"))
+		filter.Cursor = r.URL.Query().Get("cursor")
 		filter.Limit = 20
 	}
 
 	repos, n, err := s.RepoService.FindRepos(r.Context(), filter)
 	if err != nil {
-		Error(w, r, fmt.Errorf("error retrieving repos: %v", err))
+		Error(w, r, fmt.Errorf("error retrieving repos: %w", err))
 		return
 	}
+	next := nextCursor(repos, n, filter.Offset, func(repo *todev.Repo) int { return repo.ID })
 
 	switch r.Header.Get("Accept") {
 	case "application/json":
 		w.Header().Set("Content-type", "application/json")
-		if err = json.Encode(json.FindReposResponse{Repos: repos, N: n}, w); err != nil {
+		if err = json.Encode(json.FindReposResponse{Repos: repos, N: n, Next: next}, w); err != nil {
 			LogError(r, err)
 			return
 		}
 	default:
-		tmplData := html.RepoIndexTemplate{Repos: repos, N: n, Filter: filter, URL: *r.URL}
+		tmplData := html.RepoIndexTemplate{
+			Repos:      repos,
+			N:          n,
+			Filter:     filter,
+			Pagination: html.Pagination{URL: *r.URL, Next: next},
+		}
 		if tmpl, err := template.ParseFS(templateFiles, "html/base.html", "html/index.html"); err != nil {
 			LogError(r, fmt.Errorf("error parsing html file: %v", err))
 			return
@@ -106,19 +133,19 @@ func (s *Server) handleRepoView(w http.ResponseWriter, r *http.Request) {
 	// Parse ID from path.
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
 	if err != nil {
-		Error(w, r, err)
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
 		return
 	}
 
 	repo, err := s.RepoService.FindRepoByID(r.Context(), id)
 	if err != nil {
-		Error(w, r, fmt.Errorf("error retrieving repo by ID: %v", err))
+		Error(w, r, fmt.Errorf("error retrieving repo by ID: %w", err))
 		return
 	} else if repo.Contributors, _, err = s.ContributorService.FindContributors(r.Context(), todev.ContributorFilter{RepoID: &repo.ID}); err != nil {
-		Error(w, r, fmt.Errorf("error retrieving repo contributors: %v", err))
+		Error(w, r, fmt.Errorf("error retrieving repo contributors: %w", err))
 		return
 	} else if repo.Tasks, _, err = s.TaskService.FindTasks(r.Context(), todev.TaskFilter{RepoID: &repo.ID}); err != nil {
-		Error(w, r, fmt.Errorf("error retrieving repo tasks: %v", err))
+		Error(w, r, fmt.Errorf("error retrieving repo tasks: %w", err))
 		return
 	}
 
@@ -126,6 +153,7 @@ func (s *Server) handleRepoView(w http.ResponseWriter, r *http.Request) {
 	currContributor := repo.ContributorByUserID(currUserID)
 	switch r.Header.Get("Accept") {
 	case "application/json":
+		w.Header().Set("Content-type", "application/json")
 		if err = json.Encode(repo, w); err != nil {
 			LogError(r, err)
 			return
@@ -135,7 +163,6 @@ func (s *Server) handleRepoView(w http.ResponseWriter, r *http.Request) {
 			UserID:      currUserID,
 			Contributor: currContributor,
 			Repo:        repo,
-			InviteCode:  fmt.Sprintf("%s/invite/%s", s.URL(), repo.InviteCode),
 		}
 
 		if tmpl, err := template.ParseFS(templateFiles, "html/base.html", "html/repoView.html"); err != nil {
@@ -171,7 +198,7 @@ func (s *Server) handleRepoCreate(w http.ResponseWriter, r *http.Request) {
 	switch r.Header.Get("Accept") {
 	case "application/json":
 		if err != nil {
-			Error(w, r, fmt.Errorf("error creating repo: %v", err))
+			Error(w, r, fmt.Errorf("error creating repo: %w", err))
 			return
 		}
 
@@ -215,7 +242,7 @@ func (s *Server) handleRepoEdit(w http.ResponseWriter, r *http.Request) {
 
 	repo, err := s.RepoService.FindRepoByID(r.Context(), id)
 	if err != nil {
-		Error(w, r, fmt.Errorf("error retrieving repo by ID: %v", err))
+		Error(w, r, fmt.Errorf("error retrieving repo by ID: %w", err))
 		return
 	}
 
@@ -231,9 +258,10 @@ func (s *Server) handleRepoEdit(w http.ResponseWriter, r *http.Request) {
 
 }
 
-// handleRepoUpdate handles the "PATCH /repos/:id/edit" route. This route reads
-// the udpated fields and issues an updat in the database. On success, it redirects
-// repo's view page.
+// handleRepoUpdate handles the "PATCH /repos/:id" and "PATCH /repos/:id/edit"
+// routes. This route reads the udpated fields and issues an updat in the
+// database. On success, it redirects repo's view page or returns the updated
+// repo for JSON requests.
 func (s *Server) handleRepoUpdate(w http.ResponseWriter, r *http.Request) {
 	// Parse repo ID from the path.
 	id, err := strconv.Atoi(mux.Vars(r)["id"])
@@ -243,27 +271,53 @@ func (s *Server) handleRepoUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var upd todev.RepoUpdate
-	name := r.PostFormValue("name")
-	upd.Name = &name
+	switch r.Header.Get("Content-type") {
+	case "application/json":
+		if err := json.Decode(r.Body, &upd); err != nil {
+			Error(w, r, todev.Errorf(todev.EINVALID, "Invalid JSON body"))
+			return
+		}
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				LogError(r, fmt.Errorf("error closing request body: %v", err))
+			}
+		}()
+	default:
+		name := r.PostFormValue("name")
+		upd.Name = &name
+	}
 
 	repo, err := s.RepoService.UpdateRepo(r.Context(), id, upd)
-	if todev.ErrorCode(err) == todev.EINTERNAL {
-		Error(w, r, err)
-		return
-	} else if err != nil {
-		tmplData := html.RepoEditTemplate{Repo: repo}
 
-		if tmpl, err := template.ParseFS(templateFiles, "html/base.html", "html/repoEdit.html"); err != nil {
-			LogError(r, fmt.Errorf("error parsing html file: %v", err))
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err != nil {
+			Error(w, r, fmt.Errorf("error updating repo: %w", err))
 			return
-		} else if err = tmpl.Execute(w, tmplData); err != nil {
-			LogError(r, fmt.Errorf("error executing template: %v", err))
+		} else if err = json.Write(w, http.StatusOK, repo); err != nil {
+			LogError(r, fmt.Errorf("error writing response: %v", err))
+			return
+		}
+	default:
+		if err != nil && (repo == nil || todev.ErrorCode(err) == todev.EINTERNAL) {
+			Error(w, r, err)
+			return
+		} else if err != nil {
+			tmplData := html.RepoEditTemplate{Repo: repo, Err: err}
+
+			if tmpl, err := template.ParseFS(templateFiles, "html/base.html", "html/repoEdit.html"); err != nil {
+				LogError(r, fmt.Errorf("error parsing html file: %v", err))
+				return
+			} else if err = tmpl.Execute(w, tmplData); err != nil {
+				LogError(r, fmt.Errorf("error executing template: %v", err))
+				return
+			}
 			return
 		}
-	}
 
-	SetFlash(w, "Repo successfully updated.")
-	http.Redirect(w, r, fmt.Sprintf("/repos/%d", repo.ID), http.StatusFound)
+		SetFlash(w, "Repo successfully updated.")
+		http.Redirect(w, r, fmt.Sprintf("/repos/%d", repo.ID), http.StatusFound)
+	}
 }
 
 // handleRepoDelete handles the "DELETE /repos/:id" route. This route permanently
@@ -278,19 +332,312 @@ func (s *Server) handleRepoDelete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err = s.RepoService.DeleteRepo(r.Context(), id); err != nil {
-		Error(w, r, err)
+		Error(w, r, fmt.Errorf("error deleting repo: %w", err))
 		return
 	}
 
 	switch r.Header.Get("Accept") {
 	case "application/json":
-		json.Write(w, http.StatusOK, []byte("{}"))
+		if err = json.Write(w, http.StatusOK, struct{}{}); err != nil {
+			LogError(r, fmt.Errorf("error writing response: %v", err))
+			return
+		}
 	default:
 		SetFlash(w, "Repo successfully  deleted.")
 		http.Redirect(w, r, "/repos", http.StatusFound)
 	}
 }
 
+// handleRepoRestore handles the "POST /repos/:id/restore" route. It moves a
+// repo out of the trash.
+func (s *Server) handleRepoRestore(w http.ResponseWriter, r *http.Request) {
+	// Parse repo ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	repo, err := s.RepoService.RestoreRepo(r.Context(), id)
+	if err != nil {
+		Error(w, r, fmt.Errorf("error restoring repo: %w", err))
+		return
+	}
+
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err = json.Write(w, http.StatusOK, repo); err != nil {
+			LogError(r, fmt.Errorf("error writing response: %v", err))
+			return
+		}
+	default:
+		SetFlash(w, "Repo successfully restored.")
+		http.Redirect(w, r, fmt.Sprintf("/repos/%d", repo.ID), http.StatusFound)
+	}
+}
+
+// handleRepoTrash handles the "GET /repos/:id/trash" route. It lists the
+// trashed tasks of a repo, most recently created first. An optional "cursor"
+// query parameter selects the page.
+//
+// The endpoint works with HTML and JSON formats.
+func (s *Server) handleRepoTrash(w http.ResponseWriter, r *http.Request) {
+	// Parse repo ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	repo, err := s.RepoService.FindRepoByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, fmt.Errorf("error retrieving repo by ID: %w", err))
+		return
+	}
+
+	filter := todev.TaskFilter{
+		RepoID:  &id,
+		Trashed: true,
+		Cursor:  r.URL.Query().Get("cursor"),
+		Limit:   20,
+		SortBy:  todev.TasksSortByCreatedAtDesc,
+	}
+	tasks, n, err := s.TaskService.FindTasks(r.Context(), filter)
+	if err != nil {
+		Error(w, r, fmt.Errorf("error retrieving trashed tasks: %w", err))
+		return
+	}
+	next := nextCursor(tasks, n, filter.Offset, func(task *todev.Task) int { return task.ID })
+
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err = json.Write(w, http.StatusOK, json.FindTasksResponse{Tasks: tasks, N: n, Next: next}); err != nil {
+			LogError(r, fmt.Errorf("error writing response: %v", err))
+			return
+		}
+	default:
+		tmplData := html.RepoTrashTemplate{
+			Repo:       repo,
+			Tasks:      tasks,
+			Pagination: html.Pagination{URL: *r.URL, Next: next},
+		}
+		if tmpl, err := template.ParseFS(templateFiles, "html/base.html", "html/repoTrash.html"); err != nil {
+			LogError(r, fmt.Errorf("error parsing html file: %v", err))
+			return
+		} else if err = tmpl.Execute(w, tmplData); err != nil {
+			LogError(r, fmt.Errorf("error executing template: %v", err))
+			return
+		}
+	}
+}
+
+// Default report settings used when not specified by the request.
+const (
+	DefaultReportInterval = 24 * time.Hour
+	DefaultReportRecords  = 30
+)
+
+// handleRepoReport handles the "GET /repos/:id/report" route. It accepts
+// optional "start" and "end" RFC 3339 timestamps and an "interval" duration
+// as query parameters. By default, the report covers the last 30 days of the
+// repo or its whole lifetime if it is younger.
+//
+// The endpoint works with JSON and CSV formats.
+func (s *Server) handleRepoReport(w http.ResponseWriter, r *http.Request) {
+	// Parse repo ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	// Default to JSON output for anything that is not CSV.
+	if r.Header.Get("Accept") != "text/csv" {
+		r.Header.Set("Accept", "application/json")
+	}
+
+	start, end, interval, err := s.parseReportRange(r, id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	report, err := s.RepoService.TasksLeftReport(r.Context(), id, start, end, interval)
+	if err != nil {
+		Error(w, r, fmt.Errorf("error generating report: %w", err))
+		return
+	}
+
+	switch r.Header.Get("Accept") {
+	case "text/csv":
+		w.Header().Set("Content-type", "text/csv")
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"timestamp", "tasks_left"})
+		for _, record := range report.Records {
+			cw.Write([]string{record.Timestamp.UTC().Format(time.RFC3339), strconv.Itoa(len(record.Tasks))})
+		}
+		cw.Flush()
+		if err = cw.Error(); err != nil {
+			LogError(r, fmt.Errorf("error writing response: %v", err))
+			return
+		}
+	default:
+		if err = json.Write(w, http.StatusOK, report); err != nil {
+			LogError(r, fmt.Errorf("error writing response: %v", err))
+			return
+		}
+	}
+}
+
+// handleRepoActivity handles the "GET /repos/:id/activity" route. It accepts
+// an optional JSON filter in the body and a "cursor" query parameter and
+// returns the activity log of the repo, newest first. This route is only
+// called via JSON API.
+func (s *Server) handleRepoActivity(w http.ResponseWriter, r *http.Request) {
+	r.Header.Set("Accept", "application/json")
+
+	// Parse repo ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	var filter todev.ActivityFilter
+	if err := json.Decode(r.Body, &filter); err != nil && err != io.EOF {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid JSON body"))
+		return
+	}
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			LogError(r, fmt.Errorf("error closing request body: %v", err))
+		}
+	}()
+
+	filter.RepoID = &id
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		filter.Cursor = v
+	}
+
+	activities, n, err := s.ActivityService.FindActivities(r.Context(), filter)
+	if err != nil {
+		Error(w, r, fmt.Errorf("error retrieving activities: %w", err))
+		return
+	} else if err = json.Write(w, http.StatusOK, json.FindActivitiesResponse{
+		Activities: activities,
+		N:          n,
+		Next:       nextCursor(activities, n, filter.Offset, func(a *todev.Activity) int { return a.ID }),
+	}); err != nil {
+		LogError(r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
+// handleRepoImport handles the "POST /repos/:id/import" route. It fetches the
+// open issues of the GitHub repo linked to the repo on behalf of the current
+// user and imports them as tasks.
+func (s *Server) handleRepoImport(w http.ResponseWriter, r *http.Request) {
+	// Parse repo ID from the path.
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	repo, err := s.RepoService.FindRepoByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, fmt.Errorf("error retrieving repo by ID: %w", err))
+		return
+	} else if repo.GitHubRepo == "" {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Repo is not linked to GitHub."))
+		return
+	}
+
+	// Issues are read with the GitHub token of the current user so private
+	// repositories are only visible to users who can see them on GitHub.
+	userID, source := todev.UserIDFromContext(r.Context()), todev.AuthSourceGitHub
+	auths, _, err := s.AuthService.FindAuths(r.Context(), todev.AuthFilter{UserID: &userID, Source: &source})
+	if err != nil {
+		Error(w, r, fmt.Errorf("error retrieving auths: %w", err))
+		return
+	} else if len(auths) == 0 {
+		Error(w, r, todev.Errorf(todev.EUNAUTHORIZED, "You must sign in with GitHub to import issues."))
+		return
+	}
+
+	accessToken, err := s.AuthService.AccessToken(r.Context(), auths[0].ID)
+	if err != nil {
+		Error(w, r, fmt.Errorf("error retrieving github token: %w", err))
+		return
+	}
+
+	issues, err := s.IssueService.FindOpenIssues(r.Context(), accessToken, repo.GitHubRepo)
+	if err != nil {
+		Error(w, r, fmt.Errorf("error retrieving issues: %w", err))
+		return
+	}
+
+	result, err := s.RepoService.ImportIssues(r.Context(), id, issues)
+	if err != nil {
+		Error(w, r, fmt.Errorf("error importing issues: %w", err))
+		return
+	}
+
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err = json.Write(w, http.StatusOK, result); err != nil {
+			LogError(r, fmt.Errorf("error writing response: %v", err))
+			return
+		}
+	default:
+		SetFlash(w, fmt.Sprintf("Imported %d new and %d updated issues.", result.Created, result.Updated))
+		http.Redirect(w, r, fmt.Sprintf("/repos/%d", id), http.StatusFound)
+	}
+}
+
+// parseReportRange reads the report range from the query parameters and fills
+// missing values with defaults.
+func (s *Server) parseReportRange(r *http.Request, repoID int) (start, end time.Time, interval time.Duration, err error) {
+	q := r.URL.Query()
+
+	interval = DefaultReportInterval
+	if v := q.Get("interval"); v != "" {
+		if interval, err = time.ParseDuration(v); err != nil {
+			return start, end, interval, todev.Errorf(todev.EINVALID, "Invalid interval format.")
+		}
+	}
+
+	end = time.Now().UTC().Truncate(time.Second)
+	if v := q.Get("end"); v != "" {
+		if end, err = time.Parse(time.RFC3339, v); err != nil {
+			return start, end, interval, todev.Errorf(todev.EINVALID, "Invalid end format.")
+		}
+	}
+
+	if v := q.Get("start"); v != "" {
+		if start, err = time.Parse(time.RFC3339, v); err != nil {
+			return start, end, interval, todev.Errorf(todev.EINVALID, "Invalid start format.")
+		}
+		return start, end, interval, nil
+	}
+
+	// Start from the repo creation unless it would exceed the default
+	// number of records.
+	repo, err := s.RepoService.FindRepoByID(r.Context(), repoID)
+	if err != nil {
+		return start, end, interval, fmt.Errorf("error retrieving repo by ID: %w", err)
+	}
+	start = end.Add(-(DefaultReportRecords - 1) * interval)
+	if repo.CreatedAt.After(start) {
+		start = repo.CreatedAt
+	}
+	if start.After(end) {
+		start = end
+	}
+
+	return start, end, interval, nil
+}
+
 // RepoService implements the todev.RepoService over the HTTP protocol.
 type RepoService struct {
 	Client *Client
@@ -322,11 +669,6 @@ func (s *RepoService) FindRepoByID(ctx context.Context, id int) (*todev.Repo, er
 	if err = json.Decode(resp.Body, &repo); err != nil {
 		return nil, err
 	}
-	defer func() {
-		if err := req.Body.Close(); err != nil {
-			LogError(req, fmt.Errorf("error closing request body: %v", err))
-		}
-	}()
 
 	return &repo, nil
 }
@@ -351,7 +693,7 @@ func (s *RepoService) FindRepos(ctx context.Context, filter todev.RepoFilter) ([
 	if err != nil {
 		return nil, 0, fmt.Errorf("error making request: %v", err)
 	} else if resp.StatusCode != http.StatusOK {
-		return nil, 0, fmt.Errorf("error status code: %d", resp.StatusCode)
+		return nil, 0, parseResponseError(resp)
 	}
 
 	// Unmarshal result set of repos and total repo count.
@@ -386,32 +728,48 @@ func (s *RepoService) CreateRepo(ctx context.Context, repo *todev.Repo) error {
 	if err != nil {
 		return err
 	} else if resp.StatusCode != http.StatusCreated {
-		log.Printf("status code: %d", resp.StatusCode)
 		return parseResponseError(resp)
 	}
 	defer resp.Body.Close()
 
-	if err = json.Decode(req.Body, &repo); err != nil {
-		return err
-	}
-	defer func() {
-		if err := req.Body.Close(); err != nil {
-			LogError(req, fmt.Errorf("error closing request body: %v", err))
-		}
-	}()
-	return nil
+	return json.Decode(resp.Body, repo)
 }
 
-// UpdateRepo is not implemented by the HTTP service.
+// UpdateRepo updates an existing repo by ID. Only the repo owner can update
+// a repo.
 func (s *RepoService) UpdateRepo(ctx context.Context, id int, upd todev.RepoUpdate) (*todev.Repo, error) {
-	return nil, todev.Errorf(todev.ENOTIMPLEMENTED, "Not implemented.")
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := json.Encode(upd, buf); err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "PATCH", fmt.Sprintf("/repos/%d", id), buf)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var repo todev.Repo
+	if err = json.Decode(resp.Body, &repo); err != nil {
+		return nil, err
+	}
+	return &repo, nil
 }
 
-// DeleteRepo permanently removes a repo by ID. Only the repo owner can delete
+// DeleteRepo moves a repo to the trash by ID. Only the repo owner can delete
 // a repo.
 func (s *RepoService) DeleteRepo(ctx context.Context, id int) error {
 	// Create request with API key.
-	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/repos/%d", id), nil)
+	req, err := s.Client.newRequest(ctx, "DELETE", fmt.Sprintf("/repos/%d", id), nil)
 	if err != nil {
 		return err
 	}
@@ -427,3 +785,84 @@ func (s *RepoService) DeleteRepo(ctx context.Context, id int) error {
 
 	return nil
 }
+
+// RestoreRepo moves a repo out of the trash. Only the repo owner can restore
+// a repo.
+func (s *RepoService) RestoreRepo(ctx context.Context, id int) (*todev.Repo, error) {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/repos/%d/restore", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var repo todev.Repo
+	if err = json.Decode(resp.Body, &repo); err != nil {
+		return nil, err
+	}
+	return &repo, nil
+}
+
+// TasksLeftReport returns a report of the uncompleted tasks of a repo at
+// every interval between start and end.
+func (s *RepoService) TasksLeftReport(ctx context.Context, repoID int, start, end time.Time, interval time.Duration) (*todev.RepoTasksReport, error) {
+	q := url.Values{}
+	q.Set("start", start.UTC().Format(time.RFC3339))
+	q.Set("end", end.UTC().Format(time.RFC3339))
+	q.Set("interval", interval.String())
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/repos/%d/report?%s", repoID, q.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var report todev.RepoTasksReport
+	if err = json.Decode(resp.Body, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ImportIssues imports the open issues of the GitHub repo linked to a repo as
+// tasks. The issues are read by the server on behalf of the current user.
+// The issues argument is ignored.
+func (s *RepoService) ImportIssues(ctx context.Context, repoID int, issues []*todev.Issue) (*todev.IssueImport, error) {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/repos/%d/import", repoID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var result todev.IssueImport
+	if err = json.Decode(resp.Body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
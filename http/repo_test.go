@@ -3,6 +3,9 @@ package http_test
 import (
 	"context"
 	"fmt"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -20,12 +23,11 @@ func TestRepoIndex(t *testing.T) {
 	ctx0 := todev.NewContextWithUser(context.Background(), user0)
 
 	repo := &todev.Repo{
-		ID:         1,
-		UserID:     1,
-		Name:       "repo1",
-		InviteCode: "inviteCode",
-		CreatedAt:  time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
-		UpdatedAt:  time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
+		ID:        1,
+		UserID:    1,
+		Name:      "repo1",
+		CreatedAt: time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
 	}
 
 	s.RepoService.FindReposFn = func(ctx context.Context, filter todev.RepoFilter) ([]*todev.Repo, int, error) {
@@ -33,12 +35,7 @@ func TestRepoIndex(t *testing.T) {
 	}
 
 	t.Run("JSON", func(t *testing.T) {
-		s.UserService.FindUsersFn = func(ctx context.Context, filter todev.UserFilter) ([]*todev.User, int, error) {
-			if filter.APIKey == nil || *filter.APIKey != "apiKey" {
-				t.Fatalf("unexpected api key: %#v", filter.APIKey)
-			}
-			return []*todev.User{user0}, 1, nil
-		}
+		s.MustAuthenticateAPIKey(t, user0)
 
 		repoService := todevhttp.NewRepoService(todevhttp.NewClient(s.URL()))
 		if repos, n, err := repoService.FindRepos(ctx0, todev.RepoFilter{}); err != nil {
@@ -54,3 +51,241 @@ func TestRepoIndex(t *testing.T) {
 	})
 
 }
+
+// Ensure the HTTP client can update and delete repos through the JSON API.
+func TestRepoService(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &todev.User{ID: 1, Name: "user1", APIKey: "apiKey"}
+	ctx0 := todev.NewContextWithUser(context.Background(), user0)
+	s.MustAuthenticateAPIKey(t, user0)
+
+	repoService := todevhttp.NewRepoService(todevhttp.NewClient(s.URL()))
+
+	t.Run("UpdateRepo", func(t *testing.T) {
+		s.RepoService.UpdateRepoFn = func(ctx context.Context, id int, upd todev.RepoUpdate) (*todev.Repo, error) {
+			if id != 1 {
+				t.Fatalf("unexpected id: %d", id)
+			} else if upd.Name == nil || *upd.Name != "repo2" {
+				t.Fatalf("unexpected name: %#v", upd.Name)
+			}
+			return &todev.Repo{ID: 1, UserID: 1, Name: *upd.Name}, nil
+		}
+
+		name := "repo2"
+		if repo, err := repoService.UpdateRepo(ctx0, 1, todev.RepoUpdate{Name: &name}); err != nil {
+			t.Fatal(err)
+		} else if got, want := repo.Name, "repo2"; got != want {
+			t.Fatalf("Name=%q, want %q", got, want)
+		}
+	})
+
+	t.Run("DeleteRepo", func(t *testing.T) {
+		s.RepoService.DeleteRepoFn = func(ctx context.Context, id int) error {
+			if id != 1 {
+				t.Fatalf("unexpected id: %d", id)
+			}
+			return nil
+		}
+
+		if err := repoService.DeleteRepo(ctx0, 1); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// Ensure error codes returned by the service are preserved by the client.
+	t.Run("ErrNotFound", func(t *testing.T) {
+		s.RepoService.DeleteRepoFn = func(ctx context.Context, id int) error {
+			return todev.Errorf(todev.ENOTFOUND, "Repo not found.")
+		}
+
+		if err := repoService.DeleteRepo(ctx0, 2); todev.ErrorCode(err) != todev.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if got, want := todev.ErrorMessage(err), "Repo not found."; got != want {
+			t.Fatalf("ErrorMessage=%q, want %q", got, want)
+		}
+	})
+}
+
+// Ensure the HTTP server imports the issues of a linked GitHub repo using the
+// refreshed GitHub token of the current user.
+func TestRepoImport(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &todev.User{ID: 1, Name: "user1", APIKey: "apiKey"}
+	ctx0 := todev.NewContextWithUser(context.Background(), user0)
+	s.MustAuthenticateAPIKey(t, user0)
+
+	repoService := todevhttp.NewRepoService(todevhttp.NewClient(s.URL()))
+	issues := []*todev.Issue{{Number: 1, Title: "Fix login", AssigneeIDs: []string{"100"}}}
+
+	s.AuthService.FindAuthsFn = func(ctx context.Context, filter todev.AuthFilter) ([]*todev.Auth, int, error) {
+		if filter.UserID == nil || *filter.UserID != 1 {
+			t.Fatalf("unexpected user id: %#v", filter.UserID)
+		} else if filter.Source == nil || *filter.Source != todev.AuthSourceGitHub {
+			t.Fatalf("unexpected source: %#v", filter.Source)
+		}
+		return []*todev.Auth{{ID: 2, UserID: 1, Source: todev.AuthSourceGitHub, AccessToken: "EXPIRED"}}, 1, nil
+	}
+	s.AuthService.AccessTokenFn = func(ctx context.Context, id int) (string, error) {
+		if id != 2 {
+			t.Fatalf("unexpected auth id: %d", id)
+		}
+		return "TOKEN", nil
+	}
+	s.IssueService.FindOpenIssuesFn = func(ctx context.Context, accessToken, fullName string) ([]*todev.Issue, error) {
+		if accessToken != "TOKEN" {
+			t.Fatalf("unexpected access token: %q", accessToken)
+		} else if fullName != "bob/todo" {
+			t.Fatalf("unexpected repo: %q", fullName)
+		}
+		return issues, nil
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		s.RepoService.FindRepoByIDFn = func(ctx context.Context, id int) (*todev.Repo, error) {
+			return &todev.Repo{ID: id, UserID: 1, Name: "repo1", GitHubRepo: "bob/todo"}, nil
+		}
+		s.RepoService.ImportIssuesFn = func(ctx context.Context, repoID int, other []*todev.Issue) (*todev.IssueImport, error) {
+			if repoID != 1 {
+				t.Fatalf("unexpected repo id: %d", repoID)
+			} else if diff := cmp.Diff(other, issues); diff != "" {
+				t.Fatal(diff)
+			}
+			return &todev.IssueImport{Created: 1}, nil
+		}
+
+		if result, err := repoService.ImportIssues(ctx0, 1, nil); err != nil {
+			t.Fatal(err)
+		} else if diff := cmp.Diff(result, &todev.IssueImport{Created: 1}); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("ErrNotLinked", func(t *testing.T) {
+		s.RepoService.FindRepoByIDFn = func(ctx context.Context, id int) (*todev.Repo, error) {
+			return &todev.Repo{ID: id, UserID: 1, Name: "repo1"}, nil
+		}
+
+		if _, err := repoService.ImportIssues(ctx0, 1, nil); todev.ErrorCode(err) != todev.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+// Ensure the HTTP server can return the tasks left report as JSON and CSV.
+func TestRepoReport(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &todev.User{ID: 1, Name: "user1", APIKey: "apiKey"}
+	ctx0 := todev.NewContextWithUser(context.Background(), user0)
+	s.MustAuthenticateAPIKey(t, user0)
+
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(48 * time.Hour)
+
+	s.RepoService.TasksLeftReportFn = func(ctx context.Context, repoID int, from, to time.Time, interval time.Duration) (*todev.RepoTasksReport, error) {
+		if repoID != 1 {
+			t.Fatalf("unexpected repo id: %d", repoID)
+		} else if !from.Equal(start) || !to.Equal(end) || interval != 24*time.Hour {
+			t.Fatalf("unexpected range: %s-%s/%s", from, to, interval)
+		}
+		return &todev.RepoTasksReport{Records: []*todev.RepoTasksRecord{
+			{Timestamp: start, Tasks: []todev.Task{{ID: 1}, {ID: 2}}},
+			{Timestamp: start.Add(24 * time.Hour), Tasks: []todev.Task{{ID: 2}}},
+			{Timestamp: end, Tasks: []todev.Task{}},
+		}}, nil
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		repoService := todevhttp.NewRepoService(todevhttp.NewClient(s.URL()))
+		if report, err := repoService.TasksLeftReport(ctx0, 1, start, end, 24*time.Hour); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(report.Records), 3; got != want {
+			t.Fatalf("len=%d, want %d", got, want)
+		} else if got, want := len(report.Records[1].Tasks), 1; got != want {
+			t.Fatalf("len(Tasks)=%d, want %d", got, want)
+		}
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		req, err := http.NewRequest("GET", s.URL()+"/repos/1/report.csv?start=2000-01-01T00:00:00Z&end=2000-01-03T00:00:00Z&interval=24h", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer apiKey")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		buf, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("StatusCode=%d, want %d", got, want)
+		} else if got, want := string(buf), "timestamp,tasks_left\n"+
+			"2000-01-01T00:00:00Z,2\n"+
+			"2000-01-02T00:00:00Z,1\n"+
+			"2000-01-03T00:00:00Z,0\n"; got != want {
+			t.Fatalf("body=%q, want %q", got, want)
+		}
+	})
+
+	t.Run("ErrInvalidInterval", func(t *testing.T) {
+		repoService := todevhttp.NewRepoService(todevhttp.NewClient(s.URL()))
+		s.RepoService.TasksLeftReportFn = func(ctx context.Context, repoID int, from, to time.Time, interval time.Duration) (*todev.RepoTasksReport, error) {
+			return nil, todev.ValidateRepoTasksReport(from, to, interval)
+		}
+		if _, err := repoService.TasksLeftReport(ctx0, 1, start, end, 0); todev.ErrorCode(err) != todev.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+// Ensure the HTTP server returns the activity log of a repo.
+func TestRepoActivity(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &todev.User{ID: 1, Name: "user1", APIKey: "apiKey"}
+	s.MustAuthenticateAPIKey(t, user0)
+
+	s.ActivityService.FindActivitiesFn = func(ctx context.Context, filter todev.ActivityFilter) ([]*todev.Activity, int, error) {
+		if filter.RepoID == nil || *filter.RepoID != 1 {
+			t.Fatalf("unexpected filter: %#v", filter)
+		} else if filter.Action == nil || *filter.Action != todev.ActivityActionDelete {
+			t.Fatalf("unexpected action: %#v", filter.Action)
+		}
+		return []*todev.Activity{{
+			ID:         5,
+			RepoID:     1,
+			UserID:     1,
+			EntityType: todev.ActivityEntityTask,
+			EntityID:   3,
+			Action:     todev.ActivityActionDelete,
+			Before:     map[string]interface{}{"description": "task3"},
+		}}, 2, nil
+	}
+
+	req, err := http.NewRequest("GET", s.URL()+"/repos/1/activity", strings.NewReader(`{"action": "delete", "limit": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer apiKey")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	} else if got, want := resp.StatusCode, http.StatusOK; got != want {
+		t.Fatalf("StatusCode=%d, want %d", got, want)
+	} else if body := MustReadAll(t, resp.Body); !strings.Contains(body, `"before":{"description":"task3"}`) ||
+		!strings.Contains(body, `"next":"`+todev.EncodeCursor(5)+`"`) {
+		t.Fatalf("unexpected body: %s", body)
+	}
+}
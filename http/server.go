@@ -25,8 +25,6 @@ import (
 	"github.com/saiddis/todev/http/html"
 	"github.com/saiddis/todev/http/json"
 	"golang.org/x/crypto/acme/autocert"
-	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/github"
 )
 
 //go:embed html/*.html
@@ -70,17 +68,34 @@ type Server struct {
 	HashKey  string
 	BlockKey string
 
-	// GitHub OAuth settings.
-	GitHubClientID     string
-	GitHubClientSecret string
+	// Providers users can log in with. At least one is required unless
+	// local accounts are enabled.
+	OAuthProviders []todev.OAuthProvider
+
+	// Service used for local accounts, which are disabled if nil. Requires
+	// a mailer to send verification and password reset links.
+	PasswordService todev.PasswordService
+	Mailer          todev.Mailer
+
+	// Secret used to verify the signature of GitHub webhook payloads.
+	// Webhooks are disabled if empty.
+	GitHubWebhookSecret string
 
 	// Services used by the various HTTP routes.
 	AuthService        todev.AuthService
 	RepoService        todev.RepoService
 	ContributorService todev.ContributorService
 	TaskService        todev.TaskService
+	CommentService     todev.CommentService
 	UserService        todev.UserService
+	TokenService       todev.TokenService
+	SessionService     todev.SessionService
 	EventService       todev.EventService
+	EventLogService    todev.EventLogService
+	ActivityService    todev.ActivityService
+
+	// Service used to read issues from GitHub.
+	IssueService todev.IssueService
 }
 
 // NewServer returns a new instance of server.
@@ -122,16 +137,53 @@ func NewServer() *Server {
 		r := s.router.PathPrefix("/").Subrouter()
 		r.Use(s.requireNoAuth)
 		s.registerAuthRoutes(r)
+		s.registerPasswordRoutes(r)
+	}
+
+	// Register routes that work whether the user is logged in or not.
+	{
+		r := router.PathPrefix("/").Subrouter()
+		s.registerLogoutRoutes(r)
+		s.registerEmailRoutes(r)
 	}
 
-	// Register authenticated routes.
+	// Register webhook routes. Requests are authenticated by their signature.
+	{
+		r := router.PathPrefix("/").Subrouter()
+		s.registerWebhookRoutes(r)
+	}
+
+	// Register authenticated routes. Requests made with an access token are
+	// restricted to the routes allowed by its scopes.
 	{
 		r := router.PathPrefix("/").Subrouter()
 		r.Use(s.requireAuth)
-		s.registerRepoRoutes(r)
-		s.registerContributorRoutes(r)
-		s.registerTaskRoutes(r)
-		s.registerEventRoutes(r)
+
+		{
+			r := r.PathPrefix("/").Subrouter()
+			r.Use(s.requireScope(todev.ScopeReposWrite))
+			s.registerRepoRoutes(r)
+			s.registerContributorRoutes(r)
+			s.registerInvitationRoutes(r)
+		}
+
+		{
+			r := r.PathPrefix("/").Subrouter()
+			r.Use(s.requireScope(todev.ScopeTasksWrite))
+			s.registerTaskRoutes(r)
+			s.registerCommentRoutes(r)
+		}
+
+		{
+			r := r.PathPrefix("/").Subrouter()
+			r.Use(s.requireScope(todev.ScopeAll))
+			s.registerUserRoutes(r)
+			s.registerAuthAPIRoutes(r)
+			s.registerTokenRoutes(r)
+			s.registerSessionRoutes(r)
+			s.registerPasswordSettingsRoutes(r)
+			s.registerEventRoutes(r)
+		}
 	}
 
 	return s
@@ -182,10 +234,17 @@ func (s *Server) Open() (err error) {
 		return err
 	}
 
-	if s.GitHubClientID == "" {
-		return fmt.Errorf("github client id required")
-	} else if s.GitHubClientSecret == "" {
-		return fmt.Errorf("github client secret required")
+	if len(s.OAuthProviders) == 0 && s.PasswordService == nil {
+		return fmt.Errorf("oauth provider or password service required")
+	} else if s.PasswordService != nil && s.Mailer == nil {
+		return fmt.Errorf("mailer required for local accounts")
+	}
+	sources := make(map[string]bool)
+	for _, p := range s.OAuthProviders {
+		if sources[p.Source()] {
+			return fmt.Errorf("duplicate oauth provider: %s", p.Source())
+		}
+		sources[p.Source()] = true
 	}
 
 	if s.Domain != "" {
@@ -231,14 +290,15 @@ func (s *Server) Close() error {
 	return s.server.Shutdown(ctx)
 }
 
-// OAuth2Config returns the GitHub OAuth2 configuration.
-func (s *Server) OAuth2Config() *oauth2.Config {
-	return &oauth2.Config{
-		ClientID:     s.GitHubClientID,
-		ClientSecret: s.GitHubClientSecret,
-		Scopes:       []string{},
-		Endpoint:     github.Endpoint,
+// OAuthProvider returns the login provider for the given auth source.
+// Returns ENOTFOUND if no such provider is configured.
+func (s *Server) OAuthProvider(source string) (todev.OAuthProvider, error) {
+	for _, p := range s.OAuthProviders {
+		if p.Source() == source {
+			return p, nil
+		}
 	}
+	return nil, todev.Errorf(todev.ENOTFOUND, "OAuth provider not found.")
 }
 
 func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
@@ -262,22 +322,19 @@ func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
 	s.router.ServeHTTP(w, r)
 }
 
-// authenticate is middleware for loading session data from a cookie or API key header.
+// authenticate is middleware for loading session data from a cookie or
+// personal access token header.
 func (s *Server) authenticate(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if v := r.Header.Get("Authorization"); strings.HasPrefix(v, "Bearer ") {
-			apiKey := strings.TrimPrefix(v, "Bearer ")
-
-			users, _, err := s.UserService.FindUsers(r.Context(), todev.UserFilter{APIKey: &apiKey})
+			token, err := s.TokenService.AuthenticateToken(r.Context(), strings.TrimPrefix(v, "Bearer "))
 			if err != nil {
 				Error(w, r, err)
 				return
-			} else if len(users) == 0 {
-				Error(w, r, todev.Errorf(todev.EUNAUTHORIZED, "Invalid API key."))
-				return
 			}
 
-			r = r.WithContext(todev.NewContextWithUser(r.Context(), users[0]))
+			ctx := todev.NewContextWithUser(r.Context(), token.User)
+			r = r.WithContext(todev.NewContextWithToken(ctx, token))
 
 			next.ServeHTTP(w, r)
 			return
@@ -285,9 +342,12 @@ func (s *Server) authenticate(next http.Handler) http.Handler {
 
 		session, _ := s.session(r)
 
-		if session.UserID != 0 {
-			if user, err := s.UserService.FindUserByID(r.Context(), session.UserID); err != nil {
-				log.Printf("error retrieving session user; id=%d err=%s", session.UserID, err)
+		// Revoked and expired sessions are treated as logged out.
+		if session.Secret != "" {
+			if other, err := s.SessionService.AuthenticateSession(r.Context(), session.Secret, clientIP(r)); err != nil {
+				if todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+					log.Printf("error retrieving session: %s", err)
+				}
 			} else {
 				http.SetCookie(w, &http.Cookie{
 					Name:     "avatar",
@@ -297,7 +357,8 @@ func (s *Server) authenticate(next http.Handler) http.Handler {
 					HttpOnly: false,
 				})
 
-				r = r.WithContext(todev.NewContextWithUser(r.Context(), user))
+				ctx := todev.NewContextWithUser(r.Context(), other.User)
+				r = r.WithContext(todev.NewContextWithSession(ctx, other))
 			}
 		}
 
@@ -342,6 +403,25 @@ func (s *Server) requireAuth(next http.Handler) http.Handler {
 
 }
 
+// requireScope returns middleware rejecting requests made with an access token
+// that does not grant scope. GET and HEAD requests only need read access.
+// Requests made with a browser session are not restricted.
+func (s *Server) requireScope(scope string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token := todev.TokenFromContext(r.Context()); token != nil {
+				readOnly := r.Method == http.MethodGet || r.Method == http.MethodHead
+				if !token.Allows(scope, readOnly) {
+					Error(w, r, todev.Errorf(todev.EUNAUTHORIZED, "Token is missing the %q scope.", scope))
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // loadFlash is middleware for reading flash data from the cookie.
 // Data is only loaded once and then immediately cleared.
 func (s *Server) loadFlash(next http.Handler) http.Handler {
@@ -2,11 +2,13 @@ package http_test
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"net/http"
 	"testing"
 
 	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/github"
 	todevhttp "github.com/saiddis/todev/http"
 	"github.com/saiddis/todev/mock"
 )
@@ -24,10 +26,21 @@ type Server struct {
 
 	AuthService        mock.AuthService
 	UserService        mock.UserService
+	TokenService       mock.TokenService
+	SessionService     mock.SessionService
 	ContributorService mock.ContributorService
 	TaskService        mock.TaskService
+	CommentService     mock.CommentService
 	RepoService        mock.RepoService
 	EventService       mock.EventService
+	EventLogService    mock.EventLogService
+	ActivityService    mock.ActivityService
+	IssueService       mock.IssueService
+	PasswordService    mock.PasswordService
+	Mailer             mock.Mailer
+
+	// Sessions attached by MustNewRequest, keyed by secret.
+	sessions map[string]*todev.Session
 }
 
 // MustOpenServer is a test helper function for starting a new test HTTP server.
@@ -38,15 +51,22 @@ func MustOpenServer(tb testing.TB) *Server {
 	s := &Server{Server: todevhttp.NewServer()}
 	s.HashKey = TestHashKey
 	s.BlockKey = TestBlockKey
-	s.GitHubClientID = TestGitHubClientID
-	s.GitHubClientSecret = TestGitHubClientSecret
+	s.OAuthProviders = []todev.OAuthProvider{github.NewOAuthProvider(TestGitHubClientID, TestGitHubClientSecret)}
 
 	s.Server.AuthService = &s.AuthService
 	s.Server.UserService = &s.UserService
+	s.Server.TokenService = &s.TokenService
+	s.Server.SessionService = &s.SessionService
 	s.Server.ContributorService = &s.ContributorService
 	s.Server.TaskService = &s.TaskService
+	s.Server.CommentService = &s.CommentService
 	s.Server.RepoService = &s.RepoService
 	s.Server.EventService = &s.EventService
+	s.Server.EventLogService = &s.EventLogService
+	s.Server.ActivityService = &s.ActivityService
+	s.Server.IssueService = &s.IssueService
+	s.Server.PasswordService = &s.PasswordService
+	s.Server.Mailer = &s.Mailer
 
 	if err := s.Open(); err != nil {
 		tb.Fatal(err)
@@ -74,7 +94,8 @@ func (s *Server) MustNewRequest(tb testing.TB, ctx context.Context, method, url
 	}
 
 	if user := todev.UserFromContext(ctx); user != nil {
-		data, err := s.MarshalSession(todevhttp.Session{UserID: user.ID})
+		secret := s.mustAttachSession(user)
+		data, err := s.MarshalSession(todevhttp.Session{Secret: secret})
 		if err != nil {
 			tb.Fatal(err)
 		}
@@ -87,3 +108,43 @@ func (s *Server) MustNewRequest(tb testing.TB, ctx context.Context, method, url
 
 	return r
 }
+
+// mustAttachSession registers a session for user and stubs the session lookup
+// done by the authentication middleware to find it. Returns the secret of the
+// session.
+func (s *Server) mustAttachSession(user *todev.User) string {
+	if s.sessions == nil {
+		s.sessions = make(map[string]*todev.Session)
+	}
+	secret := fmt.Sprintf("session-%d", user.ID)
+	s.sessions[secret] = &todev.Session{ID: user.ID, UserID: user.ID, User: user}
+
+	s.SessionService.AuthenticateSessionFn = func(ctx context.Context, secret, ip string) (*todev.Session, error) {
+		if session, ok := s.sessions[secret]; ok {
+			return session, nil
+		}
+		return nil, todev.Errorf(todev.EUNAUTHORIZED, "Session revoked.")
+	}
+	return secret
+}
+
+// MustAuthenticateAPIKey stubs the token lookup done by the authentication
+// middleware so that requests carrying user's API key are made as user with
+// full access.
+func (s *Server) MustAuthenticateAPIKey(tb testing.TB, user *todev.User) {
+	tb.Helper()
+	s.MustAuthenticateToken(tb, &todev.Token{UserID: user.ID, User: user, Scopes: []string{todev.ScopeAll}, Secret: user.APIKey})
+}
+
+// MustAuthenticateToken stubs the token lookup done by the authentication
+// middleware so that requests carrying the token's secret are made with it.
+func (s *Server) MustAuthenticateToken(tb testing.TB, token *todev.Token) {
+	tb.Helper()
+
+	s.TokenService.AuthenticateTokenFn = func(ctx context.Context, secret string) (*todev.Token, error) {
+		if secret != token.Secret {
+			tb.Fatalf("unexpected api key: %q", secret)
+		}
+		return token, nil
+	}
+}
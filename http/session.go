@@ -0,0 +1,259 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/http/html"
+	"github.com/saiddis/todev/http/json"
+)
+
+// maxUserAgentLen is the maximum length of the user agent stored with a
+// session.
+const maxUserAgentLen = 255
+
+// registerSessionRoutes is a helper function for registering the routes used
+// to manage the current user's browser sessions.
+func (s *Server) registerSessionRoutes(r *mux.Router) {
+	// Listing of the current user's sessions.
+	r.HandleFunc("/sessions", s.handleSessionIndex).Methods("GET")
+
+	// Revoking all sessions.
+	r.HandleFunc("/sessions", s.handleSessionDeleteAll).Methods("DELETE")
+
+	// Revoking a single session.
+	r.HandleFunc("/sessions/{id}", s.handleSessionDelete).Methods("DELETE")
+}
+
+// handleSessionIndex handles the "GET /sessions" route. It lists the current
+// user's sessions, newest first. The JSON format accepts an optional filter
+// as the body and a "cursor" query parameter.
+func (s *Server) handleSessionIndex(w http.ResponseWriter, r *http.Request) {
+	var filter todev.SessionFilter
+	if err := json.Decode(r.Body, &filter); err != nil && err != io.EOF {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid JSON body"))
+		return
+	}
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			LogError(r, fmt.Errorf("error closing request body: %v", err))
+		}
+	}()
+
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		filter.Cursor = v
+	}
+
+	sessions, n, err := s.SessionService.FindSessions(r.Context(), filter)
+	if err != nil {
+		Error(w, r, fmt.Errorf("error retrieving sessions: %w", err))
+		return
+	}
+	next := nextCursor(sessions, n, filter.Offset, func(session *todev.Session) int { return session.ID })
+
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err = json.Write(w, http.StatusOK, json.FindSessionsResponse{Sessions: sessions, N: n, Next: next}); err != nil {
+			LogError(r, fmt.Errorf("error writing response: %v", err))
+			return
+		}
+	default:
+		tmplData := html.SessionIndexTemplate{
+			Sessions:   sessions,
+			Pagination: html.Pagination{URL: *r.URL, Next: next},
+		}
+		if session := todev.SessionFromContext(r.Context()); session != nil {
+			tmplData.CurrentID = session.ID
+		}
+
+		if tmpl, err := template.ParseFS(templateFiles, "html/base.html", "html/sessionIndex.html"); err != nil {
+			LogError(r, fmt.Errorf("error parsing html file: %v", err))
+			return
+		} else if err = tmpl.Execute(w, tmplData); err != nil {
+			LogError(r, fmt.Errorf("error executing template: %v", err))
+			return
+		}
+	}
+}
+
+// handleSessionDelete handles the "DELETE /sessions/:id" route. The browser
+// using the session is logged out on its next request.
+func (s *Server) handleSessionDelete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	if err = s.SessionService.DeleteSession(r.Context(), id); err != nil {
+		Error(w, r, fmt.Errorf("error revoking session: %w", err))
+		return
+	}
+
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err = json.Write(w, http.StatusOK, struct{}{}); err != nil {
+			LogError(r, fmt.Errorf("error writing response: %v", err))
+			return
+		}
+	default:
+		if session := todev.SessionFromContext(r.Context()); session != nil && session.ID == id {
+			s.clearSession(w, r)
+			return
+		}
+		SetFlash(w, "Session successfully revoked.")
+		http.Redirect(w, r, "/sessions", http.StatusFound)
+	}
+}
+
+// handleSessionDeleteAll handles the "DELETE /sessions" route. It logs the
+// current user out everywhere, including the current browser.
+func (s *Server) handleSessionDeleteAll(w http.ResponseWriter, r *http.Request) {
+	if err := s.SessionService.DeleteSessions(r.Context()); err != nil {
+		Error(w, r, fmt.Errorf("error revoking sessions: %w", err))
+		return
+	}
+
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err := json.Write(w, http.StatusOK, struct{}{}); err != nil {
+			LogError(r, fmt.Errorf("error writing response: %v", err))
+			return
+		}
+	default:
+		s.clearSession(w, r)
+	}
+}
+
+// clearSession clears the session cookie of a browser whose session was
+// revoked and sends it to the login page.
+func (s *Server) clearSession(w http.ResponseWriter, r *http.Request) {
+	if err := s.setSession(w, Session{}); err != nil {
+		Error(w, r, err)
+		return
+	}
+	http.Redirect(w, r, "/login", http.StatusFound)
+}
+
+// clientIP returns the address of the client that sent the request.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// clientUserAgent returns the user agent of the request, truncated to fit the
+// session store.
+func clientUserAgent(r *http.Request) string {
+	ua := r.UserAgent()
+	if len(ua) <= maxUserAgentLen {
+		return ua
+	}
+
+	// Cut on a rune boundary so the stored value stays valid UTF-8.
+	n := maxUserAgentLen
+	for n > 0 && !utf8.RuneStart(ua[n]) {
+		n--
+	}
+	return ua[:n]
+}
+
+// SessionService implements the todev.SessionService over the HTTP protocol.
+type SessionService struct {
+	Client *Client
+}
+
+// NewSessionService returns a new instance of SessionService.
+func NewSessionService(client *Client) *SessionService {
+	return &SessionService{Client: client}
+}
+
+// FindSessions retrieves the current user's sessions based on filter.
+func (s *SessionService) FindSessions(ctx context.Context, filter todev.SessionFilter) ([]*todev.Session, int, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := json.Encode(filter, buf); err != nil {
+		return nil, 0, fmt.Errorf("error creating request: %v", err)
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", "/sessions", buf)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var jsonResponse json.FindSessionsResponse
+	if err = json.Decode(resp.Body, &jsonResponse); err != nil {
+		return nil, 0, fmt.Errorf("error decoding response: %v", err)
+	}
+	return jsonResponse.Sessions, jsonResponse.N, nil
+}
+
+// CreateSession is not supported by the HTTP client as sessions are created
+// by the server when logging in.
+func (s *SessionService) CreateSession(ctx context.Context, session *todev.Session) error {
+	return todev.Errorf(todev.ENOTIMPLEMENTED, "Not implemented.")
+}
+
+// AuthenticateSession is not supported by the HTTP client as sessions are
+// authenticated by the server on every request.
+func (s *SessionService) AuthenticateSession(ctx context.Context, secret, ip string) (*todev.Session, error) {
+	return nil, todev.Errorf(todev.ENOTIMPLEMENTED, "Not implemented.")
+}
+
+// DeleteSession revokes one of the current user's sessions by ID.
+func (s *SessionService) DeleteSession(ctx context.Context, id int) error {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "DELETE", fmt.Sprintf("/sessions/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+// DeleteSessions revokes all the current user's sessions.
+func (s *SessionService) DeleteSessions(ctx context.Context) error {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "DELETE", "/sessions", nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+	return nil
+}
@@ -0,0 +1,179 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/saiddis/todev"
+	todevhttp "github.com/saiddis/todev/http"
+)
+
+// Ensure the HTTP client round-trips session operations through the JSON API.
+func TestSessionService(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &todev.User{ID: 1, Name: "user1", APIKey: "apiKey"}
+	ctx0 := todev.NewContextWithUser(context.Background(), user0)
+	s.MustAuthenticateAPIKey(t, user0)
+
+	sessionService := todevhttp.NewSessionService(todevhttp.NewClient(s.URL()))
+
+	t.Run("FindSessions", func(t *testing.T) {
+		s.SessionService.FindSessionsFn = func(ctx context.Context, filter todev.SessionFilter) ([]*todev.Session, int, error) {
+			return []*todev.Session{{ID: 1, UserID: 1, UserAgent: "Firefox", IP: "127.0.0.1"}}, 1, nil
+		}
+
+		if sessions, n, err := sessionService.FindSessions(ctx0, todev.SessionFilter{}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(sessions), 1; got != want {
+			t.Fatalf("len=%d, want %d", got, want)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		} else if got, want := sessions[0].UserAgent, "Firefox"; got != want {
+			t.Fatalf("UserAgent=%q, want %q", got, want)
+		}
+	})
+
+	t.Run("DeleteSession", func(t *testing.T) {
+		s.SessionService.DeleteSessionFn = func(ctx context.Context, id int) error {
+			return todev.Errorf(todev.ENOTFOUND, "Session not found.")
+		}
+
+		if err := sessionService.DeleteSession(ctx0, 3); todev.ErrorCode(err) != todev.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("DeleteSessions", func(t *testing.T) {
+		var deleted bool
+		s.SessionService.DeleteSessionsFn = func(ctx context.Context) error {
+			deleted = true
+			return nil
+		}
+
+		if err := sessionService.DeleteSessions(ctx0); err != nil {
+			t.Fatal(err)
+		} else if !deleted {
+			t.Fatal("expected sessions to be deleted")
+		}
+	})
+}
+
+// Ensure a browser whose session was revoked is treated as logged out.
+func TestAuthenticate_SessionRevoked(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	s.SessionService.AuthenticateSessionFn = func(ctx context.Context, secret, ip string) (*todev.Session, error) {
+		if got, want := secret, "SESSION"; got != want {
+			t.Fatalf("secret=%q, want %q", got, want)
+		}
+		return nil, todev.Errorf(todev.EUNAUTHORIZED, "Session revoked.")
+	}
+
+	data, err := s.MarshalSession(todevhttp.Session{Secret: "SESSION"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest("GET", s.URL()+"/sessions", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.AddCookie(&http.Cookie{Name: todevhttp.SessionCookieName, Value: data, Path: "/"})
+
+	resp, err := NoRedirectClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	} else if err = resp.Body.Close(); err != nil {
+		t.Fatal(err)
+	} else if got, want := resp.StatusCode, http.StatusFound; got != want {
+		t.Fatalf("StatusCode=%d, want %d", got, want)
+	} else if got, want := resp.Header.Get("Location"), "/login"; got != want {
+		t.Fatalf("Location=%q, want %q", got, want)
+	}
+}
+
+// Ensure revoking every session logs the current browser out.
+func TestSessionDeleteAll(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &todev.User{ID: 1, Name: "user1"}
+	ctx0 := todev.NewContextWithUser(context.Background(), user0)
+
+	s.SessionService.DeleteSessionsFn = func(ctx context.Context) error {
+		if got, want := todev.UserIDFromContext(ctx), 1; got != want {
+			t.Fatalf("UserID=%d, want %d", got, want)
+		}
+		return nil
+	}
+
+	req := s.MustNewRequest(t, ctx0, "POST", "/sessions", strings.NewReader("_method=DELETE"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := NoRedirectClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	} else if err = resp.Body.Close(); err != nil {
+		t.Fatal(err)
+	} else if got, want := resp.StatusCode, http.StatusFound; got != want {
+		t.Fatalf("StatusCode=%d, want %d", got, want)
+	}
+
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name != todevhttp.SessionCookieName {
+			continue
+		}
+		var session todevhttp.Session
+		if err = s.UnmarshalSession(cookie.Value, &session); err != nil {
+			t.Fatal(err)
+		} else if session.Secret != "" {
+			t.Fatalf("unexpected secret: %q", session.Secret)
+		}
+		return
+	}
+	t.Fatal("expected session cookie")
+}
+
+// Ensure long user agents are cut on a rune boundary before being stored.
+func TestLogin_LongUserAgent(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	s.PasswordService.LoginFn = func(ctx context.Context, email, password string) (*todev.User, error) {
+		return &todev.User{ID: 1, Name: "bob", Email: email}, nil
+	}
+
+	var userAgent string
+	s.SessionService.CreateSessionFn = func(ctx context.Context, session *todev.Session) error {
+		userAgent = session.UserAgent
+		session.ID, session.Secret = 1, "SESSION"
+		return nil
+	}
+
+	form := url.Values{"email": {"bob@gmail.com"}, "password": {"password1"}}
+	req, err := http.NewRequest("POST", s.URL()+"/login", strings.NewReader(form.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", strings.Repeat("ж", 200))
+
+	resp, err := NoRedirectClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if got, want := resp.StatusCode, http.StatusFound; got != want {
+		t.Fatalf("StatusCode=%d, want %d", got, want)
+	} else if got, want := len(userAgent), 254; got != want {
+		t.Fatalf("len(UserAgent)=%d, want %d", got, want)
+	} else if !utf8.ValidString(userAgent) {
+		t.Fatalf("invalid UserAgent: %q", userAgent)
+	}
+}
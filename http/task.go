@@ -1,7 +1,10 @@
 package http
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -18,24 +21,46 @@ func (s *Server) registerTaskRoutes(r *mux.Router) {
 	// API endpoint for creating tasks.
 	r.HandleFunc("/tasks", s.handleTaskCreate).Methods("POST")
 
+	// View a single task.
+	r.HandleFunc("/tasks/{id}", s.handleTaskView).Methods("GET")
+
 	// Update task
 	r.HandleFunc("/tasks/{id}", s.handleTaskUpdate).Methods("PATCH")
 
 	// Delete task
 	r.HandleFunc("/tasks/{id}", s.handleTaskDelete).Methods("DELETE")
 
+	// Restoring a task from the trash of its repo.
+	r.HandleFunc("/tasks/{id}/restore", s.handleTaskRestore).Methods("POST")
+
 	// Attach contributor.
 	r.HandleFunc("/tasks/{taskID}/contributor/{contributorID}", s.handleTaskAttachContributor).Methods("POST")
 
 	// Unattach contributor.
 	r.HandleFunc("/tasks/{taskID}/contributor/{contributorID}", s.handleTaskUnattachContributor).Methods("DELETE")
+
+	// API endpoint for adding checklist items to a task.
+	r.HandleFunc("/tasks/{id}/checklist", s.handleChecklistItemCreate).Methods("POST")
+
+	// Check, edit or move a checklist item.
+	r.HandleFunc("/checklist/{id}", s.handleChecklistItemUpdate).Methods("PATCH")
+
+	// Delete checklist item.
+	r.HandleFunc("/checklist/{id}", s.handleChecklistItemDelete).Methods("DELETE")
+
+	// Block a task by another task.
+	r.HandleFunc("/tasks/{id}/dependencies/{blockerID}", s.handleTaskAddDependency).Methods("POST")
+
+	// Unblock a task.
+	r.HandleFunc("/tasks/{id}/dependencies/{blockerID}", s.handleTaskRemoveDependency).Methods("DELETE")
 }
 
 // handleTaskRepoView handles the "GET /tasks" route. This route retrieves all
-// tasks for the current user.
+// tasks for the current user. The filter is read from the JSON body while the
+// optional "q" query parameter searches the task descriptions.
 func (s *Server) handleTasksFind(w http.ResponseWriter, r *http.Request) {
 	var filter todev.TaskFilter
-	if err := json.Decode(r.Body, &filter); err != nil {
+	if err := json.Decode(r.Body, &filter); err != nil && err != io.EOF {
 		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid JSON body"))
 		return
 	}
@@ -45,21 +70,53 @@ func (s *Server) handleTasksFind(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
+	if v := r.URL.Query().Get("q"); v != "" {
+		filter.Query = &v
+	}
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		filter.Cursor = v
+	}
+	r.Header.Set("Accept", "application/json")
+
 	tasks, n, err := s.TaskService.FindTasks(r.Context(), filter)
 	if err != nil {
-		Error(w, r, fmt.Errorf("error retrieving tasks: %v", err))
+		Error(w, r, fmt.Errorf("error retrieving tasks: %w", err))
 		return
 	}
 
 	switch r.Header.Get("Accept") {
 	case "application/json":
-		if err = json.Encode(json.FindTasksResponse{Tasks: tasks, N: n}, w); err != nil {
+		if err = json.Encode(json.FindTasksResponse{
+			Tasks: tasks,
+			N:     n,
+			Next:  nextCursor(tasks, n, filter.Offset, func(task *todev.Task) int { return task.ID }),
+		}, w); err != nil {
 			LogError(r, fmt.Errorf("error writing response: %v", err))
 			return
 		}
 	}
 }
 
+// handleTaskView handles the "GET /tasks/:id" route. This route is only
+// called via JSON API.
+func (s *Server) handleTaskView(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	r.Header.Set("Accept", "application/json")
+
+	if task, err := s.TaskService.FindTaskByID(r.Context(), id); err != nil {
+		Error(w, r, fmt.Errorf("error retrieving task by ID=%d: %w", id, err))
+		return
+	} else if err = json.Write(w, http.StatusOK, task); err != nil {
+		Error(w, r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
 // handleTaskCreate handles the "POST /tasks" route.
 func (s *Server) handleTaskCreate(w http.ResponseWriter, r *http.Request) {
 	var task todev.Task
@@ -80,7 +137,7 @@ func (s *Server) handleTaskCreate(w http.ResponseWriter, r *http.Request) {
 
 	err := s.TaskService.CreateTask(r.Context(), &task)
 	if err != nil {
-		Error(w, r, fmt.Errorf("error creating task: %v", err))
+		Error(w, r, fmt.Errorf("error creating task: %w", err))
 		return
 	}
 
@@ -117,7 +174,7 @@ func (s *Server) handleTaskUpdate(w http.ResponseWriter, r *http.Request) {
 	}()
 
 	if task, err := s.TaskService.UpdateTask(r.Context(), id, upd); err != nil {
-		Error(w, r, fmt.Errorf("error updating task: %v", err))
+		Error(w, r, fmt.Errorf("error updating task: %w", err))
 		return
 	} else if err = json.Write(w, http.StatusOK, task); err != nil {
 		Error(w, r, fmt.Errorf("error writing response: %v", err))
@@ -134,15 +191,44 @@ func (s *Server) handleTaskDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	r.Header.Set("Accept", "application/json")
+
 	if err = s.TaskService.DeleteTask(r.Context(), id); err != nil {
-		Error(w, r, fmt.Errorf("error deleting task by ID=%d: %v", id, err))
+		Error(w, r, fmt.Errorf("error deleting task by ID=%d: %w", id, err))
 		return
-	} else if err = json.Encode("{}", w); err != nil {
+	} else if err = json.Write(w, http.StatusOK, struct{}{}); err != nil {
 		Error(w, r, fmt.Errorf("error writing response: %v", err))
 		return
 	}
 }
 
+// handleTaskRestore handles the "POST /tasks/:id/restore" route. It moves a
+// task out of the trash of its repo.
+func (s *Server) handleTaskRestore(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	task, err := s.TaskService.RestoreTask(r.Context(), id)
+	if err != nil {
+		Error(w, r, fmt.Errorf("error restoring task by ID=%d: %w", id, err))
+		return
+	}
+
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err = json.Write(w, http.StatusOK, task); err != nil {
+			LogError(r, fmt.Errorf("error writing response: %v", err))
+			return
+		}
+	default:
+		SetFlash(w, "Task successfully restored.")
+		http.Redirect(w, r, fmt.Sprintf("/repos/%d/trash", task.RepoID), http.StatusFound)
+	}
+}
+
 func (s *Server) handleTaskAttachContributor(w http.ResponseWriter, r *http.Request) {
 	taskID, err := strconv.Atoi(mux.Vars(r)["taskID"])
 	if err != nil {
@@ -158,10 +244,10 @@ func (s *Server) handleTaskAttachContributor(w http.ResponseWriter, r *http.Requ
 	r.Header.Set("Accept", "application/json")
 
 	if task, err := s.TaskService.FindTaskByID(r.Context(), taskID); err != nil {
-		Error(w, r, fmt.Errorf("error retrieving task by ID=%d: %v", taskID, err))
+		Error(w, r, fmt.Errorf("error retrieving task by ID=%d: %w", taskID, err))
 		return
 	} else if err = s.TaskService.AttachContributor(r.Context(), task, contributorID); err != nil {
-		Error(w, r, fmt.Errorf("error contributor ID for task with ID=%d: %v", taskID, err))
+		Error(w, r, fmt.Errorf("error contributor ID for task with ID=%d: %w", taskID, err))
 		// LogError(r, fmt.Errorf("error contributor ID for task with ID=%d: %v", taskID, err))
 		return
 	} else if err = json.Encode(task, w); err != nil {
@@ -185,13 +271,451 @@ func (s *Server) handleTaskUnattachContributor(w http.ResponseWriter, r *http.Re
 	r.Header.Set("Accept", "application/json")
 
 	if task, err := s.TaskService.FindTaskByID(r.Context(), taskID); err != nil {
-		Error(w, r, fmt.Errorf("error deleting task by ID=%d: %v", taskID, err))
+		Error(w, r, fmt.Errorf("error retrieving task by ID=%d: %w", taskID, err))
 		return
 	} else if err = s.TaskService.UnattachContributor(r.Context(), task, contributorID); err != nil {
-		Error(w, r, fmt.Errorf("error contributor ID for task with ID=%d: %v", taskID, err))
+		Error(w, r, fmt.Errorf("error contributor ID for task with ID=%d: %w", taskID, err))
 		return
 	} else if err = json.Encode(task, w); err != nil {
 		LogError(r, err)
 		return
 	}
 }
+
+// handleChecklistItemCreate handles the "POST /tasks/:id/checklist" route.
+func (s *Server) handleChecklistItemCreate(w http.ResponseWriter, r *http.Request) {
+	taskID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	r.Header.Set("Accept", "application/json")
+
+	var item todev.ChecklistItem
+	if err = json.Decode(r.Body, &item); err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid JSON body"))
+		return
+	}
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			LogError(r, fmt.Errorf("error closing request body: %v", err))
+		}
+	}()
+	item.TaskID = taskID
+
+	if err = s.TaskService.CreateChecklistItem(r.Context(), &item); err != nil {
+		Error(w, r, fmt.Errorf("error creating checklist item: %w", err))
+		return
+	} else if err = json.Write(w, http.StatusCreated, item); err != nil {
+		Error(w, r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
+// handleChecklistItemUpdate handles the "PATCH /checklist/:id" route.
+func (s *Server) handleChecklistItemUpdate(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	r.Header.Set("Accept", "application/json")
+
+	var upd todev.ChecklistItemUpdate
+	if err = json.Decode(r.Body, &upd); err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid JSON body"))
+		return
+	}
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			LogError(r, fmt.Errorf("error closing request body: %v", err))
+		}
+	}()
+
+	if item, err := s.TaskService.UpdateChecklistItem(r.Context(), id, upd); err != nil {
+		Error(w, r, fmt.Errorf("error updating checklist item: %w", err))
+		return
+	} else if err = json.Write(w, http.StatusOK, item); err != nil {
+		Error(w, r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
+// handleChecklistItemDelete handles the "DELETE /checklist/:id" route.
+func (s *Server) handleChecklistItemDelete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	r.Header.Set("Accept", "application/json")
+
+	if err = s.TaskService.DeleteChecklistItem(r.Context(), id); err != nil {
+		Error(w, r, fmt.Errorf("error deleting checklist item by ID=%d: %w", id, err))
+		return
+	} else if err = json.Write(w, http.StatusOK, struct{}{}); err != nil {
+		Error(w, r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
+// handleTaskAddDependency handles the "POST /tasks/:id/dependencies/:blockerID" route.
+func (s *Server) handleTaskAddDependency(w http.ResponseWriter, r *http.Request) {
+	taskID, blockerID, err := parseDependencyVars(r)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	r.Header.Set("Accept", "application/json")
+
+	if err = s.TaskService.AddDependency(r.Context(), taskID, blockerID); err != nil {
+		Error(w, r, fmt.Errorf("error adding dependency for task with ID=%d: %w", taskID, err))
+		return
+	} else if err = json.Write(w, http.StatusOK, todev.TaskDependency{TaskID: taskID, BlockerID: blockerID}); err != nil {
+		Error(w, r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
+// handleTaskRemoveDependency handles the "DELETE /tasks/:id/dependencies/:blockerID" route.
+func (s *Server) handleTaskRemoveDependency(w http.ResponseWriter, r *http.Request) {
+	taskID, blockerID, err := parseDependencyVars(r)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	r.Header.Set("Accept", "application/json")
+
+	if err = s.TaskService.RemoveDependency(r.Context(), taskID, blockerID); err != nil {
+		Error(w, r, fmt.Errorf("error removing dependency for task with ID=%d: %w", taskID, err))
+		return
+	} else if err = json.Write(w, http.StatusOK, struct{}{}); err != nil {
+		Error(w, r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
+// parseDependencyVars returns the task and blocker IDs from the route variables.
+func parseDependencyVars(r *http.Request) (taskID, blockerID int, err error) {
+	if taskID, err = strconv.Atoi(mux.Vars(r)["id"]); err != nil {
+		return 0, 0, todev.Errorf(todev.EINVALID, "Invalid ID format")
+	} else if blockerID, err = strconv.Atoi(mux.Vars(r)["blockerID"]); err != nil {
+		return 0, 0, todev.Errorf(todev.EINVALID, "Invalid blocker ID format")
+	}
+	return taskID, blockerID, nil
+}
+
+// TaskService implements the todev.TaskService over the HTTP protocol.
+type TaskService struct {
+	Client *Client
+}
+
+func NewTaskService(client *Client) *TaskService {
+	return &TaskService{Client: client}
+}
+
+// FindTaskByID retrieves a single task by ID along with associated contributor IDs.
+func (s *TaskService) FindTaskByID(ctx context.Context, id int) (*todev.Task, error) {
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/tasks/%d", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var task todev.Task
+	if err = json.Decode(resp.Body, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// FindTasks retrieves a list of tasks based on a filter. Also returns a count
+// of total matching tasks.
+func (s *TaskService) FindTasks(ctx context.Context, filter todev.TaskFilter) ([]*todev.Task, int, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := json.Encode(filter, buf); err != nil {
+		return nil, 0, fmt.Errorf("error creating request: %v", err)
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", "/tasks", buf)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal result set of tasks and total task count.
+	var jsonResponse json.FindTasksResponse
+	if err = json.Decode(resp.Body, &jsonResponse); err != nil {
+		return nil, 0, fmt.Errorf("error decoding response: %v", err)
+	}
+	return jsonResponse.Tasks, jsonResponse.N, nil
+}
+
+// CreateTask creates a new task in a repo. Only the repo owner can create tasks.
+func (s *TaskService) CreateTask(ctx context.Context, task *todev.Task) error {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := json.Encode(task, buf); err != nil {
+		return fmt.Errorf("error creating request: %v", err)
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "POST", "/tasks", buf)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-201 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusCreated {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return json.Decode(resp.Body, task)
+}
+
+// UpdateTask updates an existing task by ID and returns its new state.
+func (s *TaskService) UpdateTask(ctx context.Context, id int, upd todev.TaskUpdate) (*todev.Task, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := json.Encode(upd, buf); err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "PATCH", fmt.Sprintf("/tasks/%d", id), buf)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var task todev.Task
+	if err = json.Decode(resp.Body, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// DeleteTask moves a task to the trash of its repo by ID. Only repo admins
+// can delete tasks.
+func (s *TaskService) DeleteTask(ctx context.Context, id int) error {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "DELETE", fmt.Sprintf("/tasks/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// RestoreTask moves a task out of the trash of its repo. Only repo admins can
+// restore tasks.
+func (s *TaskService) RestoreTask(ctx context.Context, id int) (*todev.Task, error) {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/tasks/%d/restore", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var task todev.Task
+	if err = json.Decode(resp.Body, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// AttachContributor gives the task to a contributor. On success, task is
+// updated with the new state returned by the server.
+func (s *TaskService) AttachContributor(ctx context.Context, task *todev.Task, contributorID int) error {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/tasks/%d/contributor/%d", task.ID, contributorID), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return json.Decode(resp.Body, task)
+}
+
+// UnattachContributor takes the task away from a contributor. On success,
+// task is updated with the new state returned by the server.
+func (s *TaskService) UnattachContributor(ctx context.Context, task *todev.Task, contributorID int) error {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "DELETE", fmt.Sprintf("/tasks/%d/contributor/%d", task.ID, contributorID), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return json.Decode(resp.Body, task)
+}
+
+// CreateChecklistItem appends a new item to the checklist of a task. On
+// success, item is updated with the new state returned by the server.
+func (s *TaskService) CreateChecklistItem(ctx context.Context, item *todev.ChecklistItem) error {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := json.Encode(item, buf); err != nil {
+		return fmt.Errorf("error creating request: %v", err)
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "POST", fmt.Sprintf("/tasks/%d/checklist", item.TaskID), buf)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-201 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusCreated {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return json.Decode(resp.Body, item)
+}
+
+// UpdateChecklistItem updates a checklist item by ID and returns its new state.
+func (s *TaskService) UpdateChecklistItem(ctx context.Context, id int, upd todev.ChecklistItemUpdate) (*todev.ChecklistItem, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := json.Encode(upd, buf); err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "PATCH", fmt.Sprintf("/checklist/%d", id), buf)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var item todev.ChecklistItem
+	if err = json.Decode(resp.Body, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// DeleteChecklistItem permanently removes a checklist item by ID.
+func (s *TaskService) DeleteChecklistItem(ctx context.Context, id int) error {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "DELETE", fmt.Sprintf("/checklist/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// AddDependency marks a task as blocked by another task of the same repo.
+func (s *TaskService) AddDependency(ctx context.Context, taskID, blockerID int) error {
+	return s.doDependency(ctx, "POST", taskID, blockerID)
+}
+
+// RemoveDependency removes a dependency between two tasks.
+func (s *TaskService) RemoveDependency(ctx context.Context, taskID, blockerID int) error {
+	return s.doDependency(ctx, "DELETE", taskID, blockerID)
+}
+
+// doDependency issues a request against the dependency route of a task.
+func (s *TaskService) doDependency(ctx context.Context, method string, taskID, blockerID int) error {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, method, fmt.Sprintf("/tasks/%d/dependencies/%d", taskID, blockerID), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
@@ -0,0 +1,262 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/saiddis/todev"
+	todevhttp "github.com/saiddis/todev/http"
+)
+
+// Ensure the HTTP client round-trips every task operation through the JSON API.
+func TestTaskService(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &todev.User{ID: 1, Name: "user1", APIKey: "apiKey"}
+	ctx0 := todev.NewContextWithUser(context.Background(), user0)
+	s.MustAuthenticateAPIKey(t, user0)
+
+	taskService := todevhttp.NewTaskService(todevhttp.NewClient(s.URL()))
+
+	t.Run("FindTaskByID", func(t *testing.T) {
+		task := &todev.Task{ID: 1, RepoID: 1, OwnerID: 1, Description: "task1", ContributorIDs: []int{2}}
+		s.TaskService.FindTaskByIDFn = func(ctx context.Context, id int) (*todev.Task, error) {
+			if id != 1 {
+				return nil, todev.Errorf(todev.ENOTFOUND, "Task not found.")
+			}
+			return task, nil
+		}
+
+		if other, err := taskService.FindTaskByID(ctx0, 1); err != nil {
+			t.Fatal(err)
+		} else if diff := cmp.Diff(other, task); diff != "" {
+			t.Fatal(diff)
+		}
+
+		if _, err := taskService.FindTaskByID(ctx0, 2); todev.ErrorCode(err) != todev.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("FindTasks", func(t *testing.T) {
+		s.TaskService.FindTasksFn = func(ctx context.Context, filter todev.TaskFilter) ([]*todev.Task, int, error) {
+			if filter.RepoID == nil || *filter.RepoID != 1 {
+				t.Fatalf("unexpected filter: %#v", filter)
+			}
+			return []*todev.Task{{ID: 1, RepoID: 1}, {ID: 2, RepoID: 1}}, 2, nil
+		}
+
+		repoID := 1
+		if tasks, n, err := taskService.FindTasks(ctx0, todev.TaskFilter{RepoID: &repoID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(tasks), 2; got != want {
+			t.Fatalf("len=%d, want %d", got, want)
+		} else if got, want := n, 2; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		}
+	})
+
+	// Ensure the "q" query parameter searches task descriptions.
+	t.Run("Search", func(t *testing.T) {
+		s.TaskService.FindTasksFn = func(ctx context.Context, filter todev.TaskFilter) ([]*todev.Task, int, error) {
+			if filter.Query == nil || *filter.Query != "login bug" {
+				t.Fatalf("unexpected filter: %#v", filter)
+			}
+			return []*todev.Task{{ID: 1, RepoID: 1, Description: "Fix login bug", Snippet: "Fix <mark>login</mark> <mark>bug</mark>"}}, 1, nil
+		}
+
+		req := s.MustNewRequest(t, context.Background(), "GET", "/tasks.json?q=login+bug", nil)
+		req.Header.Set("Authorization", "Bearer "+user0.APIKey)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		} else if body := MustReadAll(t, resp.Body); !strings.Contains(body, `"snippet":"Fix \u003cmark\u003elogin\u003c/mark\u003e \u003cmark\u003ebug\u003c/mark\u003e"`) {
+			t.Fatalf("unexpected body: %s", body)
+		}
+	})
+
+	t.Run("Cursor", func(t *testing.T) {
+		s.TaskService.FindTasksFn = func(ctx context.Context, filter todev.TaskFilter) ([]*todev.Task, int, error) {
+			if got, want := filter.Cursor, todev.EncodeCursor(1); got != want {
+				t.Fatalf("Cursor=%q, want %q", got, want)
+			}
+			return []*todev.Task{{ID: 2, RepoID: 1}, {ID: 3, RepoID: 1}}, 3, nil
+		}
+
+		req := s.MustNewRequest(t, context.Background(), "GET", "/tasks.json?cursor="+todev.EncodeCursor(1), nil)
+		req.Header.Set("Authorization", "Bearer "+user0.APIKey)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		} else if body := MustReadAll(t, resp.Body); !strings.Contains(body, `"next":"`+todev.EncodeCursor(3)+`"`) {
+			t.Fatalf("unexpected body: %s", body)
+		}
+	})
+
+	t.Run("CreateTask", func(t *testing.T) {
+		s.TaskService.CreateTaskFn = func(ctx context.Context, task *todev.Task) error {
+			task.ID = 3
+			return nil
+		}
+
+		task := &todev.Task{RepoID: 1, Description: "task3"}
+		if err := taskService.CreateTask(ctx0, task); err != nil {
+			t.Fatal(err)
+		} else if got, want := task.ID, 3; got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		}
+	})
+
+	t.Run("UpdateTask", func(t *testing.T) {
+		s.TaskService.UpdateTaskFn = func(ctx context.Context, id int, upd todev.TaskUpdate) (*todev.Task, error) {
+			if upd.SetStatus == nil || *upd.SetStatus != 3 {
+				t.Fatalf("unexpected status: %#v", upd.SetStatus)
+			} else if id != 1 {
+				return nil, todev.Errorf(todev.EUNAUTHORIZED, "You are not allowed to update tasks.")
+			}
+			return &todev.Task{ID: id, StateID: *upd.SetStatus, IsCompleted: true}, nil
+		}
+
+		stateID := 3
+		if task, err := taskService.UpdateTask(ctx0, 1, todev.TaskUpdate{SetStatus: &stateID}); err != nil {
+			t.Fatal(err)
+		} else if !task.IsCompleted {
+			t.Fatal("expected task to be completed")
+		} else if got, want := task.StateID, stateID; got != want {
+			t.Fatalf("StateID=%d, want %d", got, want)
+		}
+
+		// Permission errors from the service must reach the client unchanged.
+		if _, err := taskService.UpdateTask(ctx0, 2, todev.TaskUpdate{SetStatus: &stateID}); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("DeleteTask", func(t *testing.T) {
+		s.TaskService.DeleteTaskFn = func(ctx context.Context, id int) error {
+			if id != 1 {
+				return todev.Errorf(todev.EUNAUTHORIZED, "You are not allowed to delete this task.")
+			}
+			return nil
+		}
+
+		if err := taskService.DeleteTask(ctx0, 1); err != nil {
+			t.Fatal(err)
+		} else if err = taskService.DeleteTask(ctx0, 2); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("RestoreTask", func(t *testing.T) {
+		s.TaskService.RestoreTaskFn = func(ctx context.Context, id int) (*todev.Task, error) {
+			if id != 1 {
+				return nil, todev.Errorf(todev.ENOTFOUND, "Task not found in trash.")
+			}
+			return &todev.Task{ID: id, RepoID: 2}, nil
+		}
+
+		if task, err := taskService.RestoreTask(ctx0, 1); err != nil {
+			t.Fatal(err)
+		} else if got, want := task.RepoID, 2; got != want {
+			t.Fatalf("RepoID=%d, want %d", got, want)
+		} else if _, err = taskService.RestoreTask(ctx0, 2); todev.ErrorCode(err) != todev.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("AttachContributor", func(t *testing.T) {
+		s.TaskService.FindTaskByIDFn = func(ctx context.Context, id int) (*todev.Task, error) {
+			return &todev.Task{ID: id}, nil
+		}
+		s.TaskService.AttachContributorFn = func(ctx context.Context, task *todev.Task, contributorID int) error {
+			task.ContributorIDs = append(task.ContributorIDs, contributorID)
+			return nil
+		}
+		s.TaskService.UnattachContributorFn = func(ctx context.Context, task *todev.Task, contributorID int) error {
+			task.ContributorIDs = nil
+			return nil
+		}
+
+		task := &todev.Task{ID: 1}
+		if err := taskService.AttachContributor(ctx0, task, 2); err != nil {
+			t.Fatal(err)
+		} else if diff := cmp.Diff(task.ContributorIDs, []int{2}); diff != "" {
+			t.Fatal(diff)
+		}
+
+		if err := taskService.UnattachContributor(ctx0, task, 2); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(task.ContributorIDs), 0; got != want {
+			t.Fatalf("len=%d, want %d", got, want)
+		}
+	})
+
+	t.Run("Checklist", func(t *testing.T) {
+		s.TaskService.CreateChecklistItemFn = func(ctx context.Context, item *todev.ChecklistItem) error {
+			item.ID, item.Position = 5, 2
+			return nil
+		}
+		s.TaskService.UpdateChecklistItemFn = func(ctx context.Context, id int, upd todev.ChecklistItemUpdate) (*todev.ChecklistItem, error) {
+			if upd.IsChecked == nil || !*upd.IsChecked {
+				t.Fatalf("unexpected update: %#v", upd)
+			}
+			return &todev.ChecklistItem{ID: id, TaskID: 1, Text: "step", IsChecked: true}, nil
+		}
+		s.TaskService.DeleteChecklistItemFn = func(ctx context.Context, id int) error {
+			if id != 5 {
+				return todev.Errorf(todev.ENOTFOUND, "Checklist item not found.")
+			}
+			return nil
+		}
+
+		item := &todev.ChecklistItem{TaskID: 1, Text: "step"}
+		if err := taskService.CreateChecklistItem(ctx0, item); err != nil {
+			t.Fatal(err)
+		} else if got, want := item.ID, 5; got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		} else if got, want := item.Position, 2; got != want {
+			t.Fatalf("Position=%d, want %d", got, want)
+		}
+
+		checked := true
+		if other, err := taskService.UpdateChecklistItem(ctx0, item.ID, todev.ChecklistItemUpdate{IsChecked: &checked}); err != nil {
+			t.Fatal(err)
+		} else if !other.IsChecked {
+			t.Fatal("expected item to be checked")
+		}
+
+		if err := taskService.DeleteChecklistItem(ctx0, item.ID); err != nil {
+			t.Fatal(err)
+		} else if err := taskService.DeleteChecklistItem(ctx0, 6); todev.ErrorCode(err) != todev.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("Dependencies", func(t *testing.T) {
+		s.TaskService.AddDependencyFn = func(ctx context.Context, taskID, blockerID int) error {
+			if taskID == blockerID {
+				return todev.Errorf(todev.EINVALID, "Dependency would create a cycle.")
+			}
+			return nil
+		}
+		s.TaskService.RemoveDependencyFn = func(ctx context.Context, taskID, blockerID int) error {
+			return todev.Errorf(todev.ENOTFOUND, "Dependency not found.")
+		}
+
+		if err := taskService.AddDependency(ctx0, 1, 2); err != nil {
+			t.Fatal(err)
+		} else if err := taskService.AddDependency(ctx0, 1, 1); todev.ErrorCode(err) != todev.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if err := taskService.RemoveDependency(ctx0, 1, 2); todev.ErrorCode(err) != todev.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
@@ -0,0 +1,258 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/http/html"
+	"github.com/saiddis/todev/http/json"
+)
+
+// registerTokenRoutes is a helper function for registering personal access
+// token routes.
+func (s *Server) registerTokenRoutes(r *mux.Router) {
+	// Listing of the current user's tokens.
+	r.HandleFunc("/tokens", s.handleTokenIndex).Methods("GET")
+
+	// Creating a new token.
+	r.HandleFunc("/tokens", s.handleTokenCreate).Methods("POST")
+
+	// Revoking a token.
+	r.HandleFunc("/tokens/{id}", s.handleTokenDelete).Methods("DELETE")
+}
+
+// handleTokenIndex handles the "GET /tokens" route. It lists the current
+// user's tokens, newest first. The JSON format accepts an optional filter as
+// the body and a "cursor" query parameter.
+func (s *Server) handleTokenIndex(w http.ResponseWriter, r *http.Request) {
+	var filter todev.TokenFilter
+	if err := json.Decode(r.Body, &filter); err != nil && err != io.EOF {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid JSON body"))
+		return
+	}
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			LogError(r, fmt.Errorf("error closing request body: %v", err))
+		}
+	}()
+
+	if v := r.URL.Query().Get("cursor"); v != "" {
+		filter.Cursor = v
+	}
+
+	tokens, n, err := s.TokenService.FindTokens(r.Context(), filter)
+	if err != nil {
+		Error(w, r, fmt.Errorf("error retrieving tokens: %w", err))
+		return
+	}
+	next := nextCursor(tokens, n, filter.Offset, func(token *todev.Token) int { return token.ID })
+
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err = json.Write(w, http.StatusOK, json.FindTokensResponse{Tokens: tokens, N: n, Next: next}); err != nil {
+			LogError(r, fmt.Errorf("error writing response: %v", err))
+			return
+		}
+	default:
+		s.renderTokenIndex(w, r, html.TokenIndexTemplate{
+			Tokens:     tokens,
+			Pagination: html.Pagination{URL: *r.URL, Next: next},
+		})
+	}
+}
+
+// handleTokenCreate handles the "POST /tokens" route. HTML forms set the
+// scopes as repeated "scope" fields and the expiry as a duration from now in
+// the "expiresIn" field. The secret is only returned by this route.
+func (s *Server) handleTokenCreate(w http.ResponseWriter, r *http.Request) {
+	var token todev.Token
+	switch r.Header.Get("Content-type") {
+	case "application/json":
+		if err := json.Decode(r.Body, &token); err != nil {
+			Error(w, r, todev.Errorf(todev.EINVALID, "Invalid JSON body"))
+			return
+		}
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				LogError(r, fmt.Errorf("error closing request body: %v", err))
+			}
+		}()
+	default:
+		if err := r.ParseForm(); err != nil {
+			Error(w, r, todev.Errorf(todev.EINVALID, "Invalid form"))
+			return
+		}
+		token.Name = r.PostFormValue("name")
+		token.Scopes = r.PostForm["scope"]
+		if v := r.PostFormValue("expiresIn"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				Error(w, r, todev.Errorf(todev.EINVALID, "Invalid expiry format"))
+				return
+			}
+			token.ExpiresAt = time.Now().Add(d)
+		}
+	}
+
+	if err := s.TokenService.CreateToken(r.Context(), &token); err != nil {
+		Error(w, r, fmt.Errorf("error creating token: %w", err))
+		return
+	}
+
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err := json.Write(w, http.StatusCreated, token); err != nil {
+			LogError(r, fmt.Errorf("error writing response: %v", err))
+			return
+		}
+	default:
+		// Render the page directly instead of redirecting as the secret
+		// cannot be retrieved again.
+		tokens, n, err := s.TokenService.FindTokens(r.Context(), todev.TokenFilter{})
+		if err != nil {
+			Error(w, r, fmt.Errorf("error retrieving tokens: %w", err))
+			return
+		}
+
+		s.renderTokenIndex(w, r, html.TokenIndexTemplate{
+			Tokens:     tokens,
+			Created:    &token,
+			Pagination: html.Pagination{URL: *r.URL, Next: nextCursor(tokens, n, 0, func(token *todev.Token) int { return token.ID })},
+		})
+	}
+}
+
+// renderTokenIndex renders the page used to manage tokens.
+func (s *Server) renderTokenIndex(w http.ResponseWriter, r *http.Request, data html.TokenIndexTemplate) {
+	data.Scopes = todev.Scopes
+	if tmpl, err := template.ParseFS(templateFiles, "html/base.html", "html/tokenIndex.html"); err != nil {
+		LogError(r, fmt.Errorf("error parsing html file: %v", err))
+		return
+	} else if err = tmpl.Execute(w, data); err != nil {
+		LogError(r, fmt.Errorf("error executing template: %v", err))
+		return
+	}
+}
+
+// handleTokenDelete handles the "DELETE /tokens/:id" route. The token stops
+// working immediately.
+func (s *Server) handleTokenDelete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	if err = s.TokenService.DeleteToken(r.Context(), id); err != nil {
+		Error(w, r, fmt.Errorf("error deleting token: %w", err))
+		return
+	}
+
+	switch r.Header.Get("Accept") {
+	case "application/json":
+		if err = json.Write(w, http.StatusOK, struct{}{}); err != nil {
+			LogError(r, fmt.Errorf("error writing response: %v", err))
+			return
+		}
+	default:
+		SetFlash(w, "Token successfully revoked.")
+		http.Redirect(w, r, "/tokens", http.StatusFound)
+	}
+}
+
+// TokenService implements the todev.TokenService over the HTTP protocol.
+type TokenService struct {
+	Client *Client
+}
+
+// NewTokenService returns a new instance of TokenService.
+func NewTokenService(client *Client) *TokenService {
+	return &TokenService{Client: client}
+}
+
+// FindTokens retrieves the current user's tokens based on filter.
+func (s *TokenService) FindTokens(ctx context.Context, filter todev.TokenFilter) ([]*todev.Token, int, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := json.Encode(filter, buf); err != nil {
+		return nil, 0, fmt.Errorf("error creating request: %v", err)
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", "/tokens", buf)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var jsonResponse json.FindTokensResponse
+	if err = json.Decode(resp.Body, &jsonResponse); err != nil {
+		return nil, 0, fmt.Errorf("error decoding response: %v", err)
+	}
+	return jsonResponse.Tokens, jsonResponse.N, nil
+}
+
+// CreateToken creates a new token for the current user and sets its secret.
+func (s *TokenService) CreateToken(ctx context.Context, token *todev.Token) error {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := json.Encode(token, buf); err != nil {
+		return fmt.Errorf("error creating request: %v", err)
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "POST", "/tokens", buf)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-201 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusCreated {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return json.Decode(resp.Body, token)
+}
+
+// DeleteToken revokes one of the current user's tokens by ID.
+func (s *TokenService) DeleteToken(ctx context.Context, id int) error {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "DELETE", fmt.Sprintf("/tokens/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+	return nil
+}
+
+// AuthenticateToken is not supported by the HTTP client as tokens are
+// authenticated by the server on every request.
+func (s *TokenService) AuthenticateToken(ctx context.Context, secret string) (*todev.Token, error) {
+	return nil, todev.Errorf(todev.ENOTIMPLEMENTED, "Not implemented.")
+}
@@ -0,0 +1,113 @@
+package http_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/saiddis/todev"
+	todevhttp "github.com/saiddis/todev/http"
+)
+
+// Ensure the HTTP client round-trips token operations through the JSON API.
+func TestTokenService(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &todev.User{ID: 1, Name: "user1", APIKey: "apiKey"}
+	ctx0 := todev.NewContextWithUser(context.Background(), user0)
+	s.MustAuthenticateAPIKey(t, user0)
+
+	tokenService := todevhttp.NewTokenService(todevhttp.NewClient(s.URL()))
+
+	t.Run("FindTokens", func(t *testing.T) {
+		s.TokenService.FindTokensFn = func(ctx context.Context, filter todev.TokenFilter) ([]*todev.Token, int, error) {
+			return []*todev.Token{{ID: 1, UserID: 1, Name: "cli", Scopes: []string{todev.ScopeRead}}}, 1, nil
+		}
+
+		if tokens, n, err := tokenService.FindTokens(ctx0, todev.TokenFilter{}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(tokens), 1; got != want {
+			t.Fatalf("len=%d, want %d", got, want)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		} else if got, want := tokens[0].Scopes[0], todev.ScopeRead; got != want {
+			t.Fatalf("Scopes[0]=%q, want %q", got, want)
+		}
+	})
+
+	t.Run("CreateToken", func(t *testing.T) {
+		s.TokenService.CreateTokenFn = func(ctx context.Context, token *todev.Token) error {
+			if got, want := token.Name, "cli"; got != want {
+				t.Fatalf("Name=%q, want %q", got, want)
+			}
+			token.ID, token.UserID, token.Secret = 2, todev.UserIDFromContext(ctx), "todev_secret"
+			return nil
+		}
+
+		token := &todev.Token{Name: "cli", Scopes: []string{todev.ScopeAll}}
+		if err := tokenService.CreateToken(ctx0, token); err != nil {
+			t.Fatal(err)
+		} else if got, want := token.ID, 2; got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		} else if got, want := token.Secret, "todev_secret"; got != want {
+			t.Fatalf("Secret=%q, want %q", got, want)
+		}
+	})
+
+	t.Run("DeleteToken", func(t *testing.T) {
+		s.TokenService.DeleteTokenFn = func(ctx context.Context, id int) error {
+			return todev.Errorf(todev.ENOTFOUND, "Token not found.")
+		}
+
+		if err := tokenService.DeleteToken(ctx0, 3); todev.ErrorCode(err) != todev.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+// Ensure requests made with a token are limited to the routes of its scopes.
+func TestRequireScope(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &todev.User{ID: 1, Name: "user1", APIKey: "todev_tasks"}
+	ctx0 := todev.NewContextWithUser(context.Background(), user0)
+	s.MustAuthenticateToken(t, &todev.Token{
+		UserID: 1,
+		User:   user0,
+		Scopes: []string{todev.ScopeRead, todev.ScopeTasksWrite},
+		Secret: "todev_tasks",
+	})
+
+	s.RepoService.FindReposFn = func(ctx context.Context, filter todev.RepoFilter) ([]*todev.Repo, int, error) {
+		return []*todev.Repo{{ID: 1, UserID: 1, Name: "repo1"}}, 1, nil
+	}
+	s.RepoService.CreateRepoFn = func(ctx context.Context, repo *todev.Repo) error {
+		t.Fatal("unexpected repo creation")
+		return nil
+	}
+	s.TaskService.CreateTaskFn = func(ctx context.Context, task *todev.Task) error {
+		task.ID = 1
+		return nil
+	}
+
+	client := todevhttp.NewClient(s.URL())
+	repoService := todevhttp.NewRepoService(client)
+	taskService := todevhttp.NewTaskService(client)
+
+	// The read scope grants access to every listing.
+	if _, _, err := repoService.FindRepos(ctx0, todev.RepoFilter{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := taskService.CreateTask(ctx0, &todev.Task{RepoID: 1, Description: "task1"}); err != nil {
+		t.Fatal(err)
+	} else if err = repoService.CreateRepo(ctx0, &todev.Repo{Name: "repo2"}); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	// Tokens cannot be used to create other tokens without full access.
+	if err := todevhttp.NewTokenService(client).CreateToken(ctx0, &todev.Token{Name: "cli", Scopes: []string{todev.ScopeAll}}); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+}
@@ -0,0 +1,267 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/http/json"
+)
+
+// registerUserRoutes is a helper function for registering user routes.
+// These routes are only called via JSON API.
+func (s *Server) registerUserRoutes(r *mux.Router) {
+	// Listing of users.
+	r.HandleFunc("/users", s.handleUserIndex).Methods("GET")
+
+	// View a single user.
+	r.HandleFunc("/users/{id}", s.handleUserView).Methods("GET")
+
+	// Update user.
+	r.HandleFunc("/users/{id}", s.handleUserUpdate).Methods("PATCH")
+
+	// Delete user.
+	r.HandleFunc("/users/{id}", s.handleUserDelete).Methods("DELETE")
+}
+
+// handleUserIndex handles the "GET /users" route. Only the current user is
+// returned with their email and auths.
+func (s *Server) handleUserIndex(w http.ResponseWriter, r *http.Request) {
+	r.Header.Set("Accept", "application/json")
+
+	var filter todev.UserFilter
+	if err := json.Decode(r.Body, &filter); err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid JSON body"))
+		return
+	}
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			LogError(r, fmt.Errorf("error closing request body: %v", err))
+		}
+	}()
+
+	users, n, err := s.UserService.FindUsers(r.Context(), filter)
+	if err != nil {
+		Error(w, r, fmt.Errorf("error retrieving users: %w", err))
+		return
+	}
+	for i := range users {
+		users[i] = publicUser(r.Context(), users[i])
+	}
+
+	if err = json.Write(w, http.StatusOK, json.FindUsersResponse{
+		Users: users,
+		N:     n,
+		Next:  nextCursor(users, n, filter.Offset, func(user *todev.User) int { return user.ID }),
+	}); err != nil {
+		LogError(r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
+// handleUserView handles the "GET /users/:id" route. Email and auths are only
+// returned for the current user.
+func (s *Server) handleUserView(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	r.Header.Set("Accept", "application/json")
+
+	if user, err := s.UserService.FindUserByID(r.Context(), id); err != nil {
+		Error(w, r, fmt.Errorf("error retrieving user by ID=%d: %w", id, err))
+		return
+	} else if err = json.Write(w, http.StatusOK, publicUser(r.Context(), user)); err != nil {
+		LogError(r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
+// publicUser returns a copy of user without their email and auths unless it is
+// the current user.
+func publicUser(ctx context.Context, user *todev.User) *todev.User {
+	if user.ID == todev.UserIDFromContext(ctx) {
+		return user
+	}
+	return &todev.User{
+		ID:        user.ID,
+		Name:      user.Name,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
+
+// handleUserUpdate handles the "PATCH /users/:id" route.
+func (s *Server) handleUserUpdate(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	r.Header.Set("Accept", "application/json")
+
+	var upd todev.UserUpdate
+	if err = json.Decode(r.Body, &upd); err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid JSON body"))
+		return
+	}
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			LogError(r, fmt.Errorf("error closing request body: %v", err))
+		}
+	}()
+
+	if user, err := s.UserService.UpdateUser(r.Context(), id, upd); err != nil {
+		Error(w, r, fmt.Errorf("error updating user: %w", err))
+		return
+	} else if err = json.Write(w, http.StatusOK, user); err != nil {
+		LogError(r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
+// handleUserDelete handles the "DELETE /users/:id" route.
+func (s *Server) handleUserDelete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid ID format"))
+		return
+	}
+
+	r.Header.Set("Accept", "application/json")
+
+	if err = s.UserService.DeleteUser(r.Context(), id); err != nil {
+		Error(w, r, fmt.Errorf("error deleting user by ID=%d: %w", id, err))
+		return
+	} else if err = json.Write(w, http.StatusOK, struct{}{}); err != nil {
+		LogError(r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
+// UserService implements the todev.UserService over the HTTP protocol.
+type UserService struct {
+	Client *Client
+}
+
+func NewUserService(client *Client) *UserService {
+	return &UserService{Client: client}
+}
+
+// FindUserByID retrieves a user by ID along with their associated auths.
+func (s *UserService) FindUserByID(ctx context.Context, id int) (*todev.User, error) {
+	req, err := s.Client.newRequest(ctx, "GET", fmt.Sprintf("/users/%d", id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var user todev.User
+	if err = json.Decode(resp.Body, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindUsers retrieves a list of users based on a filter. Also returns a count
+// of total matching users.
+func (s *UserService) FindUsers(ctx context.Context, filter todev.UserFilter) ([]*todev.User, int, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := json.Encode(filter, buf); err != nil {
+		return nil, 0, fmt.Errorf("error creating request: %v", err)
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "GET", "/users", buf)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, 0, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	// Unmarshal result set of users and total user count.
+	var jsonResponse json.FindUsersResponse
+	if err = json.Decode(resp.Body, &jsonResponse); err != nil {
+		return nil, 0, fmt.Errorf("error decoding response: %v", err)
+	}
+	return jsonResponse.Users, jsonResponse.N, nil
+}
+
+// CreateUser is not supported by the HTTP client as users are only created by
+// logging in or registering.
+func (s *UserService) CreateUser(ctx context.Context, user *todev.User) error {
+	return todev.Errorf(todev.ENOTIMPLEMENTED, "Not implemented.")
+}
+
+// UpdateUser updates a user object and returns its new state. Only the user
+// themselves can be updated.
+func (s *UserService) UpdateUser(ctx context.Context, id int, upd todev.UserUpdate) (*todev.User, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := json.Encode(upd, buf); err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "PATCH", fmt.Sprintf("/users/%d", id), buf)
+	if err != nil {
+		return nil, err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		return nil, parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	var user todev.User
+	if err = json.Decode(resp.Body, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DeleteUser permanently removes a user and all owned repos. Only the user
+// themselves can be deleted.
+func (s *UserService) DeleteUser(ctx context.Context, id int) error {
+	// Create request with API key.
+	req, err := s.Client.newRequest(ctx, "DELETE", fmt.Sprintf("/users/%d", id), nil)
+	if err != nil {
+		return err
+	}
+
+	// Issue request. Any non-200 code is considered an error.
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	} else if resp.StatusCode != http.StatusOK {
+		return parseResponseError(resp)
+	}
+	defer resp.Body.Close()
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package http_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/saiddis/todev"
+	todevhttp "github.com/saiddis/todev/http"
+)
+
+// Ensure the HTTP client round-trips user operations through the JSON API.
+func TestUserService(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+
+	user0 := &todev.User{ID: 1, Name: "user1", Email: "user1@gmail.com", APIKey: "apiKey"}
+	ctx0 := todev.NewContextWithUser(context.Background(), user0)
+	s.MustAuthenticateAPIKey(t, user0)
+
+	userService := todevhttp.NewUserService(todevhttp.NewClient(s.URL()))
+
+	t.Run("FindUserByID", func(t *testing.T) {
+		s.UserService.FindUserByIDFn = func(ctx context.Context, id int) (*todev.User, error) {
+			return user0, nil
+		}
+
+		if user, err := userService.FindUserByID(ctx0, 1); err != nil {
+			t.Fatal(err)
+		} else if got, want := user.Email, "user1@gmail.com"; got != want {
+			t.Fatalf("Email=%q, want %q", got, want)
+		} else if user.APIKey != "" {
+			t.Fatal("expected API key to be hidden")
+		}
+	})
+
+	// Ensure other users are returned without their email and auths.
+	t.Run("FindUserByID/OtherUser", func(t *testing.T) {
+		s.UserService.FindUserByIDFn = func(ctx context.Context, id int) (*todev.User, error) {
+			return &todev.User{ID: id, Name: "user2", Email: "user2@gmail.com", Auths: []*todev.Auth{{ID: 1, UserID: id}}}, nil
+		}
+
+		if user, err := userService.FindUserByID(ctx0, 2); err != nil {
+			t.Fatal(err)
+		} else if got, want := user.Name, "user2"; got != want {
+			t.Fatalf("Name=%q, want %q", got, want)
+		} else if user.Email != "" || len(user.Auths) != 0 {
+			t.Fatalf("unexpected private fields: %#v", user)
+		}
+	})
+
+	t.Run("FindUsers", func(t *testing.T) {
+		s.UserService.FindUsersFn = func(ctx context.Context, filter todev.UserFilter) ([]*todev.User, int, error) {
+			return []*todev.User{user0, {ID: 2, Name: "user2", Email: "user2@gmail.com"}}, 2, nil
+		}
+
+		if users, _, err := userService.FindUsers(ctx0, todev.UserFilter{}); err != nil {
+			t.Fatal(err)
+		} else if got, want := users[0].Email, "user1@gmail.com"; got != want {
+			t.Fatalf("Email=%q, want %q", got, want)
+		} else if users[1].Email != "" {
+			t.Fatalf("unexpected email: %q", users[1].Email)
+		}
+	})
+
+	t.Run("CreateUser", func(t *testing.T) {
+		if err := userService.CreateUser(ctx0, &todev.User{Name: "user2"}); todev.ErrorCode(err) != todev.ENOTIMPLEMENTED {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("UpdateUser", func(t *testing.T) {
+		s.UserService.UpdateUserFn = func(ctx context.Context, id int, upd todev.UserUpdate) (*todev.User, error) {
+			return &todev.User{ID: id, Name: *upd.Name}, nil
+		}
+
+		name := "user3"
+		if user, err := userService.UpdateUser(ctx0, 1, todev.UserUpdate{Name: &name}); err != nil {
+			t.Fatal(err)
+		} else if got, want := user.Name, "user3"; got != want {
+			t.Fatalf("Name=%q, want %q", got, want)
+		}
+	})
+
+	t.Run("DeleteUser", func(t *testing.T) {
+		s.UserService.DeleteUserFn = func(ctx context.Context, id int) error {
+			return todev.Errorf(todev.EUNAUTHORIZED, "You are not allowed to delete this user.")
+		}
+
+		if err := userService.DeleteUser(ctx0, 2); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
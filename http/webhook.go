@@ -0,0 +1,180 @@
+package http
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+	"github.com/gorilla/mux"
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/http/json"
+)
+
+// MaxWebhookPayloadSize is the maximum size of a webhook payload, in bytes.
+const MaxWebhookPayloadSize = 5 << 20
+
+// GitHubSignatureHeader is the header carrying the HMAC-SHA256 signature of a
+// GitHub webhook payload.
+const GitHubSignatureHeader = "X-Hub-Signature-256"
+
+// closingRefRegexp matches references closing todev tasks, such as
+// "closes todev#123" or "Fixed todev#7".
+var closingRefRegexp = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?)\s+todev#(\d+)\b`)
+
+// registerWebhookRoutes is a helper function to register webhook routes.
+func (s *Server) registerWebhookRoutes(r *mux.Router) {
+	r.HandleFunc("/webhooks/github", s.handleGitHubWebhook).Methods("POST")
+}
+
+// handleGitHubWebhook handles the "POST /webhooks/github" route. It completes
+// the tasks referenced by merged pull requests and by commits pushed to the
+// default branch. Tasks are completed on behalf of the GitHub user who sent
+// the event, so they must have a todev account with access to the task.
+//
+// Payloads must be sent as JSON and signed with GitHubWebhookSecret.
+func (s *Server) handleGitHubWebhook(w http.ResponseWriter, r *http.Request) {
+	// GitHub does not set the Accept header, so always report errors as JSON.
+	r.Header.Set("Accept", "application/json")
+
+	if s.GitHubWebhookSecret == "" {
+		Error(w, r, todev.Errorf(todev.ENOTFOUND, "GitHub webhooks are not enabled."))
+		return
+	} else if r.Header.Get("Content-type") != "application/json" {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Webhook payload must be JSON."))
+		return
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(r.Body, MaxWebhookPayloadSize))
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid webhook payload."))
+		return
+	} else if !validGitHubSignature(payload, r.Header.Get(GitHubSignatureHeader), s.GitHubWebhookSecret) {
+		Error(w, r, todev.Errorf(todev.EUNAUTHORIZED, "Invalid webhook signature."))
+		return
+	}
+
+	event, err := github.ParseWebHook(github.WebHookType(r), payload)
+	if err != nil {
+		Error(w, r, todev.Errorf(todev.EINVALID, "Invalid webhook payload."))
+		return
+	}
+
+	// Collect the IDs of the referenced tasks. Events of other types, such
+	// as the "ping" sent when the webhook is created, are acknowledged and
+	// ignored.
+	var taskIDs []int
+	var fullName string
+	var senderID int64
+	switch event := event.(type) {
+	case *github.PullRequestEvent:
+		if pr := event.GetPullRequest(); event.GetAction() == "closed" && pr.GetMerged() {
+			taskIDs = parseClosingRefs(pr.GetTitle() + "\n" + pr.GetBody())
+		}
+		fullName, senderID = event.GetRepo().GetFullName(), event.GetSender().GetID()
+	case *github.PushEvent:
+		if event.GetRef() == "refs/heads/"+event.GetRepo().GetDefaultBranch() {
+			for _, commit := range event.Commits {
+				taskIDs = append(taskIDs, parseClosingRefs(commit.GetMessage())...)
+			}
+		}
+		fullName, senderID = event.GetRepo().GetFullName(), event.GetSender().GetID()
+	}
+
+	completedIDs := make([]int, 0, len(taskIDs))
+	if len(taskIDs) != 0 {
+		if completedIDs, err = s.completeGitHubTasks(r.Context(), fullName, senderID, taskIDs); err != nil {
+			Error(w, r, fmt.Errorf("error completing tasks: %w", err))
+			return
+		}
+	}
+
+	if err = json.Write(w, http.StatusOK, json.WebhookResponse{CompletedTaskIDs: completedIDs}); err != nil {
+		LogError(r, fmt.Errorf("error writing response: %v", err))
+		return
+	}
+}
+
+// completeGitHubTasks moves the given tasks to the terminal state of their
+// repo's workflow on behalf of the todev user linked to the GitHub sender.
+// Tasks that cannot be seen or completed by the user, or that belong to a repo
+// not linked to fullName, are skipped. Returns the IDs of completed tasks.
+func (s *Server) completeGitHubTasks(ctx context.Context, fullName string, senderID int64, taskIDs []int) ([]int, error) {
+	completedIDs := make([]int, 0, len(taskIDs))
+
+	source, sourceID := todev.AuthSourceGitHub, strconv.FormatInt(senderID, 10)
+	auths, _, err := s.AuthService.FindAuths(ctx, todev.AuthFilter{Source: &source, SourceID: &sourceID})
+	if err != nil {
+		return nil, err
+	} else if len(auths) == 0 || auths[0].User == nil {
+		return completedIDs, nil
+	}
+	ctx = todev.NewContextWithUser(ctx, auths[0].User)
+
+	for _, id := range taskIDs {
+		task, err := s.TaskService.FindTaskByID(ctx, id)
+		if todev.ErrorCode(err) == todev.ENOTFOUND {
+			continue
+		} else if err != nil {
+			return nil, err
+		} else if task.IsCompleted {
+			continue
+		}
+
+		repo, err := s.RepoService.FindRepoByID(ctx, task.RepoID)
+		if err != nil {
+			return nil, err
+		} else if !strings.EqualFold(repo.GitHubRepo, fullName) {
+			continue
+		}
+
+		state := repo.TerminalState()
+		if state == nil {
+			continue
+		}
+		if _, err = s.TaskService.UpdateTask(ctx, task.ID, todev.TaskUpdate{SetStatus: &state.ID}); todev.ErrorCode(err) == todev.EUNAUTHORIZED {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		completedIDs = append(completedIDs, task.ID)
+	}
+	return completedIDs, nil
+}
+
+// validGitHubSignature returns true if signature is the "sha256=" prefixed
+// HMAC of payload computed with secret.
+func validGitHubSignature(payload []byte, signature, secret string) bool {
+	sum, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil || !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(sum, mac.Sum(nil))
+}
+
+// parseClosingRefs returns the IDs of the tasks closed by text, in order of
+// appearance and without duplicates.
+func parseClosingRefs(text string) []int {
+	var ids []int
+	for _, m := range closingRefRegexp.FindAllStringSubmatch(text, -1) {
+		id, err := strconv.Atoi(m[1])
+		if err != nil || id <= 0 {
+			continue
+		}
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
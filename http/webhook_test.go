@@ -0,0 +1,173 @@
+package http_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/saiddis/todev"
+	todevhttp "github.com/saiddis/todev/http"
+)
+
+// TestWebhookSecret is the secret used to sign webhook payloads in tests.
+const TestWebhookSecret = "secret"
+
+// Ensure GitHub webhooks complete the tasks referenced by merged pull requests
+// and by commits pushed to the default branch.
+func TestGitHubWebhook(t *testing.T) {
+	s := MustOpenServer(t)
+	defer MustCloseServer(t, s)
+	s.GitHubWebhookSecret = TestWebhookSecret
+
+	user0 := &todev.User{ID: 1, Name: "user1"}
+	repo := &todev.Repo{
+		ID:         1,
+		UserID:     1,
+		Name:       "repo1",
+		GitHubRepo: "bob/todo",
+		States: []*todev.TaskState{
+			{ID: 1, RepoID: 1, Name: "To do"},
+			{ID: 2, RepoID: 1, Name: "Done", Position: 1, IsTerminal: true},
+		},
+	}
+
+	s.AuthService.FindAuthsFn = func(ctx context.Context, filter todev.AuthFilter) ([]*todev.Auth, int, error) {
+		if filter.SourceID == nil || *filter.SourceID != "100" {
+			return nil, 0, nil
+		}
+		return []*todev.Auth{{UserID: 1, Source: todev.AuthSourceGitHub, SourceID: "100", User: user0}}, 1, nil
+	}
+	s.TaskService.FindTaskByIDFn = func(ctx context.Context, id int) (*todev.Task, error) {
+		if id != 1 {
+			return nil, todev.Errorf(todev.ENOTFOUND, "Task not found.")
+		}
+		return &todev.Task{ID: 1, RepoID: 1, StateID: 1, Description: "Fix login"}, nil
+	}
+	s.RepoService.FindRepoByIDFn = func(ctx context.Context, id int) (*todev.Repo, error) {
+		return repo, nil
+	}
+
+	t.Run("PullRequest", func(t *testing.T) {
+		var updated bool
+		s.TaskService.UpdateTaskFn = func(ctx context.Context, id int, upd todev.TaskUpdate) (*todev.Task, error) {
+			if todev.UserIDFromContext(ctx) != 1 {
+				t.Fatalf("unexpected user: %d", todev.UserIDFromContext(ctx))
+			} else if id != 1 {
+				t.Fatalf("unexpected id: %d", id)
+			} else if upd.SetStatus == nil || *upd.SetStatus != 2 {
+				t.Fatalf("unexpected status: %#v", upd.SetStatus)
+			}
+			updated = true
+			return &todev.Task{ID: 1, RepoID: 1, StateID: 2, IsCompleted: true}, nil
+		}
+
+		resp := s.MustPostWebhook(t, "pull_request", `{
+			"action": "closed",
+			"pull_request": {"merged": true, "title": "Fix login", "body": "Closes todev#1 and fixes todev#2."},
+			"repository": {"full_name": "bob/todo"},
+			"sender": {"id": 100}
+		}`)
+		if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		} else if body := MustReadAll(t, resp.Body); !strings.Contains(body, `"completedTaskIDs":[1]`) {
+			t.Fatalf("unexpected body: %s", body)
+		} else if !updated {
+			t.Fatal("expected task to be completed")
+		}
+	})
+
+	// Unmerged pull requests, other branches and other repos are ignored.
+	t.Run("Ignored", func(t *testing.T) {
+		s.TaskService.UpdateTaskFn = func(ctx context.Context, id int, upd todev.TaskUpdate) (*todev.Task, error) {
+			t.Fatal("unexpected task update")
+			return nil, nil
+		}
+
+		for _, tt := range []struct{ event, payload string }{
+			{"pull_request", `{"action": "closed", "pull_request": {"merged": false, "body": "closes todev#1"}, "repository": {"full_name": "bob/todo"}, "sender": {"id": 100}}`},
+			{"push", `{"ref": "refs/heads/dev", "commits": [{"message": "closes todev#1"}], "repository": {"full_name": "bob/todo", "default_branch": "main"}, "sender": {"id": 100}}`},
+			{"push", `{"ref": "refs/heads/main", "commits": [{"message": "closes todev#1"}], "repository": {"full_name": "bob/other", "default_branch": "main"}, "sender": {"id": 100}}`},
+			{"ping", `{"zen": "Keep it logically awesome."}`},
+		} {
+			if resp := s.MustPostWebhook(t, tt.event, tt.payload); resp.StatusCode != http.StatusOK {
+				t.Fatalf("StatusCode=%v, want %v", resp.StatusCode, http.StatusOK)
+			}
+		}
+	})
+
+	t.Run("Push", func(t *testing.T) {
+		var updated bool
+		s.TaskService.UpdateTaskFn = func(ctx context.Context, id int, upd todev.TaskUpdate) (*todev.Task, error) {
+			updated = true
+			return &todev.Task{ID: 1, RepoID: 1, StateID: 2, IsCompleted: true}, nil
+		}
+
+		resp := s.MustPostWebhook(t, "push", `{
+			"ref": "refs/heads/main",
+			"commits": [{"message": "Fix login\n\nResolves todev#1"}],
+			"repository": {"full_name": "bob/todo", "default_branch": "main"},
+			"sender": {"id": 100}
+		}`)
+		if got, want := resp.StatusCode, http.StatusOK; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		} else if !updated {
+			t.Fatal("expected task to be completed")
+		}
+	})
+
+	t.Run("ErrInvalidSignature", func(t *testing.T) {
+		req, err := http.NewRequest("POST", s.URL()+"/webhooks/github", strings.NewReader(`{}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-type", "application/json")
+		req.Header.Set("X-GitHub-Event", "ping")
+		req.Header.Set(todevhttp.GitHubSignatureHeader, "sha256=00")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := resp.StatusCode, http.StatusUnauthorized; got != want {
+			t.Fatalf("StatusCode=%v, want %v", got, want)
+		}
+	})
+}
+
+// MustPostWebhook sends a GitHub webhook event signed with TestWebhookSecret.
+func (s *Server) MustPostWebhook(tb testing.TB, event, payload string) *http.Response {
+	tb.Helper()
+
+	mac := hmac.New(sha256.New, []byte(TestWebhookSecret))
+	mac.Write([]byte(payload))
+
+	req, err := http.NewRequest("POST", s.URL()+"/webhooks/github", strings.NewReader(payload))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	req.Header.Set("Content-type", "application/json")
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set(todevhttp.GitHubSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return resp
+}
+
+// MustReadAll reads the whole body and closes it.
+func MustReadAll(tb testing.TB, r io.ReadCloser) string {
+	tb.Helper()
+	defer r.Close()
+
+	buf, err := io.ReadAll(r)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return string(buf)
+}
@@ -2,6 +2,7 @@ package inmem
 
 import (
 	"context"
+	"slices"
 	"sync"
 
 	"github.com/saiddis/todev"
@@ -38,8 +39,12 @@ func (s *EventService) PublishEvent(userID int, event todev.Event) {
 		return
 	}
 
-	// Publish event to all subscriptions for the user.
+	// Publish event to all subscriptions for the user that match it.
 	for sub := range subs {
+		if !sub.filter.Match(event) {
+			continue
+		}
+
 		select {
 		case sub.c <- event:
 		default:
@@ -48,9 +53,10 @@ func (s *EventService) PublishEvent(userID int, event todev.Event) {
 	}
 }
 
-// Subscribe creates a new subscription for the currently logged in user.
-// Returns ENOTAUTHORIZED if the user is not logged in.
-func (s *EventService) Subscribe(ctx context.Context) (todev.Subscription, error) {
+// Subscribe creates a new subscription for the currently logged in user which
+// only receives events matching filter. Returns ENOTAUTHORIZED if the user is
+// not logged in.
+func (s *EventService) Subscribe(ctx context.Context, filter todev.SubscriptionFilter) (todev.Subscription, error) {
 	userID := todev.UserIDFromContext(ctx)
 	if userID == 0 {
 		return nil, todev.Errorf(todev.EUNAUTHORIZED, "Must be logged in to subscribe to events.")
@@ -60,9 +66,13 @@ func (s *EventService) Subscribe(ctx context.Context) (todev.Subscription, error
 	sub := &Subscription{
 		service: s,
 		userID:  userID,
+		filter:  todev.SubscriptionFilter{RepoIDs: slices.Clone(filter.RepoIDs)},
 		c:       make(chan todev.Event, EventBufferSize),
 	}
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	// Add to list of user's subscriptions.
 	// Subscriptions stored as a map for each user, so we can easily delete them.
 	subs, ok := s.m[userID]
@@ -108,6 +118,8 @@ type Subscription struct {
 	service *EventService // service subscription was created from
 	userID  int           // subscribed user
 
+	filter todev.SubscriptionFilter // events delivered to the subscription
+
 	c    chan todev.Event // channel of events
 	once sync.Once        // ensures c is only closed once
 }
@@ -15,17 +15,17 @@ func TestEventService(t *testing.T) {
 		ctx1 := todev.NewContextWithUser(ctx, &todev.User{ID: 2})
 
 		s := inmem.NewEventService()
-		sub0a, err := s.Subscribe(ctx0)
+		sub0a, err := s.Subscribe(ctx0, todev.SubscriptionFilter{})
 		if err != nil {
 			t.Fatal(err)
 		}
 
-		sub0b, err := s.Subscribe(ctx0)
+		sub0b, err := s.Subscribe(ctx0, todev.SubscriptionFilter{})
 		if err != nil {
 			t.Fatal(err)
 		}
 
-		sub1, err := s.Subscribe(ctx1)
+		sub1, err := s.Subscribe(ctx1, todev.SubscriptionFilter{})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -58,7 +58,7 @@ func TestEventService(t *testing.T) {
 		ctx0 := todev.NewContextWithUser(ctx, &todev.User{ID: 1})
 
 		s := inmem.NewEventService()
-		sub, err := s.Subscribe(ctx0)
+		sub, err := s.Subscribe(ctx0, todev.SubscriptionFilter{})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -80,4 +80,34 @@ func TestEventService(t *testing.T) {
 		// Ensure unsubscribing twice is ok.
 		sub.Close()
 	})
+
+	t.Run("RepoScope", func(t *testing.T) {
+		ctx0 := todev.NewContextWithUser(context.Background(), &todev.User{ID: 1})
+
+		s := inmem.NewEventService()
+		sub, err := s.Subscribe(ctx0, todev.SubscriptionFilter{RepoIDs: []int{7}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sub.Close()
+
+		s.PublishEvent(1, todev.Event{Type: "test1", RepoID: 12})
+		s.PublishEvent(1, todev.Event{Type: "test2", RepoID: 7})
+
+		// Ensure only the event of the subscribed repo is received.
+		select {
+		case event := <-sub.C():
+			if got, want := event.RepoID, 7; got != want {
+				t.Fatalf("RepoID=%d, want %d", got, want)
+			}
+		default:
+			t.Fatal("expected event")
+		}
+
+		select {
+		case <-sub.C():
+			t.Fatal("expected no event")
+		default:
+		}
+	})
 }
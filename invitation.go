@@ -0,0 +1,113 @@
+package todev
+
+import (
+	"time"
+)
+
+// Invitation represents a link that lets users join a repo. A repo can have
+// several invitations, each granting its own role and optionally limited in
+// time and in number of uses.
+type Invitation struct {
+	ID     int `json:"id"`
+	RepoID int `json:"repoID"`
+
+	// Associated repo. Only set when the invitation is looked up by code.
+	Repo *Repo `json:"repo,omitempty"`
+
+	// User who created the invitation. Zero if the user has been deleted.
+	UserID int `json:"userID"`
+
+	// Secret part of the invitation URL. Replaced when the invitation is
+	// rotated.
+	Code string `json:"code"`
+
+	// Role given to users joining through the invitation.
+	Role Role `json:"role"`
+
+	// Number of times the invitation can be used. Zero allows any number
+	// of uses.
+	MaxUses int `json:"maxUses"`
+
+	// Number of users that joined through the invitation.
+	Uses int `json:"uses"`
+
+	// Time after which the invitation can no longer be used. Zero never
+	// expires.
+	ExpiresAt time.Time `json:"expiresAt"`
+
+	// Set once the invitation is revoked.
+	RevokedAt time.Time `json:"revokedAt"`
+
+	// Timestamps for invitation creation and last update.
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Validate returns an error if the invitation contains invalid fields.
+func (i Invitation) Validate() error {
+	if i.RepoID == 0 {
+		return Errorf(EINVALID, "Repo required for invitation.")
+	} else if err := i.Role.Validate(); err != nil {
+		return err
+	} else if i.Role == RoleOwner {
+		return Errorf(EINVALID, "Repo ownership cannot be granted by invitation.")
+	} else if i.MaxUses < 0 {
+		return Errorf(EINVALID, "Invitation max uses cannot be negative.")
+	}
+	return nil
+}
+
+// CanJoin returns an error if the invitation cannot be used to join its repo
+// at the given time.
+func (i Invitation) CanJoin(now time.Time) error {
+	if !i.RevokedAt.IsZero() {
+		return Errorf(EUNAUTHORIZED, "Invitation has been revoked.")
+	} else if !i.ExpiresAt.IsZero() && !now.Before(i.ExpiresAt) {
+		return Errorf(EUNAUTHORIZED, "Invitation has expired.")
+	} else if i.MaxUses > 0 && i.Uses >= i.MaxUses {
+		return Errorf(EUNAUTHORIZED, "Invitation has been used up.")
+	}
+	return nil
+}
+
+// CanManageInvitation returns an error if a contributor with the given role
+// cannot create, rotate or revoke inv. Only admins can manage invitations and
+// only for roles they outrank.
+func CanManageInvitation(role Role, inv Invitation) error {
+	if !role.IsAdmin() || !role.Outranks(inv.Role) {
+		return Errorf(EUNAUTHORIZED, "You are not allowed to manage this invitation.")
+	}
+	return nil
+}
+
+// InvitationUse represents a user joining a repo through an invitation.
+type InvitationUse struct {
+	ID           int `json:"id"`
+	InvitationID int `json:"invitationID"`
+
+	// User who joined. Zero if the user has been deleted.
+	UserID int `json:"userID"`
+
+	// Contributor created for the user. Zero if the user has since left.
+	ContributorID int `json:"contributorID"`
+
+	// Timestamp of the join.
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// InvitationFilter represents a filter used by FindInvitations().
+type InvitationFilter struct {
+	ID     *int `json:"id"`
+	RepoID *int `json:"repoID"`
+
+	// Restricts to invitations that have not been revoked.
+	Active bool `json:"active"`
+
+	// Restricts to a subset of results.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+
+	// Resumes listing after the item the cursor points to, as returned
+	// with the previous page. Cannot be combined with Offset.
+	Cursor string `json:"cursor"`
+}
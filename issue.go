@@ -0,0 +1,64 @@
+package todev
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+)
+
+// Issue represents an open issue of a GitHub repository that can be imported
+// into a repo as a task.
+type Issue struct {
+	// Number of the issue within its GitHub repository.
+	Number int `json:"number"`
+
+	Title string `json:"title"`
+
+	// GitHub user IDs of the issue assignees. They match the source IDs of
+	// GitHub auths.
+	AssigneeIDs []string `json:"assigneeIDs"`
+}
+
+// Validate returns an error if the issue cannot be imported.
+func (i Issue) Validate() error {
+	if i.Number <= 0 {
+		return Errorf(EINVALID, "Issue number required.")
+	} else if i.Title == "" {
+		return Errorf(EINVALID, "Issue title required.")
+	}
+	return nil
+}
+
+// TaskDescription returns the issue title shortened to fit a task description.
+func (i Issue) TaskDescription() string {
+	if utf8.RuneCountInString(i.Title) <= MaxTaskDescriptionLen {
+		return i.Title
+	}
+	return string([]rune(i.Title)[:MaxTaskDescriptionLen-3]) + "..."
+}
+
+// IssueImport represents the result of importing issues into a repo.
+type IssueImport struct {
+	// Number of tasks created for new issues.
+	Created int `json:"created"`
+
+	// Number of existing tasks whose description changed since the last import.
+	Updated int `json:"updated"`
+}
+
+// SplitGitHubRepo splits a GitHub repository full name into its owner and
+// name. Returns EINVALID if the full name is not in the "owner/name" form.
+func SplitGitHubRepo(fullName string) (owner, name string, err error) {
+	owner, name, ok := strings.Cut(fullName, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", Errorf(EINVALID, "GitHub repo must be in the owner/name form.")
+	}
+	return owner, name, nil
+}
+
+// IssueService represents a service for reading issues from GitHub.
+type IssueService interface {
+	// Retrieves the open issues of a GitHub repository on behalf of the user
+	// owning the access token. Pull requests are not included.
+	FindOpenIssues(ctx context.Context, accessToken, fullName string) ([]*Issue, error)
+}
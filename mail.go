@@ -0,0 +1,17 @@
+package todev
+
+import "context"
+
+// Mail represents a plain text email.
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer represents a service for sending emails.
+type Mailer interface {
+	// Sends the email. Returns once the mail has been handed over for
+	// delivery.
+	SendMail(ctx context.Context, mail *Mail) error
+}
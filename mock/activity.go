@@ -0,0 +1,17 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/saiddis/todev"
+)
+
+var _ todev.ActivityService = (*ActivityService)(nil)
+
+type ActivityService struct {
+	FindActivitiesFn func(ctx context.Context, filter todev.ActivityFilter) ([]*todev.Activity, int, error)
+}
+
+func (s *ActivityService) FindActivities(ctx context.Context, filter todev.ActivityFilter) ([]*todev.Activity, int, error) {
+	return s.FindActivitiesFn(ctx, filter)
+}
@@ -11,6 +11,7 @@ var _ todev.AuthService = (*AuthService)(nil)
 type AuthService struct {
 	FindAuthByIDFn func(ctx context.Context, id int) (*todev.Auth, error)
 	FindAuthsFn    func(ctx context.Context, filter todev.AuthFilter) ([]*todev.Auth, int, error)
+	AccessTokenFn  func(ctx context.Context, id int) (string, error)
 	CreateAuthFn   func(ctx context.Context, auth *todev.Auth) error
 	DeleteAuthFn   func(ctx context.Context, id int) error
 }
@@ -23,6 +24,10 @@ func (s *AuthService) FindAuths(ctx context.Context, filter todev.AuthFilter) ([
 	return s.FindAuthsFn(ctx, filter)
 }
 
+func (s *AuthService) AccessToken(ctx context.Context, id int) (string, error) {
+	return s.AccessTokenFn(ctx, id)
+}
+
 func (s *AuthService) CreateAuth(ctx context.Context, auth *todev.Auth) error {
 	return s.CreateAuthFn(ctx, auth)
 }
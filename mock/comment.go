@@ -0,0 +1,37 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/saiddis/todev"
+)
+
+var _ todev.CommentService = (*CommentService)(nil)
+
+type CommentService struct {
+	FindCommentByIDFn func(ctx context.Context, id int) (*todev.Comment, error)
+	FindCommentsFn    func(ctx context.Context, filter todev.CommentFilter) ([]*todev.Comment, int, error)
+	CreateCommentFn   func(ctx context.Context, comment *todev.Comment) error
+	UpdateCommentFn   func(ctx context.Context, id int, upd todev.CommentUpdate) (*todev.Comment, error)
+	DeleteCommentFn   func(ctx context.Context, id int) error
+}
+
+func (s *CommentService) FindCommentByID(ctx context.Context, id int) (*todev.Comment, error) {
+	return s.FindCommentByIDFn(ctx, id)
+}
+
+func (s *CommentService) FindComments(ctx context.Context, filter todev.CommentFilter) ([]*todev.Comment, int, error) {
+	return s.FindCommentsFn(ctx, filter)
+}
+
+func (s *CommentService) CreateComment(ctx context.Context, comment *todev.Comment) error {
+	return s.CreateCommentFn(ctx, comment)
+}
+
+func (s *CommentService) UpdateComment(ctx context.Context, id int, upd todev.CommentUpdate) (*todev.Comment, error) {
+	return s.UpdateCommentFn(ctx, id, upd)
+}
+
+func (s *CommentService) DeleteComment(ctx context.Context, id int) error {
+	return s.DeleteCommentFn(ctx, id)
+}
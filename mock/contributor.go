@@ -14,6 +14,14 @@ type ContributorService struct {
 	CreateContributorFn   func(ctx context.Context, repo *todev.Contributor) error
 	UpdateContributorFn   func(ctx context.Context, id int, upd todev.ContributorUpdate) (*todev.Contributor, error)
 	DeleteContributorFn   func(ctx context.Context, id int) error
+
+	JoinRepoFn             func(ctx context.Context, code string) (*todev.Contributor, error)
+	FindInvitationByCodeFn func(ctx context.Context, code string) (*todev.Invitation, error)
+	FindInvitationsFn      func(ctx context.Context, filter todev.InvitationFilter) ([]*todev.Invitation, int, error)
+	CreateInvitationFn     func(ctx context.Context, inv *todev.Invitation) error
+	RotateInvitationFn     func(ctx context.Context, id int) (*todev.Invitation, error)
+	RevokeInvitationFn     func(ctx context.Context, id int) error
+	FindInvitationUsesFn   func(ctx context.Context, invitationID int) ([]*todev.InvitationUse, error)
 }
 
 func (s *ContributorService) FindContributorByID(ctx context.Context, id int) (*todev.Contributor, error) {
@@ -35,3 +43,31 @@ func (s *ContributorService) UpdateContributor(ctx context.Context, id int, upd
 func (s *ContributorService) DeleteContributor(ctx context.Context, id int) error {
 	return s.DeleteContributorFn(ctx, id)
 }
+
+func (s *ContributorService) JoinRepo(ctx context.Context, code string) (*todev.Contributor, error) {
+	return s.JoinRepoFn(ctx, code)
+}
+
+func (s *ContributorService) FindInvitationByCode(ctx context.Context, code string) (*todev.Invitation, error) {
+	return s.FindInvitationByCodeFn(ctx, code)
+}
+
+func (s *ContributorService) FindInvitations(ctx context.Context, filter todev.InvitationFilter) ([]*todev.Invitation, int, error) {
+	return s.FindInvitationsFn(ctx, filter)
+}
+
+func (s *ContributorService) CreateInvitation(ctx context.Context, inv *todev.Invitation) error {
+	return s.CreateInvitationFn(ctx, inv)
+}
+
+func (s *ContributorService) RotateInvitation(ctx context.Context, id int) (*todev.Invitation, error) {
+	return s.RotateInvitationFn(ctx, id)
+}
+
+func (s *ContributorService) RevokeInvitation(ctx context.Context, id int) error {
+	return s.RevokeInvitationFn(ctx, id)
+}
+
+func (s *ContributorService) FindInvitationUses(ctx context.Context, invitationID int) ([]*todev.InvitationUse, error) {
+	return s.FindInvitationUsesFn(ctx, invitationID)
+}
@@ -10,7 +10,7 @@ var _ todev.EventService = (*EventService)(nil)
 
 type EventService struct {
 	PublishEventFn    func(id int, event todev.Event)
-	SubscribeFn       func(ctx context.Context) (todev.Subscription, error)
+	SubscribeFn       func(ctx context.Context, filter todev.SubscriptionFilter) (todev.Subscription, error)
 	GetSubscribtionFn func(id int) (todev.Subscription, bool)
 }
 
@@ -18,14 +18,24 @@ func (s *EventService) PublishEvent(id int, event todev.Event) {
 	s.PublishEventFn(id, event)
 }
 
-func (s *EventService) Subscribe(ctx context.Context) (todev.Subscription, error) {
-	return s.SubscribeFn(ctx)
+func (s *EventService) Subscribe(ctx context.Context, filter todev.SubscriptionFilter) (todev.Subscription, error) {
+	return s.SubscribeFn(ctx, filter)
 }
 
 func (s *EventService) GetSubscription(id int) (todev.Subscription, bool) {
 	return s.GetSubscribtionFn(id)
 }
 
+var _ todev.EventLogService = (*EventLogService)(nil)
+
+type EventLogService struct {
+	FindEventsFn func(ctx context.Context, repoID, since int) ([]todev.Event, error)
+}
+
+func (s *EventLogService) FindEvents(ctx context.Context, repoID, since int) ([]todev.Event, error) {
+	return s.FindEventsFn(ctx, repoID, since)
+}
+
 type Subscription struct {
 	CloseFn func()
 	CFn     func() <-chan todev.Event
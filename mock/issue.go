@@ -0,0 +1,17 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/saiddis/todev"
+)
+
+var _ todev.IssueService = (*IssueService)(nil)
+
+type IssueService struct {
+	FindOpenIssuesFn func(ctx context.Context, accessToken, fullName string) ([]*todev.Issue, error)
+}
+
+func (s *IssueService) FindOpenIssues(ctx context.Context, accessToken, fullName string) ([]*todev.Issue, error) {
+	return s.FindOpenIssuesFn(ctx, accessToken, fullName)
+}
@@ -0,0 +1,17 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/saiddis/todev"
+)
+
+var _ todev.Mailer = (*Mailer)(nil)
+
+type Mailer struct {
+	SendMailFn func(ctx context.Context, mail *todev.Mail) error
+}
+
+func (m *Mailer) SendMail(ctx context.Context, mail *todev.Mail) error {
+	return m.SendMailFn(ctx, mail)
+}
@@ -0,0 +1,42 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/saiddis/todev"
+)
+
+var _ todev.OAuthProvider = (*OAuthProvider)(nil)
+
+type OAuthProvider struct {
+	SourceFn      func() string
+	NameFn        func() string
+	AuthCodeURLFn func(state, redirectURL string) string
+	ExchangeFn    func(ctx context.Context, code, redirectURL string) (*todev.OAuthToken, error)
+	RefreshFn     func(ctx context.Context, refreshToken string) (*todev.OAuthToken, error)
+	UserInfoFn    func(ctx context.Context, accessToken string) (*todev.OAuthUser, error)
+}
+
+func (p *OAuthProvider) Source() string {
+	return p.SourceFn()
+}
+
+func (p *OAuthProvider) Name() string {
+	return p.NameFn()
+}
+
+func (p *OAuthProvider) AuthCodeURL(state, redirectURL string) string {
+	return p.AuthCodeURLFn(state, redirectURL)
+}
+
+func (p *OAuthProvider) Exchange(ctx context.Context, code, redirectURL string) (*todev.OAuthToken, error) {
+	return p.ExchangeFn(ctx, code, redirectURL)
+}
+
+func (p *OAuthProvider) Refresh(ctx context.Context, refreshToken string) (*todev.OAuthToken, error) {
+	return p.RefreshFn(ctx, refreshToken)
+}
+
+func (p *OAuthProvider) UserInfo(ctx context.Context, accessToken string) (*todev.OAuthUser, error) {
+	return p.UserInfoFn(ctx, accessToken)
+}
@@ -0,0 +1,42 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/saiddis/todev"
+)
+
+var _ todev.PasswordService = (*PasswordService)(nil)
+
+type PasswordService struct {
+	RegisterFn            func(ctx context.Context, user *todev.User, password string) (string, error)
+	LoginFn               func(ctx context.Context, email, password string) (*todev.User, error)
+	ChangePasswordFn      func(ctx context.Context, current, password string) error
+	VerifyEmailFn         func(ctx context.Context, token string) error
+	CreatePasswordResetFn func(ctx context.Context, email string) (string, error)
+	ResetPasswordFn       func(ctx context.Context, token, password string) error
+}
+
+func (s *PasswordService) Register(ctx context.Context, user *todev.User, password string) (string, error) {
+	return s.RegisterFn(ctx, user, password)
+}
+
+func (s *PasswordService) Login(ctx context.Context, email, password string) (*todev.User, error) {
+	return s.LoginFn(ctx, email, password)
+}
+
+func (s *PasswordService) ChangePassword(ctx context.Context, current, password string) error {
+	return s.ChangePasswordFn(ctx, current, password)
+}
+
+func (s *PasswordService) VerifyEmail(ctx context.Context, token string) error {
+	return s.VerifyEmailFn(ctx, token)
+}
+
+func (s *PasswordService) CreatePasswordReset(ctx context.Context, email string) (string, error) {
+	return s.CreatePasswordResetFn(ctx, email)
+}
+
+func (s *PasswordService) ResetPassword(ctx context.Context, token, password string) error {
+	return s.ResetPasswordFn(ctx, token, password)
+}
@@ -2,6 +2,7 @@ package mock
 
 import (
 	"context"
+	"time"
 
 	"github.com/saiddis/todev"
 )
@@ -14,6 +15,10 @@ type RepoService struct {
 	CreateRepoFn   func(ctx context.Context, repo *todev.Repo) error
 	UpdateRepoFn   func(ctx context.Context, id int, upd todev.RepoUpdate) (*todev.Repo, error)
 	DeleteRepoFn   func(ctx context.Context, id int) error
+	RestoreRepoFn  func(ctx context.Context, id int) (*todev.Repo, error)
+
+	TasksLeftReportFn func(ctx context.Context, repoID int, start, end time.Time, interval time.Duration) (*todev.RepoTasksReport, error)
+	ImportIssuesFn    func(ctx context.Context, repoID int, issues []*todev.Issue) (*todev.IssueImport, error)
 }
 
 func (s *RepoService) FindRepoByID(ctx context.Context, id int) (*todev.Repo, error) {
@@ -35,3 +40,15 @@ func (s *RepoService) UpdateRepo(ctx context.Context, id int, upd todev.RepoUpda
 func (s *RepoService) DeleteRepo(ctx context.Context, id int) error {
 	return s.DeleteRepoFn(ctx, id)
 }
+
+func (s *RepoService) RestoreRepo(ctx context.Context, id int) (*todev.Repo, error) {
+	return s.RestoreRepoFn(ctx, id)
+}
+
+func (s *RepoService) TasksLeftReport(ctx context.Context, repoID int, start, end time.Time, interval time.Duration) (*todev.RepoTasksReport, error) {
+	return s.TasksLeftReportFn(ctx, repoID, start, end, interval)
+}
+
+func (s *RepoService) ImportIssues(ctx context.Context, repoID int, issues []*todev.Issue) (*todev.IssueImport, error) {
+	return s.ImportIssuesFn(ctx, repoID, issues)
+}
@@ -0,0 +1,37 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/saiddis/todev"
+)
+
+var _ todev.SessionService = (*SessionService)(nil)
+
+type SessionService struct {
+	FindSessionsFn        func(ctx context.Context, filter todev.SessionFilter) ([]*todev.Session, int, error)
+	CreateSessionFn       func(ctx context.Context, session *todev.Session) error
+	AuthenticateSessionFn func(ctx context.Context, secret, ip string) (*todev.Session, error)
+	DeleteSessionFn       func(ctx context.Context, id int) error
+	DeleteSessionsFn      func(ctx context.Context) error
+}
+
+func (s *SessionService) FindSessions(ctx context.Context, filter todev.SessionFilter) ([]*todev.Session, int, error) {
+	return s.FindSessionsFn(ctx, filter)
+}
+
+func (s *SessionService) CreateSession(ctx context.Context, session *todev.Session) error {
+	return s.CreateSessionFn(ctx, session)
+}
+
+func (s *SessionService) AuthenticateSession(ctx context.Context, secret, ip string) (*todev.Session, error) {
+	return s.AuthenticateSessionFn(ctx, secret, ip)
+}
+
+func (s *SessionService) DeleteSession(ctx context.Context, id int) error {
+	return s.DeleteSessionFn(ctx, id)
+}
+
+func (s *SessionService) DeleteSessions(ctx context.Context) error {
+	return s.DeleteSessionsFn(ctx)
+}
@@ -14,8 +14,14 @@ type TaskService struct {
 	CreateTaskFn          func(ctx context.Context, task *todev.Task) error
 	UpdateTaskFn          func(ctx context.Context, id int, upd todev.TaskUpdate) (*todev.Task, error)
 	DeleteTaskFn          func(ctx context.Context, id int) error
+	RestoreTaskFn         func(ctx context.Context, id int) (*todev.Task, error)
 	AttachContributorFn   func(ctx context.Context, task *todev.Task, contributorID int) error
 	UnattachContributorFn func(ctx context.Context, task *todev.Task, contributorID int) error
+	CreateChecklistItemFn func(ctx context.Context, item *todev.ChecklistItem) error
+	UpdateChecklistItemFn func(ctx context.Context, id int, upd todev.ChecklistItemUpdate) (*todev.ChecklistItem, error)
+	DeleteChecklistItemFn func(ctx context.Context, id int) error
+	AddDependencyFn       func(ctx context.Context, taskID, blockerID int) error
+	RemoveDependencyFn    func(ctx context.Context, taskID, blockerID int) error
 }
 
 func (s *TaskService) FindTaskByID(ctx context.Context, id int) (*todev.Task, error) {
@@ -38,6 +44,10 @@ func (s *TaskService) DeleteTask(ctx context.Context, id int) error {
 	return s.DeleteTaskFn(ctx, id)
 }
 
+func (s *TaskService) RestoreTask(ctx context.Context, id int) (*todev.Task, error) {
+	return s.RestoreTaskFn(ctx, id)
+}
+
 func (s TaskService) AttachContributor(ctx context.Context, task *todev.Task, contributorID int) error {
 	return s.AttachContributorFn(ctx, task, contributorID)
 }
@@ -45,3 +55,23 @@ func (s TaskService) AttachContributor(ctx context.Context, task *todev.Task, co
 func (s TaskService) UnattachContributor(ctx context.Context, task *todev.Task, contributorID int) error {
 	return s.UnattachContributorFn(ctx, task, contributorID)
 }
+
+func (s *TaskService) CreateChecklistItem(ctx context.Context, item *todev.ChecklistItem) error {
+	return s.CreateChecklistItemFn(ctx, item)
+}
+
+func (s *TaskService) UpdateChecklistItem(ctx context.Context, id int, upd todev.ChecklistItemUpdate) (*todev.ChecklistItem, error) {
+	return s.UpdateChecklistItemFn(ctx, id, upd)
+}
+
+func (s *TaskService) DeleteChecklistItem(ctx context.Context, id int) error {
+	return s.DeleteChecklistItemFn(ctx, id)
+}
+
+func (s *TaskService) AddDependency(ctx context.Context, taskID, blockerID int) error {
+	return s.AddDependencyFn(ctx, taskID, blockerID)
+}
+
+func (s *TaskService) RemoveDependency(ctx context.Context, taskID, blockerID int) error {
+	return s.RemoveDependencyFn(ctx, taskID, blockerID)
+}
@@ -0,0 +1,32 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/saiddis/todev"
+)
+
+var _ todev.TokenService = (*TokenService)(nil)
+
+type TokenService struct {
+	FindTokensFn        func(ctx context.Context, filter todev.TokenFilter) ([]*todev.Token, int, error)
+	CreateTokenFn       func(ctx context.Context, token *todev.Token) error
+	DeleteTokenFn       func(ctx context.Context, id int) error
+	AuthenticateTokenFn func(ctx context.Context, secret string) (*todev.Token, error)
+}
+
+func (s *TokenService) FindTokens(ctx context.Context, filter todev.TokenFilter) ([]*todev.Token, int, error) {
+	return s.FindTokensFn(ctx, filter)
+}
+
+func (s *TokenService) CreateToken(ctx context.Context, token *todev.Token) error {
+	return s.CreateTokenFn(ctx, token)
+}
+
+func (s *TokenService) DeleteToken(ctx context.Context, id int) error {
+	return s.DeleteTokenFn(ctx, id)
+}
+
+func (s *TokenService) AuthenticateToken(ctx context.Context, secret string) (*todev.Token, error) {
+	return s.AuthenticateTokenFn(ctx, secret)
+}
@@ -0,0 +1,50 @@
+package todev
+
+import (
+	"context"
+	"time"
+)
+
+// OAuthToken represents the tokens issued by an OAuth provider.
+type OAuthToken struct {
+	AccessToken  string
+	RefreshToken string
+
+	// Time the access token expires. Zero if it never expires.
+	Expiry time.Time
+}
+
+// OAuthUser represents the profile of a user as returned by an OAuth provider.
+type OAuthUser struct {
+	// The provider's ID for the user. Stored as the auth's source ID.
+	ID string
+
+	Name  string
+	Email string
+
+	// URL of the user's avatar image, if any.
+	AvatarURL string
+}
+
+// OAuthProvider represents an external identity provider users can log in with.
+type OAuthProvider interface {
+	// Returns the auth source of the provider (e.g. "github"). Must be unique
+	// among the configured providers.
+	Source() string
+
+	// Returns the human readable name of the provider shown on the login page.
+	Name() string
+
+	// Returns the URL users are redirected to in order to authorize the
+	// application. The provider sends users back to redirectURL once done.
+	AuthCodeURL(state, redirectURL string) string
+
+	// Exchanges an authorization code for OAuth tokens.
+	Exchange(ctx context.Context, code, redirectURL string) (*OAuthToken, error)
+
+	// Exchanges a refresh token for new OAuth tokens.
+	Refresh(ctx context.Context, refreshToken string) (*OAuthToken, error)
+
+	// Retrieves the profile of the user the access token belongs to.
+	UserInfo(ctx context.Context, accessToken string) (*OAuthUser, error)
+}
@@ -0,0 +1,216 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/saiddis/todev"
+	"golang.org/x/oauth2"
+)
+
+// Ensure provider implements interface.
+var _ todev.OAuthProvider = (*OAuthProvider)(nil)
+
+// DiscoveryPath is the path, relative to the issuer, of the OpenID Connect
+// discovery document.
+const DiscoveryPath = "/.well-known/openid-configuration"
+
+// DefaultScopes are the scopes requested if none are configured.
+var DefaultScopes = []string{"openid", "profile", "email"}
+
+// OAuthProvider implements todev.OAuthProvider for any OpenID Connect
+// identity provider. Endpoints are read from the issuer's discovery
+// document so Open() must be called before the provider is used.
+//
+// The user is identified through the userinfo endpoint using the access
+// token obtained directly from the token endpoint, so ID tokens are not
+// inspected.
+type OAuthProvider struct {
+	// Name shown on the login page. Defaults to "OpenID Connect".
+	DisplayName string
+
+	// Issuer identifier of the provider (e.g. "https://id.example.com").
+	Issuer string
+
+	ClientID     string
+	ClientSecret string
+
+	// Scopes requested on login. Uses DefaultScopes if empty.
+	Scopes []string
+
+	// Endpoints read from the discovery document.
+	authURL     string
+	tokenURL    string
+	userInfoURL string
+}
+
+// NewOAuthProvider returns a new instance of OAuthProvider.
+func NewOAuthProvider(issuer, clientID, clientSecret string) *OAuthProvider {
+	return &OAuthProvider{Issuer: issuer, ClientID: clientID, ClientSecret: clientSecret}
+}
+
+// Open fetches the discovery document of the issuer.
+func (p *OAuthProvider) Open(ctx context.Context) error {
+	if p.Issuer == "" {
+		return fmt.Errorf("oidc issuer required")
+	}
+	issuer := strings.TrimSuffix(p.Issuer, "/")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", issuer+DiscoveryPath, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error retrieving discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error retrieving discovery document: unexpected status %d", resp.StatusCode)
+	}
+
+	var doc struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return fmt.Errorf("error decoding discovery document: %w", err)
+	}
+
+	// The spec requires the issuer to match exactly the one it was
+	// discovered from.
+	if strings.TrimSuffix(doc.Issuer, "/") != issuer {
+		return fmt.Errorf("oidc issuer mismatch: %q", doc.Issuer)
+	} else if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.UserInfoEndpoint == "" {
+		return fmt.Errorf("oidc discovery document missing endpoints")
+	}
+
+	p.authURL = doc.AuthorizationEndpoint
+	p.tokenURL = doc.TokenEndpoint
+	p.userInfoURL = doc.UserInfoEndpoint
+	return nil
+}
+
+// Source returns the OIDC auth source.
+func (p *OAuthProvider) Source() string {
+	return todev.AuthSourceOIDC
+}
+
+// Name returns the name shown on the login page.
+func (p *OAuthProvider) Name() string {
+	if p.DisplayName == "" {
+		return "OpenID Connect"
+	}
+	return p.DisplayName
+}
+
+// AuthCodeURL returns the URL of the provider's authorization page.
+func (p *OAuthProvider) AuthCodeURL(state, redirectURL string) string {
+	return p.config(redirectURL).AuthCodeURL(state)
+}
+
+// Exchange exchanges an authorization code for OAuth tokens.
+func (p *OAuthProvider) Exchange(ctx context.Context, code, redirectURL string) (*todev.OAuthToken, error) {
+	tok, err := p.config(redirectURL).Exchange(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("oauth exchange error: %w", err)
+	}
+	return &todev.OAuthToken{
+		AccessToken:  tok.AccessToken,
+		RefreshToken: tok.RefreshToken,
+		Expiry:       tok.Expiry,
+	}, nil
+}
+
+// Refresh exchanges a refresh token for new OAuth tokens.
+func (p *OAuthProvider) Refresh(ctx context.Context, refreshToken string) (*todev.OAuthToken, error) {
+	tok, err := p.config("").TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		return nil, fmt.Errorf("oauth refresh error: %w", err)
+	}
+	return &todev.OAuthToken{
+		AccessToken:  tok.AccessToken,
+		RefreshToken: tok.RefreshToken,
+		Expiry:       tok.Expiry,
+	}, nil
+}
+
+// UserInfo retrieves the claims of the user the access token belongs to from
+// the userinfo endpoint.
+func (p *OAuthProvider) UserInfo(ctx context.Context, accessToken string) (*todev.OAuthUser, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.userInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving user info: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error retrieving user info: unexpected status %d", resp.StatusCode)
+	}
+
+	var claims struct {
+		Subject           string `json:"sub"`
+		Name              string `json:"name"`
+		PreferredUsername string `json:"preferred_username"`
+		Email             string `json:"email"`
+		EmailVerified     bool   `json:"email_verified"`
+		Picture           string `json:"picture"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&claims); err != nil {
+		return nil, fmt.Errorf("error decoding user info: %w", err)
+	} else if claims.Subject == "" {
+		return nil, fmt.Errorf("subject not returned by provider, cannot authenticate user")
+	}
+
+	name := claims.Name
+	if name == "" {
+		name = claims.PreferredUsername
+	}
+
+	// Emails are used to link auths to existing users so only trust the
+	// ones the provider reports as verified. Providers omitting the claim
+	// may let users set any email.
+	email := claims.Email
+	if !claims.EmailVerified {
+		email = ""
+	}
+
+	return &todev.OAuthUser{
+		ID:        claims.Subject,
+		Name:      name,
+		Email:     email,
+		AvatarURL: claims.Picture,
+	}, nil
+}
+
+// config returns the OAuth2 configuration for the discovered endpoints.
+func (p *OAuthProvider) config(redirectURL string) *oauth2.Config {
+	scopes := p.Scopes
+	if len(scopes) == 0 {
+		scopes = DefaultScopes
+	}
+
+	return &oauth2.Config{
+		ClientID:     p.ClientID,
+		ClientSecret: p.ClientSecret,
+		RedirectURL:  redirectURL,
+		Scopes:       scopes,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  p.authURL,
+			TokenURL: p.tokenURL,
+		},
+	}
+}
@@ -0,0 +1,132 @@
+package oidc_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/oidc"
+)
+
+func TestOAuthProvider(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		ts := MustOpenIdP(t, `{"sub": "abc-123", "preferred_username": "bob", "email": "bob@gmail.com", "email_verified": true, "picture": "https://example.com/bob.png"}`)
+		defer ts.Close()
+
+		p := oidc.NewOAuthProvider(ts.URL, "CLIENTID", "SECRET")
+		p.DisplayName = "Acme SSO"
+		if err := p.Open(context.Background()); err != nil {
+			t.Fatal(err)
+		} else if got, want := p.Name(), "Acme SSO"; got != want {
+			t.Fatalf("Name=%q, want %q", got, want)
+		}
+
+		if loc, err := url.Parse(p.AuthCodeURL("STATE", "http://localhost/oauth/oidc/callback")); err != nil {
+			t.Fatal(err)
+		} else if got, want := loc.Path, "/authorize"; got != want {
+			t.Fatalf("Path=%s, want %s", got, want)
+		} else if got, want := loc.Query().Get("scope"), "openid profile email"; got != want {
+			t.Fatalf("scope=%s, want %s", got, want)
+		}
+
+		ctx := context.Background()
+		tok, err := p.Exchange(ctx, "CODE", "http://localhost/oauth/oidc/callback")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if u, err := p.UserInfo(ctx, tok.AccessToken); err != nil {
+			t.Fatal(err)
+		} else if want := (&todev.OAuthUser{
+			ID:        "abc-123",
+			Name:      "bob",
+			Email:     "bob@gmail.com",
+			AvatarURL: "https://example.com/bob.png",
+		}); !reflect.DeepEqual(u, want) {
+			t.Fatalf("unexpected user: %#v", u)
+		}
+	})
+
+	// Ensure unverified emails are not used to link users.
+	t.Run("UnverifiedEmail", func(t *testing.T) {
+		ts := MustOpenIdP(t, `{"sub": "abc-123", "name": "Bob", "email": "bob@gmail.com", "email_verified": false}`)
+		defer ts.Close()
+
+		p := oidc.NewOAuthProvider(ts.URL, "CLIENTID", "SECRET")
+		if err := p.Open(context.Background()); err != nil {
+			t.Fatal(err)
+		} else if u, err := p.UserInfo(context.Background(), "TOKEN"); err != nil {
+			t.Fatal(err)
+		} else if u.Email != "" {
+			t.Fatalf("unexpected email: %q", u.Email)
+		}
+	})
+
+	// Ensure emails are not trusted if the provider does not report them as
+	// verified.
+	t.Run("MissingEmailVerified", func(t *testing.T) {
+		ts := MustOpenIdP(t, `{"sub": "abc-123", "name": "Bob", "email": "bob@gmail.com"}`)
+		defer ts.Close()
+
+		p := oidc.NewOAuthProvider(ts.URL, "CLIENTID", "SECRET")
+		if err := p.Open(context.Background()); err != nil {
+			t.Fatal(err)
+		} else if u, err := p.UserInfo(context.Background(), "TOKEN"); err != nil {
+			t.Fatal(err)
+		} else if u.Email != "" {
+			t.Fatalf("unexpected email: %q", u.Email)
+		}
+	})
+
+	t.Run("ErrIssuerMismatch", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"issuer": "https://evil.example.com", "authorization_endpoint": "a", "token_endpoint": "b", "userinfo_endpoint": "c"}`)
+		}))
+		defer ts.Close()
+
+		if err := oidc.NewOAuthProvider(ts.URL, "CLIENTID", "SECRET").Open(context.Background()); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+// MustOpenIdP starts a stand-in OpenID Connect provider whose userinfo
+// endpoint returns claims for the access token "TOKEN".
+func MustOpenIdP(tb testing.TB, claims string) *httptest.Server {
+	tb.Helper()
+
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+
+	mux.HandleFunc(oidc.DiscoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{
+			"issuer": %[1]q,
+			"authorization_endpoint": "%[1]s/authorize",
+			"token_endpoint": "%[1]s/token",
+			"userinfo_endpoint": "%[1]s/userinfo"
+		}`, ts.URL)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.FormValue("code"), "CODE"; got != want {
+			tb.Errorf("code=%q, want %q", got, want)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"access_token": "TOKEN", "token_type": "Bearer", "id_token": "x.y.z"}`)
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("Authorization"), "Bearer TOKEN"; got != want {
+			tb.Errorf("Authorization=%q, want %q", got, want)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, claims)
+	})
+
+	return ts
+}
@@ -0,0 +1,69 @@
+package todev
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Password length limits. Passwords are hashed with bcrypt which ignores
+// anything past 72 bytes.
+const (
+	MinPasswordLength = 8
+	MaxPasswordLength = 72
+)
+
+// Lifetimes of the tokens sent by email for local accounts.
+const (
+	EmailVerificationTTL = 48 * time.Hour
+	PasswordResetTTL     = time.Hour
+)
+
+// ValidatePassword returns an error if password doesn't meet the length limits.
+func ValidatePassword(password string) error {
+	if utf8.RuneCountInString(password) < MinPasswordLength {
+		return Errorf(EINVALID, "Password must be at least %d characters long.", MinPasswordLength)
+	} else if len(password) > MaxPasswordLength {
+		return Errorf(EINVALID, "Password must be at most %d bytes long.", MaxPasswordLength)
+	}
+	return nil
+}
+
+// NormalizeEmail returns email in the form used to look up local accounts.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// PasswordService represents a service for managing local accounts, which let
+// users log in with their email and a password instead of an OAuth provider.
+//
+// Tokens returned by the service are secrets meant to be sent to the user's
+// email. Only their hashes are stored.
+type PasswordService interface {
+	// Creates a new user that logs in with the given password. Returns
+	// ECONFLICT if the email is already registered. Also returns the token
+	// verifying the user's email.
+	Register(ctx context.Context, user *User, password string) (string, error)
+
+	// Looks up the user with the given email and password. Returns
+	// EUNAUTHORIZED if they don't match a local account.
+	Login(ctx context.Context, email, password string) (*User, error)
+
+	// Replaces the password of the current user and logs them out of their
+	// other sessions. Returns EUNAUTHORIZED if the current password is wrong.
+	ChangePassword(ctx context.Context, current, password string) error
+
+	// Marks the email of a local account as verified. Returns EUNAUTHORIZED
+	// if the token is invalid or expired.
+	VerifyEmail(ctx context.Context, token string) error
+
+	// Returns a token to reset the password of the local account with the
+	// given email. Returns ENOTFOUND if no local account uses the email.
+	CreatePasswordReset(ctx context.Context, email string) (string, error)
+
+	// Replaces the password of the account the reset token was created for
+	// and logs it out everywhere. Returns EUNAUTHORIZED if the token is
+	// invalid or expired.
+	ResetPassword(ctx context.Context, token, password string) error
+}
@@ -0,0 +1,274 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/saiddis/todev"
+)
+
+var _ todev.ActivityService = (*ActivityService)(nil)
+
+// ActivityService represents a service for reading the activity log of repos.
+type ActivityService struct {
+	conn *Conn
+}
+
+// NewActivityService returns a new instance of ActivityService.
+func NewActivityService(conn *Conn) *ActivityService {
+	return &ActivityService{conn: conn}
+}
+
+// FindActivities retrieves a list of matching activities based on filter,
+// newest first. Only returns activities of repos the current user is a member
+// of. Returns ENOTFOUND if filtering by a repo the user cannot view.
+func (s *ActivityService) FindActivities(ctx context.Context, filter todev.ActivityFilter) ([]*todev.Activity, int, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("FindActivities: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	if filter.RepoID != nil {
+		if _, err = findRepoByID(ctx, tx, *filter.RepoID); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	activities, n, err := findActivities(ctx, tx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return activities, n, nil
+}
+
+// createActivity records a change made by the current user in the activity
+// log of a repo.
+func createActivity(ctx context.Context, tx *Tx, activity *todev.Activity) error {
+	activity.UserID = todev.UserIDFromContext(ctx)
+	activity.CreatedAt = tx.now
+	if activity.Before == nil {
+		activity.Before = map[string]interface{}{}
+	}
+	if activity.After == nil {
+		activity.After = map[string]interface{}{}
+	}
+
+	before, err := json.Marshal(activity.Before)
+	if err != nil {
+		return fmt.Errorf("error encoding activity: %w", err)
+	}
+	after, err := json.Marshal(activity.After)
+	if err != nil {
+		return fmt.Errorf("error encoding activity: %w", err)
+	}
+
+	if err = tx.QueryRowContext(ctx, `
+		INSERT INTO activities (repo_id, user_id, entity_type, entity_id, action, before, after, created_at)
+		VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6, $7, $8)
+		RETURNING id;`,
+		activity.RepoID,
+		activity.UserID,
+		activity.EntityType,
+		activity.EntityID,
+		activity.Action,
+		string(before),
+		string(after),
+		(*NullTime)(&activity.CreatedAt),
+	).Scan(&activity.ID); err != nil {
+		return fmt.Errorf("error inserting activity: %w", err)
+	}
+
+	return nil
+}
+
+// createUpdateActivity records an update of an entity with only the fields
+// that differ between before and after. Nothing is recorded if no field
+// changed.
+func createUpdateActivity(ctx context.Context, tx *Tx, repoID int, entityType string, entityID int, before, after map[string]interface{}) error {
+	for k, v := range before {
+		if reflect.DeepEqual(v, after[k]) {
+			delete(before, k)
+			delete(after, k)
+		}
+	}
+	if len(before) == 0 && len(after) == 0 {
+		return nil
+	}
+
+	return createActivity(ctx, tx, &todev.Activity{
+		RepoID:     repoID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     todev.ActivityActionUpdate,
+		Before:     before,
+		After:      after,
+	})
+}
+
+func findActivities(ctx context.Context, tx *Tx, filter todev.ActivityFilter) ([]*todev.Activity, int, error) {
+	where, args := []string{"1 = 1"}, []interface{}{}
+	var argIndex int
+	if v := filter.RepoID; v != nil {
+		argIndex++
+		where, args = append(where, fmt.Sprintf("a.repo_id = $%d", argIndex)), append(args, *v)
+	}
+	if v := filter.UserID; v != nil {
+		argIndex++
+		where, args = append(where, fmt.Sprintf("a.user_id = $%d", argIndex)), append(args, *v)
+	}
+	if v := filter.EntityType; v != nil {
+		argIndex++
+		where, args = append(where, fmt.Sprintf("a.entity_type = $%d", argIndex)), append(args, *v)
+	}
+	if v := filter.EntityID; v != nil {
+		argIndex++
+		where, args = append(where, fmt.Sprintf("a.entity_id = $%d", argIndex)), append(args, *v)
+	}
+	if v := filter.Action; v != nil {
+		argIndex++
+		where, args = append(where, fmt.Sprintf("a.action = $%d", argIndex)), append(args, *v)
+	}
+
+	// Limit to repos the user is a member of.
+	argIndex++
+	where = append(where, fmt.Sprintf(`(
+		a.repo_id IN (SELECT repo_id FROM contributors WHERE user_id = $%d)
+		)`, argIndex),
+	)
+	args = append(args, todev.UserIDFromContext(ctx))
+
+	keys := []sortKey{{expr: "a.id", desc: true}}
+	cursorID, err := findCursorID(ctx, tx, "activities", filter.Cursor, filter.Offset)
+	if err != nil {
+		return nil, 0, err
+	} else if cursorID != 0 {
+		argIndex++
+		where, args = append(where, formatKeyset(keys, "activities a", argIndex)), append(args, cursorID)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			a.id,
+			a.repo_id,
+			COALESCE(a.user_id, 0),
+			a.entity_type,
+			a.entity_id,
+			a.action,
+			a.before,
+			a.after,
+			a.created_at,
+			COUNT(*) OVER()
+		FROM activities a
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY `+formatOrderBy(keys)+`
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error retrieving activities: %w", err)
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("error closing rows: %v", err)
+		}
+	}()
+
+	activities := make([]*todev.Activity, 0)
+
+	var n int
+	for rows.Next() {
+		var activity todev.Activity
+		var before, after []byte
+		if err = rows.Scan(
+			&activity.ID,
+			&activity.RepoID,
+			&activity.UserID,
+			&activity.EntityType,
+			&activity.EntityID,
+			&activity.Action,
+			&before,
+			&after,
+			(*NullTime)(&activity.CreatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, fmt.Errorf("error scanning: %w", err)
+		}
+
+		if err = json.Unmarshal(before, &activity.Before); err != nil {
+			return nil, 0, fmt.Errorf("error decoding activity: %w", err)
+		} else if err = json.Unmarshal(after, &activity.After); err != nil {
+			return nil, 0, fmt.Errorf("error decoding activity: %w", err)
+		}
+		activities = append(activities, &activity)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return activities, n, nil
+}
+
+// taskActivityFields returns the audited fields of a task.
+func taskActivityFields(task *todev.Task) map[string]interface{} {
+	labels, contributorIDs := task.Labels, task.ContributorIDs
+	if labels == nil {
+		labels = []string{}
+	}
+	if contributorIDs == nil {
+		contributorIDs = []int{}
+	}
+
+	return map[string]interface{}{
+		"description":    task.Description,
+		"stateID":        task.StateID,
+		"isCompleted":    task.IsCompleted,
+		"dueAt":          formatActivityTime(task.DueAt),
+		"priority":       task.Priority,
+		"labels":         labels,
+		"contributorIDs": contributorIDs,
+	}
+}
+
+// contributorActivityFields returns the audited fields of a contributor.
+func contributorActivityFields(contributor *todev.Contributor) map[string]interface{} {
+	return map[string]interface{}{
+		"userID": contributor.UserID,
+		"role":   contributor.Role,
+	}
+}
+
+// repoActivityFields returns the audited fields of a repo.
+func repoActivityFields(repo *todev.Repo) map[string]interface{} {
+	return map[string]interface{}{
+		"name":       repo.Name,
+		"githubRepo": repo.GitHubRepo,
+	}
+}
+
+// formatActivityTime formats t so that equal instants compare equal. Returns
+// an empty string for the zero time.
+func formatActivityTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
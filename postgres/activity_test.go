@@ -0,0 +1,84 @@
+package postgres_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/postgres"
+)
+
+func TestActivityService_FindActivities(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		WithSchema(t, findActivities_OK)
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		WithSchema(t, findActivities_ErrNotFound)
+	})
+}
+
+func findActivities_OK(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewActivityService(conn)
+	ts := postgres.NewTaskService(conn)
+
+	ctx := context.Background()
+	user0, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	task0 := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "task0", RepoID: repo0.ID})
+
+	description := "task0 renamed"
+	if _, err := ts.UpdateTask(ctx0, task0.ID, todev.TaskUpdate{Description: &description}); err != nil {
+		t.Fatal(err)
+	} else if err = ts.DeleteTask(ctx0, task0.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Activities are returned newest first and updates only record changes.
+	entityType := todev.ActivityEntityTask
+	activities, n, err := s.FindActivities(ctx0, todev.ActivityFilter{RepoID: &repo0.ID, EntityType: &entityType})
+	if err != nil {
+		t.Fatal(err)
+	} else if got, want := n, 3; got != want {
+		t.Fatalf("n=%d, want %d", got, want)
+	}
+	for i, action := range []string{todev.ActivityActionDelete, todev.ActivityActionUpdate, todev.ActivityActionCreate} {
+		if got := activities[i]; got.Action != action || got.EntityID != task0.ID || got.UserID != user0.ID {
+			t.Fatalf("unexpected activity: %#v", got)
+		}
+	}
+	if got, want := activities[1].Before, map[string]interface{}{"description": "task0"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Before=%v, want %v", got, want)
+	} else if got, want := activities[1].After, map[string]interface{}{"description": "task0 renamed"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("After=%v, want %v", got, want)
+	} else if got, want := activities[0].Before["description"], "task0 renamed"; got != want {
+		t.Fatalf("Before[description]=%v, want %v", got, want)
+	}
+
+	// Pages resume from the cursor of the last activity.
+	if activities, _, err = s.FindActivities(ctx0, todev.ActivityFilter{
+		RepoID:     &repo0.ID,
+		EntityType: &entityType,
+		Cursor:     todev.EncodeCursor(activities[1].ID),
+	}); err != nil {
+		t.Fatal(err)
+	} else if got, want := len(activities), 1; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	} else if got, want := activities[0].Action, todev.ActivityActionCreate; got != want {
+		t.Fatalf("Action=%q, want %q", got, want)
+	}
+}
+
+func findActivities_ErrNotFound(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewActivityService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	_, ctx1 := MustCreateUser(t, ctx, conn, &todev.User{Name: "judy", Email: "judy@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+
+	if _, _, err := s.FindActivities(ctx1, todev.ActivityFilter{RepoID: &repo0.ID}); todev.ErrorCode(err) != todev.ENOTFOUND {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -2,16 +2,30 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/saiddis/todev"
+	"golang.org/x/oauth2"
 )
 
+// authExpiryDelta is how long before their expiry access tokens are
+// refreshed so they do not expire while in use.
+const authExpiryDelta = time.Minute
+
+// authRefreshTimeout limits how long the auth row stays locked while waiting
+// for the provider to refresh an access token.
+const authRefreshTimeout = 10 * time.Second
+
 type AuthService struct {
 	conn *Conn
+
+	// Providers used to refresh expired access tokens, matched by source.
+	OAuthProviders []todev.OAuthProvider
 }
 
 func NewAuthService(conn *Conn) *AuthService {
@@ -85,6 +99,39 @@ func (s *AuthService) FindAuths(ctx context.Context, filter todev.AuthFilter) ([
 	return auths, n, nil
 }
 
+// AccessToken returns a valid access token for the auth by ID, refreshing it
+// through its provider if it has expired. Returns EUNAUTHORIZED if the auth
+// belongs to another user or if it is revoked. Auths that cannot be refreshed
+// are marked as revoked until the user signs in again.
+func (s *AuthService) AccessToken(ctx context.Context, id int) (string, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("error beginning transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("AccessToken: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	auth, err := refreshAuth(ctx, tx, id, s.OAuthProviders)
+	if err != nil {
+		return "", err
+	} else if !auth.RevokedAt.IsZero() {
+		// Returned without setting err so that the revocation is committed.
+		return "", todev.Errorf(todev.EUNAUTHORIZED, "Your %s authorization has expired, please sign in again.", auth.Source)
+	}
+	return auth.AccessToken, nil
+}
+
 // CreateAuth creates a new authentication object if a user is attached to auth,
 // then the auth object is linked to an existing user. Otherwise a new user object created.
 func (s *AuthService) CreateAuth(ctx context.Context, auth *todev.Auth) error {
@@ -110,7 +157,7 @@ func (s *AuthService) CreateAuth(ctx context.Context, auth *todev.Auth) error {
 	other, err := findAuthBySourceID(ctx, tx, auth.Source, auth.SourceID)
 	if err == nil {
 		// If an auth already exist for the source user, update with the new token.
-		if other, err = updateAuth(ctx, tx, other.ID, auth.AccessToken, auth.RefreshToken, auth.Expiry); err != nil {
+		if other, err = updateAuth(ctx, tx, other.ID, auth.AccessToken, auth.RefreshToken, auth.Expiry, auth.AvatarURL); err != nil {
 			return err
 		} else if err = attachAuthAssociations(ctx, tx, other); err != nil {
 			return err
@@ -128,6 +175,13 @@ func (s *AuthService) CreateAuth(ctx context.Context, auth *todev.Auth) error {
 		// Look up the user by email. If no user can be found then create a new
 		// user with the auth.User object passed in.
 		if user, err := findUserByEmail(ctx, tx, auth.User.Email); err == nil {
+			// Anyone can register a local account with any email so only
+			// link to local accounts that proved they own it.
+			if p, err := findPassword(ctx, tx, user.ID); err == nil && p.EmailVerifiedAt.IsZero() {
+				return todev.Errorf(todev.ECONFLICT, "Email is registered to an unverified account.")
+			} else if err != nil && todev.ErrorCode(err) != todev.ENOTFOUND {
+				return err
+			}
 			auth.User = user
 		} else if todev.ErrorCode(err) == todev.ENOTFOUND {
 			if err = createUser(ctx, tx, auth.User); err != nil {
@@ -210,10 +264,11 @@ func createAuth(ctx context.Context, tx *Tx, auth *todev.Auth) (err error) {
 			access_token,
 			refresh_token,
 			expiry,
+			avatar_url,
 			created_at,
 			updated_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id;`,
 		auth.UserID,
 		auth.Source,
@@ -221,6 +276,7 @@ func createAuth(ctx context.Context, tx *Tx, auth *todev.Auth) (err error) {
 		auth.AccessToken,
 		auth.RefreshToken,
 		(*NullTime)(&auth.Expiry),
+		auth.AvatarURL,
 		(*NullTime)(&auth.CreatedAt),
 		(*NullTime)(&auth.UpdatedAt),
 	).Scan(&id)
@@ -282,6 +338,8 @@ func findAuths(ctx context.Context, tx *Tx, filter todev.AuthFilter) ([]*todev.A
 			access_token,
 			refresh_token,
 			expiry,
+			avatar_url,
+			revoked_at,
 			created_at,
 			updated_at,
 			COUNT(*) OVER()
@@ -319,6 +377,8 @@ func findAuths(ctx context.Context, tx *Tx, filter todev.AuthFilter) ([]*todev.A
 			&auth.AccessToken,
 			&auth.RefreshToken,
 			(*NullTime)(&auth.Expiry),
+			&auth.AvatarURL,
+			(*NullTime)(&auth.RevokedAt),
 			(*NullTime)(&auth.CreatedAt),
 			(*NullTime)(&auth.UpdatedAt),
 			&n,
@@ -335,9 +395,10 @@ func findAuths(ctx context.Context, tx *Tx, filter todev.AuthFilter) ([]*todev.A
 	return auths, n, nil
 }
 
-// updateAuth updates tokens and expiry on existing auth object.
+// updateAuth updates tokens and expiry on existing auth object and clears its
+// revocation. The avatar URL is only replaced if a new one is given.
 // Returns new state of the auth object.
-func updateAuth(ctx context.Context, tx *Tx, id int, accessToken, refreshToken string, expiry time.Time) (*todev.Auth, error) {
+func updateAuth(ctx context.Context, tx *Tx, id int, accessToken, refreshToken string, expiry time.Time, avatarURL string) (*todev.Auth, error) {
 	auth, err := findAuthByID(ctx, tx, id)
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving auth by ID: %w", err)
@@ -346,6 +407,10 @@ func updateAuth(ctx context.Context, tx *Tx, id int, accessToken, refreshToken s
 	auth.AccessToken = accessToken
 	auth.RefreshToken = refreshToken
 	auth.Expiry = expiry
+	if avatarURL != "" {
+		auth.AvatarURL = avatarURL
+	}
+	auth.RevokedAt = time.Time{}
 	auth.UpdatedAt = tx.now
 
 	if err = auth.Validate(); err != nil {
@@ -358,11 +423,14 @@ func updateAuth(ctx context.Context, tx *Tx, id int, accessToken, refreshToken s
 			access_token = $1,
 			refresh_token = $2,
 			expiry = $3,
-			updated_at = $4
-		WHERE id = $5;`,
+			avatar_url = $4,
+			revoked_at = NULL,
+			updated_at = $5
+		WHERE id = $6;`,
 		auth.AccessToken,
 		auth.RefreshToken,
 		(*NullTime)(&auth.Expiry),
+		auth.AvatarURL,
 		(*NullTime)(&auth.UpdatedAt),
 		id,
 	)
@@ -373,11 +441,102 @@ func updateAuth(ctx context.Context, tx *Tx, id int, accessToken, refreshToken s
 	return auth, nil
 }
 
+// refreshAuth returns the auth by ID with a valid access token. Expired tokens
+// are refreshed through the provider of the auth and saved. If the provider
+// rejects the refresh token, the auth is marked as revoked and returned without
+// error so that callers can commit the revocation. Other refresh failures,
+// such as provider outages, are returned as errors and leave the auth as is.
+func refreshAuth(ctx context.Context, tx *Tx, id int, providers []todev.OAuthProvider) (*todev.Auth, error) {
+	// Lock the auth so that concurrent requests do not refresh it twice, as
+	// providers rotating refresh tokens would reject the second refresh.
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM auths WHERE id = $1 FOR UPDATE;`, id); err != nil {
+		return nil, fmt.Errorf("error locking auth: %w", err)
+	}
+
+	auth, err := findAuthByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	} else if auth.UserID != todev.UserIDFromContext(ctx) {
+		return nil, todev.Errorf(todev.EUNAUTHORIZED, "You are not allowed to use this auth.")
+	} else if !auth.RevokedAt.IsZero() {
+		return auth, nil
+	} else if auth.Expiry.IsZero() || tx.now.Add(authExpiryDelta).Before(auth.Expiry) {
+		return auth, nil
+	}
+
+	var provider todev.OAuthProvider
+	for _, p := range providers {
+		if p.Source() == auth.Source {
+			provider = p
+			break
+		}
+	}
+	if provider == nil {
+		return nil, todev.Errorf(todev.EUNAUTHORIZED, "Sign in with %s is not enabled.", auth.Source)
+	}
+
+	if auth.RefreshToken == "" {
+		return revokeAuth(ctx, tx, auth)
+	}
+	refreshCtx, cancel := context.WithTimeout(ctx, authRefreshTimeout)
+	defer cancel()
+	tok, err := provider.Refresh(refreshCtx, auth.RefreshToken)
+	if err != nil && isRefreshRejected(err) {
+		log.Printf("error refreshing auth; id=%d err=%s", auth.ID, err)
+		return revokeAuth(ctx, tx, auth)
+	} else if err != nil {
+		return nil, fmt.Errorf("error refreshing auth: %w", err)
+	}
+
+	// Keep the current refresh token if the provider does not rotate it.
+	if tok.RefreshToken == "" {
+		tok.RefreshToken = auth.RefreshToken
+	}
+	return updateAuth(ctx, tx, auth.ID, tok.AccessToken, tok.RefreshToken, tok.Expiry, "")
+}
+
+// isRefreshRejected returns true if the provider refused to refresh the access
+// token, meaning the user has to sign in again. Transport failures and server
+// errors are temporary and do not count as rejections.
+func isRefreshRejected(err error) bool {
+	var e *oauth2.RetrieveError
+	if !errors.As(err, &e) {
+		return false
+	} else if e.ErrorCode == "invalid_grant" {
+		return true
+	} else if e.Response == nil {
+		return false
+	}
+
+	switch code := e.Response.StatusCode; code {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return false
+	default:
+		return code >= 400 && code < 500
+	}
+}
+
+// revokeAuth marks an auth as revoked. Returns the new state of the auth.
+func revokeAuth(ctx context.Context, tx *Tx, auth *todev.Auth) (*todev.Auth, error) {
+	auth.RevokedAt = tx.now
+	auth.UpdatedAt = tx.now
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE auths SET revoked_at = $1, updated_at = $2 WHERE id = $3;`,
+		(*NullTime)(&auth.RevokedAt),
+		(*NullTime)(&auth.UpdatedAt),
+		auth.ID,
+	); err != nil {
+		return nil, fmt.Errorf("error revoking auth: %w", err)
+	}
+	return auth, nil
+}
+
 // deleteAuth permanently removes auth object by ID.
 func deleteAuth(ctx context.Context, tx *Tx, id int) (err error) {
 	if auth, err := findAuthByID(ctx, tx, id); err != nil {
 		return fmt.Errorf("error retrieving error by ID: %w", err)
-	} else if auth.ID != todev.UserIDFromContext(ctx) {
+	} else if auth.UserID != todev.UserIDFromContext(ctx) {
 		return todev.Errorf(todev.EUNAUTHORIZED, "You are not allowed to delete this auth.")
 	}
 
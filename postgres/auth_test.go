@@ -2,13 +2,18 @@ package postgres_test
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"reflect"
 	"testing"
 	"time"
 
 	_ "github.com/lib/pq"
 	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/mock"
 	"github.com/saiddis/todev/postgres"
+	"golang.org/x/oauth2"
 )
 
 func TestAuthService_CreateAuth(t *testing.T) {
@@ -47,6 +52,20 @@ func TestAuthService_DeleteAuth(t *testing.T) {
 	})
 }
 
+func TestAuthService_AccessToken(t *testing.T) {
+	t.Run("Refresh", func(t *testing.T) {
+		WithSchema(t, accessToken_Refresh)
+	})
+
+	t.Run("ErrRevoked", func(t *testing.T) {
+		WithSchema(t, accessToken_ErrRevoked)
+	})
+
+	t.Run("ErrUnavailable", func(t *testing.T) {
+		WithSchema(t, accessToken_ErrUnavailable)
+	})
+}
+
 func TestAuthService_FindAuthByID(t *testing.T) {
 	t.Run("ErrNotFound", func(t *testing.T) {
 		WithSchema(t, findAuthByID_ErrNotFound)
@@ -234,6 +253,125 @@ func findAuths_OK(t testing.TB, conn *postgres.Conn) {
 
 }
 
+func accessToken_Refresh(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewAuthService(conn)
+	s.OAuthProviders = []todev.OAuthProvider{&mock.OAuthProvider{
+		SourceFn: func() string { return todev.AuthSourceGitHub },
+		RefreshFn: func(ctx context.Context, refreshToken string) (*todev.OAuthToken, error) {
+			if refreshToken != "REFRESH" {
+				t.Fatalf("unexpected refresh token: %q", refreshToken)
+			}
+			return &todev.OAuthToken{AccessToken: "ACCESS2", Expiry: time.Now().Add(time.Hour)}, nil
+		},
+	}}
+
+	auth, ctx0 := MustCreateAuth(t, context.Background(), conn, &todev.Auth{
+		Source:       todev.AuthSourceGitHub,
+		SourceID:     "SOURCEID",
+		AccessToken:  "ACCESS",
+		RefreshToken: "REFRESH",
+		Expiry:       time.Now().Add(-time.Hour),
+		User:         &todev.User{Name: "jill"},
+	})
+
+	if token, err := s.AccessToken(ctx0, auth.ID); err != nil {
+		t.Fatal(err)
+	} else if got, want := token, "ACCESS2"; got != want {
+		t.Fatalf("token=%q, want %q", got, want)
+	}
+
+	// The refresh token is kept if the provider does not issue a new one.
+	if other, err := s.FindAuthByID(ctx0, auth.ID); err != nil {
+		t.Fatal(err)
+	} else if got, want := other.RefreshToken, "REFRESH"; got != want {
+		t.Fatalf("RefreshToken=%q, want %q", got, want)
+	} else if !other.Expiry.After(time.Now()) {
+		t.Fatalf("unexpected expiry: %s", other.Expiry)
+	}
+
+	// Other users cannot use the auth.
+	_, ctx1 := MustCreateUser(t, context.Background(), conn, &todev.User{Name: "bob"})
+	if _, err := s.AccessToken(ctx1, auth.ID); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func accessToken_ErrRevoked(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewAuthService(conn)
+	s.OAuthProviders = []todev.OAuthProvider{&mock.OAuthProvider{
+		SourceFn: func() string { return todev.AuthSourceGitHub },
+		RefreshFn: func(ctx context.Context, refreshToken string) (*todev.OAuthToken, error) {
+			return nil, fmt.Errorf("oauth refresh error: %w", &oauth2.RetrieveError{ErrorCode: "invalid_grant"})
+		},
+	}}
+
+	auth, ctx0 := MustCreateAuth(t, context.Background(), conn, &todev.Auth{
+		Source:       todev.AuthSourceGitHub,
+		SourceID:     "SOURCEID",
+		AccessToken:  "ACCESS",
+		RefreshToken: "REFRESH",
+		Expiry:       time.Now().Add(-time.Hour),
+		User:         &todev.User{Name: "jill"},
+	})
+
+	if _, err := s.AccessToken(ctx0, auth.ID); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	} else if other, err := s.FindAuthByID(ctx0, auth.ID); err != nil {
+		t.Fatal(err)
+	} else if other.RevokedAt.IsZero() {
+		t.Fatal("expected revoked at")
+	}
+
+	// Signing in again replaces the tokens and clears the revocation.
+	if err := s.CreateAuth(ctx0, &todev.Auth{
+		Source:      todev.AuthSourceGitHub,
+		SourceID:    "SOURCEID",
+		AccessToken: "ACCESS2",
+	}); err != nil {
+		t.Fatal(err)
+	} else if token, err := s.AccessToken(ctx0, auth.ID); err != nil {
+		t.Fatal(err)
+	} else if got, want := token, "ACCESS2"; got != want {
+		t.Fatalf("token=%q, want %q", got, want)
+	}
+}
+
+// Ensure provider outages do not revoke auths.
+func accessToken_ErrUnavailable(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewAuthService(conn)
+	s.OAuthProviders = []todev.OAuthProvider{&mock.OAuthProvider{
+		SourceFn: func() string { return todev.AuthSourceGitHub },
+		RefreshFn: func(ctx context.Context, refreshToken string) (*todev.OAuthToken, error) {
+			return nil, &oauth2.RetrieveError{Response: &http.Response{StatusCode: http.StatusServiceUnavailable}}
+		},
+	}}
+
+	auth, ctx0 := MustCreateAuth(t, context.Background(), conn, &todev.Auth{
+		Source:       todev.AuthSourceGitHub,
+		SourceID:     "SOURCEID",
+		AccessToken:  "ACCESS",
+		RefreshToken: "REFRESH",
+		Expiry:       time.Now().Add(-time.Hour),
+		User:         &todev.User{Name: "jill"},
+	})
+
+	if _, err := s.AccessToken(ctx0, auth.ID); err == nil || todev.ErrorCode(err) == todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Network failures are not rejections either.
+	s.OAuthProviders[0].(*mock.OAuthProvider).RefreshFn = func(ctx context.Context, refreshToken string) (*todev.OAuthToken, error) {
+		return nil, errors.New("connection refused")
+	}
+	if _, err := s.AccessToken(ctx0, auth.ID); err == nil || todev.ErrorCode(err) == todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	} else if other, err := s.FindAuthByID(ctx0, auth.ID); err != nil {
+		t.Fatal(err)
+	} else if !other.RevokedAt.IsZero() {
+		t.Fatal("expected auth not to be revoked")
+	}
+}
+
 func MustCreateAuth(tb testing.TB, ctx context.Context, conn *postgres.Conn, auth *todev.Auth) (*todev.Auth, context.Context) {
 	tb.Helper()
 	if err := postgres.NewAuthService(conn).CreateAuth(ctx, auth); err != nil {
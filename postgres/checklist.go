@@ -0,0 +1,344 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/saiddis/todev"
+)
+
+// CreateChecklistItem appends a new item to the checklist of a task.
+// Returns EUNAUTHORIZED if the current user is not a repo admin.
+func (s *TaskService) CreateChecklistItem(ctx context.Context, item *todev.ChecklistItem) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("CreateChecklistItem: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	if err = createChecklistItem(ctx, tx, item); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateChecklistItem updates a checklist item by ID. Members can only check
+// items of the tasks given to them. Returns EUNAUTHORIZED otherwise.
+func (s *TaskService) UpdateChecklistItem(ctx context.Context, id int, upd todev.ChecklistItemUpdate) (*todev.ChecklistItem, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("UpdateChecklistItem: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	item, err := updateChecklistItem(ctx, tx, id, upd)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// DeleteChecklistItem permanently deletes a checklist item by ID.
+// Returns EUNAUTHORIZED if the current user is not a repo admin.
+func (s *TaskService) DeleteChecklistItem(ctx context.Context, id int) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("DeleteChecklistItem: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	if err = deleteChecklistItem(ctx, tx, id); err != nil {
+		return err
+	}
+	return nil
+}
+
+func createChecklistItem(ctx context.Context, tx *Tx, item *todev.ChecklistItem) error {
+	item.CreatedAt = tx.now
+	item.UpdatedAt = item.CreatedAt
+	item.IsChecked = false
+
+	if err := item.Validate(); err != nil {
+		return err
+	}
+
+	task, err := findTaskByID(ctx, tx, item.TaskID)
+	if err != nil {
+		return err
+	} else if err = checkCanEditTask(ctx, tx, task); err != nil {
+		return err
+	}
+
+	// Lock the task so that concurrent additions do not take the same
+	// position.
+	if err = lockChecklist(ctx, tx, item.TaskID); err != nil {
+		return err
+	}
+
+	// New items always go to the end of the checklist.
+	if err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(1) FROM checklist_items WHERE task_id = $1;`,
+		item.TaskID,
+	).Scan(&item.Position); err != nil {
+		return fmt.Errorf("error counting checklist items: %w", err)
+	} else if item.Position >= todev.MaxChecklistItems {
+		return todev.Errorf(todev.EINVALID, "Too many checklist items.")
+	}
+
+	if err = tx.QueryRowContext(ctx, `
+		INSERT INTO checklist_items (task_id, text, position, is_checked, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id;`,
+		item.TaskID,
+		item.Text,
+		item.Position,
+		item.IsChecked,
+		(*NullTime)(&item.CreatedAt),
+		(*NullTime)(&item.UpdatedAt),
+	).Scan(&item.ID); err != nil {
+		return fmt.Errorf("error inserting checklist item: %w", err)
+	}
+
+	return publishRepoEvent(ctx, tx, task.RepoID, todev.Event{
+		Type: todev.EventTypeTaskChecklistItemAdded,
+		Payload: todev.TaskChecklistItemAdded{
+			Item: item,
+		},
+	})
+}
+
+// findChecklistItems returns the checklist of a task in order.
+func findChecklistItems(ctx context.Context, tx *Tx, taskID int) ([]*todev.ChecklistItem, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, task_id, text, position, is_checked, created_at, updated_at
+		FROM checklist_items
+		WHERE task_id = $1
+		ORDER BY position ASC, id ASC;`,
+		taskID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving checklist items: %w", err)
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("error closing rows: %v", err)
+		}
+	}()
+
+	items := make([]*todev.ChecklistItem, 0)
+	for rows.Next() {
+		var item todev.ChecklistItem
+		if err = rows.Scan(
+			&item.ID,
+			&item.TaskID,
+			&item.Text,
+			&item.Position,
+			&item.IsChecked,
+			(*NullTime)(&item.CreatedAt),
+			(*NullTime)(&item.UpdatedAt),
+		); err != nil {
+			return nil, fmt.Errorf("error scanning: %w", err)
+		}
+		items = append(items, &item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return items, nil
+}
+
+// findChecklistItemByID returns an item along with its task. Returns ENOTFOUND
+// if the item does not exist or its task is not visible to the current user.
+func findChecklistItemByID(ctx context.Context, tx *Tx, id int) (*todev.ChecklistItem, *todev.Task, error) {
+	var taskID int
+	if err := tx.QueryRowContext(ctx, `SELECT task_id FROM checklist_items WHERE id = $1;`, id).Scan(&taskID); err == sql.ErrNoRows {
+		return nil, nil, todev.Errorf(todev.ENOTFOUND, "Checklist item not found.")
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("error retrieving checklist item: %w", err)
+	}
+
+	task, err := findTaskByID(ctx, tx, taskID)
+	if err != nil {
+		return nil, nil, err
+	} else if err = attachTaskAssociations(ctx, tx, task); err != nil {
+		return nil, nil, err
+	}
+
+	for _, item := range task.Checklist {
+		if item.ID == id {
+			return item, task, nil
+		}
+	}
+	return nil, nil, todev.Errorf(todev.ENOTFOUND, "Checklist item not found.")
+}
+
+func updateChecklistItem(ctx context.Context, tx *Tx, id int, upd todev.ChecklistItemUpdate) (*todev.ChecklistItem, error) {
+	if err := lockChecklistOfItem(ctx, tx, id); err != nil {
+		return nil, err
+	}
+
+	item, task, err := findChecklistItemByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Members can only check items of their own tasks. Any other change
+	// requires admin privileges.
+	role, contributorID, err := findCurrentRole(ctx, tx, task.RepoID)
+	if err != nil {
+		return nil, err
+	} else if !role.IsAdmin() && (upd.Text != nil || upd.Position != nil ||
+		!todev.CanSetTaskStatus(role, contributorID, *task)) {
+		return nil, todev.Errorf(todev.EUNAUTHORIZED, "You are not allowed to update checklist items.")
+	}
+
+	if v := upd.Text; v != nil {
+		item.Text = *v
+	}
+	if v := upd.IsChecked; v != nil {
+		item.IsChecked = *v
+	}
+	item.UpdatedAt = tx.now
+
+	if err = item.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Shift the items between the old and the new position by one.
+	if v := upd.Position; v != nil && *v != item.Position {
+		position := max(0, min(*v, len(task.Checklist)-1))
+		if position < item.Position {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE checklist_items SET position = position + 1
+				WHERE task_id = $1 AND position >= $2 AND position < $3;`,
+				item.TaskID, position, item.Position,
+			)
+		} else {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE checklist_items SET position = position - 1
+				WHERE task_id = $1 AND position > $2 AND position <= $3;`,
+				item.TaskID, item.Position, position,
+			)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error moving checklist items: %w", err)
+		}
+		item.Position = position
+	}
+
+	if _, err = tx.ExecContext(ctx, `
+		UPDATE checklist_items
+		SET text = $1, is_checked = $2, position = $3, updated_at = $4
+		WHERE id = $5;`,
+		item.Text,
+		item.IsChecked,
+		item.Position,
+		(*NullTime)(&item.UpdatedAt),
+		id,
+	); err != nil {
+		return nil, fmt.Errorf("error updating checklist item: %w", err)
+	}
+
+	if err = publishRepoEvent(ctx, tx, task.RepoID, todev.Event{
+		Type: todev.EventTypeTaskChecklistItemChanged,
+		Payload: todev.TaskChecklistItemChanged{
+			Item: item,
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func deleteChecklistItem(ctx context.Context, tx *Tx, id int) error {
+	if err := lockChecklistOfItem(ctx, tx, id); err != nil {
+		return err
+	}
+
+	item, task, err := findChecklistItemByID(ctx, tx, id)
+	if err != nil {
+		return err
+	} else if err = checkCanEditTask(ctx, tx, task); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM checklist_items WHERE id = $1;", id); err != nil {
+		return fmt.Errorf("error deleting checklist item: %w", err)
+	} else if _, err = tx.ExecContext(ctx, `
+		UPDATE checklist_items SET position = position - 1
+		WHERE task_id = $1 AND position > $2;`,
+		item.TaskID, item.Position,
+	); err != nil {
+		return fmt.Errorf("error moving checklist items: %w", err)
+	}
+
+	return publishRepoEvent(ctx, tx, task.RepoID, todev.Event{
+		Type: todev.EventTypeTaskChecklistItemRemoved,
+		Payload: todev.TaskChecklistItemRemoved{
+			ID:     item.ID,
+			TaskID: item.TaskID,
+		},
+	})
+}
+
+// lockChecklist locks the task owning a checklist until the end of the
+// transaction. Every change to item positions takes the lock first so that
+// positions stay unique and contiguous.
+func lockChecklist(ctx context.Context, tx *Tx, taskID int) error {
+	if _, err := tx.ExecContext(ctx, `SELECT id FROM tasks WHERE id = $1 FOR UPDATE;`, taskID); err != nil {
+		return fmt.Errorf("error locking checklist: %w", err)
+	}
+	return nil
+}
+
+// lockChecklistOfItem locks the checklist an item belongs to. Does nothing if
+// the item does not exist.
+func lockChecklistOfItem(ctx context.Context, tx *Tx, id int) error {
+	if _, err := tx.ExecContext(ctx, `
+		SELECT t.id FROM tasks t
+		JOIN checklist_items i ON i.task_id = t.id
+		WHERE i.id = $1
+		FOR UPDATE OF t;`,
+		id,
+	); err != nil {
+		return fmt.Errorf("error locking checklist: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package postgres_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/postgres"
+)
+
+func TestTaskService_Checklist(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		WithSchema(t, checklist_OK)
+	})
+
+	t.Run("Member", func(t *testing.T) {
+		WithSchema(t, checklist_Member)
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		WithSchema(t, checklist_Concurrent)
+	})
+}
+
+func checklist_OK(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewTaskService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	task := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Do some stuff.", RepoID: repo0.ID})
+
+	items := make([]*todev.ChecklistItem, 3)
+	for i, text := range []string{"first", "second", "third"} {
+		items[i] = &todev.ChecklistItem{TaskID: task.ID, Text: text}
+		if err := s.CreateChecklistItem(ctx0, items[i]); err != nil {
+			t.Fatal(err)
+		} else if got, want := items[i].Position, i; got != want {
+			t.Fatalf("Position=%d, want %d", got, want)
+		}
+	}
+
+	if err := s.CreateChecklistItem(ctx0, &todev.ChecklistItem{TaskID: task.ID}); todev.ErrorCode(err) != todev.EINVALID {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Move the last item to the top and check it.
+	position, checked := 0, true
+	if _, err := s.UpdateChecklistItem(ctx0, items[2].ID, todev.ChecklistItemUpdate{Position: &position, IsChecked: &checked}); err != nil {
+		t.Fatal(err)
+	}
+	assertChecklist(t, ctx0, s, task.ID, []string{"third", "first", "second"}, 1)
+
+	// Remaining items close the gap left by a deleted one.
+	if err := s.DeleteChecklistItem(ctx0, items[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	assertChecklist(t, ctx0, s, task.ID, []string{"third", "second"}, 1)
+
+	if err := s.DeleteChecklistItem(ctx0, items[0].ID); todev.ErrorCode(err) != todev.ENOTFOUND {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func checklist_Member(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewTaskService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	_, ctx1 := MustCreateUser(t, ctx, conn, &todev.User{Name: "judy", Email: "judy@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	MustCreateContributor(t, ctx1, conn, &todev.Contributor{RepoID: repo0.ID})
+	task := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Do some stuff.", RepoID: repo0.ID})
+
+	item := &todev.ChecklistItem{TaskID: task.ID, Text: "first"}
+	if err := s.CreateChecklistItem(ctx0, item); err != nil {
+		t.Fatal(err)
+	}
+
+	// Members can check items but not change the checklist itself.
+	checked := true
+	if other, err := s.UpdateChecklistItem(ctx1, item.ID, todev.ChecklistItemUpdate{IsChecked: &checked}); err != nil {
+		t.Fatal(err)
+	} else if !other.IsChecked {
+		t.Fatal("expected item to be checked")
+	}
+
+	text := "other"
+	if _, err := s.UpdateChecklistItem(ctx1, item.ID, todev.ChecklistItemUpdate{Text: &text}); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := s.CreateChecklistItem(ctx1, &todev.ChecklistItem{TaskID: task.ID, Text: "second"}); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := s.DeleteChecklistItem(ctx1, item.ID); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// assertChecklist checks the order of a task's checklist and its progress.
+func checklist_Concurrent(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewTaskService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	task := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Do some stuff.", RepoID: repo0.ID})
+
+	// Concurrent additions each get their own position.
+	const n = 8
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			errs[i] = s.CreateChecklistItem(ctx0, &todev.ChecklistItem{TaskID: task.ID, Text: fmt.Sprint(i)})
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	other, err := s.FindTaskByID(ctx0, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if got, want := len(other.Checklist), n; got != want {
+		t.Fatalf("len(Checklist)=%d, want %d", got, want)
+	}
+	for i, item := range other.Checklist {
+		if item.Position != i {
+			t.Fatalf("[%d] Position=%d, want %d", i, item.Position, i)
+		}
+	}
+}
+
+func assertChecklist(tb testing.TB, ctx context.Context, s *postgres.TaskService, taskID int, texts []string, checked int) {
+	tb.Helper()
+	task, err := s.FindTaskByID(ctx, taskID)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	if got, total := task.ChecklistProgress(); got != checked || total != len(texts) {
+		tb.Fatalf("progress=%d/%d, want %d/%d", got, total, checked, len(texts))
+	}
+	for i, item := range task.Checklist {
+		if item.Text != texts[i] {
+			tb.Fatalf("[%d] Text=%q, want %q", i, item.Text, texts[i])
+		} else if item.Position != i {
+			tb.Fatalf("[%d] Position=%d, want %d", i, item.Position, i)
+		}
+	}
+}
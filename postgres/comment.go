@@ -0,0 +1,380 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/saiddis/todev"
+)
+
+var _ todev.CommentService = (*CommentService)(nil)
+
+// CommentService represents a service for managing task comments.
+type CommentService struct {
+	conn *Conn
+}
+
+// NewCommentService returns a new instance of CommentService.
+func NewCommentService(conn *Conn) *CommentService {
+	return &CommentService{conn: conn}
+}
+
+// FindCommentByID retrieves a comment by ID along with its author. Returns
+// ENOTFOUND if the comment does not exist or the user cannot view its repo.
+func (s *CommentService) FindCommentByID(ctx context.Context, id int) (*todev.Comment, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("FindCommentByID: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	comment, err := findCommentByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	} else if err = attachCommentAssociations(ctx, tx, comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// FindComments retrieves a list of matching comments based on filter. Only
+// returns comments on tasks of repos the current user is a member of.
+func (s *CommentService) FindComments(ctx context.Context, filter todev.CommentFilter) ([]*todev.Comment, int, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("FindComments: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	comments, n, err := findComments(ctx, tx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, comment := range comments {
+		if err = attachCommentAssociations(ctx, tx, comment); err != nil {
+			return nil, 0, err
+		}
+	}
+	return comments, n, nil
+}
+
+// CreateComment creates a new comment on a task for the current user.
+// Returns EUNAUTHORIZED if the user is not allowed to comment in the repo.
+func (s *CommentService) CreateComment(ctx context.Context, comment *todev.Comment) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("CreateComment: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	if err = createComment(ctx, tx, comment); err != nil {
+		return err
+	} else if err = attachCommentAssociations(ctx, tx, comment); err != nil {
+		return err
+	} else if err = publishRepoEvent(ctx, tx, comment.RepoID, todev.Event{
+		Type: todev.EventTypeCommentAdded,
+		Payload: todev.CommentAdded{
+			Comment: comment,
+		},
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UpdateComment updates the body of a comment by ID. Returns EUNAUTHORIZED if
+// the current user is not the author of the comment.
+func (s *CommentService) UpdateComment(ctx context.Context, id int, upd todev.CommentUpdate) (*todev.Comment, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("UpdateComment: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	comment, err := updateComment(ctx, tx, id, upd)
+	if err != nil {
+		return nil, err
+	} else if err = attachCommentAssociations(ctx, tx, comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// DeleteComment permanently deletes a comment by ID. Returns EUNAUTHORIZED if
+// the current user is neither the author nor a repo admin.
+func (s *CommentService) DeleteComment(ctx context.Context, id int) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("DeleteComment: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	if err = deleteComment(ctx, tx, id); err != nil {
+		return err
+	}
+	return nil
+}
+
+func createComment(ctx context.Context, tx *Tx, comment *todev.Comment) error {
+	comment.UserID = todev.UserIDFromContext(ctx)
+	if comment.UserID == 0 {
+		return todev.Errorf(todev.EUNAUTHORIZED, "You must be logged in to comment.")
+	}
+
+	comment.CreatedAt = tx.now
+	comment.UpdatedAt = comment.CreatedAt
+
+	if err := comment.Validate(); err != nil {
+		return err
+	}
+
+	// Ensure the task is visible to the user before checking their role.
+	task, err := findTaskByID(ctx, tx, comment.TaskID)
+	if err != nil {
+		return err
+	}
+	comment.RepoID = task.RepoID
+
+	if role, _, err := findCurrentRole(ctx, tx, comment.RepoID); err != nil {
+		return err
+	} else if !role.CanComment() {
+		return todev.Errorf(todev.EUNAUTHORIZED, "You are not allowed to comment on tasks.")
+	}
+
+	if err = tx.QueryRowContext(ctx, `
+		INSERT INTO comments (task_id, user_id, body, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id;`,
+		comment.TaskID,
+		comment.UserID,
+		comment.Body,
+		(*NullTime)(&comment.CreatedAt),
+		(*NullTime)(&comment.UpdatedAt),
+	).Scan(&comment.ID); err != nil {
+		return fmt.Errorf("error inserting comment: %w", err)
+	}
+	return nil
+}
+
+func findCommentByID(ctx context.Context, tx *Tx, id int) (*todev.Comment, error) {
+	comments, _, err := findComments(ctx, tx, todev.CommentFilter{ID: &id})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving comment by ID: %w", err)
+	} else if len(comments) == 0 {
+		return nil, todev.Errorf(todev.ENOTFOUND, "Comment not found.")
+	}
+	return comments[0], nil
+}
+
+func findComments(ctx context.Context, tx *Tx, filter todev.CommentFilter) ([]*todev.Comment, int, error) {
+	where, args := []string{"1 = 1"}, []interface{}{}
+	var argIndex int
+	if v := filter.ID; v != nil {
+		argIndex++
+		where, args = append(where, fmt.Sprintf("c.id = $%d", argIndex)), append(args, *v)
+	}
+	if v := filter.TaskID; v != nil {
+		argIndex++
+		where, args = append(where, fmt.Sprintf("c.task_id = $%d", argIndex)), append(args, *v)
+	}
+
+	// Limit to repos the user is a member of.
+	argIndex++
+	where = append(where, fmt.Sprintf(`(
+		r.user_id = $%d OR
+		t.repo_id IN (SELECT repo_id FROM contributors WHERE user_id = $%d)
+		)`, argIndex, argIndex),
+	)
+	args = append(args, todev.UserIDFromContext(ctx))
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			c.id,
+			c.task_id,
+			t.repo_id,
+			c.user_id,
+			c.body,
+			c.created_at,
+			c.updated_at,
+			COUNT(*) OVER()
+		FROM comments c
+		JOIN tasks t ON c.task_id = t.id
+		JOIN repos r ON t.repo_id = r.id
+		WHERE `+strings.Join(where, " AND ")+` AND t.deleted_at IS NULL AND r.deleted_at IS NULL
+		ORDER BY c.created_at ASC, c.id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error retrieving comments: %w", err)
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("error closing rows: %v", err)
+		}
+	}()
+
+	comments := make([]*todev.Comment, 0)
+
+	var n int
+	for rows.Next() {
+		var comment todev.Comment
+		if err = rows.Scan(
+			&comment.ID,
+			&comment.TaskID,
+			&comment.RepoID,
+			&comment.UserID,
+			&comment.Body,
+			(*NullTime)(&comment.CreatedAt),
+			(*NullTime)(&comment.UpdatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, fmt.Errorf("error scanning: %w", err)
+		}
+		comments = append(comments, &comment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return comments, n, nil
+}
+
+func updateComment(ctx context.Context, tx *Tx, id int, upd todev.CommentUpdate) (*todev.Comment, error) {
+	comment, err := findCommentByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	} else if comment.UserID != todev.UserIDFromContext(ctx) {
+		return nil, todev.Errorf(todev.EUNAUTHORIZED, "You are not allowed to edit this comment.")
+	}
+
+	if v := upd.Body; v != nil {
+		comment.Body = *v
+	}
+	comment.UpdatedAt = tx.now
+
+	if err = comment.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx, `
+		UPDATE comments
+		SET body = $1, updated_at = $2
+		WHERE id = $3;`,
+		comment.Body,
+		(*NullTime)(&comment.UpdatedAt),
+		id,
+	); err != nil {
+		return nil, fmt.Errorf("error updating comment: %w", err)
+	}
+
+	if upd.Body != nil {
+		if err = publishRepoEvent(ctx, tx, comment.RepoID, todev.Event{
+			Type: todev.EventTypeCommentEdited,
+			Payload: todev.CommentEdited{
+				ID:     comment.ID,
+				TaskID: comment.TaskID,
+				Value:  comment.Body,
+			},
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return comment, nil
+}
+
+func deleteComment(ctx context.Context, tx *Tx, id int) error {
+	comment, err := findCommentByID(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if role, _, err := findCurrentRole(ctx, tx, comment.RepoID); err != nil {
+		return err
+	} else if !todev.CanDeleteComment(ctx, role, *comment) {
+		return todev.Errorf(todev.EUNAUTHORIZED, "You are not allowed to delete this comment.")
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM comments WHERE id = $1;", id); err != nil {
+		return fmt.Errorf("error deleting comment: %w", err)
+	} else if err = publishRepoEvent(ctx, tx, comment.RepoID, todev.Event{
+		Type: todev.EventTypeCommentDeleted,
+		Payload: todev.CommentDeleted{
+			ID:     comment.ID,
+			TaskID: comment.TaskID,
+		},
+	}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// attachCommentAssociations is a helper function to look up and attach the
+// author of the comment.
+func attachCommentAssociations(ctx context.Context, tx *Tx, comment *todev.Comment) (err error) {
+	if comment.User, err = findUserByID(ctx, tx, comment.UserID); err != nil {
+		return fmt.Errorf("error attaching comment user: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package postgres_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/postgres"
+)
+
+func TestCommentService_CreateComment(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		WithSchema(t, createComment_OK)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		WithSchema(t, createComment_Errors)
+	})
+}
+
+func TestCommentService_UpdateComment(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		WithSchema(t, updateComment_OK)
+	})
+}
+
+func TestCommentService_DeleteComment(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		WithSchema(t, deleteComment_OK)
+	})
+}
+
+func createComment_OK(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewCommentService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	_, ctx1 := MustCreateUser(t, ctx, conn, &todev.User{Name: "judy", Email: "judy@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	MustCreateContributor(t, ctx1, conn, &todev.Contributor{RepoID: repo0.ID})
+	task := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Do some stuff.", RepoID: repo0.ID})
+
+	comment0 := MustCreateComment(t, ctx0, conn, &todev.Comment{TaskID: task.ID, Body: "First."})
+	comment1 := MustCreateComment(t, ctx1, conn, &todev.Comment{TaskID: task.ID, Body: "Second."})
+
+	if got, want := comment1.RepoID, repo0.ID; got != want {
+		t.Fatalf("RepoID=%d, want %d", got, want)
+	} else if got, want := comment1.User.Name, "judy"; got != want {
+		t.Fatalf("User.Name=%s, want %s", got, want)
+	}
+
+	// Comments are returned from the oldest to the newest.
+	if comments, n, err := s.FindComments(ctx1, todev.CommentFilter{TaskID: &task.ID}); err != nil {
+		t.Fatal(err)
+	} else if got, want := n, 2; got != want {
+		t.Fatalf("n=%d, want %d", got, want)
+	} else if !reflect.DeepEqual(comments, []*todev.Comment{comment0, comment1}) {
+		t.Fatalf("mismatch: %#v", comments)
+	}
+}
+
+func createComment_Errors(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewCommentService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	_, ctx1 := MustCreateUser(t, ctx, conn, &todev.User{Name: "judy", Email: "judy@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	task := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Do some stuff.", RepoID: repo0.ID})
+
+	if err := s.CreateComment(ctx0, &todev.Comment{TaskID: task.ID}); todev.ErrorCode(err) != todev.EINVALID {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	// Users outside of the repo cannot see the task.
+	if err := s.CreateComment(ctx1, &todev.Comment{TaskID: task.ID, Body: "Hi."}); todev.ErrorCode(err) != todev.ENOTFOUND {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	// Viewers can see the task but cannot comment.
+	contributor := MustCreateContributor(t, ctx1, conn, &todev.Contributor{RepoID: repo0.ID})
+	viewer := todev.RoleViewer
+	if _, err := postgres.NewContrubutorService(conn).UpdateContributor(ctx0, contributor.ID, todev.ContributorUpdate{Role: &viewer}); err != nil {
+		t.Fatal(err)
+	} else if err := s.CreateComment(ctx1, &todev.Comment{TaskID: task.ID, Body: "Hi."}); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+}
+
+func updateComment_OK(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewCommentService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	_, ctx1 := MustCreateUser(t, ctx, conn, &todev.User{Name: "judy", Email: "judy@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	MustCreateContributor(t, ctx1, conn, &todev.Contributor{RepoID: repo0.ID})
+	task := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Do some stuff.", RepoID: repo0.ID})
+	comment := MustCreateComment(t, ctx1, conn, &todev.Comment{TaskID: task.ID, Body: "First."})
+
+	body := "Edited."
+	if other, err := s.UpdateComment(ctx1, comment.ID, todev.CommentUpdate{Body: &body}); err != nil {
+		t.Fatal(err)
+	} else if got, want := other.Body, body; got != want {
+		t.Fatalf("Body=%s, want %s", got, want)
+	}
+
+	// Even admins cannot edit the comments of others.
+	if _, err := s.UpdateComment(ctx0, comment.ID, todev.CommentUpdate{Body: &body}); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+}
+
+func deleteComment_OK(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewCommentService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	_, ctx1 := MustCreateUser(t, ctx, conn, &todev.User{Name: "judy", Email: "judy@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	MustCreateContributor(t, ctx1, conn, &todev.Contributor{RepoID: repo0.ID})
+	task := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Do some stuff.", RepoID: repo0.ID})
+	comment0 := MustCreateComment(t, ctx0, conn, &todev.Comment{TaskID: task.ID, Body: "First."})
+	comment1 := MustCreateComment(t, ctx1, conn, &todev.Comment{TaskID: task.ID, Body: "Second."})
+
+	// Members cannot delete the comments of others.
+	if err := s.DeleteComment(ctx1, comment0.ID); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	// Admins can delete any comment.
+	if err := s.DeleteComment(ctx0, comment1.ID); err != nil {
+		t.Fatal(err)
+	} else if _, err := s.FindCommentByID(ctx0, comment1.ID); todev.ErrorCode(err) != todev.ENOTFOUND {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+}
+
+func MustCreateComment(tb testing.TB, ctx context.Context, conn *postgres.Conn, comment *todev.Comment) *todev.Comment {
+	tb.Helper()
+	if err := postgres.NewCommentService(conn).CreateComment(ctx, comment); err != nil {
+		tb.Fatalf("MustCreateComment: %v", err)
+	}
+	return comment
+}
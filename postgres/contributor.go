@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log"
 	"strings"
@@ -45,19 +46,13 @@ func (s *ContributorService) CreateContributor(ctx context.Context, contributor
 	}
 	contributor.UserID = userID
 
-	if err = createContributor(ctx, tx, contributor); err != nil {
-		return err
-	} else if err = attachContributorAssociations(ctx, tx, contributor); err != nil {
+	// Users joining a repo without an invitation always start as members.
+	// Admins can promote them later.
+	contributor.Role = todev.RoleMember
+
+	if err = addContributor(ctx, tx, contributor, 0); err != nil {
 		return err
 	}
-	// else if err = tx.conn.EventService.PublishEvent(contributor.RepoID, todev.Event{
-	// 	Type: todev.EventTypeContributorAdded,
-	// 	Payload: todev.ContributorAdded{
-	// 		Contributor: contributor,
-	// 	},
-	// }); err != nil {
-	// 	return fmt.Errorf("error publishing event: %w", err)
-	// }
 	return nil
 }
 
@@ -176,11 +171,42 @@ func (s *ContributorService) DeleteContributor(ctx context.Context, id int) erro
 	return nil
 }
 
+// addContributor creates a contributor and notifies the repo about it. The ID
+// of the invitation the user joined through is recorded in the activity log
+// unless it is zero.
+func addContributor(ctx context.Context, tx *Tx, contributor *todev.Contributor, invitationID int) error {
+	if err := createContributor(ctx, tx, contributor); err != nil {
+		return err
+	} else if err = attachContributorAssociations(ctx, tx, contributor); err != nil {
+		return err
+	} else if err = publishRepoEvent(ctx, tx, contributor.RepoID, todev.Event{
+		Type: todev.EventTypeContributorAdded,
+		Payload: todev.ContributorAdded{
+			Contributor: contributor,
+		},
+	}); err != nil {
+		return err
+	}
+
+	after := contributorActivityFields(contributor)
+	if invitationID != 0 {
+		after["invitationID"] = invitationID
+	}
+	return createActivity(ctx, tx, &todev.Activity{
+		RepoID:     contributor.RepoID,
+		EntityType: todev.ActivityEntityContributor,
+		EntityID:   contributor.ID,
+		Action:     todev.ActivityActionCreate,
+		After:      after,
+	})
+}
+
 func createSelfContributor(ctx context.Context, tx *Tx, repo *todev.Repo) (err error) {
 	contributor := todev.Contributor{
 		RepoID:    repo.ID,
 		UserID:    repo.UserID,
 		OwnerID:   repo.UserID,
+		Role:      todev.RoleOwner,
 		IsAdmin:   true,
 		CreatedAt: tx.now,
 		UpdatedAt: tx.now,
@@ -195,7 +221,7 @@ func createSelfContributor(ctx context.Context, tx *Tx, repo *todev.Repo) (err e
 			repo_id,
 			user_id,
 			owner_id,
-			is_admin,
+			role,
 			created_at,
 			updated_at
 		)
@@ -204,7 +230,7 @@ func createSelfContributor(ctx context.Context, tx *Tx, repo *todev.Repo) (err e
 		contributor.RepoID,
 		contributor.UserID,
 		contributor.OwnerID,
-		contributor.IsAdmin,
+		contributor.Role,
 		(*NullTime)(&contributor.CreatedAt),
 		(*NullTime)(&contributor.UpdatedAt),
 	).Scan(&contributor.ID)
@@ -221,6 +247,12 @@ func createContributor(ctx context.Context, tx *Tx, contributor *todev.Contribut
 	contributor.CreatedAt = tx.now
 	contributor.UpdatedAt = contributor.CreatedAt
 
+	// Users joining a repo start as members unless given another role.
+	if contributor.Role == "" {
+		contributor.Role = todev.RoleMember
+	}
+	contributor.IsAdmin = contributor.Role.IsAdmin()
+
 	if err = contributor.Validate(); err != nil {
 		return err
 	}
@@ -241,7 +273,7 @@ func createContributor(ctx context.Context, tx *Tx, contributor *todev.Contribut
 			owner_id,
 			created_at,
 			updated_at,
-			is_admin
+			role
 		)
 		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id;`,
@@ -250,7 +282,7 @@ func createContributor(ctx context.Context, tx *Tx, contributor *todev.Contribut
 		contributor.OwnerID,
 		(*NullTime)(&contributor.CreatedAt),
 		(*NullTime)(&contributor.UpdatedAt),
-		contributor.IsAdmin,
+		contributor.Role,
 	).Scan(&contributor.ID)
 	if err != nil {
 		return fmt.Errorf("error inserting contributor: %w", err)
@@ -288,16 +320,26 @@ func findContributors(ctx context.Context, tx *Tx, filter todev.ContributorFilte
 	)
 	argIndex++
 
-	var sortBy string
+	args = append(args, userID, userID)
+
+	var keys []sortKey
 	switch filter.SortBy {
 	case todev.ContributorSortByUpdatedAtDesc:
-		sortBy = "c.updated_at DESC"
+		keys = []sortKey{{expr: "c.updated_at", desc: true}}
 	default:
 		argIndex++
-		sortBy = fmt.Sprintf(`CASE c.user_id WHEN $%d THEN 0 ELSE 1 END ASC, u.name ASC`, argIndex)
+		keys = []sortKey{{expr: fmt.Sprintf("CASE c.user_id WHEN $%d THEN 0 ELSE 1 END", argIndex)}, {expr: "u.name"}}
 		args = append(args, userID)
 	}
-	args = append(args, userID, userID)
+	keys = append(keys, sortKey{expr: "c.id"})
+
+	cursorID, err := findCursorID(ctx, tx, "contributors", filter.Cursor, filter.Offset)
+	if err != nil {
+		return nil, 0, err
+	} else if cursorID != 0 {
+		argIndex++
+		where, args = append(where, formatKeyset(keys, "contributors c JOIN users u ON c.user_id = u.id", argIndex)), append(args, cursorID)
+	}
 
 	stmt, err := tx.PrepareContext(ctx, `
 		SELECT
@@ -306,7 +348,7 @@ func findContributors(ctx context.Context, tx *Tx, filter todev.ContributorFilte
 			c.user_id,
 			c.created_at,
 			c.updated_at,
-			c.is_admin,
+			c.role,
 			r.user_id AS repo_user_id,
 			COUNT(*) OVER()
 		FROM contributors c
@@ -315,7 +357,7 @@ func findContributors(ctx context.Context, tx *Tx, filter todev.ContributorFilte
 		LEFT JOIN tasks_contributors tc on c.id = tc.contributor_id
 		WHERE `+strings.Join(where, " AND ")+`
 		GROUP BY c.id, r.user_id, u.name
-		ORDER BY `+sortBy+`
+		ORDER BY `+formatOrderBy(keys)+`
 		`+FormatLimitOffset(filter.Limit, filter.Offset)+`;`,
 	)
 	if err != nil {
@@ -344,12 +386,13 @@ func findContributors(ctx context.Context, tx *Tx, filter todev.ContributorFilte
 			&contributor.UserID,
 			(*NullTime)(&contributor.CreatedAt),
 			(*NullTime)(&contributor.UpdatedAt),
-			&contributor.IsAdmin,
+			&contributor.Role,
 			&repoUserID,
 			&n,
 		); err != nil {
 			return nil, 0, fmt.Errorf("error scanning: %w", err)
 		}
+		contributor.IsAdmin = contributor.Role.IsAdmin()
 		contributors = append(contributors, &contributor)
 	}
 
@@ -376,35 +419,34 @@ func updateContributor(ctx context.Context, tx *Tx, id int, upd todev.Contributo
 		return nil, fmt.Errorf("error updating contributor: %w", err)
 	} else if err = attachContributorAssociations(ctx, tx, contributor); err != nil {
 		return nil, err
-	} else if !todev.CanEditContributor(ctx, *contributor) {
-		return contributor, todev.Errorf(todev.EUNAUTHORIZED, "You don't have permission to update the contributor.")
 	}
 
+	role, wasAdmin := contributor.Role, contributor.IsAdmin
+	before := contributorActivityFields(contributor)
 	if v := upd.IsAdmin; v != nil {
-		// var event todev.Event
-		// if *v {
-		// 	event = todev.Event{
-		// 		Type: todev.EventTypeContributorSetAdmin,
-		// 		Payload: todev.ContributorSetAdmin{
-		// 			ID: contributor.ID,
-		// 		},
-		// 	}
-		// } else {
-		// 	event = todev.Event{
-		// 		Type: todev.EventTypeContributorSetAdmin,
-		// 		Payload: todev.ContributorResetAdmin{
-		// 			ID: contributor.ID,
-		// 		},
-		// 	}
-		// }
-		// defer func() {
-		// 	if err == nil {
-		// 		err = tx.conn.EventService.PublishEvent(contributor.RepoID, event)
-		// 	}
-		// }()
-		contributor.IsAdmin = *v
+		if *v {
+			role = todev.RoleAdmin
+		} else {
+			role = todev.RoleMember
+		}
+	}
+	if v := upd.Role; v != nil {
+		role = *v
+	}
+
+	// Check permissions before the no-op shortcut so that an unchanged role
+	// does not let anyone through.
+	currRole, _, err := findCurrentRole(ctx, tx, contributor.RepoID)
+	if err != nil {
+		return nil, err
+	} else if err = todev.CanUpdateContributorRole(currRole, *contributor, role); err != nil {
+		return nil, err
+	} else if role == contributor.Role {
+		return contributor, nil
 	}
 
+	contributor.Role = role
+	contributor.IsAdmin = role.IsAdmin()
 	contributor.UpdatedAt = tx.now
 
 	if err = contributor.Validate(); err != nil {
@@ -412,14 +454,33 @@ func updateContributor(ctx context.Context, tx *Tx, id int, upd todev.Contributo
 	}
 
 	_, err = tx.ExecContext(ctx, `
-		UPDATE contribotors
-		SET updated_at = $1
-		WHERE id = $2;`,
+		UPDATE contributors
+		SET role = $1, updated_at = $2
+		WHERE id = $3;`,
+		contributor.Role,
 		(*NullTime)(&contributor.UpdatedAt),
 		id,
 	)
 	if err != nil {
 		return contributor, fmt.Errorf("error updating contributor: %w", err)
+	} else if err = createUpdateActivity(ctx, tx, contributor.RepoID, todev.ActivityEntityContributor, contributor.ID, before, contributorActivityFields(contributor)); err != nil {
+		return contributor, err
+	}
+
+	// Notify other contributors only when admin privileges change.
+	if contributor.IsAdmin && !wasAdmin {
+		err = publishRepoEvent(ctx, tx, contributor.RepoID, todev.Event{
+			Type:    todev.EventTypeContributorSetAdmin,
+			Payload: todev.ContributorSetAdmin{ID: contributor.ID},
+		})
+	} else if !contributor.IsAdmin && wasAdmin {
+		err = publishRepoEvent(ctx, tx, contributor.RepoID, todev.Event{
+			Type:    todev.EventTypeContributorResetAdmin,
+			Payload: todev.ContributorResetAdmin{ID: contributor.ID},
+		})
+	}
+	if err != nil {
+		return contributor, err
 	}
 
 	return contributor, nil
@@ -431,19 +492,31 @@ func deleteContirbutor(ctx context.Context, tx *Tx, id int) error {
 		return err
 	} else if err = attachContributorAssociations(ctx, tx, contributor); err != nil {
 		return err
-	} else if err = todev.CanDeleteContributor(ctx, *contributor); err != nil {
+	}
+
+	role, _, err := findCurrentRole(ctx, tx, contributor.RepoID)
+	if err != nil {
+		return err
+	} else if err = todev.CanDeleteContributor(ctx, role, *contributor); err != nil {
 		return err
 	} else if _, err = tx.ExecContext(ctx, "DELETE FROM contributors WHERE id = $1", id); err != nil {
 		return fmt.Errorf("error deleting contributor: %w", err)
+	} else if err = publishRepoEvent(ctx, tx, contributor.RepoID, todev.Event{
+		Type: todev.EventTypeContributorDeleted,
+		Payload: todev.ContributorDeleted{
+			ID: contributor.ID,
+		},
+	}); err != nil {
+		return err
+	} else if err = createActivity(ctx, tx, &todev.Activity{
+		RepoID:     contributor.RepoID,
+		EntityType: todev.ActivityEntityContributor,
+		EntityID:   contributor.ID,
+		Action:     todev.ActivityActionDelete,
+		Before:     contributorActivityFields(contributor),
+	}); err != nil {
+		return err
 	}
-	// else if err = tx.conn.EventService.PublishEvent(contributor.RepoID, todev.Event{
-	// 	Type: todev.EventTypeContributorDeleted,
-	// 	Payload: todev.ContributorDeleted{
-	// 		ID: contributor.RepoID,
-	// 	},
-	// }); err != nil {
-	// 	return fmt.Errorf("error publishing event: %w", err)
-	// }
 
 	return nil
 }
@@ -461,3 +534,21 @@ func attachContributorAssociations(ctx context.Context, tx *Tx, contributor *tod
 	contributor.UserID = contributor.User.ID
 	return nil
 }
+
+// findCurrentRole returns the role and the contributor ID of the current user
+// within a repo. Returns an empty role if the user is not a contributor.
+func findCurrentRole(ctx context.Context, tx *Tx, repoID int) (todev.Role, int, error) {
+	var role todev.Role
+	var contributorID int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT id, role FROM contributors
+		WHERE repo_id = $1 AND user_id = $2;`,
+		repoID,
+		todev.UserIDFromContext(ctx),
+	).Scan(&contributorID, &role); err == sql.ErrNoRows {
+		return "", 0, nil
+	} else if err != nil {
+		return "", 0, fmt.Errorf("error retrieving current role: %w", err)
+	}
+	return role, contributorID, nil
+}
@@ -37,9 +37,17 @@ func TestContributorService_FindContributors(t *testing.T) {
 
 func TestContributorService_UpdateContributor(t *testing.T) {
 	t.Run("OK", func(t *testing.T) {
+		WithSchema(t, updateContributor_OK)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		WithSchema(t, func(t testing.TB, conn *postgres.Conn) {
+			updateContributor_Errors(t.(*testing.T), conn)
+		})
 	})
 }
-func updateContributor(t testing.TB, conn *postgres.Conn) {
+
+func updateContributor_OK(t testing.TB, conn *postgres.Conn) {
 	ctx := context.Background()
 	s := postgres.NewContrubutorService(conn)
 
@@ -49,18 +57,117 @@ func updateContributor(t testing.TB, conn *postgres.Conn) {
 	repo := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo"})
 
 	contributor := MustCreateContributor(t, ctx1, conn, &todev.Contributor{RepoID: repo.ID})
+	if got, want := contributor.Role, todev.RoleMember; got != want {
+		t.Fatalf("Role=%q, want %q", got, want)
+	}
 
 	isAdmin := true
 	if other, err := s.UpdateContributor(ctx0, contributor.ID, todev.ContributorUpdate{IsAdmin: &isAdmin}); err != nil {
 		t.Fatal(err)
 	} else if !other.IsAdmin {
 		t.Fatalf("IsAdmin=%v, want %v", other.IsAdmin, true)
-	} else if !reflect.DeepEqual(contributor, other) {
-		t.Fatalf("mismatch: %#v !=\n %#v", contributor, other)
-	} else if !reflect.DeepEqual(repo.Contributors[1], other) {
-		t.Fatalf("mismatch: %#v !=\n %#v", repo.Contributors[1], other)
+	} else if got, want := other.Role, todev.RoleAdmin; got != want {
+		t.Fatalf("Role=%q, want %q", got, want)
+	} else if other, err = s.FindContributorByID(ctx0, contributor.ID); err != nil {
+		t.Fatal(err)
+	} else if got, want := other.Role, todev.RoleAdmin; got != want {
+		t.Fatalf("Role=%q, want %q", got, want)
+	}
+
+	// Admins can create tasks once promoted.
+	MustCreateTask(t, ctx1, conn, &todev.Task{Description: "Do some stuff.", RepoID: repo.ID})
+}
+
+func updateContributor_Errors(t *testing.T, conn *postgres.Conn) {
+	type testData struct {
+		ctx      context.Context
+		id       int
+		role     todev.Role
+		expected error
+	}
+	ctx := context.Background()
+	s := postgres.NewContrubutorService(conn)
+
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob"})
+	_, ctx1 := MustCreateUser(t, ctx, conn, &todev.User{Name: "judy"})
+	_, ctx2 := MustCreateUser(t, ctx, conn, &todev.User{Name: "george"})
+	_, ctx3 := MustCreateUser(t, ctx, conn, &todev.User{Name: "jill"})
+	repo := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo"})
+
+	admin := MustCreateContributor(t, ctx1, conn, &todev.Contributor{RepoID: repo.ID})
+	member := MustCreateContributor(t, ctx2, conn, &todev.Contributor{RepoID: repo.ID})
+
+	role := todev.RoleAdmin
+	if _, err := s.UpdateContributor(ctx0, admin.ID, todev.ContributorUpdate{Role: &role}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]testData{
+		"ErrSelfPromotion": {
+			ctx:  ctx2,
+			id:   member.ID,
+			role: todev.RoleAdmin,
+			expected: &todev.Error{
+				Code:    todev.EUNAUTHORIZED,
+				Message: "You do not have permission to update the contributor.",
+			},
+		},
+		"ErrAdminPromotesToAdmin": {
+			ctx:  ctx1,
+			id:   member.ID,
+			role: todev.RoleAdmin,
+			expected: &todev.Error{
+				Code:    todev.EUNAUTHORIZED,
+				Message: "You do not have permission to update the contributor.",
+			},
+		},
+		"ErrMemberUnchangedRole": {
+			ctx:  ctx2,
+			id:   admin.ID,
+			role: todev.RoleAdmin,
+			expected: &todev.Error{
+				Code:    todev.EUNAUTHORIZED,
+				Message: "You do not have permission to update the contributor.",
+			},
+		},
+		"ErrNonContributorUnchangedRole": {
+			ctx:  ctx3,
+			id:   member.ID,
+			role: todev.RoleMember,
+			expected: &todev.Error{
+				Code:    todev.EUNAUTHORIZED,
+				Message: "You do not have permission to update the contributor.",
+			},
+		},
+		"ErrTransferOwnership": {
+			ctx:  ctx0,
+			id:   admin.ID,
+			role: todev.RoleOwner,
+			expected: &todev.Error{
+				Code:    todev.EUNAUTHORIZED,
+				Message: "Repo ownership cannot be changed.",
+			},
+		},
+		"ErrInvalidRole": {
+			ctx:  ctx0,
+			id:   member.ID,
+			role: "superuser",
+			expected: &todev.Error{
+				Code:    todev.EINVALID,
+				Message: "Invalid role.",
+			},
+		},
 	}
 
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := s.UpdateContributor(tt.ctx, tt.id, todev.ContributorUpdate{Role: &tt.role}); err == nil {
+				t.Fatal("expected error")
+			} else if todev.ErrorCode(err) != todev.ErrorCode(tt.expected) || todev.ErrorMessage(err) != todev.ErrorMessage(tt.expected) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
 }
 
 func TestContributorService_DeleteContributor(t *testing.T) {
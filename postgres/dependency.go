@@ -0,0 +1,331 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/saiddis/todev"
+)
+
+// taskBlockedExpr evaluates to true if the task aliased as "t" has any
+// uncompleted blocker. Trashed blockers are ignored.
+const taskBlockedExpr = `EXISTS (
+	SELECT 1 FROM task_dependencies d
+	JOIN tasks b ON d.blocker_id = b.id
+	WHERE d.task_id = t.id AND NOT b.is_completed AND b.deleted_at IS NULL
+	)`
+
+// AddDependency marks a task as blocked by another task of the same repo.
+// Returns EINVALID if the dependency would create a cycle and EUNAUTHORIZED
+// if the current user is not a repo admin.
+func (s *TaskService) AddDependency(ctx context.Context, taskID, blockerID int) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("AddDependency: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	if err = addDependency(ctx, tx, todev.TaskDependency{TaskID: taskID, BlockerID: blockerID}); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RemoveDependency removes a dependency between two tasks. Returns ENOTFOUND
+// if the dependency does not exist.
+func (s *TaskService) RemoveDependency(ctx context.Context, taskID, blockerID int) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("RemoveDependency: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	if err = removeDependency(ctx, tx, todev.TaskDependency{TaskID: taskID, BlockerID: blockerID}); err != nil {
+		return err
+	}
+	return nil
+}
+
+func addDependency(ctx context.Context, tx *Tx, dep todev.TaskDependency) error {
+	if err := dep.Validate(); err != nil {
+		return err
+	}
+
+	task, err := findTaskByID(ctx, tx, dep.TaskID)
+	if err != nil {
+		return err
+	}
+	blocker, err := findTaskByID(ctx, tx, dep.BlockerID)
+	if err != nil {
+		return err
+	} else if task.RepoID != blocker.RepoID {
+		return todev.Errorf(todev.EINVALID, "Dependent tasks must belong to the same repo.")
+	} else if err = checkCanEditTask(ctx, tx, task); err != nil {
+		return err
+	}
+
+	// Lock the repo so that concurrent additions cannot each pass the cycle
+	// check below and create a cycle together.
+	if _, err = tx.ExecContext(ctx, `SELECT id FROM repos WHERE id = $1 FOR UPDATE;`, task.RepoID); err != nil {
+		return fmt.Errorf("error locking repo: %w", err)
+	}
+
+	if ok, err := hasDependency(ctx, tx, dep); err != nil {
+		return err
+	} else if ok {
+		return nil
+	}
+
+	// The task must not already be one of the blocker's direct or indirect
+	// blockers, otherwise neither of them could ever be started.
+	var cycle bool
+	if err = tx.QueryRowContext(ctx, `
+		WITH RECURSIVE blockers(id) AS (
+			SELECT blocker_id FROM task_dependencies WHERE task_id = $1
+			UNION
+			SELECT d.blocker_id FROM task_dependencies d
+			JOIN blockers b ON d.task_id = b.id
+		)
+		SELECT EXISTS (SELECT 1 FROM blockers WHERE id = $2);`,
+		dep.BlockerID,
+		dep.TaskID,
+	).Scan(&cycle); err != nil {
+		return fmt.Errorf("error checking dependency cycle: %w", err)
+	} else if cycle {
+		return todev.Errorf(todev.EINVALID, "Dependency would create a cycle.")
+	}
+
+	ids := []int{task.ID}
+	before, err := snapshotBlocked(ctx, tx, ids)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `
+		INSERT INTO task_dependencies (task_id, blocker_id)
+		VALUES ($1, $2);`,
+		dep.TaskID,
+		dep.BlockerID,
+	); err != nil {
+		return fmt.Errorf("error inserting dependency: %w", err)
+	} else if err = publishRepoEvent(ctx, tx, task.RepoID, todev.Event{
+		Type: todev.EventTypeTaskDependencyAdded,
+		Payload: todev.TaskDependencyAdded{
+			Dependency: &dep,
+		},
+	}); err != nil {
+		return err
+	}
+	return publishBlockedChanges(ctx, tx, task.RepoID, ids, before)
+}
+
+func removeDependency(ctx context.Context, tx *Tx, dep todev.TaskDependency) error {
+	task, err := findTaskByID(ctx, tx, dep.TaskID)
+	if err != nil {
+		return err
+	} else if err = checkCanEditTask(ctx, tx, task); err != nil {
+		return err
+	}
+
+	if ok, err := hasDependency(ctx, tx, dep); err != nil {
+		return err
+	} else if !ok {
+		return todev.Errorf(todev.ENOTFOUND, "Dependency not found.")
+	}
+
+	ids := []int{task.ID}
+	before, err := snapshotBlocked(ctx, tx, ids)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `
+		DELETE FROM task_dependencies
+		WHERE task_id = $1 AND blocker_id = $2;`,
+		dep.TaskID,
+		dep.BlockerID,
+	); err != nil {
+		return fmt.Errorf("error deleting dependency: %w", err)
+	} else if err = publishRepoEvent(ctx, tx, task.RepoID, todev.Event{
+		Type: todev.EventTypeTaskDependencyRemoved,
+		Payload: todev.TaskDependencyRemoved{
+			Dependency: &dep,
+		},
+	}); err != nil {
+		return err
+	}
+	return publishBlockedChanges(ctx, tx, task.RepoID, ids, before)
+}
+
+func hasDependency(ctx context.Context, tx *Tx, dep todev.TaskDependency) (bool, error) {
+	var ok bool
+	if err := tx.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM task_dependencies
+			WHERE task_id = $1 AND blocker_id = $2
+		);`,
+		dep.TaskID,
+		dep.BlockerID,
+	).Scan(&ok); err != nil {
+		return false, fmt.Errorf("error retrieving dependency: %w", err)
+	}
+	return ok, nil
+}
+
+// findTaskDependencies returns the dependency graph of a repo. Dependencies
+// of trashed tasks are left out.
+func findTaskDependencies(ctx context.Context, tx *Tx, repoID int) ([]*todev.TaskDependency, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT d.task_id, d.blocker_id
+		FROM task_dependencies d
+		JOIN tasks t ON d.task_id = t.id
+		JOIN tasks b ON d.blocker_id = b.id
+		WHERE t.repo_id = $1 AND t.deleted_at IS NULL AND b.deleted_at IS NULL
+		ORDER BY d.task_id ASC, d.blocker_id ASC;`,
+		repoID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving dependencies: %w", err)
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("error closing rows: %v", err)
+		}
+	}()
+
+	deps := make([]*todev.TaskDependency, 0)
+	for rows.Next() {
+		var dep todev.TaskDependency
+		if err = rows.Scan(&dep.TaskID, &dep.BlockerID); err != nil {
+			return nil, fmt.Errorf("error scanning: %w", err)
+		}
+		deps = append(deps, &dep)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return deps, nil
+}
+
+// findTaskIDs returns the IDs of the tasks matching a single column of the
+// task_dependencies table, e.g. the blockers or the dependents of a task.
+func findTaskIDs(ctx context.Context, tx *Tx, query string, id int) ([]int, error) {
+	rows, err := tx.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving task IDs: %w", err)
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("error closing rows: %v", err)
+		}
+	}()
+
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return ids, nil
+}
+
+// findBlockerIDs returns the IDs of the live tasks blocking a task.
+func findBlockerIDs(ctx context.Context, tx *Tx, taskID int) ([]int, error) {
+	return findTaskIDs(ctx, tx, `
+		SELECT d.blocker_id FROM task_dependencies d
+		JOIN tasks b ON d.blocker_id = b.id
+		WHERE d.task_id = $1 AND b.deleted_at IS NULL
+		ORDER BY d.blocker_id ASC;`,
+		taskID,
+	)
+}
+
+// findDependentIDs returns the IDs of the live tasks blocked by a task.
+func findDependentIDs(ctx context.Context, tx *Tx, blockerID int) ([]int, error) {
+	return findTaskIDs(ctx, tx, `
+		SELECT d.task_id FROM task_dependencies d
+		JOIN tasks t ON d.task_id = t.id
+		WHERE d.blocker_id = $1 AND t.deleted_at IS NULL
+		ORDER BY d.task_id ASC;`,
+		blockerID,
+	)
+}
+
+// snapshotBlocked returns whether each of the tasks is currently blocked. It
+// is used along with publishBlockedChanges() to notify about tasks whose
+// blockers were completed, reopened or removed.
+func snapshotBlocked(ctx context.Context, tx *Tx, ids []int) ([]bool, error) {
+	blocked := make([]bool, len(ids))
+	for i, id := range ids {
+		if err := tx.QueryRowContext(ctx, `
+			SELECT `+taskBlockedExpr+`
+			FROM tasks t
+			WHERE t.id = $1;`,
+			id,
+		).Scan(&blocked[i]); err != nil {
+			return nil, fmt.Errorf("error checking blocked task: %w", err)
+		}
+	}
+	return blocked, nil
+}
+
+// publishBlockedChanges publishes a task:blocked or task:unblocked event for
+// every task whose blocked status differs from the snapshot.
+func publishBlockedChanges(ctx context.Context, tx *Tx, repoID int, ids []int, before []bool) error {
+	after, err := snapshotBlocked(ctx, tx, ids)
+	if err != nil {
+		return err
+	}
+
+	for i, id := range ids {
+		if before[i] == after[i] {
+			continue
+		}
+
+		eventType := todev.EventTypeTaskUnblocked
+		if after[i] {
+			eventType = todev.EventTypeTaskBlocked
+		}
+		if err = publishRepoEvent(ctx, tx, repoID, todev.Event{
+			Type: eventType,
+			Payload: todev.TaskBlockedChanged{
+				ID: id,
+			},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
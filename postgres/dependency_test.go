@@ -0,0 +1,188 @@
+package postgres_test
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/postgres"
+)
+
+func TestTaskService_AddDependency(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		WithSchema(t, addDependency_OK)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		WithSchema(t, addDependency_Errors)
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		WithSchema(t, addDependency_Concurrent)
+	})
+}
+
+func TestTaskService_RemoveDependency(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		WithSchema(t, removeDependency_OK)
+	})
+}
+
+func addDependency_OK(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewTaskService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	user1, ctx1 := MustCreateUser(t, ctx, conn, &todev.User{Name: "judy", Email: "judy@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	MustCreateContributor(t, ctx1, conn, &todev.Contributor{RepoID: repo0.ID})
+
+	task0 := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Design.", RepoID: repo0.ID})
+	task1 := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Build.", RepoID: repo0.ID})
+	MustAddDependency(t, ctx0, conn, task1.ID, task0.ID)
+
+	if other, err := s.FindTaskByID(ctx0, task1.ID); err != nil {
+		t.Fatal(err)
+	} else if !other.IsBlocked {
+		t.Fatal("expected task to be blocked")
+	} else if !slices.Equal(other.BlockedByIDs, []int{task0.ID}) {
+		t.Fatalf("BlockedByIDs=%v, want %v", other.BlockedByIDs, []int{task0.ID})
+	}
+
+	// The repo exposes the dependency graph.
+	if repo, err := postgres.NewRepoService(conn).FindRepoByID(ctx0, repo0.ID); err != nil {
+		t.Fatal(err)
+	} else if got, want := len(repo.Dependencies), 1; got != want {
+		t.Fatalf("len(Dependencies)=%d, want %d", got, want)
+	} else if got, want := *repo.Dependencies[0], (todev.TaskDependency{TaskID: task1.ID, BlockerID: task0.ID}); got != want {
+		t.Fatalf("Dependency=%#v, want %#v", got, want)
+	}
+
+	isBlocked, isReady := true, true
+	if tasks, _, err := s.FindTasks(ctx0, todev.TaskFilter{RepoID: &repo0.ID, IsBlocked: &isBlocked}); err != nil {
+		t.Fatal(err)
+	} else if len(tasks) != 1 || tasks[0].ID != task1.ID {
+		t.Fatalf("unexpected blocked tasks: %#v", tasks)
+	}
+	if tasks, _, err := s.FindTasks(ctx0, todev.TaskFilter{RepoID: &repo0.ID, IsReady: &isReady}); err != nil {
+		t.Fatal(err)
+	} else if len(tasks) != 1 || tasks[0].ID != task0.ID {
+		t.Fatalf("unexpected ready tasks: %#v", tasks)
+	}
+
+	// Completing the blocker unblocks its dependent.
+	events := RecordEvents(conn)
+	MustUpdateTask(t, ctx0, conn, task0.ID, todev.TaskUpdate{SetStatus: &repo0.TerminalState().ID})
+
+	if other, err := s.FindTaskByID(ctx0, task1.ID); err != nil {
+		t.Fatal(err)
+	} else if other.IsBlocked {
+		t.Fatal("expected task to be unblocked")
+	} else if !slices.ContainsFunc(*events, func(e PublishedEvent) bool {
+		return e.UserID == user1.ID && e.Event.Type == todev.EventTypeTaskUnblocked
+	}) {
+		t.Fatalf("expected unblocked event: %#v", *events)
+	}
+}
+
+func addDependency_Errors(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewTaskService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	_, ctx1 := MustCreateUser(t, ctx, conn, &todev.User{Name: "judy", Email: "judy@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	repo1 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo2"})
+	MustCreateContributor(t, ctx1, conn, &todev.Contributor{RepoID: repo0.ID})
+
+	task0 := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "first", RepoID: repo0.ID})
+	task1 := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "second", RepoID: repo0.ID})
+	task2 := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "third", RepoID: repo0.ID})
+	other := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "other", RepoID: repo1.ID})
+
+	MustAddDependency(t, ctx0, conn, task1.ID, task0.ID)
+	MustAddDependency(t, ctx0, conn, task2.ID, task1.ID)
+
+	// Direct and indirect cycles are rejected.
+	if err := s.AddDependency(ctx0, task0.ID, task0.ID); todev.ErrorCode(err) != todev.EINVALID {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := s.AddDependency(ctx0, task0.ID, task1.ID); todev.ErrorCode(err) != todev.EINVALID {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err := s.AddDependency(ctx0, task0.ID, task2.ID); todev.ErrorCode(err) != todev.EINVALID {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Tasks of different repos cannot depend on each other.
+	if err := s.AddDependency(ctx0, task0.ID, other.ID); todev.ErrorCode(err) != todev.EINVALID {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Only admins can add dependencies.
+	if err := s.AddDependency(ctx1, task2.ID, task0.ID); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func removeDependency_OK(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewTaskService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+
+	task0 := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "first", RepoID: repo0.ID})
+	task1 := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "second", RepoID: repo0.ID})
+	MustAddDependency(t, ctx0, conn, task1.ID, task0.ID)
+
+	if err := s.RemoveDependency(ctx0, task1.ID, task0.ID); err != nil {
+		t.Fatal(err)
+	} else if other, err := s.FindTaskByID(ctx0, task1.ID); err != nil {
+		t.Fatal(err)
+	} else if other.IsBlocked || len(other.BlockedByIDs) != 0 {
+		t.Fatalf("IsBlocked=%v, BlockedByIDs=%v", other.IsBlocked, other.BlockedByIDs)
+	}
+
+	if err := s.RemoveDependency(ctx0, task1.ID, task0.ID); todev.ErrorCode(err) != todev.ENOTFOUND {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// MustAddDependency blocks a task by another task. Fatal on error.
+func MustAddDependency(tb testing.TB, ctx context.Context, conn *postgres.Conn, taskID, blockerID int) {
+	tb.Helper()
+	if err := postgres.NewTaskService(conn).AddDependency(ctx, taskID, blockerID); err != nil {
+		tb.Fatalf("MustAddDependency: %v", err)
+	}
+}
+
+// Ensure concurrent opposite dependencies cannot both be added.
+func addDependency_Concurrent(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewTaskService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	task0 := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Design.", RepoID: repo0.ID})
+	task1 := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Build.", RepoID: repo0.ID})
+
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); errs[0] = s.AddDependency(ctx0, task0.ID, task1.ID) }()
+	go func() { defer wg.Done(); errs[1] = s.AddDependency(ctx0, task1.ID, task0.ID) }()
+	wg.Wait()
+
+	var added int
+	for _, err := range errs {
+		if err == nil {
+			added++
+		} else if todev.ErrorCode(err) != todev.EINVALID {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if got, want := added, 1; got != want {
+		t.Fatalf("added=%d, want %d", got, want)
+	}
+}
@@ -2,67 +2,380 @@ package postgres
 
 import (
 	"context"
-	"errors"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
+	"log"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/saiddis/todev"
 )
 
+// EventChannel is the default channel used to deliver events between instances.
+const EventChannel = "todev_events"
+
+// Listener reconnection intervals.
+const (
+	MinListenerReconnectInterval = 10 * time.Second
+	MaxListenerReconnectInterval = time.Minute
+)
+
+var _ todev.EventService = (*EventService)(nil)
+
+// EventService represents a service for delivering events across multiple
+// instances of the application sharing the same database.
+//
+// Events are delivered to the local event service, which manages the
+// subscriptions of the current process, and then announced with NOTIFY. Other
+// instances receive the announcement on a dedicated LISTEN connection. As
+// notification payloads are limited to 8000 bytes, logged repo events are
+// only announced by key and loaded from the event log by the receivers.
 type EventService struct {
-	Repos map[int]Subscribtion
+	conn     *Conn
+	local    todev.EventService
+	listener *pq.Listener
+
+	// Random ID used to ignore the notifications sent by this instance.
+	instance string
+
+	ctx    context.Context
+	cancel func()
+	done   chan struct{}
+
+	// Name of the notification channel. Defaults to EventChannel.
+	Channel string
+}
+
+// NewEventService returns a new instance of EventService that fans out
+// received events through local.
+func NewEventService(conn *Conn, local todev.EventService) *EventService {
+	s := &EventService{
+		conn:    conn,
+		local:   local,
+		done:    make(chan struct{}),
+		Channel: EventChannel,
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	return s
 }
 
-func NewEventService() *EventService {
-	return &EventService{
-		Repos: map[int]Subscribtion{},
+// Open starts listening for events on the notification channel.
+func (s *EventService) Open() error {
+	if s.conn.DSN == "" {
+		return fmt.Errorf("dsn required")
+	}
+
+	id := make([]byte, 8)
+	if _, err := io.ReadFull(rand.Reader, id); err != nil {
+		return fmt.Errorf("error generating instance id: %w", err)
 	}
+	s.instance = hex.EncodeToString(id)
+
+	s.listener = pq.NewListener(s.conn.DSN, MinListenerReconnectInterval, MaxListenerReconnectInterval, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("event listener: %v", err)
+		}
+	})
+	if err := s.listener.Listen(s.Channel); err != nil {
+		s.listener.Close()
+		s.listener = nil
+		return fmt.Errorf("error listening on channel %q: %w", s.Channel, err)
+	}
+
+	go func() { defer close(s.done); s.listen() }()
+
+	return nil
 }
 
-func (s EventService) PublishEvent(repoID int, event todev.Event) error {
-	if sub, ok := s.Repos[repoID]; ok {
-		sub.Event <- event
+// Close stops listening for events.
+func (s *EventService) Close() error {
+	s.cancel()
+	if s.listener == nil {
 		return nil
 	}
-	return fmt.Errorf("no repos with id: %d", repoID)
+
+	err := s.listener.Close()
+	<-s.done
+	return err
+}
+
+// listen publishes received notifications to the local event service until
+// the service is closed.
+func (s *EventService) listen() {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case n, ok := <-s.listener.Notify:
+			if !ok {
+				return
+			} else if n == nil {
+				// Connection was re-established so events may have been lost.
+				log.Printf("event listener reconnected")
+				continue
+			}
+
+			var msg eventMessage
+			if err := json.Unmarshal([]byte(n.Extra), &msg); err != nil {
+				log.Printf("error decoding event: %v", err)
+				continue
+			} else if msg.Instance == s.instance {
+				// Already delivered locally when published.
+				continue
+			}
+
+			payload := msg.Payload
+			if payload == nil {
+				var err error
+				if payload, err = s.findEventPayload(msg.RepoID, msg.Seq); err != nil {
+					log.Printf("error retrieving event: repo=%d seq=%d err=%v", msg.RepoID, msg.Seq, err)
+					continue
+				}
+			}
+			s.local.PublishEvent(msg.UserID, todev.Event{
+				Type:    msg.Type,
+				RepoID:  msg.RepoID,
+				Seq:     msg.Seq,
+				Payload: payload,
+			})
+		}
+	}
 }
 
-func (s EventService) Subscribe(ctx context.Context) (todev.Subscription, error) {
-	sub := Subscribtion{
-		Event: make(chan todev.Event),
-		done:  make(chan struct{}),
+// findEventPayload loads the payload of a logged repo event.
+func (s *EventService) findEventPayload(repoID, seq int) (json.RawMessage, error) {
+	var payload []byte
+	if err := s.conn.DB.QueryRowContext(s.ctx, `
+		SELECT payload FROM events WHERE repo_id = $1 AND seq = $2;`,
+		repoID,
+		seq,
+	).Scan(&payload); err != nil {
+		return nil, err
 	}
+	return json.RawMessage(payload), nil
+}
+
+// PublishEvent publishes an event to a user's subscriptions on every instance.
+//
+// The event is delivered to the current instance first so it does not depend
+// on the notification. Logged repo events are announced without their payload
+// which other instances load from the event log. Publishing is best effort, so
+// errors are only logged.
+func (s *EventService) PublishEvent(userID int, event todev.Event) {
+	s.local.PublishEvent(userID, event)
 
-	if repoID, ok := ctx.Value("repoID").(int); ok {
-		s.Repos[repoID] = sub
-		return sub, nil
+	msg := eventMessage{
+		Instance: s.instance,
+		UserID:   userID,
+		Type:     event.Type,
+		RepoID:   event.RepoID,
+		Seq:      event.Seq,
 	}
+	if event.Seq == 0 {
+		payload, err := json.Marshal(event.Payload)
+		if err != nil {
+			log.Printf("error encoding event payload: %v", err)
+			return
+		}
+		msg.Payload = payload
+	}
+
+	buf, err := json.Marshal(msg)
+	if err != nil {
+		log.Printf("error encoding event: %v", err)
+		return
+	}
+
+	if _, err = s.conn.DB.ExecContext(s.ctx, `SELECT pg_notify($1, $2);`, s.Channel, string(buf)); err != nil {
+		log.Printf("error publishing event: %v", err)
+	}
+}
+
+// Subscribe creates a new subscription for the currently logged in user on the
+// local event service.
+func (s *EventService) Subscribe(ctx context.Context, filter todev.SubscriptionFilter) (todev.Subscription, error) {
+	return s.local.Subscribe(ctx, filter)
+}
+
+// eventMessage represents the payload of an event notification.
+type eventMessage struct {
+	Instance string `json:"instance"`
+	UserID   int    `json:"userID"`
+	Type     string `json:"type"`
+	RepoID   int    `json:"repoID,omitempty"`
+	Seq      int    `json:"seq,omitempty"`
 
-	return Subscribtion{}, errors.New("failed to convert value from context")
+	// Payload is kept encoded so that it is forwarded as is. Only set for
+	// events missing from the event log.
+	Payload json.RawMessage `json:"payload,omitempty"`
 }
 
-func (s *EventService) GetSubscribtion(repoID int) (todev.Subscription, bool) {
-	sub, ok := s.Repos[repoID]
-	return sub, ok
+var _ todev.EventLogService = (*EventLogService)(nil)
+
+// EventLogService represents a service for replaying past repo events.
+type EventLogService struct {
+	conn *Conn
 }
 
-type Subscribtion struct {
-	Event chan todev.Event
-	done  chan struct{}
+// NewEventLogService returns a new instance of EventLogService.
+func NewEventLogService(conn *Conn) *EventLogService {
+	return &EventLogService{conn: conn}
 }
 
-func NewSubscribtion() *Subscribtion {
-	return &Subscribtion{}
+// FindEvents retrieves the events of a repo with a sequence number greater
+// than since in sequence order. Returns ENOTFOUND if the repo does not exist
+// or the current user does not have permission to view it. Returns ECONFLICT
+// if some of the requested events have already been purged.
+func (s *EventLogService) FindEvents(ctx context.Context, repoID, since int) ([]todev.Event, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("FindEvents: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	repo, err := findRepoByID(ctx, tx, repoID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := findEvents(ctx, tx, repoID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensure no events between the cursor and the first returned event have
+	// been purged. Otherwise the caller cannot catch up by replaying.
+	if since > repo.EventSeq ||
+		(len(events) == 0 && since < repo.EventSeq) ||
+		(len(events) > 0 && events[0].Seq != since+1) {
+		return nil, todev.Errorf(todev.ECONFLICT, "Events since %d are no longer available.", since)
+	}
+
+	return events, nil
 }
 
-func (s Subscribtion) C() <-chan todev.Event {
-	return s.Event
+// PurgeEvents permanently deletes all events created before the given time.
+// Returns the number of deleted events.
+func (s *EventLogService) PurgeEvents(ctx context.Context, before time.Time) (int, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("PurgeEvents: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	n, err := purgeEvents(ctx, tx, before)
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
 }
 
-func (s Subscribtion) Done() chan struct{} {
-	return s.done
+// createEvent assigns the next sequence number of a repo to event and saves it
+// to the repo's event log.
+func createEvent(ctx context.Context, tx *Tx, repoID int, event *todev.Event) error {
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return fmt.Errorf("error encoding event payload: %w", err)
+	}
+
+	// Incrementing the counter locks the repo row until the transaction ends
+	// so concurrent events of a repo are always numbered in commit order.
+	event.RepoID = repoID
+	if err = tx.QueryRowContext(ctx, `
+		UPDATE repos SET event_seq = event_seq + 1
+		WHERE id = $1
+		RETURNING event_seq;`,
+		repoID,
+	).Scan(&event.Seq); err != nil {
+		return fmt.Errorf("error incrementing event sequence: %w", err)
+	}
+
+	if _, err = tx.ExecContext(ctx, `
+		INSERT INTO events (repo_id, seq, type, payload, created_at)
+		VALUES ($1, $2, $3, $4, $5);`,
+		event.RepoID,
+		event.Seq,
+		event.Type,
+		string(payload),
+		(*NullTime)(&tx.now),
+	); err != nil {
+		return fmt.Errorf("error inserting event: %w", err)
+	}
+
+	return nil
 }
 
-func (s Subscribtion) Close() {
-	close(s.Event)
-	close(s.done)
+func findEvents(ctx context.Context, tx *Tx, repoID, since int) ([]todev.Event, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT repo_id, seq, type, payload
+		FROM events
+		WHERE repo_id = $1 AND seq > $2
+		ORDER BY seq ASC;`,
+		repoID,
+		since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving events: %w", err)
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("error closing rows: %v", err)
+		}
+	}()
+
+	events := make([]todev.Event, 0)
+	for rows.Next() {
+		var event todev.Event
+		var payload []byte
+		if err = rows.Scan(&event.RepoID, &event.Seq, &event.Type, &payload); err != nil {
+			return nil, fmt.Errorf("error scanning: %w", err)
+		}
+		event.Payload = json.RawMessage(payload)
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return events, nil
+}
+
+func purgeEvents(ctx context.Context, tx *Tx, before time.Time) (int, error) {
+	result, err := tx.ExecContext(ctx, `DELETE FROM events WHERE created_at < $1;`, (*NullTime)(&before))
+	if err != nil {
+		return 0, fmt.Errorf("error deleting events: %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error retrieving deleted events count: %w", err)
+	}
+	return int(n), nil
 }
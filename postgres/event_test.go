@@ -0,0 +1,222 @@
+package postgres_test
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/inmem"
+	"github.com/saiddis/todev/postgres"
+)
+
+func TestEventService(t *testing.T) {
+	t.Run("AcrossInstances", func(t *testing.T) {
+		WithSchema(t, eventService_AcrossInstances)
+	})
+
+	t.Run("LargePayload", func(t *testing.T) {
+		WithSchema(t, eventService_LargePayload)
+	})
+}
+
+func TestEventLogService_FindEvents(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		WithSchema(t, findEvents_OK)
+	})
+
+	t.Run("ErrExpired", func(t *testing.T) {
+		WithSchema(t, findEvents_ErrExpired)
+	})
+}
+
+func findEvents_OK(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewEventLogService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	repo := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	MustCreateTask(t, ctx0, conn, &todev.Task{Description: "First.", RepoID: repo.ID})
+	MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Second.", RepoID: repo.ID})
+
+	seq := MustFindRepoByID(t, ctx0, conn, repo.ID).EventSeq
+	if seq < 2 {
+		t.Fatalf("EventSeq=%d, want at least 2", seq)
+	}
+
+	// Only events after the cursor are returned, in sequence order.
+	if events, err := s.FindEvents(ctx0, repo.ID, seq-2); err != nil {
+		t.Fatal(err)
+	} else if got, want := len(events), 2; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	} else if events[0].Seq != seq-1 || events[1].Seq != seq {
+		t.Fatalf("unexpected sequence: %d, %d", events[0].Seq, events[1].Seq)
+	} else if got, want := events[1].Type, todev.EventTypeTaskAdded; got != want {
+		t.Fatalf("Type=%q, want %q", got, want)
+	}
+
+	// An up to date cursor returns no events.
+	if events, err := s.FindEvents(ctx0, repo.ID, seq); err != nil {
+		t.Fatal(err)
+	} else if len(events) != 0 {
+		t.Fatalf("len=%d, want 0", len(events))
+	}
+}
+
+func findEvents_ErrExpired(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewEventLogService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	repo := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	MustCreateTask(t, ctx0, conn, &todev.Task{Description: "First.", RepoID: repo.ID})
+
+	if n, err := s.PurgeEvents(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	} else if n == 0 {
+		t.Fatal("expected purged events")
+	}
+
+	if _, err := s.FindEvents(ctx0, repo.ID, 0); todev.ErrorCode(err) != todev.ECONFLICT {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+}
+
+func eventService_AcrossInstances(t testing.TB, conn *postgres.Conn) {
+	// Use a unique channel so parallel tests do not receive each other's events.
+	id := make([]byte, 4)
+	rand.Read(id)
+	channel := "test_events_" + hex.EncodeToString(id)
+
+	// Two services sharing a database represent two application instances.
+	s0 := MustOpenEventService(t, conn, channel)
+	defer MustCloseEventService(t, s0)
+	s1 := MustOpenEventService(t, conn, channel)
+	defer MustCloseEventService(t, s1)
+
+	ctx := context.Background()
+	ctx0 := todev.NewContextWithUser(ctx, &todev.User{ID: 1})
+	ctx1 := todev.NewContextWithUser(ctx, &todev.User{ID: 2})
+
+	sub0, err := s1.Subscribe(ctx0, todev.SubscriptionFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub0.Close()
+
+	sub1, err := s1.Subscribe(ctx1, todev.SubscriptionFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub1.Close()
+
+	s0.PublishEvent(1, todev.Event{
+		Type:    todev.EventTypeTaskStatusChanged,
+		Payload: todev.TaskStatusChanged{ID: 100},
+	})
+
+	select {
+	case event := <-sub0.C():
+		var payload todev.TaskStatusChanged
+		if got, want := event.Type, todev.EventTypeTaskStatusChanged; got != want {
+			t.Fatalf("Type=%q, want %q", got, want)
+		} else if err := json.Unmarshal(event.Payload.(json.RawMessage), &payload); err != nil {
+			t.Fatal(err)
+		} else if got, want := payload.ID, 100; got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected event")
+	}
+
+	// Ensure second user does not recieve event.
+	select {
+	case <-sub1.C():
+		t.Fatal("expected no event")
+	default:
+	}
+}
+
+// Ensure logged events larger than the notification limit are delivered on
+// every instance.
+func eventService_LargePayload(t testing.TB, conn *postgres.Conn) {
+	id := make([]byte, 4)
+	rand.Read(id)
+	channel := "test_events_" + hex.EncodeToString(id)
+
+	s0 := MustOpenEventService(t, conn, channel)
+	defer MustCloseEventService(t, s0)
+	s1 := MustOpenEventService(t, conn, channel)
+	defer MustCloseEventService(t, s1)
+
+	// Events of the connection are published through the first instance.
+	conn.EventService = s0
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	_, ctx1 := MustCreateUser(t, ctx, conn, &todev.User{Name: "judy", Email: "judy@gmail.com"})
+	repo := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	MustCreateContributor(t, ctx1, conn, &todev.Contributor{RepoID: repo.ID})
+	task := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "First.", RepoID: repo.ID})
+
+	local, err := s0.Subscribe(ctx1, todev.SubscriptionFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer local.Close()
+
+	remote, err := s1.Subscribe(ctx1, todev.SubscriptionFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer remote.Close()
+
+	// Escaped angle brackets take six bytes each once encoded.
+	body := strings.Repeat("<", 2000)
+	MustCreateComment(t, ctx0, conn, &todev.Comment{TaskID: task.ID, Body: body})
+
+	for _, sub := range []todev.Subscription{local, remote} {
+		select {
+		case event := <-sub.C():
+			buf, err := json.Marshal(event.Payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var payload todev.CommentAdded
+			if got, want := event.Type, todev.EventTypeCommentAdded; got != want {
+				t.Fatalf("Type=%q, want %q", got, want)
+			} else if err = json.Unmarshal(buf, &payload); err != nil {
+				t.Fatal(err)
+			} else if got, want := payload.Comment.Body, body; got != want {
+				t.Fatalf("Body=%q, want %q", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected event")
+		}
+	}
+}
+
+// MustOpenEventService returns an open event service listening on the given
+// channel. Fatal on error.
+func MustOpenEventService(tb testing.TB, conn *postgres.Conn, channel string) *postgres.EventService {
+	tb.Helper()
+	s := postgres.NewEventService(conn, inmem.NewEventService())
+	s.Channel = channel
+	if err := s.Open(); err != nil {
+		tb.Fatal(err)
+	}
+	return s
+}
+
+// MustCloseEventService closes the event service. Fatal on error.
+func MustCloseEventService(tb testing.TB, s *postgres.EventService) {
+	tb.Helper()
+	if err := s.Close(); err != nil {
+		tb.Fatal(err)
+	}
+}
@@ -0,0 +1,597 @@
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/saiddis/todev"
+)
+
+// JoinRepo creates a new contributor for the current user through the
+// invitation with the given code. Returns ENOTFOUND if the code is unknown,
+// EUNAUTHORIZED if the invitation is revoked, expired or used up and
+// ECONFLICT if the user already contributes to the repo or the last use was
+// taken by a concurrent join.
+func (s *ContributorService) JoinRepo(ctx context.Context, code string) (*todev.Contributor, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("JoinRepo: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	contributor, err := joinRepo(ctx, tx, code)
+	if err != nil {
+		return nil, err
+	}
+	return contributor, nil
+}
+
+// FindInvitationByCode retrieves an invitation by code along with the name of
+// its repo. Anyone knowing the code can look it up. Returns ENOTFOUND if the
+// code is unknown and EUNAUTHORIZED if the invitation cannot be used.
+func (s *ContributorService) FindInvitationByCode(ctx context.Context, code string) (*todev.Invitation, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("FindInvitationByCode: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	inv, err := findInvitationByCode(ctx, tx, code, false)
+	if err != nil {
+		return nil, err
+	} else if err = inv.CanJoin(tx.now); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// FindInvitations retrieves a list of matching invitations based on filter,
+// newest first. Only returns invitations of repos the current user is an
+// admin of.
+func (s *ContributorService) FindInvitations(ctx context.Context, filter todev.InvitationFilter) ([]*todev.Invitation, int, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("FindInvitations: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	invitations, n, err := findInvitations(ctx, tx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return invitations, n, nil
+}
+
+// CreateInvitation creates a new invitation to a repo. Returns EUNAUTHORIZED
+// if the current user is not a repo admin outranking the invitation role.
+func (s *ContributorService) CreateInvitation(ctx context.Context, inv *todev.Invitation) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("CreateInvitation: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	if err = createInvitation(ctx, tx, inv); err != nil {
+		return err
+	}
+	return nil
+}
+
+// RotateInvitation replaces the code of an invitation by ID. Links shared with
+// the previous code stop working. Returns EUNAUTHORIZED if the current user
+// cannot manage the invitation.
+func (s *ContributorService) RotateInvitation(ctx context.Context, id int) (*todev.Invitation, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("RotateInvitation: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	inv, err := rotateInvitation(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+// RevokeInvitation revokes an invitation by ID. Returns EUNAUTHORIZED if the
+// current user cannot manage the invitation.
+func (s *ContributorService) RevokeInvitation(ctx context.Context, id int) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("RevokeInvitation: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	if err = revokeInvitation(ctx, tx, id); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FindInvitationUses retrieves the users that joined through an invitation,
+// newest first. Returns ENOTFOUND if the current user cannot view the
+// invitation.
+func (s *ContributorService) FindInvitationUses(ctx context.Context, invitationID int) ([]*todev.InvitationUse, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("FindInvitationUses: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	if _, err = findInvitationByID(ctx, tx, invitationID); err != nil {
+		return nil, err
+	}
+
+	uses, err := findInvitationUses(ctx, tx, invitationID)
+	if err != nil {
+		return nil, err
+	}
+	return uses, nil
+}
+
+// joinRepo adds the current user to the repo of the invitation with the given
+// code and logs the use of the invitation. Uses are counted by a conditional
+// update of the invitation row so that concurrent joins cannot exceed its max
+// uses: a join waiting on the row re-checks the limit against the committed
+// count.
+func joinRepo(ctx context.Context, tx *Tx, code string) (*todev.Contributor, error) {
+	userID := todev.UserIDFromContext(ctx)
+	if userID == 0 {
+		return nil, todev.Errorf(todev.EUNAUTHORIZED, "You must be logged in to join a repo.")
+	}
+
+	inv, err := findInvitationByCode(ctx, tx, code, true)
+	if err != nil {
+		return nil, err
+	} else if err = inv.CanJoin(tx.now); err != nil {
+		return nil, err
+	}
+
+	if role, _, err := findCurrentRole(ctx, tx, inv.RepoID); err != nil {
+		return nil, err
+	} else if role != "" {
+		return nil, todev.Errorf(todev.ECONFLICT, "You are already contributing to this repo.")
+	}
+
+	if result, err := tx.ExecContext(ctx, `
+		UPDATE invitations SET uses = uses + 1
+		WHERE id = $1 AND (max_uses = 0 OR uses < max_uses);`,
+		inv.ID,
+	); err != nil {
+		return nil, fmt.Errorf("error updating invitation uses: %w", err)
+	} else if n, err := result.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("error updating invitation uses: %w", err)
+	} else if n == 0 {
+		return nil, todev.Errorf(todev.ECONFLICT, "Invitation has been used up.")
+	}
+
+	contributor := &todev.Contributor{
+		RepoID: inv.RepoID,
+		UserID: userID,
+		Role:   inv.Role,
+	}
+	if err = addContributor(ctx, tx, contributor, inv.ID); err != nil {
+		return nil, err
+	}
+
+	if _, err = tx.ExecContext(ctx, `
+		INSERT INTO invitation_uses (invitation_id, user_id, contributor_id, created_at)
+		VALUES ($1, $2, $3, $4);`,
+		inv.ID,
+		userID,
+		contributor.ID,
+		(*NullTime)(&tx.now),
+	); err != nil {
+		return nil, fmt.Errorf("error inserting invitation use: %w", err)
+	}
+
+	return contributor, nil
+}
+
+// generateInvitationCode returns a new random invitation code.
+func generateInvitationCode() (string, error) {
+	code := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, code); err != nil {
+		return "", fmt.Errorf("error generating invitation code: %w", err)
+	}
+	return hex.EncodeToString(code), nil
+}
+
+func createInvitation(ctx context.Context, tx *Tx, inv *todev.Invitation) (err error) {
+	// Invitations grant the member role unless set otherwise.
+	if inv.Role == "" {
+		inv.Role = todev.RoleMember
+	}
+	if err = inv.Validate(); err != nil {
+		return err
+	}
+
+	if role, _, err := findCurrentRole(ctx, tx, inv.RepoID); err != nil {
+		return err
+	} else if role == "" {
+		return todev.Errorf(todev.ENOTFOUND, "Repo not found.")
+	} else if err = todev.CanManageInvitation(role, *inv); err != nil {
+		return err
+	}
+
+	if inv.Code, err = generateInvitationCode(); err != nil {
+		return err
+	}
+	inv.UserID = todev.UserIDFromContext(ctx)
+	inv.Uses = 0
+	inv.RevokedAt = time.Time{}
+	inv.CreatedAt = tx.now
+	inv.UpdatedAt = inv.CreatedAt
+
+	if err = tx.QueryRowContext(ctx, `
+		INSERT INTO invitations (
+			repo_id,
+			user_id,
+			code,
+			role,
+			max_uses,
+			expires_at,
+			created_at,
+			updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id;`,
+		inv.RepoID,
+		inv.UserID,
+		inv.Code,
+		inv.Role,
+		inv.MaxUses,
+		(*NullTime)(&inv.ExpiresAt),
+		(*NullTime)(&inv.CreatedAt),
+		(*NullTime)(&inv.UpdatedAt),
+	).Scan(&inv.ID); err != nil {
+		return fmt.Errorf("error inserting invitation: %w", err)
+	}
+	return nil
+}
+
+// findInvitationByCode retrieves an invitation by code along with the name of
+// its repo, regardless of repo membership. The invitation row is locked for
+// the rest of the transaction if lock is set.
+func findInvitationByCode(ctx context.Context, tx *Tx, code string, lock bool) (*todev.Invitation, error) {
+	query := `
+		SELECT
+			i.id,
+			i.repo_id,
+			COALESCE(i.user_id, 0),
+			i.code,
+			i.role,
+			i.max_uses,
+			i.uses,
+			i.expires_at,
+			i.revoked_at,
+			i.created_at,
+			i.updated_at,
+			r.name,
+			r.user_id
+		FROM invitations i
+		JOIN repos r ON i.repo_id = r.id
+		WHERE i.code = $1 AND r.deleted_at IS NULL`
+	if lock {
+		query += ` FOR UPDATE OF i`
+	}
+
+	inv := &todev.Invitation{Repo: &todev.Repo{}}
+	if err := tx.QueryRowContext(ctx, query+`;`, code).Scan(
+		&inv.ID,
+		&inv.RepoID,
+		&inv.UserID,
+		&inv.Code,
+		&inv.Role,
+		&inv.MaxUses,
+		&inv.Uses,
+		(*NullTime)(&inv.ExpiresAt),
+		(*NullTime)(&inv.RevokedAt),
+		(*NullTime)(&inv.CreatedAt),
+		(*NullTime)(&inv.UpdatedAt),
+		&inv.Repo.Name,
+		&inv.Repo.UserID,
+	); err == sql.ErrNoRows {
+		return nil, todev.Errorf(todev.ENOTFOUND, "Invalid invitation URL.")
+	} else if err != nil {
+		return nil, fmt.Errorf("error retrieving invitation by code: %w", err)
+	}
+	inv.Repo.ID = inv.RepoID
+	return inv, nil
+}
+
+func findInvitationByID(ctx context.Context, tx *Tx, id int) (*todev.Invitation, error) {
+	invitations, _, err := findInvitations(ctx, tx, todev.InvitationFilter{ID: &id})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving invitation by ID: %w", err)
+	} else if len(invitations) == 0 {
+		return nil, todev.Errorf(todev.ENOTFOUND, "Invitation not found.")
+	}
+	return invitations[0], nil
+}
+
+func findInvitations(ctx context.Context, tx *Tx, filter todev.InvitationFilter) ([]*todev.Invitation, int, error) {
+	where, args := []string{"r.deleted_at IS NULL"}, []interface{}{}
+	var argIndex int
+	if v := filter.ID; v != nil {
+		argIndex++
+		where, args = append(where, fmt.Sprintf("i.id = $%d", argIndex)), append(args, *v)
+	}
+	if v := filter.RepoID; v != nil {
+		argIndex++
+		where, args = append(where, fmt.Sprintf("i.repo_id = $%d", argIndex)), append(args, *v)
+	}
+	if filter.Active {
+		where = append(where, "i.revoked_at IS NULL")
+	}
+
+	// Limit to repos the user is an admin of since codes let anyone join.
+	argIndex++
+	where = append(where, fmt.Sprintf(`(
+		i.repo_id IN (SELECT repo_id FROM contributors WHERE user_id = $%d AND role IN ($%d, $%d))
+		)`, argIndex, argIndex+1, argIndex+2),
+	)
+	argIndex += 2
+	args = append(args, todev.UserIDFromContext(ctx), todev.RoleOwner, todev.RoleAdmin)
+
+	keys := []sortKey{{expr: "i.id", desc: true}}
+	cursorID, err := findCursorID(ctx, tx, "invitations", filter.Cursor, filter.Offset)
+	if err != nil {
+		return nil, 0, err
+	} else if cursorID != 0 {
+		argIndex++
+		where, args = append(where, formatKeyset(keys, "invitations i", argIndex)), append(args, cursorID)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			i.id,
+			i.repo_id,
+			COALESCE(i.user_id, 0),
+			i.code,
+			i.role,
+			i.max_uses,
+			i.uses,
+			i.expires_at,
+			i.revoked_at,
+			i.created_at,
+			i.updated_at,
+			COUNT(*) OVER()
+		FROM invitations i
+		JOIN repos r ON i.repo_id = r.id
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY `+formatOrderBy(keys)+`
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error retrieving invitations: %w", err)
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("error closing rows: %v", err)
+		}
+	}()
+
+	invitations := make([]*todev.Invitation, 0)
+
+	var n int
+	for rows.Next() {
+		var inv todev.Invitation
+		if err = rows.Scan(
+			&inv.ID,
+			&inv.RepoID,
+			&inv.UserID,
+			&inv.Code,
+			&inv.Role,
+			&inv.MaxUses,
+			&inv.Uses,
+			(*NullTime)(&inv.ExpiresAt),
+			(*NullTime)(&inv.RevokedAt),
+			(*NullTime)(&inv.CreatedAt),
+			(*NullTime)(&inv.UpdatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, fmt.Errorf("error scanning: %w", err)
+		}
+		invitations = append(invitations, &inv)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return invitations, n, nil
+}
+
+// findManagedInvitation retrieves an invitation by ID and verifies the current
+// user is allowed to manage it.
+func findManagedInvitation(ctx context.Context, tx *Tx, id int) (*todev.Invitation, error) {
+	inv, err := findInvitationByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if role, _, err := findCurrentRole(ctx, tx, inv.RepoID); err != nil {
+		return nil, err
+	} else if err = todev.CanManageInvitation(role, *inv); err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
+
+func rotateInvitation(ctx context.Context, tx *Tx, id int) (*todev.Invitation, error) {
+	inv, err := findManagedInvitation(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	} else if !inv.RevokedAt.IsZero() {
+		return nil, todev.Errorf(todev.ECONFLICT, "Revoked invitations cannot be rotated.")
+	}
+
+	if inv.Code, err = generateInvitationCode(); err != nil {
+		return nil, err
+	}
+	inv.UpdatedAt = tx.now
+
+	if _, err = tx.ExecContext(ctx, `
+		UPDATE invitations
+		SET code = $1, updated_at = $2
+		WHERE id = $3;`,
+		inv.Code,
+		(*NullTime)(&inv.UpdatedAt),
+		id,
+	); err != nil {
+		return nil, fmt.Errorf("error rotating invitation: %w", err)
+	}
+	return inv, nil
+}
+
+func revokeInvitation(ctx context.Context, tx *Tx, id int) error {
+	inv, err := findManagedInvitation(ctx, tx, id)
+	if err != nil {
+		return err
+	} else if !inv.RevokedAt.IsZero() {
+		return nil
+	}
+
+	if _, err = tx.ExecContext(ctx, `
+		UPDATE invitations
+		SET revoked_at = $1, updated_at = $1
+		WHERE id = $2;`,
+		(*NullTime)(&tx.now),
+		id,
+	); err != nil {
+		return fmt.Errorf("error revoking invitation: %w", err)
+	}
+	return nil
+}
+
+func findInvitationUses(ctx context.Context, tx *Tx, invitationID int) ([]*todev.InvitationUse, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			id,
+			invitation_id,
+			COALESCE(user_id, 0),
+			COALESCE(contributor_id, 0),
+			created_at
+		FROM invitation_uses
+		WHERE invitation_id = $1
+		ORDER BY id DESC;`,
+		invitationID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving invitation uses: %w", err)
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("error closing rows: %v", err)
+		}
+	}()
+
+	uses := make([]*todev.InvitationUse, 0)
+	for rows.Next() {
+		var use todev.InvitationUse
+		if err = rows.Scan(
+			&use.ID,
+			&use.InvitationID,
+			&use.UserID,
+			&use.ContributorID,
+			(*NullTime)(&use.CreatedAt),
+		); err != nil {
+			return nil, fmt.Errorf("error scanning: %w", err)
+		}
+		uses = append(uses, &use)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return uses, nil
+}
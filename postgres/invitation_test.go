@@ -0,0 +1,207 @@
+package postgres_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/postgres"
+)
+
+func TestContributorService_JoinRepo(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		WithSchema(t, joinRepo_OK)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		WithSchema(t, joinRepo_Expired)
+	})
+
+	t.Run("RotateRevoke", func(t *testing.T) {
+		WithSchema(t, joinRepo_RotateRevoke)
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		WithSchema(t, joinRepo_Concurrent)
+	})
+}
+
+func joinRepo_OK(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewContrubutorService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	user1, ctx1 := MustCreateUser(t, ctx, conn, &todev.User{Name: "judy", Email: "judy@gmail.com"})
+	_, ctx2 := MustCreateUser(t, ctx, conn, &todev.User{Name: "alice", Email: "alice@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+
+	// New repos come with an unlimited member invitation.
+	if invitations, n, err := s.FindInvitations(ctx0, todev.InvitationFilter{RepoID: &repo0.ID}); err != nil {
+		t.Fatal(err)
+	} else if got, want := n, 1; got != want {
+		t.Fatalf("n=%d, want %d", got, want)
+	} else if got, want := invitations[0].Role, todev.RoleMember; got != want {
+		t.Fatalf("Role=%q, want %q", got, want)
+	}
+
+	inv := &todev.Invitation{RepoID: repo0.ID, Role: todev.RoleViewer, MaxUses: 1}
+	if err := s.CreateInvitation(ctx0, inv); err != nil {
+		t.Fatal(err)
+	} else if inv.Code == "" {
+		t.Fatal("expected code")
+	}
+
+	if found, err := s.FindInvitationByCode(ctx1, inv.Code); err != nil {
+		t.Fatal(err)
+	} else if got, want := found.Repo.Name, "repo1"; got != want {
+		t.Fatalf("Repo.Name=%q, want %q", got, want)
+	}
+
+	// Users join with the role of the invitation and the join is logged.
+	if contributor, err := s.JoinRepo(ctx1, inv.Code); err != nil {
+		t.Fatal(err)
+	} else if got, want := contributor.Role, todev.RoleViewer; got != want {
+		t.Fatalf("Role=%q, want %q", got, want)
+	}
+	if uses, err := s.FindInvitationUses(ctx0, inv.ID); err != nil {
+		t.Fatal(err)
+	} else if got, want := len(uses), 1; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	} else if got, want := uses[0].UserID, user1.ID; got != want {
+		t.Fatalf("UserID=%d, want %d", got, want)
+	}
+
+	// Used up invitations cannot be used again.
+	if _, err := s.JoinRepo(ctx2, inv.Code); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Contributors cannot join twice.
+	invitations, _, err := s.FindInvitations(ctx0, todev.InvitationFilter{RepoID: &repo0.ID, Active: true})
+	if err != nil {
+		t.Fatal(err)
+	} else if _, err = s.JoinRepo(ctx1, invitations[len(invitations)-1].Code); todev.ErrorCode(err) != todev.ECONFLICT {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Viewers can neither list nor create invitations.
+	if _, n, err := s.FindInvitations(ctx1, todev.InvitationFilter{RepoID: &repo0.ID}); err != nil {
+		t.Fatal(err)
+	} else if got, want := n, 0; got != want {
+		t.Fatalf("n=%d, want %d", got, want)
+	} else if err = s.CreateInvitation(ctx1, &todev.Invitation{RepoID: repo0.ID}); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func joinRepo_Expired(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewContrubutorService(conn)
+
+	// Mock the clock so the invitation can be moved past its expiry.
+	t0 := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	conn.Now = func() time.Time { return t0 }
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	_, ctx1 := MustCreateUser(t, ctx, conn, &todev.User{Name: "judy", Email: "judy@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+
+	inv := &todev.Invitation{RepoID: repo0.ID, ExpiresAt: t0.Add(time.Hour)}
+	if err := s.CreateInvitation(ctx0, inv); err != nil {
+		t.Fatal(err)
+	}
+
+	conn.Now = func() time.Time { return t0.Add(2 * time.Hour) }
+	if _, err := s.FindInvitationByCode(ctx1, inv.Code); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err = s.JoinRepo(ctx1, inv.Code); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func joinRepo_RotateRevoke(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewContrubutorService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	_, ctx1 := MustCreateUser(t, ctx, conn, &todev.User{Name: "judy", Email: "judy@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+
+	inv := &todev.Invitation{RepoID: repo0.ID}
+	if err := s.CreateInvitation(ctx0, inv); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotating replaces the code so the old link stops working.
+	rotated, err := s.RotateInvitation(ctx0, inv.ID)
+	if err != nil {
+		t.Fatal(err)
+	} else if rotated.Code == inv.Code {
+		t.Fatal("expected new code")
+	} else if _, err = s.FindInvitationByCode(ctx1, inv.Code); todev.ErrorCode(err) != todev.ENOTFOUND {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Revoked invitations cannot be used or rotated.
+	if err = s.RevokeInvitation(ctx0, inv.ID); err != nil {
+		t.Fatal(err)
+	} else if _, err = s.JoinRepo(ctx1, rotated.Code); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err = s.RotateInvitation(ctx0, inv.ID); todev.ErrorCode(err) != todev.ECONFLICT {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// Ensure a single-use invitation cannot be redeemed twice by concurrent joins.
+func joinRepo_Concurrent(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewContrubutorService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+
+	inv := &todev.Invitation{RepoID: repo0.ID, MaxUses: 1}
+	if err := s.CreateInvitation(ctx0, inv); err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 8
+	ctxs := make([]context.Context, n)
+	for i := range ctxs {
+		_, ctxs[i] = MustCreateUser(t, ctx, conn, &todev.User{Name: fmt.Sprintf("user%d", i)})
+	}
+
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range ctxs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = s.JoinRepo(ctxs[i], inv.Code)
+		}(i)
+	}
+	wg.Wait()
+
+	var joined int
+	for _, err := range errs {
+		switch todev.ErrorCode(err) {
+		case "":
+			joined++
+		case todev.EUNAUTHORIZED, todev.ECONFLICT:
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if got, want := joined, 1; got != want {
+		t.Fatalf("joined=%d, want %d", got, want)
+	}
+
+	if uses, err := s.FindInvitationUses(ctx0, inv.ID); err != nil {
+		t.Fatal(err)
+	} else if got, want := len(uses), 1; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	}
+}
@@ -0,0 +1,204 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+
+	"github.com/lib/pq"
+	"github.com/saiddis/todev"
+)
+
+// ImportIssues creates a task for every issue that was not imported into the
+// repo yet. Previously imported issues are matched by number and only have
+// their description updated. Returns EINVALID if the repo is not linked to
+// GitHub and EUNAUTHORIZED if the current user is not a repo admin.
+func (s *RepoService) ImportIssues(ctx context.Context, repoID int, issues []*todev.Issue) (*todev.IssueImport, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("ImportIssues: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	result, err := importIssues(ctx, tx, repoID, issues)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func importIssues(ctx context.Context, tx *Tx, repoID int, issues []*todev.Issue) (*todev.IssueImport, error) {
+	repo, err := findRepoByID(ctx, tx, repoID)
+	if err != nil {
+		return nil, err
+	} else if repo.GitHubRepo == "" {
+		return nil, todev.Errorf(todev.EINVALID, "Repo is not linked to GitHub.")
+	}
+
+	if role, _, err := findCurrentRole(ctx, tx, repo.ID); err != nil {
+		return nil, err
+	} else if !role.CanEditRepo() {
+		return nil, todev.Errorf(todev.EUNAUTHORIZED, "You are not allowed to import issues.")
+	}
+
+	result := &todev.IssueImport{}
+	for _, issue := range issues {
+		if err = issue.Validate(); err != nil {
+			return nil, err
+		}
+
+		taskID, trashed, err := findTaskIDByIssueNumber(ctx, tx, repo.ID, issue.Number)
+		if err != nil {
+			return nil, err
+		} else if trashed {
+			// Tasks trashed by the user are not brought back.
+			continue
+		}
+
+		// Assignees are only mapped when the issue is first imported so
+		// that later changes made in todev are kept.
+		if taskID != 0 {
+			task, err := findTaskByID(ctx, tx, taskID)
+			if err != nil {
+				return nil, err
+			}
+
+			description := issue.TaskDescription()
+			if task.Description == description {
+				continue
+			} else if _, err = updateTask(ctx, tx, task.ID, todev.TaskUpdate{Description: &description}); err != nil {
+				return nil, err
+			}
+			result.Updated++
+			continue
+		}
+
+		if err = createIssueTask(ctx, tx, repo, issue); err != nil {
+			return nil, err
+		}
+		result.Created++
+	}
+	return result, nil
+}
+
+// createIssueTask creates a task for an issue and gives it to the contributors
+// matching the issue assignees. The task is left unassigned if none of the
+// assignees contribute to the repo.
+func createIssueTask(ctx context.Context, tx *Tx, repo *todev.Repo, issue *todev.Issue) error {
+	task := &todev.Task{
+		RepoID:      repo.ID,
+		Description: issue.TaskDescription(),
+		IssueNumber: issue.Number,
+	}
+	if err := createTask(ctx, tx, task); err != nil {
+		return err
+	} else if err = createTaskContributors(ctx, tx, task); err != nil {
+		return err
+	}
+	task.OwnerID = repo.UserID
+
+	contributorIDs, err := findIssueAssigneeIDs(ctx, tx, repo.ID, issue.AssigneeIDs)
+	if err != nil {
+		return err
+	} else if len(contributorIDs) != 0 {
+		if _, err = tx.ExecContext(ctx, `
+			DELETE FROM tasks_contributors
+			WHERE task_id = $1 AND contributor_id != ALL($2);`,
+			task.ID,
+			pq.Array(contributorIDs),
+		); err != nil {
+			return fmt.Errorf("error deleting task contributors: %w", err)
+		}
+		task.ContributorIDs = contributorIDs
+	}
+
+	if err = publishRepoEvent(ctx, tx, repo.ID, todev.Event{
+		Type: todev.EventTypeTaskAdded,
+		Payload: todev.TaskAdded{
+			Task: task,
+		},
+	}); err != nil {
+		return err
+	}
+
+	return createActivity(ctx, tx, &todev.Activity{
+		RepoID:     repo.ID,
+		EntityType: todev.ActivityEntityTask,
+		EntityID:   task.ID,
+		Action:     todev.ActivityActionCreate,
+		After:      taskActivityFields(task),
+	})
+}
+
+// findTaskIDByIssueNumber returns the ID of the task imported from an issue
+// and whether the task is in the trash. Returns zero if the issue was not
+// imported into the repo.
+func findTaskIDByIssueNumber(ctx context.Context, tx *Tx, repoID, number int) (int, bool, error) {
+	var id int
+	var trashed bool
+	if err := tx.QueryRowContext(ctx, `
+		SELECT id, deleted_at IS NOT NULL FROM tasks
+		WHERE repo_id = $1 AND issue_number = $2;`,
+		repoID,
+		number,
+	).Scan(&id, &trashed); err == sql.ErrNoRows {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, fmt.Errorf("error retrieving task by issue number: %w", err)
+	}
+	return id, trashed, nil
+}
+
+// findIssueAssigneeIDs returns the IDs of the repo contributors whose GitHub
+// accounts match the given GitHub user IDs.
+func findIssueAssigneeIDs(ctx context.Context, tx *Tx, repoID int, sourceIDs []string) ([]int, error) {
+	if len(sourceIDs) == 0 {
+		return nil, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT DISTINCT c.id
+		FROM contributors c
+		JOIN auths a ON a.user_id = c.user_id
+		WHERE c.repo_id = $1 AND a.source = $2 AND a.source_id = ANY($3)
+		ORDER BY c.id ASC;`,
+		repoID,
+		todev.AuthSourceGitHub,
+		pq.Array(sourceIDs),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving assignees: %w", err)
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("error closing rows: %v", err)
+		}
+	}()
+
+	ids := make([]int, 0, len(sourceIDs))
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,99 @@
+package postgres_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/postgres"
+)
+
+func TestRepoService_ImportIssues(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		WithSchema(t, importIssues_OK)
+	})
+
+	t.Run("ErrNotLinked", func(t *testing.T) {
+		WithSchema(t, importIssues_ErrNotLinked)
+	})
+}
+
+func importIssues_OK(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewRepoService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateAuth(t, ctx, conn, &todev.Auth{
+		Source:      todev.AuthSourceGitHub,
+		SourceID:    "100",
+		AccessToken: "TOKEN0",
+		User:        &todev.User{Name: "bob", Email: "bob@gmail.com"},
+	})
+	_, ctx1 := MustCreateAuth(t, ctx, conn, &todev.Auth{
+		Source:      todev.AuthSourceGitHub,
+		SourceID:    "200",
+		AccessToken: "TOKEN1",
+		User:        &todev.User{Name: "judy", Email: "judy@gmail.com"},
+	})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	contributor1 := MustCreateContributor(t, ctx1, conn, &todev.Contributor{RepoID: repo0.ID})
+
+	// Only the owner can link the repo to GitHub.
+	githubRepo := "bob/todo"
+	if _, err := s.UpdateRepo(ctx1, repo0.ID, todev.RepoUpdate{GitHubRepo: &githubRepo}); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	} else if repo, err := s.UpdateRepo(ctx0, repo0.ID, todev.RepoUpdate{GitHubRepo: &githubRepo}); err != nil {
+		t.Fatal(err)
+	} else if got, want := repo.GitHubRepo, githubRepo; got != want {
+		t.Fatalf("GitHubRepo=%q, want %q", got, want)
+	}
+
+	issues := []*todev.Issue{
+		{Number: 1, Title: "Fix login", AssigneeIDs: []string{"200", "300"}},
+		{Number: 2, Title: "Add docs"},
+	}
+	if result, err := s.ImportIssues(ctx0, repo0.ID, issues); err != nil {
+		t.Fatal(err)
+	} else if got, want := *result, (todev.IssueImport{Created: 2}); got != want {
+		t.Fatalf("result=%#v, want %#v", got, want)
+	}
+
+	// Issue assignees are mapped to contributors through their GitHub auths.
+	ts := postgres.NewTaskService(conn)
+	tasks, _, err := ts.FindTasks(ctx0, todev.TaskFilter{RepoID: &repo0.ID})
+	if err != nil {
+		t.Fatal(err)
+	} else if got, want := len(tasks), 2; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	}
+	for _, task := range tasks {
+		if task.IssueNumber != 1 {
+			continue
+		}
+		if task, err = ts.FindTaskByID(ctx0, task.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := task.ContributorIDs, []int{contributor1.ID}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("ContributorIDs=%v, want %v", got, want)
+		}
+	}
+
+	// Importing again only updates the tasks whose issue title changed.
+	issues[1].Title = "Add more docs"
+	if result, err := s.ImportIssues(ctx0, repo0.ID, issues); err != nil {
+		t.Fatal(err)
+	} else if got, want := *result, (todev.IssueImport{Updated: 1}); got != want {
+		t.Fatalf("result=%#v, want %#v", got, want)
+	}
+}
+
+func importIssues_ErrNotLinked(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewRepoService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+
+	if _, err := s.ImportIssues(ctx0, repo0.ID, []*todev.Issue{{Number: 1, Title: "Fix login"}}); todev.ErrorCode(err) != todev.EINVALID {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
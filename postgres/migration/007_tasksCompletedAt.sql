@@ -0,0 +1,4 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ;
+
+-- Best guess for tasks completed before completion times were recorded.
+UPDATE tasks SET completed_at = updated_at WHERE is_completed AND completed_at IS NULL;
@@ -0,0 +1,7 @@
+ALTER TABLE contributors ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'member'
+	CHECK (role IN ('owner', 'admin', 'member', 'viewer'));
+
+UPDATE contributors SET role = 'admin' WHERE is_admin;
+UPDATE contributors SET role = 'owner' WHERE user_id = owner_id;
+
+ALTER TABLE contributors DROP COLUMN IF EXISTS is_admin;
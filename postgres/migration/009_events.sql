@@ -0,0 +1,13 @@
+ALTER TABLE repos ADD COLUMN IF NOT EXISTS event_seq INT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS events (
+	id SERIAL PRIMARY KEY,
+	repo_id INT NOT NULL REFERENCES repos(id) ON DELETE CASCADE,
+	seq INT NOT NULL,
+	type TEXT NOT NULL,
+	payload JSONB NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	UNIQUE(repo_id, seq)
+);
+
+CREATE INDEX IF NOT EXISTS events_created_at_idx ON events (created_at);
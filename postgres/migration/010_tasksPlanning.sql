@@ -0,0 +1,18 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS due_at TIMESTAMPTZ;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS priority INT NOT NULL DEFAULT 0
+	CHECK (priority BETWEEN 0 AND 3);
+
+CREATE INDEX IF NOT EXISTS tasks_due_at_idx ON tasks (due_at);
+
+CREATE TABLE IF NOT EXISTS labels (
+	id SERIAL PRIMARY KEY,
+	repo_id INT NOT NULL REFERENCES repos(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	UNIQUE(repo_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS tasks_labels (
+	task_id BIGINT REFERENCES tasks(id) ON DELETE CASCADE,
+	label_id INT REFERENCES labels(id) ON DELETE CASCADE,
+	UNIQUE(task_id, label_id)
+);
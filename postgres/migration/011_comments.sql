@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS comments (
+	id BIGSERIAL PRIMARY KEY,
+	task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+	user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	body TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS comments_task_id_idx ON comments (task_id);
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS task_states (
+	id SERIAL PRIMARY KEY,
+	repo_id INT NOT NULL REFERENCES repos(id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	position INT NOT NULL,
+	is_terminal BOOLEAN NOT NULL DEFAULT FALSE,
+	UNIQUE(repo_id, name)
+);
+
+-- Give every existing repo the default workflow.
+INSERT INTO task_states (repo_id, name, position, is_terminal)
+SELECT r.id, s.name, s.position, s.is_terminal
+FROM repos r
+CROSS JOIN (VALUES ('To do', 0, FALSE), ('In progress', 1, FALSE), ('Done', 2, TRUE)) AS s(name, position, is_terminal);
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS state_id INT REFERENCES task_states(id);
+
+-- Completed tasks are moved to the terminal state and all others to the first one.
+UPDATE tasks t SET state_id = s.id
+FROM task_states s
+WHERE s.repo_id = t.repo_id AND t.state_id IS NULL
+	AND s.name = CASE WHEN t.is_completed THEN 'Done' ELSE 'To do' END;
+
+ALTER TABLE tasks ALTER COLUMN state_id SET NOT NULL;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS checklist_items (
+	id BIGSERIAL PRIMARY KEY,
+	task_id BIGINT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+	text TEXT NOT NULL,
+	position INT NOT NULL,
+	is_checked BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS checklist_items_task_id_idx ON checklist_items (task_id, position);
@@ -0,0 +1,5 @@
+ALTER TABLE repos ADD COLUMN IF NOT EXISTS github_repo TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS issue_number INTEGER;
+
+CREATE UNIQUE INDEX IF NOT EXISTS tasks_repo_id_issue_number_idx ON tasks (repo_id, issue_number) WHERE issue_number IS NOT NULL;
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS activities (
+	id SERIAL PRIMARY KEY,
+	repo_id INT NOT NULL REFERENCES repos(id) ON DELETE CASCADE,
+	user_id INT REFERENCES users(id) ON DELETE SET NULL,
+	entity_type TEXT NOT NULL,
+	entity_id INT NOT NULL,
+	action TEXT NOT NULL,
+	before JSONB NOT NULL DEFAULT '{}',
+	after JSONB NOT NULL DEFAULT '{}',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS activities_repo_id_idx ON activities (repo_id, id);
+CREATE INDEX IF NOT EXISTS activities_entity_idx ON activities (entity_type, entity_id);
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS invitations (
+	id SERIAL PRIMARY KEY,
+	repo_id INT NOT NULL REFERENCES repos(id) ON DELETE CASCADE,
+	user_id INT REFERENCES users(id) ON DELETE SET NULL,
+	code VARCHAR(255) UNIQUE NOT NULL,
+	role TEXT NOT NULL DEFAULT 'member',
+	max_uses INT NOT NULL DEFAULT 0,
+	expires_at TIMESTAMPTZ,
+	revoked_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS invitations_repo_id_idx ON invitations (repo_id);
+
+CREATE TABLE IF NOT EXISTS invitation_uses (
+	id SERIAL PRIMARY KEY,
+	invitation_id INT NOT NULL REFERENCES invitations(id) ON DELETE CASCADE,
+	user_id INT REFERENCES users(id) ON DELETE SET NULL,
+	contributor_id INT REFERENCES contributors(id) ON DELETE SET NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS invitation_uses_invitation_id_idx ON invitation_uses (invitation_id);
+
+-- Keep existing invite links working as unlimited member invitations.
+INSERT INTO invitations (repo_id, user_id, code, role, created_at, updated_at)
+SELECT id, user_id, invite_code, 'member', created_at, created_at FROM repos;
+
+ALTER TABLE repos DROP COLUMN IF EXISTS invite_code;
@@ -0,0 +1,11 @@
+ALTER TABLE auths ADD COLUMN IF NOT EXISTS avatar_url TEXT NOT NULL DEFAULT '';
+
+-- GitLab and OIDC user IDs and tokens don't fit the GitHub sized columns.
+ALTER TABLE auths ALTER COLUMN source_id TYPE VARCHAR(255);
+ALTER TABLE auths ALTER COLUMN access_token TYPE TEXT;
+ALTER TABLE auths ALTER COLUMN refresh_token TYPE TEXT;
+
+-- Avatars of GitHub auths used to be derived from the source ID.
+UPDATE auths
+SET avatar_url = 'https://avatars1.githubusercontent.com/u/' || source_id || '?s=128'
+WHERE source = 'github' AND avatar_url = '';
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS passwords (
+	user_id INT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+	hash TEXT NOT NULL,
+	email_verified_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Single use tokens sent by email. Only the SHA-256 hash of a token is stored.
+CREATE TABLE IF NOT EXISTS password_tokens (
+	id SERIAL PRIMARY KEY,
+	user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	purpose VARCHAR(16) NOT NULL,
+	hash TEXT NOT NULL UNIQUE,
+	expires_at TIMESTAMPTZ NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS password_tokens_user_id_idx ON password_tokens (user_id, purpose);
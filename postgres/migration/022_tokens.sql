@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS tokens (
+	id SERIAL PRIMARY KEY,
+	user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	name VARCHAR(64) NOT NULL,
+	scopes TEXT[] NOT NULL,
+	hash TEXT NOT NULL UNIQUE,
+	expires_at TIMESTAMPTZ,
+	last_used_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS tokens_user_id_idx ON tokens (user_id);
+
+-- Keep existing API keys working as full access tokens. Only their SHA-256
+-- hash is kept.
+INSERT INTO tokens (user_id, name, scopes, hash, created_at)
+SELECT id, 'API key', ARRAY['all'], encode(sha256(convert_to(api_key, 'UTF8')), 'hex'), created_at
+FROM users
+WHERE api_key IS NOT NULL;
+
+ALTER TABLE users DROP COLUMN IF EXISTS api_key;
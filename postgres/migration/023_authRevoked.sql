@@ -0,0 +1 @@
+ALTER TABLE auths ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMPTZ;
@@ -0,0 +1,5 @@
+-- Count uses on the invitation row so concurrent joins are serialized by the
+-- update instead of reading a stale count.
+ALTER TABLE invitations ADD COLUMN IF NOT EXISTS uses INT NOT NULL DEFAULT 0;
+
+UPDATE invitations i SET uses = (SELECT COUNT(*) FROM invitation_uses u WHERE u.invitation_id = i.id);
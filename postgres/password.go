@@ -0,0 +1,438 @@
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/saiddis/todev"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Purposes of the tokens stored in the password_tokens table.
+const (
+	passwordTokenVerifyEmail   = "verify_email"
+	passwordTokenResetPassword = "reset_password"
+)
+
+// Ensure service implements interface.
+var _ todev.PasswordService = (*PasswordService)(nil)
+
+// PasswordService represents a service for managing local accounts.
+type PasswordService struct {
+	conn *Conn
+}
+
+// NewPasswordService returns a new instance of PasswordService.
+func NewPasswordService(conn *Conn) *PasswordService {
+	return &PasswordService{conn: conn}
+}
+
+// password represents the local credentials of a user.
+type password struct {
+	UserID          int
+	Hash            string
+	EmailVerifiedAt time.Time
+}
+
+// Register creates a new user along with its password. Returns the token
+// verifying the user's email.
+func (s *PasswordService) Register(ctx context.Context, user *todev.User, password string) (string, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("error beginning transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("Register: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	user.Email = todev.NormalizeEmail(user.Email)
+	if user.Email == "" {
+		return "", todev.Errorf(todev.EINVALID, "Email required.")
+	} else if err = todev.ValidatePassword(password); err != nil {
+		return "", err
+	}
+
+	if _, err = findUserByEmail(ctx, tx, user.Email); err == nil {
+		return "", todev.Errorf(todev.ECONFLICT, "Email is already registered.")
+	} else if todev.ErrorCode(err) != todev.ENOTFOUND {
+		return "", fmt.Errorf("cannot find user by email: %w", err)
+	}
+
+	if err = createUser(ctx, tx, user); err != nil {
+		return "", fmt.Errorf("cannot create user: %w", err)
+	} else if err = createPassword(ctx, tx, user.ID, password); err != nil {
+		return "", err
+	}
+
+	token, err := createPasswordToken(ctx, tx, user.ID, passwordTokenVerifyEmail, todev.EmailVerificationTTL)
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Login returns the user with the given email and password.
+func (s *PasswordService) Login(ctx context.Context, email, password string) (*todev.User, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error beginning transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("Login: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	// Don't tell unknown emails and wrong passwords apart.
+	user, err := findUserByEmail(ctx, tx, todev.NormalizeEmail(email))
+	if todev.ErrorCode(err) == todev.ENOTFOUND {
+		return nil, todev.Errorf(todev.EUNAUTHORIZED, "Invalid email or password.")
+	} else if err != nil {
+		return nil, fmt.Errorf("cannot find user by email: %w", err)
+	}
+
+	if err = checkPassword(ctx, tx, user.ID, password); err != nil {
+		return nil, err
+	} else if err = attachUserAuths(ctx, tx, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ChangePassword replaces the password of the current user and revokes their
+// other sessions.
+func (s *PasswordService) ChangePassword(ctx context.Context, current, password string) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("ChangePassword: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	userID := todev.UserIDFromContext(ctx)
+	if userID == 0 {
+		return todev.Errorf(todev.EUNAUTHORIZED, "You must be logged in to change your password.")
+	}
+
+	if err = checkPassword(ctx, tx, userID, current); todev.ErrorCode(err) == todev.EUNAUTHORIZED {
+		return todev.Errorf(todev.EUNAUTHORIZED, "Current password is wrong.")
+	} else if err != nil {
+		return err
+	}
+
+	if err = updatePassword(ctx, tx, userID, password); err != nil {
+		return err
+	}
+
+	// Log out other browsers in case the old password was compromised.
+	var sessionID int
+	if session := todev.SessionFromContext(ctx); session != nil {
+		sessionID = session.ID
+	}
+	if err = deleteUserSessions(ctx, tx, userID, sessionID); err != nil {
+		return err
+	}
+	return nil
+}
+
+// VerifyEmail marks the email of the account the token was created for as
+// verified.
+func (s *PasswordService) VerifyEmail(ctx context.Context, token string) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("VerifyEmail: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	userID, err := usePasswordToken(ctx, tx, token, passwordTokenVerifyEmail)
+	if err != nil {
+		return err
+	} else if err = verifyEmail(ctx, tx, userID); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CreatePasswordReset returns a token to reset the password of the local
+// account with the given email. Previous reset tokens of the account stop
+// working.
+func (s *PasswordService) CreatePasswordReset(ctx context.Context, email string) (string, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("error beginning transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("CreatePasswordReset: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	user, err := findUserByEmail(ctx, tx, todev.NormalizeEmail(email))
+	if err != nil {
+		return "", err
+	} else if _, err = findPassword(ctx, tx, user.ID); err != nil {
+		return "", err
+	}
+
+	token, err := createPasswordToken(ctx, tx, user.ID, passwordTokenResetPassword, todev.PasswordResetTTL)
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// ResetPassword replaces the password of the account the reset token was
+// created for and revokes all its sessions. The email is considered verified
+// as the token was sent to it.
+func (s *PasswordService) ResetPassword(ctx context.Context, token, password string) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("ResetPassword: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	userID, err := usePasswordToken(ctx, tx, token, passwordTokenResetPassword)
+	if err != nil {
+		return err
+	} else if err = updatePassword(ctx, tx, userID, password); err != nil {
+		return err
+	} else if err = verifyEmail(ctx, tx, userID); err != nil {
+		return err
+	} else if err = deleteUserSessions(ctx, tx, userID, 0); err != nil {
+		return err
+	}
+	return nil
+}
+
+// findPassword returns the local credentials of a user. Returns ENOTFOUND if
+// the user has no local account.
+func findPassword(ctx context.Context, tx *Tx, userID int) (*password, error) {
+	var p password
+	err := tx.QueryRowContext(ctx, `
+		SELECT user_id, hash, email_verified_at
+		FROM passwords
+		WHERE user_id = $1;`,
+		userID,
+	).Scan(&p.UserID, &p.Hash, (*NullTime)(&p.EmailVerifiedAt))
+	if err == sql.ErrNoRows {
+		return nil, todev.Errorf(todev.ENOTFOUND, "Local account not found.")
+	} else if err != nil {
+		return nil, fmt.Errorf("error retrieving password: %w", err)
+	}
+	return &p, nil
+}
+
+// checkPassword returns EUNAUTHORIZED if the password doesn't match the one
+// of the user or if the user has no local account.
+func checkPassword(ctx context.Context, tx *Tx, userID int, pw string) error {
+	p, err := findPassword(ctx, tx, userID)
+	if todev.ErrorCode(err) == todev.ENOTFOUND {
+		return todev.Errorf(todev.EUNAUTHORIZED, "Invalid email or password.")
+	} else if err != nil {
+		return err
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(p.Hash), []byte(pw)); err == bcrypt.ErrMismatchedHashAndPassword {
+		return todev.Errorf(todev.EUNAUTHORIZED, "Invalid email or password.")
+	} else if err != nil {
+		return fmt.Errorf("error comparing password: %w", err)
+	}
+	return nil
+}
+
+// hashPassword validates and hashes a password with bcrypt.
+func hashPassword(pw string) (string, error) {
+	if err := todev.ValidatePassword(pw); err != nil {
+		return "", err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(pw), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("error hashing password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// createPassword stores the password of a new local account.
+func createPassword(ctx context.Context, tx *Tx, userID int, pw string) error {
+	hash, err := hashPassword(pw)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `
+		INSERT INTO passwords (user_id, hash, created_at, updated_at)
+		VALUES ($1, $2, $3, $4);`,
+		userID,
+		hash,
+		(*NullTime)(&tx.now),
+		(*NullTime)(&tx.now),
+	); err != nil {
+		return fmt.Errorf("error inserting password: %w", err)
+	}
+	return nil
+}
+
+// updatePassword replaces the password of a local account.
+func updatePassword(ctx context.Context, tx *Tx, userID int, pw string) error {
+	hash, err := hashPassword(pw)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `
+		UPDATE passwords
+		SET hash = $1, updated_at = $2
+		WHERE user_id = $3;`,
+		hash,
+		(*NullTime)(&tx.now),
+		userID,
+	); err != nil {
+		return fmt.Errorf("error updating password: %w", err)
+	}
+	return nil
+}
+
+// verifyEmail marks the email of a local account as verified.
+func verifyEmail(ctx context.Context, tx *Tx, userID int) error {
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE passwords
+		SET email_verified_at = COALESCE(email_verified_at, $1), updated_at = $1
+		WHERE user_id = $2;`,
+		(*NullTime)(&tx.now),
+		userID,
+	); err != nil {
+		return fmt.Errorf("error verifying email: %w", err)
+	}
+	return nil
+}
+
+// hashToken returns the hash under which a secret token is stored.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// createPasswordToken generates a token for the given purpose. Previous tokens
+// of the user for the same purpose are removed.
+func createPasswordToken(ctx context.Context, tx *Tx, userID int, purpose string, ttl time.Duration) (string, error) {
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", fmt.Errorf("error generating token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM password_tokens WHERE user_id = $1 AND purpose = $2;`,
+		userID,
+		purpose,
+	); err != nil {
+		return "", fmt.Errorf("error deleting tokens: %w", err)
+	}
+
+	expiresAt := tx.now.Add(ttl)
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO password_tokens (user_id, purpose, hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5);`,
+		userID,
+		purpose,
+		hashToken(token),
+		(*NullTime)(&expiresAt),
+		(*NullTime)(&tx.now),
+	); err != nil {
+		return "", fmt.Errorf("error inserting token: %w", err)
+	}
+
+	return token, nil
+}
+
+// usePasswordToken consumes a token created for the given purpose and returns
+// the ID of its user. Returns EUNAUTHORIZED if the token is invalid or expired.
+func usePasswordToken(ctx context.Context, tx *Tx, token, purpose string) (int, error) {
+	var userID int
+	var expiresAt time.Time
+	err := tx.QueryRowContext(ctx, `
+		DELETE FROM password_tokens
+		WHERE hash = $1 AND purpose = $2
+		RETURNING user_id, expires_at;`,
+		hashToken(token),
+		purpose,
+	).Scan(&userID, (*NullTime)(&expiresAt))
+	if err == sql.ErrNoRows {
+		return 0, todev.Errorf(todev.EUNAUTHORIZED, "Invalid or expired token.")
+	} else if err != nil {
+		return 0, fmt.Errorf("error retrieving token: %w", err)
+	} else if !tx.now.Before(expiresAt) {
+		return 0, todev.Errorf(todev.EUNAUTHORIZED, "Invalid or expired token.")
+	}
+	return userID, nil
+}
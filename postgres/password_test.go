@@ -0,0 +1,226 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/postgres"
+)
+
+func TestPasswordService_Register(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		WithSchema(t, register_OK)
+	})
+
+	t.Run("VerifyEmail", func(t *testing.T) {
+		WithSchema(t, register_VerifyEmail)
+	})
+}
+
+func TestPasswordService_ResetPassword(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		WithSchema(t, resetPassword_OK)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		WithSchema(t, resetPassword_Expired)
+	})
+
+	t.Run("RevokesSessions", func(t *testing.T) {
+		WithSchema(t, resetPassword_RevokesSessions)
+	})
+}
+
+func TestPasswordService_ChangePassword(t *testing.T) {
+	t.Run("RevokesOtherSessions", func(t *testing.T) {
+		WithSchema(t, changePassword_RevokesOtherSessions)
+	})
+}
+
+func register_OK(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewPasswordService(conn)
+	ctx := context.Background()
+
+	user := &todev.User{Name: "bob", Email: " Bob@Gmail.com"}
+	if token, err := s.Register(ctx, user, "password1"); err != nil {
+		t.Fatal(err)
+	} else if token == "" {
+		t.Fatal("expected verification token")
+	} else if got, want := user.Email, "bob@gmail.com"; got != want {
+		t.Fatalf("Email=%q, want %q", got, want)
+	}
+
+	// Emails can only be registered once.
+	if _, err := s.Register(ctx, &todev.User{Name: "bob", Email: "bob@gmail.com"}, "password2"); todev.ErrorCode(err) != todev.ECONFLICT {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err = s.Register(ctx, &todev.User{Name: "jill", Email: "jill@gmail.com"}, "short"); todev.ErrorCode(err) != todev.EINVALID {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if other, err := s.Login(ctx, "BOB@gmail.com", "password1"); err != nil {
+		t.Fatal(err)
+	} else if got, want := other.ID, user.ID; got != want {
+		t.Fatalf("ID=%d, want %d", got, want)
+	} else if _, err = s.Login(ctx, "bob@gmail.com", "password2"); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	} else if _, err = s.Login(ctx, "judy@gmail.com", "password1"); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Changing the password requires the current one.
+	ctx0 := todev.NewContextWithUser(ctx, user)
+	if err := s.ChangePassword(ctx0, "wrong", "password2"); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err = s.ChangePassword(ctx0, "password1", "password2"); err != nil {
+		t.Fatal(err)
+	} else if _, err = s.Login(ctx, "bob@gmail.com", "password2"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Ensure OAuth logins are only linked to local accounts once their email is
+// verified.
+func register_VerifyEmail(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewPasswordService(conn)
+	ctx := context.Background()
+
+	user := &todev.User{Name: "bob", Email: "bob@gmail.com"}
+	token, err := s.Register(ctx, user, "password1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Registering logs the user in, which verifying must not undo.
+	sessions := postgres.NewSessionService(conn)
+	session := &todev.Session{UserID: user.ID}
+	if err = sessions.CreateSession(todev.NewContextWithUser(ctx, user), session); err != nil {
+		t.Fatal(err)
+	}
+
+	auth := &todev.Auth{
+		Source:      todev.AuthSourceGitHub,
+		SourceID:    "100",
+		AccessToken: "ACCESS",
+		User:        &todev.User{Name: "bob", Email: "bob@gmail.com"},
+	}
+	if err = postgres.NewAuthService(conn).CreateAuth(ctx, auth); todev.ErrorCode(err) != todev.ECONFLICT {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err = s.VerifyEmail(ctx, token); err != nil {
+		t.Fatal(err)
+	} else if err = s.VerifyEmail(ctx, token); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err = postgres.NewAuthService(conn).CreateAuth(ctx, auth); err != nil {
+		t.Fatal(err)
+	} else if _, err = sessions.AuthenticateSession(ctx, session.Secret, ""); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func resetPassword_OK(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewPasswordService(conn)
+	ctx := context.Background()
+
+	if _, err := s.Register(ctx, &todev.User{Name: "bob", Email: "bob@gmail.com"}, "password1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only local accounts can be reset.
+	MustCreateUser(t, ctx, conn, &todev.User{Name: "jill", Email: "jill@gmail.com"})
+	if _, err := s.CreatePasswordReset(ctx, "jill@gmail.com"); todev.ErrorCode(err) != todev.ENOTFOUND {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	token, err := s.CreatePasswordReset(ctx, "bob@gmail.com")
+	if err != nil {
+		t.Fatal(err)
+	} else if err = s.ResetPassword(ctx, token, "password2"); err != nil {
+		t.Fatal(err)
+	} else if _, err = s.Login(ctx, "bob@gmail.com", "password2"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Tokens can only be used once.
+	if err = s.ResetPassword(ctx, token, "password3"); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func resetPassword_Expired(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewPasswordService(conn)
+	ctx := context.Background()
+
+	t0 := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	conn.Now = func() time.Time { return t0 }
+
+	if _, err := s.Register(ctx, &todev.User{Name: "bob", Email: "bob@gmail.com"}, "password1"); err != nil {
+		t.Fatal(err)
+	}
+	token, err := s.CreatePasswordReset(ctx, "bob@gmail.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn.Now = func() time.Time { return t0.Add(todev.PasswordResetTTL) }
+	if err = s.ResetPassword(ctx, token, "password2"); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func resetPassword_RevokesSessions(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewPasswordService(conn)
+	sessions := postgres.NewSessionService(conn)
+	ctx := context.Background()
+
+	user := &todev.User{Name: "bob", Email: "bob@gmail.com"}
+	if _, err := s.Register(ctx, user, "password1"); err != nil {
+		t.Fatal(err)
+	}
+	session := &todev.Session{UserID: user.ID}
+	if err := sessions.CreateSession(todev.NewContextWithUser(ctx, user), session); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := s.CreatePasswordReset(ctx, "bob@gmail.com")
+	if err != nil {
+		t.Fatal(err)
+	} else if err = s.ResetPassword(ctx, token, "password2"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sessions.AuthenticateSession(ctx, session.Secret, ""); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func changePassword_RevokesOtherSessions(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewPasswordService(conn)
+	sessions := postgres.NewSessionService(conn)
+
+	user := &todev.User{Name: "bob", Email: "bob@gmail.com"}
+	if _, err := s.Register(context.Background(), user, "password1"); err != nil {
+		t.Fatal(err)
+	}
+	ctx := todev.NewContextWithUser(context.Background(), user)
+
+	current, other := &todev.Session{UserID: user.ID}, &todev.Session{UserID: user.ID}
+	if err := sessions.CreateSession(ctx, current); err != nil {
+		t.Fatal(err)
+	} else if err := sessions.CreateSession(ctx, other); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.ChangePassword(todev.NewContextWithSession(ctx, current), "password1", "password2"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The browser the password was changed from stays logged in.
+	if _, err := sessions.AuthenticateSession(ctx, current.Secret, ""); err != nil {
+		t.Fatal(err)
+	} else if _, err := sessions.AuthenticateSession(ctx, other.Secret, ""); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
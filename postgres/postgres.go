@@ -9,6 +9,7 @@ import (
 	"io/fs"
 	"log"
 	"sort"
+	"strings"
 	"time"
 
 	_ "github.com/lib/pq"
@@ -53,8 +54,21 @@ type Conn struct {
 	// Runs the current time. Defaults to time.Now().
 	// Can be mocked for tests
 	Now func() time.Time
+
+	// Duration events are kept for replay. Zero keeps events forever.
+	EventRetention time.Duration
+
+	// Duration trashed tasks and repos are kept for restore. Zero keeps
+	// trashed items forever.
+	TrashRetention time.Duration
 }
 
+// EventPurgeInterval is the interval between purges of expired events.
+const EventPurgeInterval = time.Hour
+
+// TrashPurgeInterval is the interval between purges of expired trash.
+const TrashPurgeInterval = time.Hour
+
 func New(dsn string) *Conn {
 	conn := &Conn{
 		DSN:          dsn,
@@ -84,6 +98,16 @@ func (conn *Conn) Open() (err error) {
 	// Monitor stats in background goroutine.
 	go conn.monitor()
 
+	// Purge expired events in background goroutine.
+	if conn.EventRetention > 0 {
+		go conn.purgeEvents()
+	}
+
+	// Purge expired trash in background goroutine.
+	if conn.TrashRetention > 0 {
+		go conn.purgeTrash()
+	}
+
 	return nil
 }
 
@@ -167,7 +191,7 @@ func (conn *Conn) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error)
 	return &Tx{
 		Tx:   tx,
 		conn: conn,
-		now:  time.Now().UTC().Truncate(time.Second),
+		now:  conn.Now().UTC().Truncate(time.Second),
 	}, nil
 }
 
@@ -219,11 +243,89 @@ func (conn *Conn) updateStats(ctx context.Context) error {
 
 }
 
+// purgeEvents runs in a goroutine and periodically deletes expired events.
+func (conn *Conn) purgeEvents() {
+	ticker := time.NewTicker(EventPurgeInterval)
+	defer ticker.Stop()
+
+	s := NewEventLogService(conn)
+	for {
+		if n, err := s.PurgeEvents(conn.Ctx, conn.Now().Add(-conn.EventRetention)); err != nil {
+			log.Printf("error purging events: %v", err)
+		} else if n > 0 {
+			log.Printf("purged %d expired events", n)
+		}
+
+		select {
+		case <-conn.Ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeTrash runs in a goroutine and periodically deletes tasks and repos
+// that were trashed longer than the retention period ago.
+func (conn *Conn) purgeTrash() {
+	ticker := time.NewTicker(TrashPurgeInterval)
+	defer ticker.Stop()
+
+	s := NewRepoService(conn)
+	for {
+		if n, err := s.PurgeTrash(conn.Ctx, conn.Now().Add(-conn.TrashRetention)); err != nil {
+			log.Printf("error purging trash: %v", err)
+		} else if n > 0 {
+			log.Printf("purged %d trashed items", n)
+		}
+
+		select {
+		case <-conn.Ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // Tx wrappes *sql.Tx object to provide a timestamp at the start of the transaction.
+// Events published within the transaction are queued until it is committed.
 type Tx struct {
 	*sql.Tx
 	conn *Conn
 	now  time.Time
+
+	events []txEvent // events to publish on commit
+}
+
+// txEvent represents an event queued for a user during a transaction.
+type txEvent struct {
+	userID int
+	event  todev.Event
+}
+
+// PublishEvent queues an event for a user. Queued events are only published
+// once the transaction commits successfully.
+func (tx *Tx) PublishEvent(userID int, event todev.Event) {
+	tx.events = append(tx.events, txEvent{userID: userID, event: event})
+}
+
+// Commit commits the transaction and then publishes queued events.
+func (tx *Tx) Commit() error {
+	if err := tx.Tx.Commit(); err != nil {
+		tx.events = nil
+		return err
+	}
+
+	for _, e := range tx.events {
+		tx.conn.EventService.PublishEvent(e.userID, e.event)
+	}
+	tx.events = nil
+	return nil
+}
+
+// Rollback aborts the transaction and discards queued events.
+func (tx *Tx) Rollback() error {
+	tx.events = nil
+	return tx.Tx.Rollback()
 }
 
 // NullTime represents a helper wrapper for time.Time. It automatically converts
@@ -263,7 +365,7 @@ func (n *NullTime) Value() (driver.Value, error) {
 
 // FormatLimitOffset returns s SQL string for the given limit & offset.
 func FormatLimitOffset(limit, offset int) string {
-	if limit > 0 && offset < 0 {
+	if limit > 0 && offset > 0 {
 		return fmt.Sprintf(`LIMIT %d OFFSET %d`, limit, offset)
 	} else if limit > 0 {
 		return fmt.Sprintf(`LIMIT %d`, limit)
@@ -274,6 +376,74 @@ func FormatLimitOffset(limit, offset int) string {
 	return ""
 }
 
+// sortKey represents a column or expression that query results are sorted by.
+type sortKey struct {
+	expr string
+	desc bool
+}
+
+// formatOrderBy returns the ORDER BY list for the given sort keys.
+func formatOrderBy(keys []sortKey) string {
+	a := make([]string, len(keys))
+	for i, key := range keys {
+		a[i] = key.expr + " ASC"
+		if key.desc {
+			a[i] = key.expr + " DESC"
+		}
+	}
+	return strings.Join(a, ", ")
+}
+
+// formatKeyset returns a condition matching the rows that are sorted after the
+// cursor row, whose ID is the query argument at argIndex. The sort key values
+// of the cursor row are looked up from the given FROM clause, which must use
+// the same aliases as the main query. The last key must be unique.
+func formatKeyset(keys []sortKey, from string, argIndex int) string {
+	anchor := func(expr string) string {
+		return fmt.Sprintf("(SELECT %s FROM %s WHERE %s = $%d)", expr, from, keys[len(keys)-1].expr, argIndex)
+	}
+
+	or := make([]string, len(keys))
+	for i, key := range keys {
+		and := make([]string, 0, i+1)
+		for _, prev := range keys[:i] {
+			and = append(and, fmt.Sprintf("%s = %s", prev.expr, anchor(prev.expr)))
+		}
+
+		op := ">"
+		if key.desc {
+			op = "<"
+		}
+		and = append(and, fmt.Sprintf("%s %s %s", key.expr, op, anchor(key.expr)))
+		or[i] = "(" + strings.Join(and, " AND ") + ")"
+	}
+	return "(" + strings.Join(or, " OR ") + ")"
+}
+
+// findCursorID returns the ID of the row of table that cursor points after, or
+// zero if cursor is empty. Returns EINVALID if the cursor is combined with an
+// offset or if the row does not exist.
+func findCursorID(ctx context.Context, tx *Tx, table, cursor string, offset int) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	} else if offset != 0 {
+		return 0, todev.Errorf(todev.EINVALID, "Cursor cannot be combined with offset.")
+	}
+
+	id, err := todev.DecodeCursor(cursor)
+	if err != nil {
+		return 0, err
+	}
+
+	var exists bool
+	if err = tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM `+table+` WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("error checking cursor: %w", err)
+	} else if !exists {
+		return 0, todev.Errorf(todev.EINVALID, "Invalid cursor.")
+	}
+	return id, nil
+}
+
 // logstr is helper fuction for printing and returning a string.
 // It can be useful for printing out query text.
 func logstr(s string) string {
@@ -8,6 +8,8 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/mock"
 	"github.com/saiddis/todev/postgres"
 )
 
@@ -121,3 +123,21 @@ func dropSchema(conn *postgres.Conn, schema string) error {
 	}
 	return nil
 }
+
+// PublishedEvent represents an event published to a user.
+type PublishedEvent struct {
+	UserID int
+	Event  todev.Event
+}
+
+// RecordEvents replaces the event service of conn with one that records all
+// published events.
+func RecordEvents(conn *postgres.Conn) *[]PublishedEvent {
+	events := make([]PublishedEvent, 0)
+	conn.EventService = &mock.EventService{
+		PublishEventFn: func(id int, event todev.Event) {
+			events = append(events, PublishedEvent{UserID: id, Event: event})
+		},
+	}
+	return &events
+}
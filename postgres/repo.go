@@ -2,12 +2,10 @@ package postgres
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
 	"fmt"
-	"io"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/saiddis/todev"
 )
@@ -57,6 +55,12 @@ func (s *RepoService) CreateRepo(ctx context.Context, repo *todev.Repo) error {
 		return fmt.Errorf("error creating self contributor: %w", err)
 	}
 
+	// Every repo starts with an invitation for members that the owner can
+	// later rotate or revoke.
+	if err = createInvitation(ctx, tx, &todev.Invitation{RepoID: repo.ID, Role: todev.RoleMember}); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -156,7 +160,7 @@ func (s *RepoService) UpdateRepo(ctx context.Context, id int, upd todev.RepoUpda
 	return repo, nil
 }
 
-// DeleteRepo pemanently removes a repo by ID. Only the repo owner may
+// DeleteRepo moves a repo to the trash by ID. Only the repo owner may
 // delete a repo. Returns ENOTFOUND if the repo does not exist.
 // Returns EUNAUTHORIZED if user is not the owner.
 func (s *RepoService) DeleteRepo(ctx context.Context, id int) error {
@@ -184,6 +188,96 @@ func (s *RepoService) DeleteRepo(ctx context.Context, id int) error {
 	return nil
 }
 
+// RestoreRepo moves a repo out of the trash. Returns ENOTFOUND if the repo is
+// not trashed or the user is not a member. Returns EUNAUTHORIZED if the user
+// is not the owner.
+func (s *RepoService) RestoreRepo(ctx context.Context, id int) (*todev.Repo, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("RestoreRepo: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	repo, err := restoreRepo(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// PurgeTrash permanently deletes the tasks and repos trashed before the given
+// time. Returns the number of deleted tasks and repos.
+func (s *RepoService) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("PurgeTrash: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	n, err := purgeTrash(ctx, tx, before)
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// TasksLeftReport returns a report of the uncompleted tasks of a repo at every
+// interval between start and end. Only the repo owner and contributors can see
+// the report. Returns ENOTFOUND if repo does not exist or user does not have
+// permission to view it.
+func (s *RepoService) TasksLeftReport(ctx context.Context, repoID int, start, end time.Time, interval time.Duration) (*todev.RepoTasksReport, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("TasksLeftReport: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	if err = todev.ValidateRepoTasksReport(start, end, interval); err != nil {
+		return nil, err
+	} else if _, err = findRepoByID(ctx, tx, repoID); err != nil {
+		return nil, err
+	}
+
+	report, err := tasksLeftReport(ctx, tx, repoID, start, end, interval)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
 func createRepo(ctx context.Context, tx *Tx, repo *todev.Repo) (err error) {
 	// Assign repo to the current user.
 	userID := todev.UserIDFromContext(ctx)
@@ -192,12 +286,6 @@ func createRepo(ctx context.Context, tx *Tx, repo *todev.Repo) (err error) {
 	}
 	repo.UserID = userID
 
-	inviteCode := make([]byte, 16)
-	if _, err = io.ReadFull(rand.Reader, inviteCode); err != nil {
-		return fmt.Errorf("error generating invite code: %w", err)
-	}
-	repo.InviteCode = hex.EncodeToString(inviteCode)
-
 	repo.CreatedAt = tx.now
 	repo.UpdatedAt = repo.CreatedAt
 
@@ -211,7 +299,7 @@ func createRepo(ctx context.Context, tx *Tx, repo *todev.Repo) (err error) {
 		INSERT INTO repos (
 			user_id,
 			name,
-			invite_code,
+			github_repo,
 			created_at,
 			updated_at
 		)
@@ -219,13 +307,20 @@ func createRepo(ctx context.Context, tx *Tx, repo *todev.Repo) (err error) {
 		RETURNING id;`,
 		repo.UserID,
 		repo.Name,
-		repo.InviteCode,
+		repo.GitHubRepo,
 		(*NullTime)(&repo.CreatedAt),
 		(*NullTime)(&repo.UpdatedAt),
 	).Scan(&repo.ID)
 	if err != nil {
 		return fmt.Errorf("error creating repo: %w", err)
 	}
+
+	for _, state := range todev.DefaultTaskStates() {
+		state.RepoID = repo.ID
+		if err = createTaskState(ctx, tx, state); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -236,16 +331,26 @@ func findRepos(ctx context.Context, tx *Tx, filter todev.RepoFilter) ([]*todev.R
 		argIndex++
 		where, args = append(where, fmt.Sprintf("id = $%d", argIndex)), append(args, *v)
 	}
-	if v := filter.InviteCode; v != nil {
-		argIndex++
-		where, args = append(where, fmt.Sprintf("invite_code = $%d", argIndex)), append(args, *v)
+	if filter.Trashed {
+		where = append(where, "deleted_at IS NOT NULL")
 	} else {
+		where = append(where, "deleted_at IS NULL")
+	}
+
+	argIndex++
+	userID := todev.UserIDFromContext(ctx)
+	where = append(where, fmt.Sprintf(`(
+		id IN (SELECT repo_id FROM contributors c WHERE c.user_id = $%d)
+		)`, argIndex))
+	args = append(args, userID)
+
+	keys := []sortKey{{expr: "id"}}
+	cursorID, err := findCursorID(ctx, tx, "repos", filter.Cursor, filter.Offset)
+	if err != nil {
+		return nil, 0, err
+	} else if cursorID != 0 {
 		argIndex++
-		userID := todev.UserIDFromContext(ctx)
-		where = append(where, fmt.Sprintf(`(
-			id IN (SELECT repo_id FROM contributors c WHERE c.user_id = $%d)
-			)`, argIndex))
-		args = append(args, userID)
+		where, args = append(where, formatKeyset(keys, "repos", argIndex)), append(args, cursorID)
 	}
 
 	stmt, err := tx.PrepareContext(ctx, `
@@ -253,14 +358,16 @@ func findRepos(ctx context.Context, tx *Tx, filter todev.RepoFilter) ([]*todev.R
 			id,
 			user_id,
 			name,
-			invite_code,
+			github_repo,
+			event_seq,
 			created_at,
 			updated_at,
+			deleted_at,
 			COUNT(*) OVER()
 		FROM repos
 		WHERE `+strings.Join(where, " AND ")+`
 		GROUP BY id
-		ORDER BY id ASC
+		ORDER BY `+formatOrderBy(keys)+`
 		`+FormatLimitOffset(filter.Limit, filter.Offset)+`;`,
 	)
 	if err != nil {
@@ -286,9 +393,11 @@ func findRepos(ctx context.Context, tx *Tx, filter todev.RepoFilter) ([]*todev.R
 			&repo.ID,
 			&repo.UserID,
 			&repo.Name,
-			&repo.InviteCode,
+			&repo.GitHubRepo,
+			&repo.EventSeq,
 			(*NullTime)(&repo.CreatedAt),
 			(*NullTime)(&repo.UpdatedAt),
+			(*NullTime)(&repo.DeletedAt),
 			&n,
 		); err != nil {
 			return nil, 0, fmt.Errorf("error scanning: %w", err)
@@ -318,13 +427,31 @@ func updateRepo(ctx context.Context, tx *Tx, id int, upd todev.RepoUpdate) (*tod
 	repo, err := findRepoByID(ctx, tx, id)
 	if err != nil {
 		return repo, fmt.Errorf("error finding repo to update: %w", err)
-	} else if !todev.CanEditRepo(ctx, *repo) {
+	}
+
+	role, _, err := findCurrentRole(ctx, tx, repo.ID)
+	if err != nil {
+		return nil, err
+	} else if !role.CanEditRepo() {
 		return nil, todev.Errorf(todev.EUNAUTHORIZED, "You are not allowed to update this repo.")
+	} else if upd.GitHubRepo != nil && role != todev.RoleOwner {
+		return nil, todev.Errorf(todev.EUNAUTHORIZED, "Only the owner can link a repo to GitHub.")
 	}
 
+	before := repoActivityFields(repo)
 	if v := upd.Name; v != nil {
 		repo.Name = *v
 	}
+	if v := upd.GitHubRepo; v != nil {
+		repo.GitHubRepo = *v
+	}
+	if upd.States != nil {
+		if repo.States, err = findTaskStates(ctx, tx, repo.ID); err != nil {
+			return nil, err
+		} else if err = replaceTaskStates(ctx, tx, repo, upd.States); err != nil {
+			return nil, err
+		}
+	}
 
 	repo.UpdatedAt = tx.now
 
@@ -334,14 +461,28 @@ func updateRepo(ctx context.Context, tx *Tx, id int, upd todev.RepoUpdate) (*tod
 
 	_, err = tx.ExecContext(ctx, `
 		UPDATE repos
-		SET name = $1, updated_at = $2
-		WHERE id = $3;`,
+		SET name = $1, github_repo = $2, updated_at = $3
+		WHERE id = $4;`,
 		repo.Name,
+		repo.GitHubRepo,
 		(*NullTime)(&repo.UpdatedAt),
 		id,
 	)
 	if err != nil {
 		return repo, fmt.Errorf("error updating repo: %w", err)
+	} else if err = createUpdateActivity(ctx, tx, repo.ID, todev.ActivityEntityRepo, repo.ID, before, repoActivityFields(repo)); err != nil {
+		return repo, err
+	}
+
+	if upd.States != nil {
+		if err = publishRepoEvent(ctx, tx, repo.ID, todev.Event{
+			Type: todev.EventTypeRepoStatesChanged,
+			Payload: todev.RepoStatesChanged{
+				States: repo.States,
+			},
+		}); err != nil {
+			return repo, err
+		}
 	}
 	return repo, nil
 }
@@ -350,17 +491,148 @@ func deleteRepo(ctx context.Context, tx *Tx, id int) (err error) {
 	repo, err := findRepoByID(ctx, tx, id)
 	if err != nil {
 		return fmt.Errorf("error retrieving user by id: %w", err)
-	} else if !todev.CanEditRepo(ctx, *repo) {
+	}
+
+	if role, _, err := findCurrentRole(ctx, tx, repo.ID); err != nil {
+		return err
+	} else if !role.CanDeleteRepo() {
 		return todev.Errorf(todev.EUNAUTHORIZED, "Only the owner can delete a repo.")
 	}
 
-	if _, err = tx.ExecContext(ctx, "DELETE FROM repos WHERE id = $1;", id); err != nil {
+	repo.DeletedAt = tx.now
+	if _, err = tx.ExecContext(ctx, "UPDATE repos SET deleted_at = $1 WHERE id = $2;", (*NullTime)(&repo.DeletedAt), id); err != nil {
 		return fmt.Errorf("error deleting repo: %w", err)
+	} else if err = createActivity(ctx, tx, &todev.Activity{
+		RepoID:     repo.ID,
+		EntityType: todev.ActivityEntityRepo,
+		EntityID:   repo.ID,
+		Action:     todev.ActivityActionDelete,
+		Before:     repoActivityFields(repo),
+	}); err != nil {
+		return err
 	}
 
 	return nil
 }
 
+func restoreRepo(ctx context.Context, tx *Tx, id int) (*todev.Repo, error) {
+	repos, _, err := findRepos(ctx, tx, todev.RepoFilter{ID: &id, Trashed: true})
+	if err != nil {
+		return nil, err
+	} else if len(repos) == 0 {
+		return nil, todev.Errorf(todev.ENOTFOUND, "Repo not found in trash.")
+	}
+	repo := repos[0]
+
+	if role, _, err := findCurrentRole(ctx, tx, repo.ID); err != nil {
+		return nil, err
+	} else if !role.CanDeleteRepo() {
+		return nil, todev.Errorf(todev.EUNAUTHORIZED, "Only the owner can restore a repo.")
+	}
+
+	repo.DeletedAt = time.Time{}
+	if _, err = tx.ExecContext(ctx, "UPDATE repos SET deleted_at = NULL WHERE id = $1;", id); err != nil {
+		return nil, fmt.Errorf("error restoring repo: %w", err)
+	} else if err = createActivity(ctx, tx, &todev.Activity{
+		RepoID:     repo.ID,
+		EntityType: todev.ActivityEntityRepo,
+		EntityID:   repo.ID,
+		Action:     todev.ActivityActionRestore,
+		After:      repoActivityFields(repo),
+	}); err != nil {
+		return nil, err
+	} else if err = attachRepoAssociations(ctx, tx, repo); err != nil {
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// purgeTrash permanently deletes the tasks and repos trashed before the given
+// time. Tasks of purged repos are deleted along with them, as trashing a repo
+// leaves its tasks untouched.
+func purgeTrash(ctx context.Context, tx *Tx, before time.Time) (int, error) {
+	var n int
+	for _, query := range []string{
+		`DELETE FROM tasks WHERE deleted_at < $1 OR repo_id IN (SELECT id FROM repos WHERE deleted_at < $1);`,
+		`DELETE FROM repos WHERE deleted_at < $1;`,
+	} {
+		result, err := tx.ExecContext(ctx, query, (*NullTime)(&before))
+		if err != nil {
+			return 0, fmt.Errorf("error purging trash: %w", err)
+		}
+
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return 0, fmt.Errorf("error retrieving purged trash count: %w", err)
+		}
+		n += int(deleted)
+	}
+	return n, nil
+}
+
+// tasksLeftReport fetches all the tasks of a repo created before the end of
+// the report and groups them by the timestamps at which they were left.
+func tasksLeftReport(ctx context.Context, tx *Tx, repoID int, start, end time.Time, interval time.Duration) (*todev.RepoTasksReport, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			id,
+			repo_id,
+			is_completed,
+			description,
+			created_at,
+			updated_at,
+			completed_at
+		FROM tasks
+		WHERE repo_id = $1 AND created_at <= $2 AND deleted_at IS NULL
+		ORDER BY id ASC;`,
+		repoID,
+		(*NullTime)(&end),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving tasks: %w", err)
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("error closing rows: %v", err)
+		}
+	}()
+
+	tasks := make([]todev.Task, 0)
+	for rows.Next() {
+		var task todev.Task
+		if err = rows.Scan(
+			&task.ID,
+			&task.RepoID,
+			&task.IsCompleted,
+			&task.Description,
+			(*NullTime)(&task.CreatedAt),
+			(*NullTime)(&task.UpdatedAt),
+			(*NullTime)(&task.CompletedAt),
+		); err != nil {
+			return nil, fmt.Errorf("error scanning: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	report := &todev.RepoTasksReport{Records: make([]*todev.RepoTasksRecord, 0)}
+	for t := start; !t.After(end); t = t.Add(interval) {
+		record := &todev.RepoTasksRecord{Tasks: make([]todev.Task, 0), Timestamp: t}
+		for _, task := range tasks {
+			if task.IsLeftAt(t) {
+				record.Tasks = append(record.Tasks, task)
+			}
+		}
+		report.Records = append(report.Records, record)
+	}
+
+	return report, nil
+}
+
 // checkRepoExists returns nil if a repo does not exist. Otherwise returns ENOTFOUND.
 func checkRepoExists(ctx context.Context, tx *Tx, id int) error {
 	var n int
@@ -372,8 +644,13 @@ func checkRepoExists(ctx context.Context, tx *Tx, id int) error {
 	return nil
 }
 
-// publishRepoEvent publishes events to the repo contributors.
+// publishRepoEvent records an event in the repo's event log and queues it for
+// the repo contributors. Events are published once the transaction commits.
 func publishRepoEvent(ctx context.Context, tx *Tx, id int, event todev.Event) error {
+	if err := createEvent(ctx, tx, id, &event); err != nil {
+		return err
+	}
+
 	// Find all users who are members of the repo.
 	stmt, err := tx.PrepareContext(ctx, `
 		SELECT user_id FROM contributors
@@ -394,7 +671,7 @@ func publishRepoEvent(ctx context.Context, tx *Tx, id int, event todev.Event) er
 		if err = rows.Scan(&userID); err != nil {
 			return fmt.Errorf("error scanning: %w", err)
 		}
-		tx.conn.EventService.PublishEvent(userID, event)
+		tx.PublishEvent(userID, event)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -413,5 +690,11 @@ func attachRepoAssociations(ctx context.Context, tx *Tx, repo *todev.Repo) (err
 	}
 	repo.UserID = user.ID
 
+	if repo.States, err = findTaskStates(ctx, tx, repo.ID); err != nil {
+		return fmt.Errorf("error attaching repo states: %w", err)
+	} else if repo.Dependencies, err = findTaskDependencies(ctx, tx, repo.ID); err != nil {
+		return fmt.Errorf("error attaching repo dependencies: %w", err)
+	}
+
 	return nil
 }
@@ -3,8 +3,10 @@ package postgres_test
 import (
 	"context"
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/saiddis/todev"
 	"github.com/saiddis/todev/postgres"
@@ -26,6 +28,10 @@ func TestRepoService_UpdateRepo(t *testing.T) {
 	t.Run("OK", func(t *testing.T) {
 		WithSchema(t, updateRepo_OK)
 	})
+
+	t.Run("States", func(t *testing.T) {
+		WithSchema(t, updateRepo_States)
+	})
 }
 
 func TestRepoService_FindRepos(t *testing.T) {
@@ -35,9 +41,6 @@ func TestRepoService_FindRepos(t *testing.T) {
 	t.Run("Member_of", func(t *testing.T) {
 		WithSchema(t, findRepos_MemberOf)
 	})
-	t.Run("InviteCode", func(t *testing.T) {
-		WithSchema(t, findRepos_InviteCode)
-	})
 }
 
 func TestRepoService_DeleteRepo(t *testing.T) {
@@ -60,8 +63,6 @@ func createRepo_OK(t testing.TB, conn *postgres.Conn) {
 		t.Fatalf("ID=%d, want %d", got, want)
 	} else if got, want := repo.UserID, 1; got != want {
 		t.Fatalf("UserID=%d, want %d", got, want)
-	} else if repo.InviteCode == "" {
-		t.Fatal("expected invite code genearation")
 	} else if repo.CreatedAt.IsZero() {
 		t.Fatal("expected created at")
 	} else if repo.UpdatedAt.IsZero() {
@@ -145,6 +146,95 @@ func updateRepo_OK(t testing.TB, conn *postgres.Conn) {
 	}
 }
 
+func updateRepo_States(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewRepoService(conn)
+
+	_, ctx0 := MustCreateUser(t, context.Background(), conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	repo := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "NAME"})
+	todo, doing, done := repo.States[0], repo.States[1], repo.States[2]
+
+	task := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Do some stuff.", RepoID: repo.ID})
+	MustUpdateTask(t, ctx0, conn, task.ID, todev.TaskUpdate{SetStatus: &done.ID})
+	dependent := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Do more stuff.", RepoID: repo.ID})
+	MustAddDependency(t, ctx0, conn, dependent.ID, task.ID)
+
+	// Drop the unused state, add a review step and rename the terminal state.
+	states := []*todev.TaskState{
+		{ID: todo.ID, Name: todo.Name},
+		{Name: "Review"},
+		{ID: done.ID, Name: "Shipped", IsTerminal: true},
+	}
+	if other, err := s.UpdateRepo(ctx0, repo.ID, todev.RepoUpdate{States: states}); err != nil {
+		t.Fatal(err)
+	} else if got, want := len(other.States), 3; got != want {
+		t.Fatalf("len(States)=%d, want %d", got, want)
+	} else if got, want := other.States[1].Name, "Review"; got != want {
+		t.Fatalf("Name=%q, want %q", got, want)
+	} else if other.StateByID(doing.ID) != nil {
+		t.Fatal("expected state to be removed")
+	} else if got, want := other.TerminalState().Name, "Shipped"; got != want {
+		t.Fatalf("Name=%q, want %q", got, want)
+	}
+
+	// States that still have tasks cannot be removed.
+	if _, err := s.UpdateRepo(ctx0, repo.ID, todev.RepoUpdate{States: []*todev.TaskState{
+		{ID: todo.ID, Name: todo.Name},
+		{Name: "Archived", IsTerminal: true},
+	}}); todev.ErrorCode(err) != todev.ECONFLICT {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Tasks follow their state when it stops being terminal.
+	seq := MustFindRepoByID(t, ctx0, conn, repo.ID).EventSeq
+	if _, err := s.UpdateRepo(ctx0, repo.ID, todev.RepoUpdate{States: []*todev.TaskState{
+		{ID: todo.ID, Name: todo.Name},
+		{ID: done.ID, Name: "Shipped"},
+		{Name: "Archived", IsTerminal: true},
+	}}); err != nil {
+		t.Fatal(err)
+	} else if other, err := postgres.NewTaskService(conn).FindTaskByID(ctx0, task.ID); err != nil {
+		t.Fatal(err)
+	} else if other.IsCompleted {
+		t.Fatal("expected task to be reopened")
+	} else if !other.CompletedAt.IsZero() {
+		t.Fatalf("CompletedAt=%v, want zero", other.CompletedAt)
+	}
+
+	// Reopened tasks are reported like any other status change.
+	var types []string
+	if events, err := postgres.NewEventLogService(conn).FindEvents(ctx0, repo.ID, seq); err != nil {
+		t.Fatal(err)
+	} else {
+		for _, event := range events {
+			types = append(types, event.Type)
+		}
+	}
+	if !slices.Contains(types, todev.EventTypeTaskStatusChanged) {
+		t.Fatalf("expected status change event: %v", types)
+	} else if !slices.Contains(types, todev.EventTypeTaskBlocked) {
+		t.Fatalf("expected blocked event: %v", types)
+	}
+
+	entityType := todev.ActivityEntityTask
+	if activities, _, err := postgres.NewActivityService(conn).FindActivities(ctx0, todev.ActivityFilter{
+		RepoID:     &repo.ID,
+		EntityType: &entityType,
+		EntityID:   &task.ID,
+		Limit:      1,
+	}); err != nil {
+		t.Fatal(err)
+	} else if got, want := activities[0].After, map[string]interface{}{"isCompleted": false}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("After=%v, want %v", got, want)
+	}
+
+	// A workflow needs a terminal state.
+	if _, err := s.UpdateRepo(ctx0, repo.ID, todev.RepoUpdate{States: []*todev.TaskState{
+		{ID: todo.ID, Name: todo.Name},
+	}}); todev.ErrorCode(err) != todev.EINVALID {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func findRepos_owned(t testing.TB, conn *postgres.Conn) {
 	ctx := context.Background()
 	s := postgres.NewRepoService(conn)
@@ -192,23 +282,39 @@ func findRepos_MemberOf(t testing.TB, conn *postgres.Conn) {
 	}
 }
 
-func findRepos_InviteCode(t testing.TB, conn *postgres.Conn) {
+func TestRepoService_TasksLeftReport(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		WithSchema(t, tasksLeftReport_OK)
+	})
+}
+
+func tasksLeftReport_OK(t testing.TB, conn *postgres.Conn) {
 	s := postgres.NewRepoService(conn)
-	ctx := context.Background()
-	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
 
-	repo1 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	// Mock the clock so task timestamps fall on known report intervals.
+	t0 := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	conn.Now = func() time.Time { return t0 }
 
-	MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo2"})
+	_, ctx0 := MustCreateUser(t, context.Background(), conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	repo := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "NAME"})
+	task0 := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "first", RepoID: repo.ID})
+	MustCreateTask(t, ctx0, conn, &todev.Task{Description: "second", RepoID: repo.ID})
+
+	// Complete the first task an hour later.
+	conn.Now = func() time.Time { return t0.Add(time.Hour) }
+	MustUpdateTask(t, ctx0, conn, task0.ID, todev.TaskUpdate{SetStatus: &repo.TerminalState().ID})
 
-	if repos, n, err := s.FindRepos(ctx0, todev.RepoFilter{InviteCode: &repo1.InviteCode}); err != nil {
+	report, err := s.TasksLeftReport(ctx0, repo.ID, t0, t0.Add(2*time.Hour), time.Hour)
+	if err != nil {
 		t.Fatal(err)
-	} else if got, want := len(repos), 1; got != want {
-		t.Fatalf("len=%d, want %d", got, want)
-	} else if got, want := repos[0].Name, "repo1"; got != want {
-		t.Fatalf("repos[0].Name=%s, want %s", got, want)
-	} else if got, want := n, 1; got != want {
-		t.Fatalf("n=%d, want %d", got, want)
+	} else if got, want := len(report.Records), 3; got != want {
+		t.Fatalf("len(Records)=%d, want %d", got, want)
+	}
+
+	for i, want := range []int{2, 1, 1} {
+		if got := len(report.Records[i].Tasks); got != want {
+			t.Fatalf("len(Records[%d].Tasks)=%d, want %d", i, got, want)
+		}
 	}
 }
 
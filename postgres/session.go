@@ -0,0 +1,393 @@
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/saiddis/todev"
+)
+
+// sessionLastSeenInterval is how often the last activity of a session is
+// recorded so every page view does not have to write to the database.
+const sessionLastSeenInterval = time.Minute
+
+// Ensure service implements interface.
+var _ todev.SessionService = (*SessionService)(nil)
+
+// SessionService represents a service for managing browser sessions.
+type SessionService struct {
+	conn *Conn
+}
+
+// NewSessionService returns a new instance of SessionService.
+func NewSessionService(conn *Conn) *SessionService {
+	return &SessionService{conn: conn}
+}
+
+// FindSessions retrieves the current user's sessions based on filter, newest
+// first. Expired sessions are not returned. Also returns the total number of
+// matching sessions.
+func (s *SessionService) FindSessions(ctx context.Context, filter todev.SessionFilter) ([]*todev.Session, int, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("FindSessions: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	sessions, n, err := findSessions(ctx, tx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return sessions, n, nil
+}
+
+// CreateSession creates a new session for session.UserID and sets its secret.
+// Expired sessions of the user are removed at the same time.
+func (s *SessionService) CreateSession(ctx context.Context, session *todev.Session) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("CreateSession: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	if err = createSession(ctx, tx, session); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AuthenticateSession looks up a session by secret along with its user and
+// records its activity. Returns EUNAUTHORIZED if the session is unknown or
+// has expired.
+func (s *SessionService) AuthenticateSession(ctx context.Context, secret, ip string) (*todev.Session, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("AuthenticateSession: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	session, err := authenticateSession(ctx, tx, secret, ip)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// DeleteSession revokes a session by ID. Returns ENOTFOUND if the session
+// does not belong to the current user.
+func (s *SessionService) DeleteSession(ctx context.Context, id int) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("DeleteSession: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	if err = deleteSession(ctx, tx, id); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteSessions revokes all the sessions of the current user.
+func (s *SessionService) DeleteSessions(ctx context.Context) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("DeleteSessions: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	if err = deleteSessions(ctx, tx); err != nil {
+		return err
+	}
+	return nil
+}
+
+// generateSessionSecret returns a new random session secret.
+func generateSessionSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", fmt.Errorf("error generating session: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func createSession(ctx context.Context, tx *Tx, session *todev.Session) (err error) {
+	if err = session.Validate(); err != nil {
+		return err
+	}
+
+	if session.Secret, err = generateSessionSecret(); err != nil {
+		return err
+	}
+	session.LastSeenAt = tx.now
+	session.ExpiresAt = tx.now.Add(todev.SessionTTL)
+	session.CreatedAt = tx.now
+
+	if _, err = tx.ExecContext(ctx, `
+		DELETE FROM sessions WHERE user_id = $1 AND expires_at <= $2;`,
+		session.UserID,
+		(*NullTime)(&tx.now),
+	); err != nil {
+		return fmt.Errorf("error deleting expired sessions: %w", err)
+	}
+
+	if err = tx.QueryRowContext(ctx, `
+		INSERT INTO sessions (
+			user_id,
+			hash,
+			user_agent,
+			ip,
+			last_seen_at,
+			expires_at,
+			created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id;`,
+		session.UserID,
+		hashToken(session.Secret),
+		session.UserAgent,
+		session.IP,
+		(*NullTime)(&session.LastSeenAt),
+		(*NullTime)(&session.ExpiresAt),
+		(*NullTime)(&session.CreatedAt),
+	).Scan(&session.ID); err != nil {
+		return fmt.Errorf("error inserting session: %w", err)
+	}
+	return nil
+}
+
+func authenticateSession(ctx context.Context, tx *Tx, secret, ip string) (*todev.Session, error) {
+	var session todev.Session
+	if err := tx.QueryRowContext(ctx, `
+		SELECT
+			id,
+			user_id,
+			user_agent,
+			ip,
+			last_seen_at,
+			expires_at,
+			created_at
+		FROM sessions
+		WHERE hash = $1;`,
+		hashToken(secret),
+	).Scan(
+		&session.ID,
+		&session.UserID,
+		&session.UserAgent,
+		&session.IP,
+		(*NullTime)(&session.LastSeenAt),
+		(*NullTime)(&session.ExpiresAt),
+		(*NullTime)(&session.CreatedAt),
+	); err == sql.ErrNoRows {
+		return nil, todev.Errorf(todev.EUNAUTHORIZED, "Session revoked.")
+	} else if err != nil {
+		return nil, fmt.Errorf("error retrieving session: %w", err)
+	}
+
+	if session.IsExpired(tx.now) {
+		return nil, todev.Errorf(todev.EUNAUTHORIZED, "Session expired.")
+	}
+
+	if ip != session.IP || tx.now.Sub(session.LastSeenAt) >= sessionLastSeenInterval {
+		session.IP, session.LastSeenAt = ip, tx.now
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE sessions SET ip = $1, last_seen_at = $2 WHERE id = $3;`,
+			session.IP,
+			(*NullTime)(&session.LastSeenAt),
+			session.ID,
+		); err != nil {
+			return nil, fmt.Errorf("error updating session last seen: %w", err)
+		}
+	}
+
+	user, err := findUserByID(ctx, tx, session.UserID)
+	if err != nil {
+		return nil, err
+	} else if err = attachUserAuths(ctx, tx, user); err != nil {
+		return nil, err
+	}
+	session.User = user
+
+	return &session, nil
+}
+
+func deleteSession(ctx context.Context, tx *Tx, id int) error {
+	if todev.UserIDFromContext(ctx) == 0 {
+		return todev.Errorf(todev.EUNAUTHORIZED, "You must be logged in to revoke a session.")
+	}
+
+	sessions, _, err := findSessions(ctx, tx, todev.SessionFilter{ID: &id})
+	if err != nil {
+		return err
+	} else if len(sessions) == 0 {
+		return todev.Errorf(todev.ENOTFOUND, "Session not found.")
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM sessions WHERE id = $1;`, id); err != nil {
+		return fmt.Errorf("error deleting session: %w", err)
+	}
+	return nil
+}
+
+func deleteSessions(ctx context.Context, tx *Tx) error {
+	userID := todev.UserIDFromContext(ctx)
+	if userID == 0 {
+		return todev.Errorf(todev.EUNAUTHORIZED, "You must be logged in to revoke sessions.")
+	}
+
+	return deleteUserSessions(ctx, tx, userID, 0)
+}
+
+// deleteUserSessions revokes all sessions of a user except the one with the
+// given ID, if any. Used when the credentials of the user change.
+func deleteUserSessions(ctx context.Context, tx *Tx, userID, exceptID int) error {
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM sessions WHERE user_id = $1 AND id != $2;`,
+		userID,
+		exceptID,
+	); err != nil {
+		return fmt.Errorf("error deleting sessions: %w", err)
+	}
+	return nil
+}
+
+// findSessions returns the current user's sessions matching filter that have
+// not expired.
+func findSessions(ctx context.Context, tx *Tx, filter todev.SessionFilter) ([]*todev.Session, int, error) {
+	var argIndex int
+	where, args := []string{}, []interface{}{}
+
+	argIndex++
+	where, args = append(where, fmt.Sprintf("user_id = $%d", argIndex)), append(args, todev.UserIDFromContext(ctx))
+
+	argIndex++
+	where, args = append(where, fmt.Sprintf("expires_at > $%d", argIndex)), append(args, (*NullTime)(&tx.now))
+
+	if v := filter.ID; v != nil {
+		argIndex++
+		where, args = append(where, fmt.Sprintf("id = $%d", argIndex)), append(args, *v)
+	}
+
+	keys := []sortKey{{expr: "id", desc: true}}
+	cursorID, err := findCursorID(ctx, tx, "sessions", filter.Cursor, filter.Offset)
+	if err != nil {
+		return nil, 0, err
+	} else if cursorID != 0 {
+		argIndex++
+		where, args = append(where, formatKeyset(keys, "sessions", argIndex)), append(args, cursorID)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			id,
+			user_id,
+			user_agent,
+			ip,
+			last_seen_at,
+			expires_at,
+			created_at,
+			COUNT(*) OVER()
+		FROM sessions
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY `+formatOrderBy(keys)+`
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error retrieving sessions: %w", err)
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("error closing rows: %v", err)
+		}
+	}()
+
+	sessions := make([]*todev.Session, 0)
+
+	var n int
+	for rows.Next() {
+		var session todev.Session
+		if err = rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.UserAgent,
+			&session.IP,
+			(*NullTime)(&session.LastSeenAt),
+			(*NullTime)(&session.ExpiresAt),
+			(*NullTime)(&session.CreatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, fmt.Errorf("error scanning: %w", err)
+		}
+		sessions = append(sessions, &session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return sessions, n, nil
+}
@@ -0,0 +1,156 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/postgres"
+)
+
+func TestSessionService_CreateSession(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		WithSchema(t, createSession_OK)
+	})
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		WithSchema(t, createSession_ErrInvalid)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		WithSchema(t, createSession_Expired)
+	})
+}
+
+func TestSessionService_DeleteSession(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		WithSchema(t, deleteSession_OK)
+	})
+
+	t.Run("All", func(t *testing.T) {
+		WithSchema(t, deleteSessions_OK)
+	})
+}
+
+func createSession_OK(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewSessionService(conn)
+	user0, ctx0 := MustCreateUser(t, context.Background(), conn, &todev.User{Name: "bob"})
+
+	session := &todev.Session{UserID: user0.ID, UserAgent: "Firefox", IP: "127.0.0.1"}
+	if err := s.CreateSession(ctx0, session); err != nil {
+		t.Fatal(err)
+	} else if session.Secret == "" {
+		t.Fatal("expected secret")
+	} else if session.ExpiresAt.IsZero() {
+		t.Fatal("expected expiry")
+	}
+
+	// Authenticating returns the session along with its user and records the
+	// new address.
+	if other, err := s.AuthenticateSession(context.Background(), session.Secret, "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	} else if got, want := other.User.ID, user0.ID; got != want {
+		t.Fatalf("User.ID=%d, want %d", got, want)
+	} else if got, want := other.IP, "10.0.0.1"; got != want {
+		t.Fatalf("IP=%q, want %q", got, want)
+	}
+
+	if sessions, n, err := s.FindSessions(ctx0, todev.SessionFilter{}); err != nil {
+		t.Fatal(err)
+	} else if got, want := n, 1; got != want {
+		t.Fatalf("n=%d, want %d", got, want)
+	} else if got, want := sessions[0].UserAgent, "Firefox"; got != want {
+		t.Fatalf("UserAgent=%q, want %q", got, want)
+	} else if sessions[0].Secret != "" {
+		t.Fatal("expected secret to be hidden")
+	}
+
+	if _, err := s.AuthenticateSession(context.Background(), session.Secret+"0", "127.0.0.1"); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func createSession_ErrInvalid(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewSessionService(conn)
+
+	if err := s.CreateSession(context.Background(), &todev.Session{}); todev.ErrorCode(err) != todev.EINVALID {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func createSession_Expired(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewSessionService(conn)
+	user0, ctx0 := MustCreateUser(t, context.Background(), conn, &todev.User{Name: "bob"})
+
+	t0 := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	conn.Now = func() time.Time { return t0 }
+
+	session := &todev.Session{UserID: user0.ID}
+	if err := s.CreateSession(ctx0, session); err != nil {
+		t.Fatal(err)
+	}
+
+	conn.Now = func() time.Time { return t0.Add(todev.SessionTTL - time.Second) }
+	if _, err := s.AuthenticateSession(context.Background(), session.Secret, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	conn.Now = func() time.Time { return t0.Add(todev.SessionTTL) }
+	if _, err := s.AuthenticateSession(context.Background(), session.Secret, ""); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func deleteSession_OK(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewSessionService(conn)
+	user0, ctx0 := MustCreateUser(t, context.Background(), conn, &todev.User{Name: "bob"})
+	user1, ctx1 := MustCreateUser(t, context.Background(), conn, &todev.User{Name: "alice"})
+
+	session := &todev.Session{UserID: user0.ID}
+	if err := s.CreateSession(ctx0, session); err != nil {
+		t.Fatal(err)
+	} else if err = s.CreateSession(ctx1, &todev.Session{UserID: user1.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Other users cannot revoke the session.
+	if err := s.DeleteSession(ctx1, session.ID); todev.ErrorCode(err) != todev.ENOTFOUND {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err = s.DeleteSession(ctx0, session.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.AuthenticateSession(context.Background(), session.Secret, ""); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func deleteSessions_OK(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewSessionService(conn)
+	user0, ctx0 := MustCreateUser(t, context.Background(), conn, &todev.User{Name: "bob"})
+	user1, ctx1 := MustCreateUser(t, context.Background(), conn, &todev.User{Name: "alice"})
+
+	for i := 0; i < 2; i++ {
+		if err := s.CreateSession(ctx0, &todev.Session{UserID: user0.ID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other := &todev.Session{UserID: user1.ID}
+	if err := s.CreateSession(ctx1, other); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.DeleteSessions(ctx0); err != nil {
+		t.Fatal(err)
+	} else if _, n, err := s.FindSessions(ctx0, todev.SessionFilter{}); err != nil {
+		t.Fatal(err)
+	} else if got, want := n, 0; got != want {
+		t.Fatalf("n=%d, want %d", got, want)
+	}
+
+	// Sessions of other users are kept.
+	if _, err := s.AuthenticateSession(context.Background(), other.Secret, ""); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,238 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/lib/pq"
+	"github.com/saiddis/todev"
+)
+
+// findTaskStates returns the workflow states of a repo ordered by position.
+func findTaskStates(ctx context.Context, tx *Tx, repoID int) ([]*todev.TaskState, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, repo_id, name, position, is_terminal
+		FROM task_states
+		WHERE repo_id = $1
+		ORDER BY position ASC, id ASC;`,
+		repoID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving task states: %w", err)
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("error closing rows: %v", err)
+		}
+	}()
+
+	states := make([]*todev.TaskState, 0)
+	for rows.Next() {
+		var state todev.TaskState
+		if err = rows.Scan(
+			&state.ID,
+			&state.RepoID,
+			&state.Name,
+			&state.Position,
+			&state.IsTerminal,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning: %w", err)
+		}
+		states = append(states, &state)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return states, nil
+}
+
+// createTaskState inserts a new workflow state for a repo.
+func createTaskState(ctx context.Context, tx *Tx, state *todev.TaskState) error {
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO task_states (repo_id, name, position, is_terminal)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id;`,
+		state.RepoID,
+		state.Name,
+		state.Position,
+		state.IsTerminal,
+	).Scan(&state.ID); err != nil {
+		return fmt.Errorf("error inserting task state: %w", err)
+	}
+	return nil
+}
+
+// replaceTaskStates replaces the workflow of a repo with states. Existing
+// states are matched by ID so that their tasks keep their status. Returns
+// ECONFLICT if a removed state still has tasks.
+func replaceTaskStates(ctx context.Context, tx *Tx, repo *todev.Repo, states []*todev.TaskState) error {
+	if err := todev.ValidateTaskStates(states); err != nil {
+		return err
+	}
+
+	kept := make(map[int]struct{}, len(states))
+	for _, state := range states {
+		if state.ID == 0 {
+			continue
+		} else if repo.StateByID(state.ID) == nil {
+			return todev.Errorf(todev.EINVALID, "State %d does not belong to the repo.", state.ID)
+		}
+		kept[state.ID] = struct{}{}
+	}
+
+	// Remove states that are no longer part of the workflow.
+	for _, state := range repo.States {
+		if _, ok := kept[state.ID]; ok {
+			continue
+		}
+
+		// Trashed tasks keep their state so that they can be restored.
+		var live, trashed int
+		if err := tx.QueryRowContext(ctx, `
+			SELECT
+				COUNT(1) FILTER (WHERE deleted_at IS NULL),
+				COUNT(1) FILTER (WHERE deleted_at IS NOT NULL)
+			FROM tasks
+			WHERE state_id = $1;`,
+			state.ID,
+		).Scan(&live, &trashed); err != nil {
+			return fmt.Errorf("error counting state tasks: %w", err)
+		} else if live != 0 {
+			return todev.Errorf(todev.ECONFLICT, "State %q still has tasks.", state.Name)
+		} else if trashed != 0 {
+			return todev.Errorf(todev.ECONFLICT, "State %q still has tasks in the trash.", state.Name)
+		} else if _, err = tx.ExecContext(ctx, `DELETE FROM task_states WHERE id = $1;`, state.ID); err != nil {
+			return fmt.Errorf("error deleting task state: %w", err)
+		}
+	}
+
+	// Move kept states out of the way first so that states can swap names
+	// without violating the unique constraint.
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE task_states SET name = '~' || id
+		WHERE repo_id = $1;`,
+		repo.ID,
+	); err != nil {
+		return fmt.Errorf("error renaming task states: %w", err)
+	}
+
+	for i, state := range states {
+		state.RepoID, state.Position = repo.ID, i
+		if state.ID == 0 {
+			if err := createTaskState(ctx, tx, state); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE task_states
+			SET name = $1, position = $2, is_terminal = $3
+			WHERE id = $4;`,
+			state.Name,
+			state.Position,
+			state.IsTerminal,
+			state.ID,
+		); err != nil {
+			return fmt.Errorf("error updating task state: %w", err)
+		}
+	}
+
+	if err := syncTaskCompletion(ctx, tx, repo.ID); err != nil {
+		return err
+	}
+
+	repo.States = states
+	return nil
+}
+
+// syncTaskCompletion keeps the completion of a repo's tasks in line with states
+// that became terminal or stopped being terminal. Live tasks are reported as
+// if each of them had been moved by an update.
+func syncTaskCompletion(ctx context.Context, tx *Tx, repoID int) error {
+	ids, err := findTaskIDs(ctx, tx, `
+		SELECT t.id FROM tasks t
+		JOIN task_states s ON t.state_id = s.id
+		WHERE t.repo_id = $1 AND t.is_completed != s.is_terminal
+		ORDER BY t.id ASC;`,
+		repoID,
+	)
+	if err != nil {
+		return err
+	} else if len(ids) == 0 {
+		return nil
+	}
+
+	// Completing or reopening tasks may change whether their dependents are
+	// blocked.
+	var dependentIDs []int
+	seen := make(map[int]struct{})
+	for _, id := range ids {
+		other, err := findDependentIDs(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		for _, id := range other {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				dependentIDs = append(dependentIDs, id)
+			}
+		}
+	}
+	dependentsBlocked, err := snapshotBlocked(ctx, tx, dependentIDs)
+	if err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE tasks t
+		SET is_completed = s.is_terminal,
+			completed_at = CASE WHEN s.is_terminal THEN $2 ELSE NULL END,
+			updated_at = $2
+		FROM task_states s
+		WHERE t.state_id = s.id AND t.id = ANY($1)
+		RETURNING t.id, t.state_id, t.is_completed, t.deleted_at IS NULL;`,
+		pq.Array(ids),
+		(*NullTime)(&tx.now),
+	)
+	if err != nil {
+		return fmt.Errorf("error updating task completion: %w", err)
+	}
+
+	changed := make([]todev.TaskStatusChanged, 0, len(ids))
+	for rows.Next() {
+		var status todev.TaskStatusChanged
+		var live bool
+		if err = rows.Scan(&status.ID, &status.StateID, &status.IsCompleted, &live); err != nil {
+			rows.Close()
+			return fmt.Errorf("error scanning: %w", err)
+		} else if live {
+			changed = append(changed, status)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		rows.Close()
+		return fmt.Errorf("error iterating over rows: %w", err)
+	} else if err = rows.Close(); err != nil {
+		return fmt.Errorf("error closing rows: %w", err)
+	}
+
+	for _, status := range changed {
+		if err = createUpdateActivity(ctx, tx, repoID, todev.ActivityEntityTask, status.ID,
+			map[string]interface{}{"isCompleted": !status.IsCompleted},
+			map[string]interface{}{"isCompleted": status.IsCompleted},
+		); err != nil {
+			return err
+		} else if err = publishRepoEvent(ctx, tx, repoID, todev.Event{
+			Type:    todev.EventTypeTaskStatusChanged,
+			Payload: status,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return publishBlockedChanges(ctx, tx, repoID, dependentIDs, dependentsBlocked)
+}
@@ -3,9 +3,11 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"html"
 	"log"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/saiddis/todev"
 )
@@ -19,7 +21,7 @@ func NewTaskService(conn *Conn) *TaskService {
 }
 
 // CreateTask creates a new task in a repo.
-// Returns ECONFLICT if contributor creating a task is not the owner.
+// Returns EUNAUTHORIZED if the current user is not a repo admin.
 func (s *TaskService) CreateTask(ctx context.Context, task *todev.Task) error {
 	tx, err := s.conn.BeginTx(ctx, nil)
 	if err != nil {
@@ -42,6 +44,8 @@ func (s *TaskService) CreateTask(ctx context.Context, task *todev.Task) error {
 		return err
 	} else if err = createTaskContributors(ctx, tx, task); err != nil {
 		return err
+	} else if err = replaceTaskLabels(ctx, tx, task); err != nil {
+		return err
 	}
 
 	repo, err := findRepoByID(ctx, tx, task.RepoID)
@@ -51,15 +55,21 @@ func (s *TaskService) CreateTask(ctx context.Context, task *todev.Task) error {
 
 	task.OwnerID = repo.UserID
 
-	if task.OwnerID != todev.UserIDFromContext(ctx) {
-		return todev.Errorf(todev.ECONFLICT, "Only repo owner can create tasks.")
-	} else if err = publishRepoEvent(ctx, tx, task.RepoID, todev.Event{
+	if err = publishRepoEvent(ctx, tx, task.RepoID, todev.Event{
 		Type: todev.EventTypeTaskAdded,
 		Payload: todev.TaskAdded{
 			Task: task,
 		},
 	}); err != nil {
 		return err
+	} else if err = createActivity(ctx, tx, &todev.Activity{
+		RepoID:     task.RepoID,
+		EntityType: todev.ActivityEntityTask,
+		EntityID:   task.ID,
+		Action:     todev.ActivityActionCreate,
+		After:      taskActivityFields(task),
+	}); err != nil {
+		return err
 	}
 
 	return nil
@@ -179,6 +189,34 @@ func (s *TaskService) DeleteTask(ctx context.Context, id int) error {
 	return nil
 }
 
+// RestoreTask moves a task out of the trash of its repo. Returns ENOTFOUND if
+// the task is not trashed or the user cannot view it. Returns EUNAUTHORIZED if
+// the user is not a repo admin.
+func (s *TaskService) RestoreTask(ctx context.Context, id int) (*todev.Task, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("RestoreTask: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	task, err := restoreTask(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
 func (s *TaskService) AttachContributor(ctx context.Context, task *todev.Task, contributorID int) error {
 	tx, err := s.conn.BeginTx(ctx, nil)
 	if err != nil {
@@ -199,8 +237,8 @@ func (s *TaskService) AttachContributor(ctx context.Context, task *todev.Task, c
 
 	if err = attachTaskAssociations(ctx, tx, task); err != nil {
 		return err
-	} else if !todev.CanEditTask(ctx, *task) {
-		return todev.Errorf(todev.ECONFLICT, "You are not allowed to edit tasks.")
+	} else if err = checkCanEditTask(ctx, tx, task); err != nil {
+		return err
 	} else if err = attachContributor(ctx, tx, task, contributorID); err != nil {
 		return fmt.Errorf("error attaching contributor: %v", err)
 	}
@@ -228,8 +266,8 @@ func (s *TaskService) UnattachContributor(ctx context.Context, task *todev.Task,
 
 	if err = attachTaskAssociations(ctx, tx, task); err != nil {
 		return err
-	} else if !todev.CanEditTask(ctx, *task) {
-		return todev.Errorf(todev.ECONFLICT, "You are not allowed to edit tasks.")
+	} else if err = checkCanEditTask(ctx, tx, task); err != nil {
+		return err
 	} else if err = unattachContributor(ctx, tx, task, contributorID); err != nil {
 		return fmt.Errorf("error unattaching contributor: %v", err)
 	}
@@ -240,6 +278,11 @@ func (s *TaskService) UnattachContributor(ctx context.Context, task *todev.Task,
 func createTask(ctx context.Context, tx *Tx, task *todev.Task) (err error) {
 	task.CreatedAt = tx.now
 	task.UpdatedAt = task.CreatedAt
+	task.DueAt = truncateTime(task.DueAt)
+	task.Labels = todev.NormalizeTaskLabels(task.Labels)
+
+	// New tasks start without checklist items or blockers.
+	task.Checklist, task.BlockedByIDs, task.IsBlocked = []*todev.ChecklistItem{}, []int{}, false
 
 	if err = task.Validate(); err != nil {
 		return err
@@ -247,14 +290,47 @@ func createTask(ctx context.Context, tx *Tx, task *todev.Task) (err error) {
 		return err
 	}
 
+	if role, _, err := findCurrentRole(ctx, tx, task.RepoID); err != nil {
+		return err
+	} else if !role.IsAdmin() {
+		return todev.Errorf(todev.EUNAUTHORIZED, "Only repo admins can create tasks.")
+	}
+
+	// Place the task in the first state of the workflow unless told otherwise.
+	states, err := findTaskStates(ctx, tx, task.RepoID)
+	if err != nil {
+		return err
+	}
+	repo := todev.Repo{States: states}
+	if task.StateID == 0 {
+		if state := repo.InitialState(); state != nil {
+			task.StateID = state.ID
+		}
+	}
+	state := repo.StateByID(task.StateID)
+	if state == nil {
+		return todev.Errorf(todev.EINVALID, "Invalid task state.")
+	}
+	task.IsCompleted = state.IsTerminal
+	task.CompletedAt = time.Time{}
+	if task.IsCompleted {
+		task.CompletedAt = task.CreatedAt
+	}
+
 	args := []interface{}{
 		task.Description,
 		task.RepoID,
 		(*NullTime)(&task.CreatedAt),
 		(*NullTime)(&task.UpdatedAt),
+		(*NullTime)(&task.DueAt),
+		task.Priority,
+		task.StateID,
+		task.IsCompleted,
+		(*NullTime)(&task.CompletedAt),
+		task.IssueNumber,
 	}
-	insertQuery := []string{"description", "repo_id", "created_at", "updated_at"}
-	valuesQuery := []string{"$1", "$2", "$3", "$4"}
+	insertQuery := []string{"description", "repo_id", "created_at", "updated_at", "due_at", "priority", "state_id", "is_completed", "completed_at", "issue_number"}
+	valuesQuery := []string{"$1", "$2", "$3", "$4", "$5", "$6", "$7", "$8", "$9", "NULLIF($10, 0)"}
 
 	var id int
 	err = tx.QueryRowContext(ctx, `
@@ -279,7 +355,7 @@ func createTaskContributors(ctx context.Context, tx *Tx, task *todev.Task) error
 	if err != nil {
 		return fmt.Errorf("error retrieving contributors by repo ID: %v", err)
 	} else if len(contributors) == 0 {
-		return todev.Errorf(todev.ECONFLICT, "Only repo owner can create tasks.")
+		return todev.Errorf(todev.EUNAUTHORIZED, "Only repo admins can create tasks.")
 	}
 	values := new(strings.Builder)
 	values.Grow(n)
@@ -350,13 +426,57 @@ func findTasks(ctx context.Context, tx *Tx, filter todev.TaskFilter) ([]*todev.T
 		argIndex++
 		where, args = append(where, fmt.Sprintf("tc.contributor_id = $%d", argIndex)), append(args, *v)
 	}
+	if v := filter.StateID; v != nil {
+		argIndex++
+		where, args = append(where, fmt.Sprintf("t.state_id = $%d", argIndex)), append(args, *v)
+	}
 	if v := filter.IsCompleted; v != nil {
 		argIndex++
 		where, args = append(where, fmt.Sprintf("t.is_completed = $%d", argIndex)), append(args, *v)
 	}
+	if v := filter.IsBlocked; v != nil {
+		argIndex++
+		where, args = append(where, fmt.Sprintf("%s = $%d", taskBlockedExpr, argIndex)), append(args, *v)
+	}
+	if v := filter.IsReady; v != nil {
+		argIndex++
+		where, args = append(where, fmt.Sprintf("(NOT t.is_completed AND NOT %s) = $%d", taskBlockedExpr, argIndex)), append(args, *v)
+	}
+	if v := filter.DueBefore; v != nil {
+		argIndex++
+		where, args = append(where, fmt.Sprintf("t.due_at < $%d", argIndex)), append(args, (*NullTime)(v))
+	}
+	if v := filter.DueAfter; v != nil {
+		argIndex++
+		where, args = append(where, fmt.Sprintf("t.due_at > $%d", argIndex)), append(args, (*NullTime)(v))
+	}
+	if v := filter.Priority; v != nil {
+		argIndex++
+		where, args = append(where, fmt.Sprintf("t.priority = $%d", argIndex)), append(args, *v)
+	}
+	if v := filter.Label; v != nil {
+		argIndex++
+		where, args = append(where, fmt.Sprintf(`t.id IN (
+			SELECT tl.task_id FROM tasks_labels tl
+			JOIN labels l ON tl.label_id = l.id
+			WHERE l.name = $%d
+			)`, argIndex)), append(args, *v)
+	}
+	var queryArgIndex int
+	if v := filter.Query; v != nil && strings.TrimSpace(*v) != "" {
+		argIndex++
+		queryArgIndex = argIndex
+		where, args = append(where, fmt.Sprintf("t.search @@ websearch_to_tsquery('english', $%d)", argIndex)), append(args, *v)
+	}
+
+	if filter.Trashed {
+		where = append(where, "t.deleted_at IS NOT NULL")
+	} else {
+		where = append(where, "t.deleted_at IS NULL")
+	}
 
 	argIndex++
-	where = append(where, fmt.Sprintf(`(
+	where = append(where, "r.deleted_at IS NULL", fmt.Sprintf(`(
 		r.user_id = $%d OR
 		t.repo_id IN (SELECT repo_id FROM contributors WHERE user_id = $%d)
 		)`, argIndex, argIndex+1),
@@ -364,30 +484,66 @@ func findTasks(ctx context.Context, tx *Tx, filter todev.TaskFilter) ([]*todev.T
 	argIndex++
 
 	userID := todev.UserIDFromContext(ctx)
-	var sortBy string
+	args = append(args, userID, userID)
+
+	// Every sort ends with the ID so that results can be resumed from a cursor.
+	var keys []sortKey
 	switch filter.SortBy {
 	case todev.TasksSortByCreatedAtDesc:
-		sortBy = "t.created_at DESC"
+		keys = []sortKey{{expr: "t.created_at", desc: true}}
+	case todev.TasksSortByUpdatedAtDesc:
+		keys = []sortKey{{expr: "t.updated_at", desc: true}}
+	case todev.TasksSortByDueAtAsc:
+		keys = []sortKey{{expr: "COALESCE(t.due_at, 'infinity')"}}
+	case todev.TasksSortByPriorityDesc:
+		keys = []sortKey{{expr: "t.priority", desc: true}}
 	default:
-		sortBy = `t.is_completed DESC`
+		keys = []sortKey{{expr: "t.is_completed", desc: true}}
+		if queryArgIndex != 0 {
+			keys = []sortKey{{expr: fmt.Sprintf("ts_rank(t.search, websearch_to_tsquery('english', $%d))", queryArgIndex), desc: true}}
+		}
+	}
+	keys = append(keys, sortKey{expr: "t.id"})
+
+	cursorID, err := findCursorID(ctx, tx, "tasks", filter.Cursor, filter.Offset)
+	if err != nil {
+		return nil, 0, err
+	} else if cursorID != 0 {
+		argIndex++
+		where, args = append(where, formatKeyset(keys, "tasks t", argIndex)), append(args, cursorID)
+	}
+
+	// Highlight the matching terms of searched tasks.
+	snippet := "''"
+	if queryArgIndex != 0 {
+		argIndex++
+		snippet = fmt.Sprintf("ts_headline('english', t.description, websearch_to_tsquery('english', $%d), $%d)", queryArgIndex, argIndex)
+		args = append(args, snippetOptions)
 	}
-	args = append(args, userID, userID)
 
 	stmt, err := tx.PrepareContext(ctx, `
 		SELECT
 			t.id,
 			t.repo_id,
+			t.state_id,
 			t.is_completed,
 			t.description,
 			t.created_at,
 			t.updated_at,
+			t.completed_at,
+			t.due_at,
+			t.deleted_at,
+			t.priority,
+			COALESCE(t.issue_number, 0),
+			`+taskBlockedExpr+`,
+			`+snippet+`,
 			COUNT(*) OVER()
 		FROM tasks t
 		JOIN repos r ON t.repo_id = r.id
 		LEFT JOIN tasks_contributors tc ON t.id = tc.task_id
 		WHERE `+strings.Join(where, " AND ")+`
 		GROUP BY t.id
-		ORDER BY `+sortBy+`
+		ORDER BY `+formatOrderBy(keys)+`
 		`+FormatLimitOffset(filter.Limit, filter.Offset),
 	)
 	if err != nil {
@@ -413,14 +569,23 @@ func findTasks(ctx context.Context, tx *Tx, filter todev.TaskFilter) ([]*todev.T
 		if err = rows.Scan(
 			&task.ID,
 			&task.RepoID,
+			&task.StateID,
 			&task.IsCompleted,
 			&task.Description,
 			&task.CreatedAt,
 			&task.UpdatedAt,
+			(*NullTime)(&task.CompletedAt),
+			(*NullTime)(&task.DueAt),
+			(*NullTime)(&task.DeletedAt),
+			&task.Priority,
+			&task.IssueNumber,
+			&task.IsBlocked,
+			&task.Snippet,
 			&n,
 		); err != nil {
 			return nil, 0, fmt.Errorf("error scanning: %w", err)
 		}
+		task.Snippet = formatSnippet(task.Snippet)
 		tasks = append(tasks, &task)
 	}
 
@@ -431,58 +596,90 @@ func findTasks(ctx context.Context, tx *Tx, filter todev.TaskFilter) ([]*todev.T
 	return tasks, n, nil
 }
 
-func updateTask(ctx context.Context, tx *Tx, id int, upd todev.TaskUpdate) (_ *todev.Task, err error) {
+func updateTask(ctx context.Context, tx *Tx, id int, upd todev.TaskUpdate) (*todev.Task, error) {
 	task, err := findTaskByID(ctx, tx, id)
 	if err != nil {
 		return nil, err
 	} else if err = attachTaskAssociations(ctx, tx, task); err != nil {
 		return nil, err
-	} else if !todev.CanEditTask(ctx, *task) {
-		return nil, todev.Errorf(todev.ECONFLICT, "You are not allowed to update tasks.")
 	}
 
-	if v := upd.Description; v != nil {
-		defer func() {
-			err = publishRepoEvent(ctx, tx, task.RepoID, todev.Event{
-				Type: todev.EventTypeTaskDescriptionChanged,
-				Payload: todev.TaskDescriptionChanged{
-					ID:    task.ID,
-					Value: *v,
-				},
-			})
-		}()
-		task.Description = *v
+	// Members can only change the status of their own tasks. Any other
+	// change requires admin privileges.
+	role, contributorID, err := findCurrentRole(ctx, tx, task.RepoID)
+	if err != nil {
+		return nil, err
+	} else if !role.IsAdmin() && (upd.Description != nil || upd.DueAt != nil || upd.Priority != nil || upd.Labels != nil ||
+		!todev.CanSetTaskStatus(role, contributorID, *task)) {
+		return nil, todev.Errorf(todev.EUNAUTHORIZED, "You are not allowed to update tasks.")
 	}
-	if upd.ToggleCompletion {
-		defer func() {
-			err = publishRepoEvent(ctx, tx, task.RepoID, todev.Event{
-				Type: todev.EventTypeTaskCompletionToggled,
-				Payload: todev.TaskCompletionToggled{
-					ID: task.ID,
-				},
-			})
-		}()
-		if task.IsCompleted {
-			task.IsCompleted = false
-		} else {
+
+	// Moving a task in or out of a terminal state changes its completion.
+	prevStateID, wasCompleted := task.StateID, task.IsCompleted
+	before := taskActivityFields(task)
+	if v := upd.SetStatus; v != nil {
+		states, err := findTaskStates(ctx, tx, task.RepoID)
+		if err != nil {
+			return nil, err
+		}
+		state := (todev.Repo{States: states}).StateByID(*v)
+		if state == nil {
+			return nil, todev.Errorf(todev.EINVALID, "Invalid task state.")
+		}
+
+		task.StateID = state.ID
+		if state.IsTerminal && !task.IsCompleted {
 			task.IsCompleted = true
+			task.CompletedAt = tx.now
+		} else if !state.IsTerminal {
+			task.IsCompleted = false
+			task.CompletedAt = time.Time{}
 		}
 	}
 
+	if v := upd.Description; v != nil {
+		task.Description = *v
+	}
+	if v := upd.DueAt; v != nil {
+		task.DueAt = truncateTime(*v)
+	}
+	if v := upd.Priority; v != nil {
+		task.Priority = *v
+	}
+	if v := upd.Labels; v != nil {
+		task.Labels = todev.NormalizeTaskLabels(*v)
+	}
+
 	if err = task.Validate(); err != nil {
 		return nil, err
 	}
 
 	task.UpdatedAt = tx.now
 
+	// Completing or reopening a task may change whether its dependents are
+	// blocked.
+	var dependentIDs []int
+	var dependentsBlocked []bool
+	if task.IsCompleted != wasCompleted {
+		if dependentIDs, err = findDependentIDs(ctx, tx, task.ID); err != nil {
+			return nil, err
+		} else if dependentsBlocked, err = snapshotBlocked(ctx, tx, dependentIDs); err != nil {
+			return nil, err
+		}
+	}
+
 	args := []interface{}{
 		task.Description,
 		task.RepoID,
 		task.IsCompleted,
 		(*NullTime)(&task.UpdatedAt),
+		(*NullTime)(&task.CompletedAt),
+		(*NullTime)(&task.DueAt),
+		task.Priority,
+		task.StateID,
 	}
-	idArgIndex := "5;"
-	updateQuery := []string{"description = $1", "repo_id = $2", "is_completed = $3", "updated_at = $4"}
+	idArgIndex := "9;"
+	updateQuery := []string{"description = $1", "repo_id = $2", "is_completed = $3", "updated_at = $4", "completed_at = $5", "due_at = $6", "priority = $7", "state_id = $8"}
 	args = append(args, id)
 
 	_, err = tx.ExecContext(ctx, `
@@ -494,7 +691,76 @@ func updateTask(ctx context.Context, tx *Tx, id int, upd todev.TaskUpdate) (_ *t
 		return task, fmt.Errorf("error updating task: %w", err)
 	}
 
-	return task, err
+	if upd.Labels != nil {
+		if err = replaceTaskLabels(ctx, tx, task); err != nil {
+			return task, err
+		}
+	}
+
+	if err = createUpdateActivity(ctx, tx, task.RepoID, todev.ActivityEntityTask, task.ID, before, taskActivityFields(task)); err != nil {
+		return task, err
+	}
+
+	if v := upd.Description; v != nil {
+		if err = publishRepoEvent(ctx, tx, task.RepoID, todev.Event{
+			Type: todev.EventTypeTaskDescriptionChanged,
+			Payload: todev.TaskDescriptionChanged{
+				ID:    task.ID,
+				Value: *v,
+			},
+		}); err != nil {
+			return task, err
+		}
+	}
+	if task.StateID != prevStateID {
+		if err = publishRepoEvent(ctx, tx, task.RepoID, todev.Event{
+			Type: todev.EventTypeTaskStatusChanged,
+			Payload: todev.TaskStatusChanged{
+				ID:          task.ID,
+				StateID:     task.StateID,
+				IsCompleted: task.IsCompleted,
+			},
+		}); err != nil {
+			return task, err
+		} else if err = publishBlockedChanges(ctx, tx, task.RepoID, dependentIDs, dependentsBlocked); err != nil {
+			return task, err
+		}
+	}
+	if upd.DueAt != nil {
+		if err = publishRepoEvent(ctx, tx, task.RepoID, todev.Event{
+			Type: todev.EventTypeTaskDueAtChanged,
+			Payload: todev.TaskDueAtChanged{
+				ID:    task.ID,
+				Value: task.DueAt,
+			},
+		}); err != nil {
+			return task, err
+		}
+	}
+	if upd.Priority != nil {
+		if err = publishRepoEvent(ctx, tx, task.RepoID, todev.Event{
+			Type: todev.EventTypeTaskPriorityChanged,
+			Payload: todev.TaskPriorityChanged{
+				ID:    task.ID,
+				Value: task.Priority,
+			},
+		}); err != nil {
+			return task, err
+		}
+	}
+	if upd.Labels != nil {
+		if err = publishRepoEvent(ctx, tx, task.RepoID, todev.Event{
+			Type: todev.EventTypeTaskLabelsChanged,
+			Payload: todev.TaskLabelsChanged{
+				ID:    task.ID,
+				Value: task.Labels,
+			},
+		}); err != nil {
+			return task, err
+		}
+	}
+
+	return task, nil
 }
 
 func deleteTask(ctx context.Context, tx *Tx, id int) error {
@@ -503,11 +769,26 @@ func deleteTask(ctx context.Context, tx *Tx, id int) error {
 		return err
 	} else if err = attachTaskAssociations(ctx, tx, task); err != nil {
 		return err
-	} else if !todev.CanEditTask(ctx, *task) {
-		return todev.Errorf(todev.ECONFLICT, "You are not allowed to delete tasks.")
 	}
 
-	if _, err = tx.ExecContext(ctx, "DELETE FROM tasks WHERE id = $1;", id); err != nil {
+	if role, _, err := findCurrentRole(ctx, tx, task.RepoID); err != nil {
+		return err
+	} else if !role.IsAdmin() {
+		return todev.Errorf(todev.EUNAUTHORIZED, "You are not allowed to delete tasks.")
+	}
+
+	// Dependents of the task are unblocked once it is gone.
+	dependentIDs, err := findDependentIDs(ctx, tx, task.ID)
+	if err != nil {
+		return err
+	}
+	dependentsBlocked, err := snapshotBlocked(ctx, tx, dependentIDs)
+	if err != nil {
+		return err
+	}
+
+	task.DeletedAt = tx.now
+	if _, err = tx.ExecContext(ctx, "UPDATE tasks SET deleted_at = $1 WHERE id = $2;", (*NullTime)(&task.DeletedAt), id); err != nil {
 		return fmt.Errorf("error deleting task: %w", err)
 	} else if err = publishRepoEvent(ctx, tx, task.RepoID, todev.Event{
 		Type: todev.EventTypeTaskDeleted,
@@ -516,11 +797,74 @@ func deleteTask(ctx context.Context, tx *Tx, id int) error {
 		},
 	}); err != nil {
 		return err
+	} else if err = createActivity(ctx, tx, &todev.Activity{
+		RepoID:     task.RepoID,
+		EntityType: todev.ActivityEntityTask,
+		EntityID:   task.ID,
+		Action:     todev.ActivityActionDelete,
+		Before:     taskActivityFields(task),
+	}); err != nil {
+		return err
+	} else if err = publishBlockedChanges(ctx, tx, task.RepoID, dependentIDs, dependentsBlocked); err != nil {
+		return err
 	}
 
 	return nil
 }
 
+func restoreTask(ctx context.Context, tx *Tx, id int) (*todev.Task, error) {
+	tasks, _, err := findTasks(ctx, tx, todev.TaskFilter{ID: &id, Trashed: true})
+	if err != nil {
+		return nil, err
+	} else if len(tasks) == 0 {
+		return nil, todev.Errorf(todev.ENOTFOUND, "Task not found in trash.")
+	}
+	task := tasks[0]
+	if err = attachTaskAssociations(ctx, tx, task); err != nil {
+		return nil, err
+	}
+
+	if role, _, err := findCurrentRole(ctx, tx, task.RepoID); err != nil {
+		return nil, err
+	} else if !role.IsAdmin() {
+		return nil, todev.Errorf(todev.EUNAUTHORIZED, "You are not allowed to restore tasks.")
+	}
+
+	// Dependents of the task are blocked again once it is back.
+	dependentIDs, err := findDependentIDs(ctx, tx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	dependentsBlocked, err := snapshotBlocked(ctx, tx, dependentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	task.DeletedAt = time.Time{}
+	if _, err = tx.ExecContext(ctx, "UPDATE tasks SET deleted_at = NULL WHERE id = $1;", id); err != nil {
+		return nil, fmt.Errorf("error restoring task: %w", err)
+	} else if err = publishRepoEvent(ctx, tx, task.RepoID, todev.Event{
+		Type: todev.EventTypeTaskAdded,
+		Payload: todev.TaskAdded{
+			Task: task,
+		},
+	}); err != nil {
+		return nil, err
+	} else if err = createActivity(ctx, tx, &todev.Activity{
+		RepoID:     task.RepoID,
+		EntityType: todev.ActivityEntityTask,
+		EntityID:   task.ID,
+		Action:     todev.ActivityActionRestore,
+		After:      taskActivityFields(task),
+	}); err != nil {
+		return nil, err
+	} else if err = publishBlockedChanges(ctx, tx, task.RepoID, dependentIDs, dependentsBlocked); err != nil {
+		return nil, err
+	}
+
+	return task, nil
+}
+
 func attachTaskAssociations(ctx context.Context, tx *Tx, task *todev.Task) (err error) {
 	repo, err := findRepoByID(ctx, tx, task.RepoID)
 	if err != nil {
@@ -536,6 +880,126 @@ func attachTaskAssociations(ctx context.Context, tx *Tx, task *todev.Task) (err
 	for _, contributor := range contributors {
 		task.ContributorIDs = append(task.ContributorIDs, contributor.ID)
 	}
+
+	if task.Labels, err = findTaskLabels(ctx, tx, task.ID); err != nil {
+		return fmt.Errorf("error attaching task labels: %w", err)
+	} else if task.Checklist, err = findChecklistItems(ctx, tx, task.ID); err != nil {
+		return fmt.Errorf("error attaching task checklist: %w", err)
+	} else if task.BlockedByIDs, err = findBlockerIDs(ctx, tx, task.ID); err != nil {
+		return fmt.Errorf("error attaching task blockers: %w", err)
+	}
+	return nil
+}
+
+// findTaskLabels returns the names of the labels of a task in sorted order.
+func findTaskLabels(ctx context.Context, tx *Tx, taskID int) ([]string, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT l.name
+		FROM labels l
+		JOIN tasks_labels tl ON l.id = tl.label_id
+		WHERE tl.task_id = $1
+		ORDER BY l.name ASC;`,
+		taskID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving labels: %w", err)
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("error closing rows: %v", err)
+		}
+	}()
+
+	labels := make([]string, 0)
+	for rows.Next() {
+		var label string
+		if err = rows.Scan(&label); err != nil {
+			return nil, fmt.Errorf("error scanning: %w", err)
+		}
+		labels = append(labels, label)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return labels, nil
+}
+
+// replaceTaskLabels replaces the labels of a task with task.Labels. Labels are
+// created within the task's repo on first use.
+func replaceTaskLabels(ctx context.Context, tx *Tx, task *todev.Task) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tasks_labels WHERE task_id = $1;", task.ID); err != nil {
+		return fmt.Errorf("error deleting task labels: %w", err)
+	}
+
+	for _, label := range task.Labels {
+		var labelID int
+		if err := tx.QueryRowContext(ctx, `
+			INSERT INTO labels (repo_id, name)
+			VALUES ($1, $2)
+			ON CONFLICT (repo_id, name) DO UPDATE SET name = EXCLUDED.name
+			RETURNING id;`,
+			task.RepoID,
+			label,
+		).Scan(&labelID); err != nil {
+			return fmt.Errorf("error inserting label: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO tasks_labels (task_id, label_id)
+			VALUES ($1, $2);`,
+			task.ID,
+			labelID,
+		); err != nil {
+			return fmt.Errorf("error inserting task label: %w", err)
+		}
+	}
+	return nil
+}
+
+// Delimiters placed around the matching terms by ts_headline(). Descriptions
+// are not HTML escaped by Postgres so the delimiters are replaced by <mark>
+// tags once the snippet is escaped.
+const (
+	snippetStartSel = "\x02"
+	snippetStopSel  = "\x03"
+)
+
+// snippetOptions are the ts_headline() options used for search snippets.
+// Descriptions are short so they are highlighted as a whole.
+const snippetOptions = "StartSel=" + snippetStartSel + ", StopSel=" + snippetStopSel + ", HighlightAll=true"
+
+// formatSnippet returns an HTML escaped snippet with the matching terms
+// wrapped in <mark> tags.
+func formatSnippet(snippet string) string {
+	if snippet == "" {
+		return ""
+	}
+	return strings.NewReplacer(
+		snippetStartSel, "<mark>",
+		snippetStopSel, "</mark>",
+	).Replace(html.EscapeString(snippet))
+}
+
+// truncateTime drops the sub-second part of t as times are stored with second
+// precision.
+func truncateTime(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.UTC().Truncate(time.Second)
+}
+
+// checkCanEditTask returns EUNAUTHORIZED if the current user is not an admin
+// of the task's repo.
+func checkCanEditTask(ctx context.Context, tx *Tx, task *todev.Task) error {
+	role, _, err := findCurrentRole(ctx, tx, task.RepoID)
+	if err != nil {
+		return err
+	} else if !role.IsAdmin() {
+		return todev.Errorf(todev.EUNAUTHORIZED, "You are not allowed to edit tasks.")
+	}
 	return nil
 }
 
@@ -560,6 +1024,8 @@ func attachContributor(ctx context.Context, tx *Tx, task *todev.Task, contributo
 				},
 			}); err != nil {
 				return err
+			} else if err = createTaskContributorActivity(ctx, tx, task, todev.ActivityActionAttach, task.ContributorIDs, []int{contributorID}); err != nil {
+				return err
 			}
 
 			task.ContributorIDs = []int{contributorID}
@@ -606,6 +1072,8 @@ func createTaskContributor(ctx context.Context, tx *Tx, task *todev.Task, contri
 		},
 	}); err != nil {
 		return err
+	} else if err = createTaskContributorActivity(ctx, tx, task, todev.ActivityActionAttach, task.ContributorIDs, append(slices.Clone(task.ContributorIDs), contributorID)); err != nil {
+		return err
 	}
 
 	task.ContributorIDs = append(task.ContributorIDs, contributorID)
@@ -614,6 +1082,7 @@ func createTaskContributor(ctx context.Context, tx *Tx, task *todev.Task, contri
 
 func deleteTaskContributor(ctx context.Context, tx *Tx, task *todev.Task, contributorID int) error {
 	log.Print("delete")
+	prevContributorIDs := slices.Clone(task.ContributorIDs)
 	for i, id := range task.ContributorIDs {
 		if id == contributorID {
 			task.ContributorIDs = slices.Concat(task.ContributorIDs[:i], task.ContributorIDs[1+i:])
@@ -639,7 +1108,22 @@ func deleteTaskContributor(ctx context.Context, tx *Tx, task *todev.Task, contri
 		},
 	}); err != nil {
 		return err
+	} else if err = createTaskContributorActivity(ctx, tx, task, todev.ActivityActionUnattach, prevContributorIDs, task.ContributorIDs); err != nil {
+		return err
 	}
 
 	return nil
 }
+
+// createTaskContributorActivity records a change of the contributors attached
+// to a task.
+func createTaskContributorActivity(ctx context.Context, tx *Tx, task *todev.Task, action string, before, after []int) error {
+	return createActivity(ctx, tx, &todev.Activity{
+		RepoID:     task.RepoID,
+		EntityType: todev.ActivityEntityTask,
+		EntityID:   task.ID,
+		Action:     action,
+		Before:     map[string]interface{}{"contributorIDs": before},
+		After:      map[string]interface{}{"contributorIDs": after},
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/saiddis/todev"
 	"github.com/saiddis/todev/postgres"
@@ -26,6 +27,18 @@ func TestTaskService_FindTasks(t *testing.T) {
 	t.Run("ByRepoID", func(t *testing.T) {
 		WithSchema(t, findTasks_ByRepoID)
 	})
+
+	t.Run("ByPlanning", func(t *testing.T) {
+		WithSchema(t, findTasks_ByPlanning)
+	})
+
+	t.Run("ByQuery", func(t *testing.T) {
+		WithSchema(t, findTasks_ByQuery)
+	})
+
+	t.Run("ByCursor", func(t *testing.T) {
+		WithSchema(t, findTasks_ByCursor)
+	})
 }
 
 func TestTaskService_FindTaskByID(t *testing.T) {
@@ -43,6 +56,18 @@ func TestTaskService_UpdateTask(t *testing.T) {
 		WithSchema(t, updateTask_UnattachContributor)
 	})
 
+	t.Run("Member completion", func(t *testing.T) {
+		WithSchema(t, updateTask_MemberCompletion)
+	})
+
+	t.Run("Planning", func(t *testing.T) {
+		WithSchema(t, updateTask_Planning)
+	})
+
+	t.Run("SetStatus", func(t *testing.T) {
+		WithSchema(t, updateTask_SetStatus)
+	})
+
 	t.Run("Errors", func(t *testing.T) {
 		WithSchema(t, func(t testing.TB, conn *postgres.Conn) {
 			updateTask_Errors(t.(*testing.T), conn)
@@ -50,11 +75,29 @@ func TestTaskService_UpdateTask(t *testing.T) {
 	})
 }
 
+func TestTaskService_Events(t *testing.T) {
+	t.Run("PublishedOnCommit", func(t *testing.T) {
+		WithSchema(t, taskEvents_PublishedOnCommit)
+	})
+
+	t.Run("DiscardedOnRollback", func(t *testing.T) {
+		WithSchema(t, taskEvents_DiscardedOnRollback)
+	})
+}
+
 func TestTaskService_DeleteTask(t *testing.T) {
 	t.Run("OK", func(t *testing.T) {
 		WithSchema(t, deleteTask_OK)
 	})
 
+	t.Run("Restore", func(t *testing.T) {
+		WithSchema(t, deleteTask_Restore)
+	})
+
+	t.Run("PurgeRepo", func(t *testing.T) {
+		WithSchema(t, deleteTask_PurgeRepo)
+	})
+
 	t.Run("Errors", func(t *testing.T) {
 		WithSchema(t, func(t testing.TB, conn *postgres.Conn) {
 			deleteTask_Errors(t.(*testing.T), conn)
@@ -91,10 +134,9 @@ func updateTask_OK(t testing.TB, conn *postgres.Conn) {
 	task := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Do some stuff.", RepoID: repo0.ID})
 
 	description := "Do some other stuff."
-	toggleCompletion := true
 	if task, err := s.UpdateTask(ctx0, task.ID, todev.TaskUpdate{
-		Description:      &description,
-		ToggleCompletion: toggleCompletion,
+		Description: &description,
+		SetStatus:   &repo0.TerminalState().ID,
 	}); err != nil {
 		t.Fatal(err)
 	} else if other, err := s.FindTaskByID(ctx0, task.ID); err != nil {
@@ -145,6 +187,147 @@ func updateTask_AttachContributor(t testing.TB, conn *postgres.Conn) {
 	}
 }
 
+func taskEvents_PublishedOnCommit(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewTaskService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	user1, ctx1 := MustCreateUser(t, ctx, conn, &todev.User{Name: "judy", Email: "judy@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	MustCreateContributor(t, ctx1, conn, &todev.Contributor{RepoID: repo0.ID})
+
+	events := RecordEvents(conn)
+	task := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Do some stuff.", RepoID: repo0.ID})
+
+	description := "Do some other stuff."
+	if _, err := s.UpdateTask(ctx0, task.ID, todev.TaskUpdate{Description: &description, SetStatus: &repo0.TerminalState().ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the other contributor receives events, in the order they occurred.
+	want := []string{
+		todev.EventTypeTaskAdded,
+		todev.EventTypeTaskDescriptionChanged,
+		todev.EventTypeTaskStatusChanged,
+	}
+	if got := len(*events); got != len(want) {
+		t.Fatalf("len=%d, want %d", got, len(want))
+	}
+	for i, e := range *events {
+		if e.UserID != user1.ID {
+			t.Fatalf("[%d] UserID=%d, want %d", i, e.UserID, user1.ID)
+		} else if e.Event.Type != want[i] {
+			t.Fatalf("[%d] Type=%q, want %q", i, e.Event.Type, want[i])
+		}
+	}
+}
+
+func taskEvents_DiscardedOnRollback(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewTaskService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	_, ctx1 := MustCreateUser(t, ctx, conn, &todev.User{Name: "judy", Email: "judy@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	MustCreateContributor(t, ctx1, conn, &todev.Contributor{RepoID: repo0.ID})
+	task := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Do some stuff.", RepoID: repo0.ID})
+
+	events := RecordEvents(conn)
+
+	// Invalid update.
+	description := strings.Repeat("x", todev.MaxTaskDescriptionLen+1)
+	if _, err := s.UpdateTask(ctx0, task.ID, todev.TaskUpdate{Description: &description, SetStatus: &repo0.TerminalState().ID}); todev.ErrorCode(err) != todev.EINVALID {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Unauthorized delete.
+	if err := s.DeleteTask(ctx1, task.ID); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Unauthorized creation.
+	if err := s.CreateTask(ctx1, &todev.Task{Description: "Do more stuff.", RepoID: repo0.ID}); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := len(*events); got != 0 {
+		t.Fatalf("len=%d, want 0: %#v", got, *events)
+	} else if other, err := s.FindTaskByID(ctx0, task.ID); err != nil {
+		t.Fatal(err)
+	} else if other.IsCompleted {
+		t.Fatal("expected task to stay uncompleted")
+	}
+
+	// Events already queued when a transaction is rolled back are dropped.
+	event := todev.Event{Type: todev.EventTypeTaskDeleted, RepoID: repo0.ID, Payload: todev.TaskDeleted{ID: task.ID}}
+	if tx, err := conn.BeginTx(ctx0, nil); err != nil {
+		t.Fatal(err)
+	} else {
+		tx.PublishEvent(1, event)
+		if err = tx.Rollback(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := len(*events); got != 0 {
+		t.Fatalf("len=%d, want 0: %#v", got, *events)
+	}
+
+	// Whereas they are published once it commits.
+	if tx, err := conn.BeginTx(ctx0, nil); err != nil {
+		t.Fatal(err)
+	} else {
+		tx.PublishEvent(1, event)
+		if err = tx.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := len(*events); got != 1 {
+		t.Fatalf("len=%d, want 1: %#v", got, *events)
+	}
+}
+
+func updateTask_MemberCompletion(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewTaskService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	_, ctx1 := MustCreateUser(t, ctx, conn, &todev.User{Name: "judy", Email: "judy@gmail.com"})
+	_, ctx2 := MustCreateUser(t, ctx, conn, &todev.User{Name: "george", Email: "george@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+
+	contributor1 := MustCreateContributor(t, ctx1, conn, &todev.Contributor{RepoID: repo0.ID})
+	contributor2 := MustCreateContributor(t, ctx2, conn, &todev.Contributor{RepoID: repo0.ID})
+
+	// Demote the second contributor so they can only view the repo.
+	viewer := todev.RoleViewer
+	if _, err := postgres.NewContrubutorService(conn).UpdateContributor(ctx0, contributor2.ID, todev.ContributorUpdate{Role: &viewer}); err != nil {
+		t.Fatal(err)
+	}
+
+	task := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Do some stuff.", RepoID: repo0.ID})
+	if err := s.AttachContributor(ctx0, task, contributor1.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Members can complete tasks given to them.
+	if other, err := s.UpdateTask(ctx1, task.ID, todev.TaskUpdate{SetStatus: &repo0.TerminalState().ID}); err != nil {
+		t.Fatal(err)
+	} else if !other.IsCompleted {
+		t.Fatalf("IsCompleted=%v, want %v", other.IsCompleted, true)
+	}
+
+	// Viewers cannot complete any task.
+	if _, err := s.UpdateTask(ctx2, task.ID, todev.TaskUpdate{SetStatus: &repo0.TerminalState().ID}); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Members cannot change the description.
+	description := "Do some other stuff."
+	if _, err := s.UpdateTask(ctx1, task.ID, todev.TaskUpdate{Description: &description}); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func updateTask_UnattachContributor(t testing.TB, conn *postgres.Conn) {
 	s := postgres.NewTaskService(conn)
 
@@ -201,7 +384,7 @@ func updateTask_Errors(t *testing.T, conn *postgres.Conn) {
 			ctx:   ctx1,
 			input: upd,
 			expected: &todev.Error{
-				Code:    todev.ECONFLICT,
+				Code:    todev.EUNAUTHORIZED,
 				Message: "You are not allowed to update tasks.",
 			},
 			id: task.ID,
@@ -251,6 +434,92 @@ func deleteTask_OK(t testing.TB, conn *postgres.Conn) {
 	}
 }
 
+func deleteTask_Restore(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewTaskService(conn)
+	rs := postgres.NewRepoService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	task0 := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "task0", RepoID: repo0.ID})
+	task1 := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "task1", RepoID: repo0.ID})
+
+	if err := s.DeleteTask(ctx0, task0.ID); err != nil {
+		t.Fatal(err)
+	} else if err = s.DeleteTask(ctx0, task1.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Trashed tasks are only listed in the trash.
+	if _, n, err := s.FindTasks(ctx0, todev.TaskFilter{RepoID: &repo0.ID}); err != nil {
+		t.Fatal(err)
+	} else if got, want := n, 0; got != want {
+		t.Fatalf("n=%d, want %d", got, want)
+	}
+	if tasks, n, err := s.FindTasks(ctx0, todev.TaskFilter{RepoID: &repo0.ID, Trashed: true}); err != nil {
+		t.Fatal(err)
+	} else if got, want := n, 2; got != want {
+		t.Fatalf("n=%d, want %d", got, want)
+	} else if tasks[0].DeletedAt.IsZero() {
+		t.Fatal("expected DeletedAt")
+	}
+
+	// Restored tasks are live again.
+	if task, err := s.RestoreTask(ctx0, task0.ID); err != nil {
+		t.Fatal(err)
+	} else if !task.DeletedAt.IsZero() {
+		t.Fatalf("unexpected DeletedAt: %v", task.DeletedAt)
+	} else if _, err = s.FindTaskByID(ctx0, task0.ID); err != nil {
+		t.Fatal(err)
+	} else if _, err = s.RestoreTask(ctx0, task0.ID); todev.ErrorCode(err) != todev.ENOTFOUND {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Purging removes the remaining trashed task for good.
+	if n, err := rs.PurgeTrash(ctx, conn.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	} else if got, want := n, 1; got != want {
+		t.Fatalf("n=%d, want %d", got, want)
+	} else if _, err = s.RestoreTask(ctx0, task1.ID); todev.ErrorCode(err) != todev.ENOTFOUND {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func deleteTask_PurgeRepo(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewTaskService(conn)
+	rs := postgres.NewRepoService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	repo1 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo2"})
+	task0 := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "task0", RepoID: repo0.ID})
+	task1 := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "task1", RepoID: repo0.ID})
+	task2 := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "task2", RepoID: repo1.ID})
+	MustAddDependency(t, ctx0, conn, task1.ID, task0.ID)
+
+	// Live tasks stay live when their repo is trashed.
+	if err := s.DeleteTask(ctx0, task0.ID); err != nil {
+		t.Fatal(err)
+	} else if err = rs.DeleteRepo(ctx0, repo0.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Purging the repo removes all of its tasks along with it.
+	if n, err := rs.PurgeTrash(ctx, conn.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	} else if got, want := n, 3; got != want {
+		t.Fatalf("n=%d, want %d", got, want)
+	} else if _, err = rs.RestoreRepo(ctx0, repo0.ID); todev.ErrorCode(err) != todev.ENOTFOUND {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Other repos are left alone.
+	if _, err := s.FindTaskByID(ctx0, task2.ID); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func deleteTask_Errors(t *testing.T, conn *postgres.Conn) {
 	type testData struct {
 		ctx      context.Context
@@ -273,7 +542,7 @@ func deleteTask_Errors(t *testing.T, conn *postgres.Conn) {
 			ctx:   ctx1,
 			input: task.ID,
 			expected: &todev.Error{
-				Code:    todev.ECONFLICT,
+				Code:    todev.EUNAUTHORIZED,
 				Message: "You are not allowed to delete tasks.",
 			},
 		},
@@ -298,6 +567,162 @@ func deleteTask_Errors(t *testing.T, conn *postgres.Conn) {
 	}
 }
 
+func findTasks_ByPlanning(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewTaskService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+
+	now := time.Now().UTC().Truncate(time.Second)
+	task0 := MustCreateTask(t, ctx0, conn, &todev.Task{
+		Description: "Fix the bug.",
+		RepoID:      repo0.ID,
+		DueAt:       now.Add(24 * time.Hour),
+		Priority:    todev.TaskPriorityHigh,
+		Labels:      []string{"bug", " backend ", "bug"},
+	})
+	task1 := MustCreateTask(t, ctx0, conn, &todev.Task{
+		Description: "Write the docs.",
+		RepoID:      repo0.ID,
+		DueAt:       now.Add(48 * time.Hour),
+		Priority:    todev.TaskPriorityLow,
+		Labels:      []string{"docs"},
+	})
+	MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Someday.", RepoID: repo0.ID})
+
+	if got, want := task0.Labels, []string{"backend", "bug"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Labels=%v, want %v", got, want)
+	}
+
+	label := "bug"
+	if tasks, _, err := s.FindTasks(ctx0, todev.TaskFilter{RepoID: &repo0.ID, Label: &label}); err != nil {
+		t.Fatal(err)
+	} else if got, want := len(tasks), 1; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	} else if !reflect.DeepEqual(task0, tasks[0]) {
+		t.Fatalf("mismatch: %#v !=\n%#v", task0, tasks[0])
+	}
+
+	priority := todev.TaskPriorityLow
+	if tasks, _, err := s.FindTasks(ctx0, todev.TaskFilter{RepoID: &repo0.ID, Priority: &priority}); err != nil {
+		t.Fatal(err)
+	} else if got, want := len(tasks), 1; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	} else if got, want := tasks[0].ID, task1.ID; got != want {
+		t.Fatalf("ID=%d, want %d", got, want)
+	}
+
+	// Tasks without a due date never match due date filters.
+	dueBefore, dueAfter := now.Add(36*time.Hour), now
+	if tasks, _, err := s.FindTasks(ctx0, todev.TaskFilter{RepoID: &repo0.ID, DueBefore: &dueBefore}); err != nil {
+		t.Fatal(err)
+	} else if got, want := len(tasks), 1; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	} else if got, want := tasks[0].ID, task0.ID; got != want {
+		t.Fatalf("ID=%d, want %d", got, want)
+	} else if tasks, _, err := s.FindTasks(ctx0, todev.TaskFilter{RepoID: &repo0.ID, DueAfter: &dueAfter}); err != nil {
+		t.Fatal(err)
+	} else if got, want := len(tasks), 2; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	}
+
+	if tasks, _, err := s.FindTasks(ctx0, todev.TaskFilter{RepoID: &repo0.ID, SortBy: todev.TasksSortByDueAtAsc}); err != nil {
+		t.Fatal(err)
+	} else if got, want := len(tasks), 3; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	} else if tasks[0].ID != task0.ID || tasks[1].ID != task1.ID {
+		t.Fatalf("unexpected order: %d, %d", tasks[0].ID, tasks[1].ID)
+	}
+
+	if tasks, _, err := s.FindTasks(ctx0, todev.TaskFilter{RepoID: &repo0.ID, SortBy: todev.TasksSortByPriorityDesc}); err != nil {
+		t.Fatal(err)
+	} else if tasks[0].ID != task0.ID || tasks[1].ID != task1.ID {
+		t.Fatalf("unexpected order: %d, %d", tasks[0].ID, tasks[1].ID)
+	}
+}
+
+func findTasks_ByQuery(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewTaskService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+
+	task0 := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Fix the login & signup bug.", RepoID: repo0.ID})
+	task1 := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Log failed logins and other login errors.", RepoID: repo0.ID})
+	MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Write the docs.", RepoID: repo0.ID})
+
+	// Tasks mentioning the term more often rank first.
+	query := "logins"
+	if tasks, n, err := s.FindTasks(ctx0, todev.TaskFilter{RepoID: &repo0.ID, Query: &query}); err != nil {
+		t.Fatal(err)
+	} else if got, want := n, 2; got != want {
+		t.Fatalf("n=%d, want %d", got, want)
+	} else if got, want := tasks[0].ID, task1.ID; got != want {
+		t.Fatalf("ID=%d, want %d", got, want)
+	} else if got, want := tasks[1].ID, task0.ID; got != want {
+		t.Fatalf("ID=%d, want %d", got, want)
+	} else if got, want := tasks[1].Snippet, "Fix the <mark>login</mark> &amp; signup bug."; got != want {
+		t.Fatalf("Snippet=%q, want %q", got, want)
+	}
+
+	// Excluded terms and snippets are only set for searches.
+	query = "login -bug"
+	if tasks, _, err := s.FindTasks(ctx0, todev.TaskFilter{RepoID: &repo0.ID, Query: &query}); err != nil {
+		t.Fatal(err)
+	} else if got, want := len(tasks), 1; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	} else if got, want := tasks[0].ID, task1.ID; got != want {
+		t.Fatalf("ID=%d, want %d", got, want)
+	} else if tasks, _, err = s.FindTasks(ctx0, todev.TaskFilter{ID: &task0.ID}); err != nil {
+		t.Fatal(err)
+	} else if got := tasks[0].Snippet; got != "" {
+		t.Fatalf("unexpected snippet: %q", got)
+	}
+}
+
+func findTasks_ByCursor(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewTaskService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+
+	task0 := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "task0", RepoID: repo0.ID, Priority: todev.TaskPriorityLow})
+	task1 := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "task1", RepoID: repo0.ID, Priority: todev.TaskPriorityHigh})
+	task2 := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "task2", RepoID: repo0.ID, Priority: todev.TaskPriorityLow})
+
+	// Pages resume after the cursor and report the number of remaining tasks.
+	filter := todev.TaskFilter{RepoID: &repo0.ID, SortBy: todev.TasksSortByPriorityDesc, Limit: 2}
+	if tasks, n, err := s.FindTasks(ctx0, filter); err != nil {
+		t.Fatal(err)
+	} else if got, want := n, 3; got != want {
+		t.Fatalf("n=%d, want %d", got, want)
+	} else if got, want := []int{tasks[0].ID, tasks[1].ID}, []int{task1.ID, task0.ID}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("IDs=%v, want %v", got, want)
+	}
+
+	filter.Cursor = todev.EncodeCursor(task0.ID)
+	if tasks, n, err := s.FindTasks(ctx0, filter); err != nil {
+		t.Fatal(err)
+	} else if got, want := n, 1; got != want {
+		t.Fatalf("n=%d, want %d", got, want)
+	} else if got, want := tasks[0].ID, task2.ID; got != want {
+		t.Fatalf("ID=%d, want %d", got, want)
+	}
+
+	// Cursors cannot be combined with offsets or point to missing tasks.
+	filter.Offset = 1
+	if _, _, err := s.FindTasks(ctx0, filter); todev.ErrorCode(err) != todev.EINVALID {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	filter.Offset, filter.Cursor = 0, todev.EncodeCursor(task2.ID+100)
+	if _, _, err := s.FindTasks(ctx0, filter); todev.ErrorCode(err) != todev.EINVALID {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func findTasks_ByRepoID(t testing.TB, conn *postgres.Conn) {
 	s := postgres.NewTaskService(conn)
 
@@ -415,8 +840,8 @@ func createTask_Errors(t *testing.T, conn *postgres.Conn) {
 		"ErrTaskCreationReject": {
 			input: &todev.Task{Description: "Do some stuff.", RepoID: repo1.ID},
 			expected: &todev.Error{
-				Code:    todev.ECONFLICT,
-				Message: "Only repo owner can create tasks.",
+				Code:    todev.EUNAUTHORIZED,
+				Message: "Only repo admins can create tasks.",
 			},
 			ctx: ctx1,
 		},
@@ -474,3 +899,111 @@ func MustUpdateTask(tb testing.TB, ctx context.Context, conn *postgres.Conn, id
 	}
 	return task
 }
+
+func updateTask_SetStatus(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewTaskService(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	repo1 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo2"})
+	doing, done := repo0.States[1], repo0.States[2]
+
+	// New tasks start in the initial state.
+	task := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Do some stuff.", RepoID: repo0.ID})
+	if got, want := task.StateID, repo0.InitialState().ID; got != want {
+		t.Fatalf("StateID=%d, want %d", got, want)
+	}
+
+	if other, err := s.UpdateTask(ctx0, task.ID, todev.TaskUpdate{SetStatus: &doing.ID}); err != nil {
+		t.Fatal(err)
+	} else if got, want := other.StateID, doing.ID; got != want {
+		t.Fatalf("StateID=%d, want %d", got, want)
+	} else if other.IsCompleted {
+		t.Fatal("expected task to stay uncompleted")
+	}
+
+	if other, err := s.UpdateTask(ctx0, task.ID, todev.TaskUpdate{SetStatus: &done.ID}); err != nil {
+		t.Fatal(err)
+	} else if !other.IsCompleted {
+		t.Fatal("expected task to be completed")
+	} else if other.CompletedAt.IsZero() {
+		t.Fatal("expected completion time")
+	}
+
+	// Moving back out of the terminal state reopens the task.
+	if other, err := s.UpdateTask(ctx0, task.ID, todev.TaskUpdate{SetStatus: &doing.ID}); err != nil {
+		t.Fatal(err)
+	} else if other.IsCompleted || !other.CompletedAt.IsZero() {
+		t.Fatalf("IsCompleted=%v, CompletedAt=%v", other.IsCompleted, other.CompletedAt)
+	}
+
+	// States of another repo are rejected.
+	if _, err := s.UpdateTask(ctx0, task.ID, todev.TaskUpdate{SetStatus: &repo1.States[0].ID}); todev.ErrorCode(err) != todev.EINVALID {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if tasks, _, err := s.FindTasks(ctx0, todev.TaskFilter{StateID: &doing.ID}); err != nil {
+		t.Fatal(err)
+	} else if got, want := len(tasks), 1; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	}
+}
+
+func updateTask_Planning(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewTaskService(conn)
+	events := RecordEvents(conn)
+
+	ctx := context.Background()
+	_, ctx0 := MustCreateUser(t, ctx, conn, &todev.User{Name: "bob", Email: "bob@gmail.com"})
+	_, ctx1 := MustCreateUser(t, ctx, conn, &todev.User{Name: "judy", Email: "judy@gmail.com"})
+	repo0 := MustCreateRepo(t, ctx0, conn, &todev.Repo{Name: "repo1"})
+	MustCreateContributor(t, ctx1, conn, &todev.Contributor{RepoID: repo0.ID})
+	task := MustCreateTask(t, ctx0, conn, &todev.Task{Description: "Do some stuff.", RepoID: repo0.ID, Labels: []string{"bug"}})
+	*events = nil
+
+	dueAt := time.Now().Add(time.Hour)
+	priority := todev.TaskPriorityMedium
+	labels := []string{"feature"}
+	if task, err := s.UpdateTask(ctx0, task.ID, todev.TaskUpdate{DueAt: &dueAt, Priority: &priority, Labels: &labels}); err != nil {
+		t.Fatal(err)
+	} else if other, err := s.FindTaskByID(ctx0, task.ID); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(task, other) {
+		t.Fatalf("mismatch: %#v !=\n%#v", task, other)
+	} else if got, want := other.DueAt, dueAt.UTC().Truncate(time.Second); !got.Equal(want) {
+		t.Fatalf("DueAt=%v, want %v", got, want)
+	} else if got, want := other.Priority, priority; got != want {
+		t.Fatalf("Priority=%d, want %d", got, want)
+	} else if got, want := other.Labels, labels; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Labels=%v, want %v", got, want)
+	}
+
+	types := make(map[string]bool)
+	for _, e := range *events {
+		types[e.Event.Type] = true
+	}
+	for _, typ := range []string{todev.EventTypeTaskDueAtChanged, todev.EventTypeTaskPriorityChanged, todev.EventTypeTaskLabelsChanged} {
+		if !types[typ] {
+			t.Fatalf("expected %q event", typ)
+		}
+	}
+
+	// Clearing the due date.
+	var zero time.Time
+	if task, err := s.UpdateTask(ctx0, task.ID, todev.TaskUpdate{DueAt: &zero}); err != nil {
+		t.Fatal(err)
+	} else if !task.DueAt.IsZero() {
+		t.Fatalf("DueAt=%v, want zero", task.DueAt)
+	}
+
+	// Only admins can plan tasks.
+	if _, err := s.UpdateTask(ctx1, task.ID, todev.TaskUpdate{Priority: &priority}); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+
+	invalid := 10
+	if _, err := s.UpdateTask(ctx0, task.ID, todev.TaskUpdate{Priority: &invalid}); todev.ErrorCode(err) != todev.EINVALID {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+}
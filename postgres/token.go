@@ -0,0 +1,339 @@
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/saiddis/todev"
+)
+
+// tokenLastUsedInterval is how often the last use of a token is recorded so
+// every authenticated request does not have to write to the database.
+const tokenLastUsedInterval = time.Minute
+
+// Ensure service implements interface.
+var _ todev.TokenService = (*TokenService)(nil)
+
+// TokenService represents a service for managing personal access tokens.
+type TokenService struct {
+	conn *Conn
+}
+
+// NewTokenService returns a new instance of TokenService.
+func NewTokenService(conn *Conn) *TokenService {
+	return &TokenService{conn: conn}
+}
+
+// FindTokens retrieves the current user's tokens based on filter, newest
+// first. Also returns the total number of matching tokens.
+func (s *TokenService) FindTokens(ctx context.Context, filter todev.TokenFilter) ([]*todev.Token, int, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("FindTokens: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	tokens, n, err := findTokens(ctx, tx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tokens, n, nil
+}
+
+// CreateToken creates a new token for the current user and sets its secret.
+func (s *TokenService) CreateToken(ctx context.Context, token *todev.Token) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("CreateToken: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	if err = createToken(ctx, tx, token); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteToken permanently revokes a token by ID. Returns ENOTFOUND if the
+// token does not belong to the current user.
+func (s *TokenService) DeleteToken(ctx context.Context, id int) error {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("DeleteToken: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	if err = deleteToken(ctx, tx, id); err != nil {
+		return err
+	}
+	return nil
+}
+
+// AuthenticateToken looks up a token by secret along with its user and
+// records its use. Returns EUNAUTHORIZED if the secret is unknown or the token
+// has expired.
+func (s *TokenService) AuthenticateToken(ctx context.Context, secret string) (*todev.Token, error) {
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error beginning transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("AuthenticateToken: %w", err)
+			if err := tx.Rollback(); err != nil {
+				log.Printf("failed to rollback: %v", err)
+			}
+		} else {
+			if err := tx.Commit(); err != nil {
+				log.Printf("failed to commit: %v", err)
+			}
+		}
+	}()
+
+	token, err := authenticateToken(ctx, tx, secret)
+	if err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// generateTokenSecret returns a new random token secret.
+func generateTokenSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", fmt.Errorf("error generating token: %w", err)
+	}
+	return todev.TokenPrefix + hex.EncodeToString(b), nil
+}
+
+func createToken(ctx context.Context, tx *Tx, token *todev.Token) (err error) {
+	token.UserID = todev.UserIDFromContext(ctx)
+	if token.UserID == 0 {
+		return todev.Errorf(todev.EUNAUTHORIZED, "You must be logged in to create a token.")
+	} else if err = token.Validate(); err != nil {
+		return err
+	} else if token.IsExpired(tx.now) {
+		return todev.Errorf(todev.EINVALID, "Token expiration must be in the future.")
+	}
+
+	if token.Secret, err = generateTokenSecret(); err != nil {
+		return err
+	}
+	token.LastUsedAt = time.Time{}
+	token.CreatedAt = tx.now
+
+	if err = tx.QueryRowContext(ctx, `
+		INSERT INTO tokens (
+			user_id,
+			name,
+			scopes,
+			hash,
+			expires_at,
+			created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id;`,
+		token.UserID,
+		token.Name,
+		pq.Array(token.Scopes),
+		hashToken(token.Secret),
+		(*NullTime)(&token.ExpiresAt),
+		(*NullTime)(&token.CreatedAt),
+	).Scan(&token.ID); err != nil {
+		return fmt.Errorf("error inserting token: %w", err)
+	}
+	return nil
+}
+
+func deleteToken(ctx context.Context, tx *Tx, id int) error {
+	if todev.UserIDFromContext(ctx) == 0 {
+		return todev.Errorf(todev.EUNAUTHORIZED, "You must be logged in to delete a token.")
+	}
+
+	tokens, _, err := findTokens(ctx, tx, todev.TokenFilter{ID: &id})
+	if err != nil {
+		return err
+	} else if len(tokens) == 0 {
+		return todev.Errorf(todev.ENOTFOUND, "Token not found.")
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM tokens WHERE id = $1;`, id); err != nil {
+		return fmt.Errorf("error deleting token: %w", err)
+	}
+	return nil
+}
+
+func authenticateToken(ctx context.Context, tx *Tx, secret string) (*todev.Token, error) {
+	var token todev.Token
+	var scopes []string
+	if err := tx.QueryRowContext(ctx, `
+		SELECT
+			id,
+			user_id,
+			name,
+			scopes,
+			expires_at,
+			last_used_at,
+			created_at
+		FROM tokens
+		WHERE hash = $1;`,
+		hashToken(secret),
+	).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.Name,
+		pq.Array(&scopes),
+		(*NullTime)(&token.ExpiresAt),
+		(*NullTime)(&token.LastUsedAt),
+		(*NullTime)(&token.CreatedAt),
+	); err == sql.ErrNoRows {
+		return nil, todev.Errorf(todev.EUNAUTHORIZED, "Invalid API key.")
+	} else if err != nil {
+		return nil, fmt.Errorf("error retrieving token: %w", err)
+	}
+	token.Scopes = scopes
+
+	if token.IsExpired(tx.now) {
+		return nil, todev.Errorf(todev.EUNAUTHORIZED, "API key expired.")
+	}
+
+	if token.LastUsedAt.IsZero() || tx.now.Sub(token.LastUsedAt) >= tokenLastUsedInterval {
+		token.LastUsedAt = tx.now
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE tokens SET last_used_at = $1 WHERE id = $2;`,
+			(*NullTime)(&token.LastUsedAt),
+			token.ID,
+		); err != nil {
+			return nil, fmt.Errorf("error updating token last use: %w", err)
+		}
+	}
+
+	user, err := findUserByID(ctx, tx, token.UserID)
+	if err != nil {
+		return nil, err
+	} else if err = attachUserAuths(ctx, tx, user); err != nil {
+		return nil, err
+	}
+	token.User = user
+
+	return &token, nil
+}
+
+// findTokens returns the current user's tokens matching filter. Secrets are
+// never returned as only their hash is stored.
+func findTokens(ctx context.Context, tx *Tx, filter todev.TokenFilter) ([]*todev.Token, int, error) {
+	var argIndex int
+	where, args := []string{}, []interface{}{}
+
+	argIndex++
+	where, args = append(where, fmt.Sprintf("user_id = $%d", argIndex)), append(args, todev.UserIDFromContext(ctx))
+
+	if v := filter.ID; v != nil {
+		argIndex++
+		where, args = append(where, fmt.Sprintf("id = $%d", argIndex)), append(args, *v)
+	}
+
+	keys := []sortKey{{expr: "id", desc: true}}
+	cursorID, err := findCursorID(ctx, tx, "tokens", filter.Cursor, filter.Offset)
+	if err != nil {
+		return nil, 0, err
+	} else if cursorID != 0 {
+		argIndex++
+		where, args = append(where, formatKeyset(keys, "tokens", argIndex)), append(args, cursorID)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			id,
+			user_id,
+			name,
+			scopes,
+			expires_at,
+			last_used_at,
+			created_at,
+			COUNT(*) OVER()
+		FROM tokens
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY `+formatOrderBy(keys)+`
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error retrieving tokens: %w", err)
+	}
+	defer func() {
+		err := rows.Close()
+		if err != nil {
+			log.Printf("error closing rows: %v", err)
+		}
+	}()
+
+	tokens := make([]*todev.Token, 0)
+
+	var n int
+	for rows.Next() {
+		var token todev.Token
+		var scopes []string
+		if err = rows.Scan(
+			&token.ID,
+			&token.UserID,
+			&token.Name,
+			pq.Array(&scopes),
+			(*NullTime)(&token.ExpiresAt),
+			(*NullTime)(&token.LastUsedAt),
+			(*NullTime)(&token.CreatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, fmt.Errorf("error scanning: %w", err)
+		}
+		token.Scopes = scopes
+		tokens = append(tokens, &token)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return tokens, n, nil
+}
@@ -0,0 +1,108 @@
+package postgres_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/postgres"
+)
+
+func TestTokenService_CreateToken(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		WithSchema(t, createToken_OK)
+	})
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		WithSchema(t, createToken_ErrInvalid)
+	})
+}
+
+func TestTokenService_DeleteToken(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		WithSchema(t, deleteToken_OK)
+	})
+}
+
+func createToken_OK(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewTokenService(conn)
+	user0, ctx0 := MustCreateUser(t, context.Background(), conn, &todev.User{Name: "bob"})
+
+	token := &todev.Token{Name: "cli", Scopes: []string{todev.ScopeRead, todev.ScopeTasksWrite}}
+	if err := s.CreateToken(ctx0, token); err != nil {
+		t.Fatal(err)
+	} else if !strings.HasPrefix(token.Secret, todev.TokenPrefix) {
+		t.Fatalf("unexpected secret: %q", token.Secret)
+	} else if got, want := token.UserID, user0.ID; got != want {
+		t.Fatalf("UserID=%d, want %d", got, want)
+	}
+
+	// Authenticating returns the token along with its user and records the use.
+	if other, err := s.AuthenticateToken(context.Background(), token.Secret); err != nil {
+		t.Fatal(err)
+	} else if got, want := other.User.ID, user0.ID; got != want {
+		t.Fatalf("User.ID=%d, want %d", got, want)
+	} else if got, want := strings.Join(other.Scopes, ","), "read,tasks:write"; got != want {
+		t.Fatalf("Scopes=%q, want %q", got, want)
+	} else if other.LastUsedAt.IsZero() {
+		t.Fatal("expected last used at")
+	}
+
+	// Secrets are never returned after creation.
+	if tokens, n, err := s.FindTokens(ctx0, todev.TokenFilter{}); err != nil {
+		t.Fatal(err)
+	} else if got, want := n, 1; got != want {
+		t.Fatalf("n=%d, want %d", got, want)
+	} else if tokens[0].Secret != "" {
+		t.Fatal("expected secret to be hidden")
+	} else if tokens[0].LastUsedAt.IsZero() {
+		t.Fatal("expected last used at")
+	}
+
+	if _, err := s.AuthenticateToken(context.Background(), token.Secret+"0"); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func createToken_ErrInvalid(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewTokenService(conn)
+	_, ctx0 := MustCreateUser(t, context.Background(), conn, &todev.User{Name: "bob"})
+
+	if err := s.CreateToken(ctx0, &todev.Token{Name: "cli"}); todev.ErrorCode(err) != todev.EINVALID {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err = s.CreateToken(ctx0, &todev.Token{Name: "cli", Scopes: []string{"admin"}}); todev.ErrorCode(err) != todev.EINVALID {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err = s.CreateToken(ctx0, &todev.Token{Name: "cli", Scopes: []string{todev.ScopeAll}, ExpiresAt: time.Now().Add(-time.Hour)}); todev.ErrorCode(err) != todev.EINVALID {
+		t.Fatalf("unexpected error: %v", err)
+	} else if err = s.CreateToken(context.Background(), &todev.Token{Name: "cli", Scopes: []string{todev.ScopeAll}}); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func deleteToken_OK(t testing.TB, conn *postgres.Conn) {
+	s := postgres.NewTokenService(conn)
+	_, ctx0 := MustCreateUser(t, context.Background(), conn, &todev.User{Name: "bob"})
+	_, ctx1 := MustCreateUser(t, context.Background(), conn, &todev.User{Name: "jill"})
+
+	token := &todev.Token{Name: "cli", Scopes: []string{todev.ScopeAll}}
+	if err := s.CreateToken(ctx0, token); err != nil {
+		t.Fatal(err)
+	}
+
+	// Other users cannot see or revoke the token.
+	if err := s.DeleteToken(ctx1, token.ID); todev.ErrorCode(err) != todev.ENOTFOUND {
+		t.Fatalf("unexpected error: %v", err)
+	} else if tokens, _, err := s.FindTokens(ctx1, todev.TokenFilter{}); err != nil {
+		t.Fatal(err)
+	} else if got, want := len(tokens), 0; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	}
+
+	if err := s.DeleteToken(ctx0, token.ID); err != nil {
+		t.Fatal(err)
+	} else if _, err = s.AuthenticateToken(context.Background(), token.Secret); todev.ErrorCode(err) != todev.EUNAUTHORIZED {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -2,11 +2,8 @@ package postgres
 
 import (
 	"context"
-	"crypto/rand"
 	"database/sql"
-	"encoding/hex"
 	"fmt"
-	"io"
 	"log"
 	"strings"
 
@@ -180,9 +177,14 @@ func findUsers(ctx context.Context, tx *Tx, filter todev.UserFilter) ([]*todev.U
 		argIndex++
 		where, args = append(where, fmt.Sprintf("email = $%d", argIndex)), append(args, *v)
 	}
-	if v := filter.APIKey; v != nil {
+
+	keys := []sortKey{{expr: "id"}}
+	cursorID, err := findCursorID(ctx, tx, "users", filter.Cursor, filter.Offset)
+	if err != nil {
+		return nil, 0, err
+	} else if cursorID != 0 {
 		argIndex++
-		where, args = append(where, fmt.Sprintf("api_key = $%d", argIndex)), append(args, *v)
+		where, args = append(where, formatKeyset(keys, "users", argIndex)), append(args, cursorID)
 	}
 
 	// Prepare a query for retrieving users.
@@ -191,14 +193,13 @@ func findUsers(ctx context.Context, tx *Tx, filter todev.UserFilter) ([]*todev.U
 			id,
 			name,
 			email,
-			api_key,
 			created_at,
 			updated_at,
 			COUNT(*) OVER()
 		FROM users
 		WHERE `+strings.Join(where, " AND ")+`
 		GROUP BY id
-		ORDER BY id ASC
+		ORDER BY `+formatOrderBy(keys)+`
 		`+FormatLimitOffset(filter.Limit, filter.Offset),
 	)
 	if err != nil {
@@ -228,7 +229,6 @@ func findUsers(ctx context.Context, tx *Tx, filter todev.UserFilter) ([]*todev.U
 			&user.ID,
 			&user.Name,
 			&email,
-			&user.APIKey,
 			(*NullTime)(&user.CreatedAt),
 			(*NullTime)(&user.UpdatedAt),
 			&n,
@@ -295,28 +295,19 @@ func createUser(ctx context.Context, tx *Tx, user *todev.User) (err error) {
 		email = &user.Email
 	}
 
-	// Generate random API key.
-	apiKey := make([]byte, 32)
-	if _, err := io.ReadFull(rand.Reader, apiKey); err != nil {
-		return fmt.Errorf("error creating api key: %w", err)
-	}
-	user.APIKey = hex.EncodeToString(apiKey)
-
 	// Execute insertion query.
 	var id int
 	err = tx.QueryRowContext(ctx, `
 		INSERT INTO users (
 			name,
 			email,
-			api_key,
 			created_at,
 			updated_at
 		)
-		VALUES ($1, $2, $3, $4, $5)
+		VALUES ($1, $2, $3, $4)
 		RETURNING id;`,
 		user.Name,
 		email,
-		user.APIKey,
 		(*NullTime)(&user.CreatedAt),
 		(*NullTime)(&user.UpdatedAt),
 	).Scan(&id)
@@ -3,6 +3,7 @@ package todev
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 	"unicode/utf8"
 )
@@ -21,6 +22,12 @@ type Repo struct {
 	// List of the tasks attached to the repo.
 	Tasks []*Task `json:"tasks"`
 
+	// Workflow of the repo's tasks, ordered by position.
+	States []*TaskState `json:"states"`
+
+	// "Blocked by" relationships between the repo's tasks.
+	Dependencies []*TaskDependency `json:"dependencies"`
+
 	// Subscription object for recieving events from an event service.
 	Subscription Subscription `json:"subscribtion"`
 
@@ -28,15 +35,22 @@ type Repo struct {
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 
+	// Time the repo was moved to the trash. Zero if the repo is not trashed.
+	DeletedAt time.Time `json:"deletedAt"`
+
 	// Human-readable name of repo.
 	Name string `json:"name"`
 
-	// Code used to share the repo with other users.
-	InviteCode string `json:"inviteCode,omitempty"`
+	// Full name of the linked GitHub repository, in the "owner/name" form.
+	// Empty if the repo is not linked.
+	GitHubRepo string `json:"githubRepo"`
 
 	// user ID of the repo owner.
 	UserID int `json:"userID"`
 
+	// Sequence number of the latest event that occured in the repo.
+	EventSeq int `json:"eventSeq"`
+
 	ID int `json:"id"`
 }
 
@@ -50,11 +64,42 @@ func (r Repo) ContributorByUserID(userID int) *Contributor {
 	return nil
 }
 
+// StateByID returns the workflow state of the repo with the given ID.
+func (r Repo) StateByID(id int) *TaskState {
+	for _, s := range r.States {
+		if s.ID == id {
+			return s
+		}
+	}
+	return nil
+}
+
+// InitialState returns the first non-terminal state of the workflow, which
+// new tasks are placed in.
+func (r Repo) InitialState() *TaskState {
+	for _, s := range r.States {
+		if !s.IsTerminal {
+			return s
+		}
+	}
+	return nil
+}
+
+// TerminalState returns the first terminal state of the workflow.
+func (r Repo) TerminalState() *TaskState {
+	for _, s := range r.States {
+		if s.IsTerminal {
+			return s
+		}
+	}
+	return nil
+}
+
 // TasksByContributorID returns the tasks attached to contributor by the given contributor ID.
 func (r Repo) TasksByContributorID(contribID int) []*Task {
 	tasks := make([]*Task, 0, len(r.Tasks))
 	for _, t := range r.Tasks {
-		if t.ContributorID == nil || *t.ContributorID == contribID {
+		if len(t.ContributorIDs) == 0 || slices.Contains(t.ContributorIDs, contribID) {
 			tasks = append(tasks, t)
 		}
 	}
@@ -69,15 +114,14 @@ func (r Repo) Validate() error {
 		return Errorf(EINVALID, "Repo name too long.")
 	} else if r.UserID == 0 {
 		return Errorf(EINVALID, "Repo creator required.")
+	} else if r.GitHubRepo != "" {
+		if _, _, err := SplitGitHubRepo(r.GitHubRepo); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-// CanEditRepo returns true if the current user can edit the repo.
-func CanEditRepo(ctx context.Context, repo Repo) bool {
-	return repo.UserID == UserIDFromContext(ctx)
-}
-
 // RepoService represents a service for managing repos.
 type RepoService interface {
 	// Retrieves a single repo by ID along with associated contributors.
@@ -89,38 +133,81 @@ type RepoService interface {
 	// Creates a new repo and assigns the current user as the owner.
 	CreateRepo(ctx context.Context, repo *Repo) error
 
-	// Updates an existing repo by ID. Only the repo owner can update a repo.
+	// Updates an existing repo by ID. Only repo admins can update a repo.
 	UpdateRepo(ctx context.Context, id int, upd RepoUpdate) (*Repo, error)
 
-	// Permanently deletes a repo by ID. Only the repo owner can delete a repo.
+	// Moves a repo to the trash. Trashed repos are permanently deleted after
+	// a retention period. Only the repo owner can delete a repo.
 	DeleteRepo(ctx context.Context, id int) error
 
+	// Restores a repo from the trash. Only the repo owner can restore a repo.
+	RestoreRepo(ctx context.Context, id int) (*Repo, error)
+
+	// Creates tasks for the given issues of the linked GitHub repository.
+	// Issues that were imported before are matched by number and only have
+	// their description updated. Only repo admins can import issues.
+	ImportIssues(ctx context.Context, repoID int, issues []*Issue) (*IssueImport, error)
+
 	// Sets a task for the given user's contributor in a repo.
 	// SetContributorTask(ctx context.Context, repoID int, task Task) error
 
-	// TasksLeftReport returns a report of all the tasks due in this repo.
-	// TasksLeftReport(ctx context.Context, start, end time.Time, interval time.Duration) (*RepoTasksReport, error)
+	// TasksLeftReport returns a report of the uncompleted tasks of a repo
+	// at every interval between start and end.
+	TasksLeftReport(ctx context.Context, repoID int, start, end time.Time, interval time.Duration) (*RepoTasksReport, error)
 }
 
 // RepoFilter represents a filter used by FilterRepo().
 type RepoFilter struct {
 	// Filtering fields.
-	ID         *int    `json:"id"`
-	InviteCode *string `json:"inviteCode"`
+	ID *int `json:"id"`
+
+	// Restricts to trashed repos instead of live ones.
+	Trashed bool `json:"trashed"`
 
 	// Restrict to subset of range.
 	Offset int `json:"offset"`
 	Limit  int `json:"limit"`
+
+	// Resumes listing after the item the cursor points to, as returned
+	// with the previous page. Cannot be combined with Offset.
+	Cursor string `json:"cursor"`
 }
 
 // RepoUpdate represents a set of fields to update on a repo.
 type RepoUpdate struct {
 	Name *string `json:"name"`
+
+	// Links the repo to a GitHub repository. An empty string removes the
+	// link. Only the repo owner can change the link.
+	GitHubRepo *string `json:"githubRepo"`
+
+	// Replaces the workflow of the repo. States are matched by ID, states
+	// without an ID are created and missing states are removed. States are
+	// ordered as given.
+	States []*TaskState `json:"states"`
 }
 
+// Report constants.
+const (
+	// MaxRepoTasksReportRecords is the maximum number of records in a report.
+	MaxRepoTasksReportRecords = 1000
+)
+
 // RepoTasksReport represents a report generated by TasksLeftReport().
 type RepoTasksReport struct {
-	Records []*RepoTasksRecord
+	Records []*RepoTasksRecord `json:"records"`
+}
+
+// ValidateRepoTasksReport returns an error if the report range is invalid.
+func ValidateRepoTasksReport(start, end time.Time, interval time.Duration) error {
+	if interval <= 0 {
+		return Errorf(EINVALID, "Report interval must be positive.")
+	} else if end.Before(start) {
+		return Errorf(EINVALID, "Report end must be after its start.")
+	} else if end.Sub(start)/interval >= MaxRepoTasksReportRecords {
+		return Errorf(EINVALID, "Report range too large for the interval.")
+	}
+	return nil
 }
 
 // RepoTasksRecord represents all uncompleted tasks.
@@ -0,0 +1,74 @@
+package todev
+
+// Role represents the level of access a contributor has within a repo.
+type Role string
+
+// Contributor roles, from the most to the least privileged.
+const (
+	// Creator of the repo. Has full control over it, including deleting it.
+	RoleOwner Role = "owner"
+
+	// Can edit the repo, manage its tasks and manage members and viewers.
+	RoleAdmin Role = "admin"
+
+	// Can view the repo and complete tasks that are given to them.
+	RoleMember Role = "member"
+
+	// Can only view the repo.
+	RoleViewer Role = "viewer"
+)
+
+// Validate returns an error if the role is not a known role.
+func (r Role) Validate() error {
+	if r.rank() == 0 {
+		return Errorf(EINVALID, "Invalid role.")
+	}
+	return nil
+}
+
+// IsAdmin returns true if the role has administrative privileges over a repo.
+func (r Role) IsAdmin() bool {
+	return r == RoleOwner || r == RoleAdmin
+}
+
+// CanEditRepo returns true if the role allows editing the repo and its tasks.
+func (r Role) CanEditRepo() bool {
+	return r.IsAdmin()
+}
+
+// CanDeleteRepo returns true if the role allows deleting the repo.
+func (r Role) CanDeleteRepo() bool {
+	return r == RoleOwner
+}
+
+// CanCompleteTasks returns true if the role allows moving tasks through the
+// workflow, including completing them.
+func (r Role) CanCompleteTasks() bool {
+	return r.IsAdmin() || r == RoleMember
+}
+
+// CanComment returns true if the role allows commenting on tasks.
+func (r Role) CanComment() bool {
+	return r.IsAdmin() || r == RoleMember
+}
+
+// Outranks returns true if r is strictly more privileged than other.
+func (r Role) Outranks(other Role) bool {
+	return r.rank() > other.rank()
+}
+
+// rank returns the privilege level of the role. Unknown roles have no privileges.
+func (r Role) rank() int {
+	switch r {
+	case RoleOwner:
+		return 4
+	case RoleAdmin:
+		return 3
+	case RoleMember:
+		return 2
+	case RoleViewer:
+		return 1
+	default:
+		return 0
+	}
+}
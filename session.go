@@ -0,0 +1,78 @@
+package todev
+
+import (
+	"context"
+	"time"
+)
+
+// SessionTTL is how long a browser session lasts after logging in.
+const SessionTTL = 30 * 24 * time.Hour
+
+// Session represents a browser session of a logged in user.
+type Session struct {
+	ID     int `json:"id"`
+	UserID int `json:"userID"`
+
+	// Associated user. Only set by AuthenticateSession().
+	User *User `json:"-"`
+
+	// Random secret stored in the session cookie. Only set when the session
+	// is created as the secret is stored hashed.
+	Secret string `json:"-"`
+
+	// Browser the session was created from and address it was last seen from.
+	UserAgent string `json:"userAgent"`
+	IP        string `json:"ip"`
+
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Validate returns an error if the session contains invalid fields.
+func (s *Session) Validate() error {
+	if s.UserID == 0 {
+		return Errorf(EINVALID, "User required.")
+	}
+	return nil
+}
+
+// IsExpired returns true if the session cannot be used anymore at the given time.
+func (s *Session) IsExpired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// SessionService represents a service for managing browser sessions.
+type SessionService interface {
+	// Retrieves the current user's sessions based on a filter, newest first.
+	FindSessions(ctx context.Context, filter SessionFilter) ([]*Session, int, error)
+
+	// Creates a new session for the user set on the session. Only called
+	// once the user has been authenticated. On success, the secret is set on
+	// the session.
+	CreateSession(ctx context.Context, session *Session) error
+
+	// Looks up the session with the given secret along with its user and
+	// records it as seen from the given address. Returns EUNAUTHORIZED if the
+	// session has been revoked or has expired.
+	AuthenticateSession(ctx context.Context, secret, ip string) (*Session, error)
+
+	// Revokes one of the current user's sessions.
+	DeleteSession(ctx context.Context, id int) error
+
+	// Revokes all the current user's sessions, logging them out everywhere.
+	DeleteSessions(ctx context.Context) error
+}
+
+// SessionFilter represents a filter used by FindSessions().
+type SessionFilter struct {
+	ID *int `json:"id"`
+
+	// Restricts to a subset of results.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+
+	// Resumes listing after the item the cursor points to, as returned
+	// with the previous page. Cannot be combined with Offset.
+	Cursor string `json:"cursor"`
+}
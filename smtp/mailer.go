@@ -0,0 +1,75 @@
+package smtp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/saiddis/todev"
+)
+
+// Ensure mailer implements interface.
+var _ todev.Mailer = (*Mailer)(nil)
+
+// Mailer implements todev.Mailer by relaying mails through an SMTP server.
+type Mailer struct {
+	// Address of the SMTP server (e.g. "mail.example.com:587").
+	Addr string
+
+	// Credentials used to authenticate with the server. Authentication is
+	// skipped if Username is empty.
+	Username string
+	Password string
+
+	// Address mails are sent from.
+	From string
+}
+
+// NewMailer returns a new instance of Mailer.
+func NewMailer(addr, from string) *Mailer {
+	return &Mailer{Addr: addr, From: from}
+}
+
+// SendMail sends a plain text mail. The context is only checked before
+// sending as net/smtp does not support cancellation.
+func (m *Mailer) SendMail(ctx context.Context, msg *todev.Mail) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return todev.Errorf(todev.EINVALID, "Invalid recipient address.")
+	} else if strings.ContainsAny(msg.Subject, "\r\n") {
+		return todev.Errorf(todev.EINVALID, "Invalid mail subject.")
+	}
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		host, _, err := net.SplitHostPort(m.Addr)
+		if err != nil {
+			return fmt.Errorf("invalid smtp address: %w", err)
+		}
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", m.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	if err = smtp.SendMail(m.Addr, auth, m.From, []string{to.Address}, buf.Bytes()); err != nil {
+		return fmt.Errorf("error sending mail: %w", err)
+	}
+	return nil
+}
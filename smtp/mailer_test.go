@@ -0,0 +1,104 @@
+package smtp_test
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/saiddis/todev"
+	"github.com/saiddis/todev/smtp"
+)
+
+func TestMailer_SendMail(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		addr, data := MustServeSMTP(t)
+
+		m := smtp.NewMailer(addr, "todev@example.com")
+		if err := m.SendMail(context.Background(), &todev.Mail{
+			To:      "bob@gmail.com",
+			Subject: "Verify your email",
+			Body:    "Hello\nBob",
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		msg := <-data
+		if !strings.Contains(msg, "To: <bob@gmail.com>\r\n") {
+			t.Fatalf("missing recipient: %q", msg)
+		} else if !strings.Contains(msg, "Subject: Verify your email\r\n") {
+			t.Fatalf("missing subject: %q", msg)
+		} else if !strings.HasSuffix(msg, "\r\nHello\r\nBob\r\n") {
+			t.Fatalf("unexpected body: %q", msg)
+		}
+	})
+
+	// Ensure headers cannot be injected through the subject.
+	t.Run("ErrInvalidSubject", func(t *testing.T) {
+		m := smtp.NewMailer("localhost:0", "todev@example.com")
+		if err := m.SendMail(context.Background(), &todev.Mail{
+			To:      "bob@gmail.com",
+			Subject: "Hi\r\nBcc: eve@gmail.com",
+		}); todev.ErrorCode(err) != todev.EINVALID {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+// MustServeSMTP starts a stand-in SMTP server accepting a single mail. Returns
+// its address and a channel receiving the mail data.
+func MustServeSMTP(tb testing.TB) (string, <-chan string) {
+	tb.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { ln.Close() })
+
+	data := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			switch cmd := strings.ToUpper(strings.Fields(line)[0]); cmd {
+			case "EHLO", "HELO", "MAIL", "RCPT", "RSET", "NOOP":
+				reply("250 OK")
+			case "DATA":
+				reply("354 Go ahead")
+				var msg strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					} else if line == ".\r\n" {
+						break
+					}
+					msg.WriteString(line)
+				}
+				data <- msg.String()
+				reply("250 OK")
+			case "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("502 Unknown command")
+			}
+		}
+	}()
+
+	return ln.Addr().String(), data
+}
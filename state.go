@@ -0,0 +1,80 @@
+package todev
+
+import (
+	"unicode/utf8"
+)
+
+// Task state constants.
+const (
+	MaxTaskStates       = 10
+	MaxTaskStateNameLen = 32
+)
+
+// TaskState represents a column of a repo's task workflow. Tasks move through
+// the states of their repo in order, and are completed once they reach one of
+// the terminal states.
+type TaskState struct {
+	ID     int `json:"id"`
+	RepoID int `json:"repoID"`
+
+	// Human-readable name of the state, unique within the repo.
+	Name string `json:"name"`
+
+	// Position of the state within the workflow, starting from zero.
+	Position int `json:"position"`
+
+	// Set if tasks in this state are considered completed.
+	IsTerminal bool `json:"isTerminal"`
+}
+
+// Validate returns an error if the state contains invalid fields.
+func (s TaskState) Validate() error {
+	if s.Name == "" {
+		return Errorf(EINVALID, "State name required.")
+	} else if utf8.RuneCountInString(s.Name) > MaxTaskStateNameLen {
+		return Errorf(EINVALID, "State name too long.")
+	}
+	return nil
+}
+
+// DefaultTaskStates returns the workflow given to newly created repos.
+func DefaultTaskStates() []*TaskState {
+	return []*TaskState{
+		{Name: "To do", Position: 0},
+		{Name: "In progress", Position: 1},
+		{Name: "Done", Position: 2, IsTerminal: true},
+	}
+}
+
+// ValidateTaskStates returns an error if states do not form a valid workflow.
+// A workflow needs unique state names, at least one state to start tasks in
+// and at least one terminal state.
+func ValidateTaskStates(states []*TaskState) error {
+	if len(states) > MaxTaskStates {
+		return Errorf(EINVALID, "Too many states.")
+	}
+
+	names := make(map[string]struct{}, len(states))
+	var open, terminal int
+	for _, state := range states {
+		if err := state.Validate(); err != nil {
+			return err
+		} else if _, ok := names[state.Name]; ok {
+			return Errorf(EINVALID, "State names must be unique.")
+		}
+		names[state.Name] = struct{}{}
+
+		if state.IsTerminal {
+			terminal++
+		} else {
+			open++
+		}
+	}
+
+	if open == 0 {
+		return Errorf(EINVALID, "At least one non-terminal state required.")
+	} else if terminal == 0 {
+		return Errorf(EINVALID, "At least one terminal state required.")
+	}
+	return nil
+}
@@ -2,6 +2,8 @@ package todev
 
 import (
 	"context"
+	"slices"
+	"strings"
 	"time"
 	"unicode/utf8"
 )
@@ -9,6 +11,16 @@ import (
 // Task constants.
 const (
 	MaxTaskDescriptionLen = 150
+	MaxTaskLabels         = 10
+	MaxTaskLabelLen       = 30
+)
+
+// Task priorities, from the least to the most urgent.
+const (
+	TaskPriorityNone = iota
+	TaskPriorityLow
+	TaskPriorityMedium
+	TaskPriorityHigh
 )
 
 // Task represents a task that is added by the owner of the repo.
@@ -31,14 +43,72 @@ type Task struct {
 
 	ID int `json:"id"`
 
-	// To indicate whether the task is done or not.
+	// Workflow state the task is currently in.
+	StateID int `json:"stateID"`
+
+	// Set if the task is in a terminal state of the workflow.
 	IsCompleted bool `json:"isCompleted"`
+
+	// Time the task was last completed. Zero if the task is not completed.
+	CompletedAt time.Time `json:"completedAt"`
+
+	// Time the task should be completed by. Zero if the task has no deadline.
+	DueAt time.Time `json:"dueAt"`
+
+	// Time the task was moved to the trash. Zero if the task is not trashed.
+	DeletedAt time.Time `json:"deletedAt"`
+
+	// Urgency of the task. One of the TaskPriority constants.
+	Priority int `json:"priority"`
+
+	// Free-form labels used to group tasks. Labels are shared by all the
+	// tasks of a repo.
+	Labels []string `json:"labels"`
+
+	// Ordered steps of the task.
+	Checklist []*ChecklistItem `json:"checklist"`
+
+	// IDs of the tasks that have to be completed before this one.
+	BlockedByIDs []int `json:"blockedByIDs"`
+
+	// Set if any of the blocking tasks is not completed yet.
+	IsBlocked bool `json:"isBlocked"`
+
+	// Number of the GitHub issue the task was imported from. Zero if the
+	// task was not imported.
+	IssueNumber int `json:"issueNumber"`
+
+	// HTML description of the task with the terms matching a search query
+	// wrapped in <mark> tags. Only set by searches.
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// ChecklistProgress returns the number of checked items and the total number
+// of items in the task's checklist.
+func (t Task) ChecklistProgress() (checked, total int) {
+	for _, item := range t.Checklist {
+		if item.IsChecked {
+			checked++
+		}
+	}
+	return checked, len(t.Checklist)
+}
+
+// IsLeftAt returns true if the task existed and was not yet completed at the
+// given time.
+func (t Task) IsLeftAt(at time.Time) bool {
+	if t.CreatedAt.After(at) {
+		return false
+	}
+	return !t.IsCompleted || t.CompletedAt.IsZero() || t.CompletedAt.After(at)
 }
 
 const (
 	TasksSortByUpdatedAtDesc     = "updated_at_desc"
 	TasksSortByCreatedAtDesc     = "created_at_desc"
 	TasksSortByIsCompletedAtDesc = "is_completed_at_desc"
+	TasksSortByDueAtAsc          = "due_at_asc"
+	TasksSortByPriorityDesc      = "priority_desc"
 )
 
 // Validte retruns an error if a task has invalid fields.
@@ -49,13 +119,45 @@ func (t Task) Validate() error {
 		return Errorf(EINVALID, "Task description required.")
 	} else if utf8.RuneCountInString(t.Description) > MaxTaskDescriptionLen {
 		return Errorf(EINVALID, "Task description too long.")
+	} else if t.Priority < TaskPriorityNone || t.Priority > TaskPriorityHigh {
+		return Errorf(EINVALID, "Invalid task priority.")
+	} else if len(t.Labels) > MaxTaskLabels {
+		return Errorf(EINVALID, "Too many task labels.")
+	}
+
+	for _, label := range t.Labels {
+		if len(label) == 0 {
+			return Errorf(EINVALID, "Task label required.")
+		} else if utf8.RuneCountInString(label) > MaxTaskLabelLen {
+			return Errorf(EINVALID, "Task label too long.")
+		}
 	}
 	return nil
 }
 
-// CanEditTask returns true if the current user can edit the repo.
-func CanEditTask(ctx context.Context, task Task) bool {
-	return task.OwnerID == UserIDFromContext(ctx)
+// NormalizeTaskLabels trims the whitespace around labels and removes empty and
+// duplicate labels. Labels are returned in sorted order.
+func NormalizeTaskLabels(labels []string) []string {
+	other := make([]string, 0, len(labels))
+	for _, label := range labels {
+		if label = strings.TrimSpace(label); label != "" {
+			other = append(other, label)
+		}
+	}
+	slices.Sort(other)
+	return slices.Compact(other)
+}
+
+// CanSetTaskStatus returns true if a contributor with the given ID and role can
+// move a task to another workflow state. Admins can move any task while
+// members can only move unassigned tasks or tasks given to them.
+func CanSetTaskStatus(role Role, contributorID int, task Task) bool {
+	if role.IsAdmin() {
+		return true
+	} else if !role.CanCompleteTasks() {
+		return false
+	}
+	return len(task.ContributorIDs) == 0 || slices.Contains(task.ContributorIDs, contributorID)
 }
 
 // TaskService represents a service for managing a task.
@@ -69,17 +171,43 @@ type TaskService interface {
 	// Creates a new task.
 	CreateTask(ctx context.Context, task *Task) error
 
-	// Updates an existing task by ID. Only the repo owner can update a task.
+	// Updates an existing task by ID. Only repo admins can update a task while
+	// members can change the status of their own tasks.
 	UpdateTask(ctx context.Context, id int, upd TaskUpdate) (*Task, error)
 
-	// Permanently deletes a taks by ID. Only the repo owner can delete a task.
+	// Moves a task to the trash of its repo. Trashed tasks are permanently
+	// deleted after a retention period. Only repo admins can delete a task.
 	DeleteTask(ctx context.Context, id int) error
 
+	// Restores a task from the trash. Only repo admins can restore a task.
+	RestoreTask(ctx context.Context, id int) (*Task, error)
+
 	// Give a specific contributor a task by attaching contributorID on task.
 	AttachContributor(ctx context.Context, task *Task, contributorID int) error
 
 	// Take a task from a specific contributor a task by unattaching contributorID from task.
 	UnattachContributor(ctx context.Context, task *Task, contributorID int) error
+
+	// Appends a new item to the checklist of a task. Only repo admins can
+	// add items.
+	CreateChecklistItem(ctx context.Context, item *ChecklistItem) error
+
+	// Updates a checklist item by ID. Only repo admins can edit or move
+	// items while members can check the items of their own tasks.
+	UpdateChecklistItem(ctx context.Context, id int, upd ChecklistItemUpdate) (*ChecklistItem, error)
+
+	// Permanently deletes a checklist item by ID. Only repo admins can
+	// delete items.
+	DeleteChecklistItem(ctx context.Context, id int) error
+
+	// Marks a task as blocked by another task of the same repo. Returns
+	// EINVALID if the dependency would create a cycle. Only repo admins can
+	// add dependencies.
+	AddDependency(ctx context.Context, taskID, blockerID int) error
+
+	// Removes a dependency between two tasks. Only repo admins can remove
+	// dependencies.
+	RemoveDependency(ctx context.Context, taskID, blockerID int) error
 }
 
 // TaskFilter represents a filter used by FindTasks().
@@ -88,18 +216,53 @@ type TaskFilter struct {
 	UserID        *int  `json:"userID"`
 	ContributorID *int  `json:"contributorID"`
 	RepoID        *int  `json:"repoID"`
+	StateID       *int  `json:"stateID"`
 	IsCompleted   *bool `json:"isCompleted"`
 
+	// Restricts to tasks with or without uncompleted blockers. Ready tasks
+	// are uncompleted tasks that are not blocked.
+	IsBlocked *bool `json:"isBlocked"`
+	IsReady   *bool `json:"isReady"`
+
+	// Restricts to tasks due before or after the given times. Tasks without
+	// a due date never match.
+	DueBefore *time.Time `json:"dueBefore"`
+	DueAfter  *time.Time `json:"dueAfter"`
+
+	Priority *int    `json:"priority"`
+	Label    *string `json:"label"`
+
+	// Full-text search over task descriptions. Supports quoted phrases, "or"
+	// and "-" to exclude terms. Results are ranked by relevance unless
+	// SortBy is set.
+	Query *string `json:"query"`
+
+	// Restricts to trashed tasks instead of live ones.
+	Trashed bool `json:"trashed"`
+
 	// Restricts to a subset of results.
 	Offset int `json:"offset"`
 	Limit  int `json:"limit"`
 
+	// Resumes listing after the item the cursor points to, as returned
+	// with the previous page. Cannot be combined with Offset.
+	Cursor string `json:"cursor"`
+
 	// Sorting option for results.
 	SortBy string `json:"sortBy"`
 }
 
 // TaskUpdate represents a set of fields to update on a task.
 type TaskUpdate struct {
-	Description      *string `json:"description"`
-	ToggleCompletion bool    `json:"toggleCompletion"`
+	Description *string `json:"description"`
+
+	// ID of the workflow state to move the task to.
+	SetStatus *int `json:"setStatus"`
+
+	// Setting DueAt to the zero time removes the due date.
+	DueAt    *time.Time `json:"dueAt"`
+	Priority *int       `json:"priority"`
+
+	// Replaces all the labels of the task.
+	Labels *[]string `json:"labels"`
 }
@@ -0,0 +1,138 @@
+package todev
+
+import (
+	"context"
+	"time"
+)
+
+// Scopes restricting what a personal access token can be used for. Write
+// scopes also grant read access to their resources.
+const (
+	// Full access, including managing the user, auths and tokens.
+	ScopeAll = "all"
+
+	// Read-only access to everything the user can see.
+	ScopeRead = "read"
+
+	// Create, update and delete repos, contributors and invitations.
+	ScopeReposWrite = "repos:write"
+
+	// Create, update and delete tasks, checklists and comments.
+	ScopeTasksWrite = "tasks:write"
+)
+
+// Scopes lists the valid token scopes.
+var Scopes = []string{ScopeAll, ScopeRead, ScopeReposWrite, ScopeTasksWrite}
+
+// TokenPrefix starts the secret of every personal access token so leaked
+// tokens are easy to recognize.
+const TokenPrefix = "todev_"
+
+// MaxTokenNameLen is the maximum length of a token name.
+const MaxTokenNameLen = 64
+
+// Token represents a personal access token used by clients such as the CLI
+// to authenticate as a user.
+type Token struct {
+	ID     int `json:"id"`
+	UserID int `json:"userID"`
+
+	// Associated user. Only set by AuthenticateToken().
+	User *User `json:"-"`
+
+	// Name chosen by the user to recognize the token.
+	Name string `json:"name"`
+
+	// What the token can be used for. Must contain at least one scope.
+	Scopes []string `json:"scopes"`
+
+	// Plaintext secret. Only set when the token is created as the secret is
+	// stored hashed.
+	Secret string `json:"secret,omitempty"`
+
+	// Time after which the token can no longer be used. Zero never expires.
+	ExpiresAt time.Time `json:"expiresAt"`
+
+	// Last time the token authenticated a request. Zero if never used.
+	LastUsedAt time.Time `json:"lastUsedAt"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Validate returns an error if the token contains invalid fields.
+func (t *Token) Validate() error {
+	if t.UserID == 0 {
+		return Errorf(EINVALID, "User required.")
+	} else if t.Name == "" {
+		return Errorf(EINVALID, "Token name required.")
+	} else if len(t.Name) > MaxTokenNameLen {
+		return Errorf(EINVALID, "Token name too long.")
+	} else if len(t.Scopes) == 0 {
+		return Errorf(EINVALID, "Token scope required.")
+	}
+
+	for _, scope := range t.Scopes {
+		if !IsValidScope(scope) {
+			return Errorf(EINVALID, "Invalid token scope: %q.", scope)
+		}
+	}
+	return nil
+}
+
+// IsValidScope returns true if scope is one of the token scopes.
+func IsValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows returns true if the token grants the given scope. Read-only access
+// is also granted by the read scope.
+func (t *Token) Allows(scope string, readOnly bool) bool {
+	for _, s := range t.Scopes {
+		if s == ScopeAll || s == scope || (readOnly && s == ScopeRead) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired returns true if the token cannot be used anymore at the given time.
+func (t *Token) IsExpired(now time.Time) bool {
+	return !t.ExpiresAt.IsZero() && !now.Before(t.ExpiresAt)
+}
+
+// TokenService represents a service for managing personal access tokens.
+type TokenService interface {
+	// Retrieves the current user's tokens based on a filter. Secrets are
+	// never returned.
+	FindTokens(ctx context.Context, filter TokenFilter) ([]*Token, int, error)
+
+	// Creates a new token for the current user. On success, the secret is
+	// set on the token.
+	CreateToken(ctx context.Context, token *Token) error
+
+	// Permanently revokes one of the current user's tokens.
+	DeleteToken(ctx context.Context, id int) error
+
+	// Looks up the token with the given secret along with its user and
+	// records it as used. Returns EUNAUTHORIZED if the secret is invalid or
+	// the token has expired.
+	AuthenticateToken(ctx context.Context, secret string) (*Token, error)
+}
+
+// TokenFilter represents a filter used by FindTokens().
+type TokenFilter struct {
+	ID *int `json:"id"`
+
+	// Restricts to a subset of results.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+
+	// Resumes listing after the item the cursor points to, as returned
+	// with the previous page. Cannot be combined with Offset.
+	Cursor string `json:"cursor"`
+}
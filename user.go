@@ -18,7 +18,8 @@ type User struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
 
-	// Randomly generated API key for use with the CLI.
+	// Personal access token used by clients such as the CLI to
+	// authenticate as the user. Never set by the server.
 	APIKey string `json:"-"`
 
 	ID int `json:"id"`
@@ -36,8 +37,8 @@ func (u User) Validate() error {
 // AvatarURL retruns a URL to the avatar image for the user.
 func (u User) AvatarURL() string {
 	for _, auth := range u.Auths {
-		if s := auth.AvatarURL(); s != "" {
-			return s
+		if auth.AvatarURL != "" {
+			return auth.AvatarURL
 		}
 	}
 	return ""
@@ -64,13 +65,16 @@ type UserService interface {
 // UserFilter represents a filter to FindUsers().
 type UserFilter struct {
 	// Filtering fields
-	ID     *int    `json:"id"`
-	Email  *string `json:"email"`
-	APIKey *string `json:"apiKey"`
+	ID    *int    `json:"id"`
+	Email *string `json:"email"`
 
 	// Restrict to subset of results.
 	Offset int `json:"offset"`
 	Limit  int `json:"limit"`
+
+	// Resumes listing after the item the cursor points to, as returned
+	// with the previous page. Cannot be combined with Offset.
+	Cursor string `json:"cursor"`
 }
 
 // UserUpdate represents a set of fields to be updated via UpdateUser().